@@ -0,0 +1,751 @@
+package docx
+
+import (
+    "encoding/xml"
+    "fmt"
+    "math"
+    "path"
+    "strconv"
+)
+
+const (
+    SectionNextPage   = "nextPage"
+    SectionContinuous = "continuous"
+    SectionEvenPage   = "evenPage"
+    SectionOddPage    = "oddPage"
+    SectionNextColumn = "nextColumn"
+)
+
+
+type Node interface {
+    node()
+}
+
+
+type Block interface {
+    Node
+    block() interface{}
+}
+
+
+type Visitor interface {
+    Visit(node Node) (w Visitor)
+}
+
+
+type sectionBreakProperties struct {
+    XMLName xml.Name `xml:"w:pPr"`
+    SectPr  *sectPr  `xml:"w:sectPr"`
+}
+
+type sectionBreakData struct {
+    XMLName    xml.Name               `xml:"w:p"`
+    Properties sectionBreakProperties `xml:"w:pPr"`
+    breakType  string
+}
+
+
+type SectionBreak struct {
+    doc  *DocxDocument
+    data *sectionBreakData
+}
+
+
+type blockList struct {
+    doc    *DocxDocument
+    blocks *[]interface{}
+    cell   bool
+}
+
+
+type Body struct {
+    blockList
+}
+
+
+func (*Body) node()         {}
+func (*Paragraph) node()    {}
+func (*Run) node()          {}
+func (*Table) node()        {}
+func (*TableRow) node()     {}
+func (*TableCell) node()    {}
+func (*SectionBreak) node() {}
+
+
+func (p *Paragraph) block() interface{} {
+    return p.data
+}
+
+
+func (s *SectionBreak) block() interface{} {
+    return s.data
+}
+
+
+func (d *DocxDocument) Body() *Body {
+    return &Body{blockList{doc: d, blocks: &d.content}}
+}
+
+
+func (d *DocxDocument) paragraphs() []*paragraphData {
+    return collectParagraphs(d.content, nil)
+}
+
+
+func collectParagraphs(blocks []interface{}, paras []*paragraphData) []*paragraphData {
+    for _, block := range blocks {
+        switch v := block.(type) {
+        case *paragraphData:
+            paras = append(paras, v)
+        case *tableData:
+            for _, row := range v.Rows {
+                for _, cell := range row.Cells {
+                    paras = collectParagraphs(cell.Blocks, paras)
+                }
+            }
+        case *containerElement:
+            paras = collectParagraphs(v.Content, paras)
+        }
+    }
+    return paras
+}
+
+
+func (l blockList) wrap(block interface{}) Block {
+    switch v := block.(type) {
+    case *paragraphData:
+        return &Paragraph{doc: l.doc, data: v}
+    case *tableData:
+        return &Table{doc: l.doc, data: v}
+    case *sectionBreakData:
+        return &SectionBreak{doc: l.doc, data: v}
+    }
+    return nil
+}
+
+
+func (l blockList) positions() []int {
+    positions := []int{}
+    for i, block := range *l.blocks {
+        if l.wrap(block) != nil {
+            positions = append(positions, i)
+        }
+    }
+    return positions
+}
+
+
+func (l blockList) Len() int {
+    return len(l.positions())
+}
+
+
+func (l blockList) Blocks() []Block {
+    blocks := []Block{}
+    for _, block := range *l.blocks {
+        if b := l.wrap(block); b != nil {
+            blocks = append(blocks, b)
+        }
+    }
+    return blocks
+}
+
+
+func (l blockList) Block(index int) Block {
+    positions := l.positions()
+    if index < 0 || index >= len(positions) {
+        return nil
+    }
+    return l.wrap((*l.blocks)[positions[index]])
+}
+
+
+func (l blockList) Paragraphs() []*Paragraph {
+    paras := []*Paragraph{}
+    for _, block := range *l.blocks {
+        if para, ok := block.(*paragraphData); ok {
+            paras = append(paras, &Paragraph{doc: l.doc, data: para})
+        }
+    }
+    return paras
+}
+
+
+func (l blockList) Tables() []*Table {
+    tables := []*Table{}
+    for _, block := range *l.blocks {
+        if table, ok := block.(*tableData); ok {
+            tables = append(tables, &Table{doc: l.doc, data: table})
+        }
+    }
+    return tables
+}
+
+
+func (l blockList) IndexOf(block Block) int {
+    if block == nil {
+        return -1
+    }
+    target := block.block()
+    for i, position := range l.positions() {
+        if (*l.blocks)[position] == target {
+            return i
+        }
+    }
+    return -1
+}
+
+
+func (l blockList) insert(index int, block interface{}) error {
+    blocks := *l.blocks
+    positions := l.positions()
+    if index < 0 || index > len(positions) {
+        return fmt.Errorf("block index %d out of range [0, %d]", index, len(positions))
+    }
+    raw := len(blocks)
+    if index < len(positions) {
+        raw = positions[index]
+    }
+    blocks = append(blocks, nil)
+    copy(blocks[raw+1:], blocks[raw:])
+    blocks[raw] = block
+    *l.blocks = blocks
+    return nil
+}
+
+
+func (l blockList) InsertParagraph(index int, style string) (*Paragraph, error) {
+    para := &paragraphData{Properties: newParagraphProperties(style)}
+    if err := l.insert(index, para); err != nil {
+        return nil, err
+    }
+    return &Paragraph{doc: l.doc, data: para}, nil
+}
+
+
+func (l blockList) InsertTable(index int, rows int, cols int) (*Table, error) {
+    table := newTableData(rows, cols)
+    if err := l.insert(index, table); err != nil {
+        return nil, err
+    }
+    return &Table{doc: l.doc, data: table}, nil
+}
+
+
+func (l blockList) InsertSectionBreak(index int, breakType string) (*SectionBreak, error) {
+    if l.cell {
+        return nil, fmt.Errorf("section breaks cannot be inserted into a table cell")
+    }
+    data, err := newSectionBreakData(breakType)
+    if err != nil {
+        return nil, err
+    }
+    if err := l.insert(index, data); err != nil {
+        return nil, err
+    }
+    brk := &SectionBreak{doc: l.doc, data: data}
+    brk.attach()
+    return brk, nil
+}
+
+
+func (l blockList) InsertBlock(index int, block Block) error {
+    if block == nil {
+        return fmt.Errorf("cannot insert a nil block")
+    }
+    if l.IndexOf(block) >= 0 {
+        return fmt.Errorf("block is already part of this container")
+    }
+    if _, ok := block.(*SectionBreak); ok && l.cell {
+        return fmt.Errorf("section breaks cannot be inserted into a table cell")
+    }
+    for _, doc := range []*DocxDocument{l.doc, blockDocument(block)} {
+        if doc != nil && containsBlock(doc.content, block.block()) {
+            return fmt.Errorf("block is already part of another container: remove it first")
+        }
+    }
+    if err := l.insert(index, block.block()); err != nil {
+        return err
+    }
+    if brk, ok := block.(*SectionBreak); ok {
+        (&SectionBreak{doc: l.doc, data: brk.data}).attach()
+    }
+    return nil
+}
+
+
+func blockDocument(block Block) *DocxDocument {
+    switch v := block.(type) {
+    case *Paragraph:
+        return v.doc
+    case *Table:
+        return v.doc
+    case *SectionBreak:
+        return v.doc
+    }
+    return nil
+}
+
+
+func containsBlock(blocks []interface{}, target interface{}) bool {
+    for _, block := range blocks {
+        if block == target {
+            return true
+        }
+        switch v := block.(type) {
+        case *tableData:
+            for _, row := range v.Rows {
+                for _, cell := range row.Cells {
+                    if containsBlock(cell.Blocks, target) {
+                        return true
+                    }
+                }
+            }
+        case *containerElement:
+            if containsBlock(v.Content, target) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+
+func (l blockList) Remove(index int) (Block, error) {
+    blocks := *l.blocks
+    positions := l.positions()
+    if index < 0 || index >= len(positions) {
+        return nil, fmt.Errorf("block index %d out of range [0, %d)", index, len(positions))
+    }
+    raw := positions[index]
+    removed := blocks[raw]
+    if brk, ok := removed.(*sectionBreakData); ok {
+        (&SectionBreak{doc: l.doc, data: brk}).detach()
+    }
+    blocks = append(blocks[:raw], blocks[raw+1:]...)
+    if l.cell {
+        if n := len(blocks); n == 0 {
+            blocks = append(blocks, &paragraphData{})
+        } else if _, ok := blocks[n-1].(*paragraphData); !ok {
+            blocks = append(blocks, &paragraphData{})
+        }
+    }
+    *l.blocks = blocks
+    return l.wrap(removed), nil
+}
+
+
+func (l blockList) RemoveBlock(block Block) error {
+    index := l.IndexOf(block)
+    if index < 0 {
+        return fmt.Errorf("block is not part of this container")
+    }
+    _, err := l.Remove(index)
+    return err
+}
+
+
+func (l blockList) Move(from int, to int) error {
+    positions := l.positions()
+    if from < 0 || from >= len(positions) {
+        return fmt.Errorf("block index %d out of range [0, %d)", from, len(positions))
+    }
+    if to < 0 || to >= len(positions) {
+        return fmt.Errorf("block index %d out of range [0, %d)", to, len(positions))
+    }
+    if from == to {
+        return nil
+    }
+
+    blocks := *l.blocks
+    raw := positions[from]
+    block := blocks[raw]
+    brk, isBreak := block.(*sectionBreakData)
+    if isBreak {
+        (&SectionBreak{doc: l.doc, data: brk}).detach()
+    }
+    *l.blocks = append(blocks[:raw], blocks[raw+1:]...)
+    if err := l.insert(to, block); err != nil {
+        return err
+    }
+    if isBreak {
+        (&SectionBreak{doc: l.doc, data: brk}).attach()
+    }
+    return nil
+}
+
+
+func newSectionBreakData(breakType string) (*sectionBreakData, error) {
+    switch breakType {
+    case "":
+        breakType = SectionNextPage
+    case SectionNextPage, SectionContinuous, SectionEvenPage, SectionOddPage, SectionNextColumn:
+    default:
+        return nil, fmt.Errorf("unsupported section break type: %s", breakType)
+    }
+    return &sectionBreakData{Properties: sectionBreakProperties{SectPr: newSectionProperties()}, breakType: breakType}, nil
+}
+
+
+func (d *DocxDocument) AddSectionBreak(breakType string) (*SectionBreak, error) {
+    data, err := newSectionBreakData(breakType)
+    if err != nil {
+        return nil, err
+    }
+    d.content = append(d.content, data)
+    brk := &SectionBreak{doc: d, data: data}
+    brk.attach()
+    return brk, nil
+}
+
+
+func (s *SectionBreak) Type() string {
+    if next := s.following(false); next != nil {
+        return sectionStartType(next)
+    }
+    if s.data.breakType != "" {
+        return s.data.breakType
+    }
+    return SectionNextPage
+}
+
+
+func (s *SectionBreak) SetType(breakType string) error {
+    data, err := newSectionBreakData(breakType)
+    if err != nil {
+        return err
+    }
+    if next := s.following(true); next != nil {
+        setSectionStartType(next, data.breakType)
+        return nil
+    }
+    s.data.breakType = data.breakType
+    return nil
+}
+
+
+func (s *SectionBreak) following(create bool) interface{} {
+    if s.doc == nil {
+        return nil
+    }
+    markers := sectionMarkers(s.doc.content, nil)
+    for i, marker := range markers {
+        if marker != s.data {
+            continue
+        }
+        if i+1 < len(markers) {
+            if next, ok := markers[i+1].(*sectionBreakData); ok {
+                if next.Properties.SectPr == nil {
+                    if !create {
+                        return &sectPr{}
+                    }
+                    next.Properties.SectPr = &sectPr{}
+                }
+                return next.Properties.SectPr
+            }
+            return markers[i+1]
+        }
+        if s.doc.sectPr == nil {
+            if !create {
+                return &sectPr{}
+            }
+            s.doc.sectPr = &sectPr{}
+        }
+        return s.doc.sectPr
+    }
+    return nil
+}
+
+
+func (s *SectionBreak) attach() {
+    next := s.following(true)
+    if next == nil {
+        return
+    }
+    if s.data.Properties.SectPr == nil {
+        s.data.Properties.SectPr = newSectionProperties()
+    }
+    breakType := s.data.breakType
+    if breakType == "" {
+        breakType = SectionNextPage
+    }
+    s.data.Properties.SectPr.Type = &sectionType{Val: sectionStartType(next)}
+    setSectionStartType(next, breakType)
+    s.data.breakType = ""
+}
+
+
+func (s *SectionBreak) detach() {
+    next := s.following(true)
+    if next == nil {
+        return
+    }
+    s.data.breakType = sectionStartType(next)
+    setSectionStartType(next, sectionBreakType(s.data.Properties.SectPr))
+}
+
+
+func sectionMarkers(blocks []interface{}, markers []interface{}) []interface{} {
+    for _, block := range blocks {
+        switch v := block.(type) {
+        case *sectionBreakData:
+            markers = append(markers, v)
+        case *paragraphData:
+            if v.Properties == nil {
+                continue
+            }
+            for _, extra := range v.Properties.Extra {
+                if extra.XMLName.Local == "w:sectPr" {
+                    markers = append(markers, extra)
+                }
+            }
+        case *containerElement:
+            markers = sectionMarkers(v.Content, markers)
+        }
+    }
+    return markers
+}
+
+
+func sectionStartType(props interface{}) string {
+    switch v := props.(type) {
+    case *sectPr:
+        return sectionBreakType(v)
+    case *rawXML:
+        if el := childByLocalName(v, "type"); el != nil {
+            if value, ok := attrByLocalName(el, "val"); ok && value != "" {
+                return value
+            }
+        }
+    }
+    return SectionNextPage
+}
+
+
+func setSectionStartType(props interface{}, breakType string) {
+    switch v := props.(type) {
+    case *sectPr:
+        v.Type = &sectionType{Val: breakType}
+    case *rawXML:
+        if el := childByLocalName(v, "type"); el != nil {
+            el.Attrs = newValueElement("w:type", breakType).Attrs
+            return
+        }
+        index := 0
+        for i, child := range v.Children {
+            if el, ok := child.(*rawXML); ok {
+                switch localName(el.XMLName.Local) {
+                case "headerReference", "footerReference", "footnotePr", "endnotePr":
+                    index = i + 1
+                }
+            }
+        }
+        v.Children = append(v.Children[:index], append([]interface{}{newValueElement("w:type", breakType)}, v.Children[index:]...)...)
+    }
+}
+
+
+type documentSection struct {
+    blocks []interface{}
+    props  *sectPr
+}
+
+
+type documentPart struct {
+    name   string
+    rels   []relationship
+    blocks []interface{}
+}
+
+
+type pageSetup struct {
+    width  float64
+    height float64
+    top    float64
+    right  float64
+    bottom float64
+    left   float64
+    header float64
+    footer float64
+}
+
+
+
+
+func splitSections(content []interface{}, final *sectPr) []documentSection {
+    sections := []documentSection{}
+    start := 0
+    for i, block := range content {
+        switch v := block.(type) {
+        case *sectionBreakData:
+            sections = append(sections, documentSection{blocks: content[start:i], props: v.Properties.SectPr})
+            start = i + 1
+        case *paragraphData:
+            if v.Properties == nil {
+                continue
+            }
+            for _, extra := range v.Properties.Extra {
+                if extra.XMLName.Local == "w:sectPr" {
+                    sections = append(sections, documentSection{blocks: content[start : i+1], props: newDocumentReader(nil).readSectionProperties(extra)})
+                    start = i + 1
+                    break
+                }
+            }
+        }
+    }
+    return append(sections, documentSection{blocks: content[start:], props: final})
+}
+
+
+func sectionBreakType(props *sectPr) string {
+    if props == nil || props.Type == nil || props.Type.Val == "" {
+        return SectionNextPage
+    }
+    return props.Type.Val
+}
+
+
+
+
+func newPageSetup(props *sectPr) pageSetup {
+    setup := pageSetup{width: 612, height: 792, top: 72, right: 72, bottom: 72, left: 72, header: 36, footer: 36}
+    if props == nil {
+        return setup
+    }
+    if props.PgSz != nil && props.PgSz.W > 0 && props.PgSz.H > 0 {
+        setup.width, setup.height = float64(props.PgSz.W)/20, float64(props.PgSz.H)/20
+    }
+    if m := props.PgMar; m != nil {
+        setup.top, setup.bottom = math.Abs(float64(m.Top))/20, math.Abs(float64(m.Bottom))/20
+        setup.left, setup.right = float64(m.Left+m.Gutter)/20, float64(m.Right)/20
+        setup.header, setup.footer = float64(m.Header)/20, float64(m.Footer)/20
+    }
+    for _, extra := range props.Extra {
+        switch extra.XMLName.Local {
+        case "w:pgSz":
+            if w, ok := attrUint(extra, "w:w"); ok && w > 0 {
+                setup.width = float64(w) / 20
+            }
+            if h, ok := attrUint(extra, "w:h"); ok && h > 0 {
+                setup.height = float64(h) / 20
+            }
+        case "w:pgMar":
+            for _, margin := range []struct {
+                name   string
+                target *float64
+            }{
+                {"w:top", &setup.top}, {"w:right", &setup.right}, {"w:bottom", &setup.bottom}, {"w:left", &setup.left},
+                {"w:header", &setup.header}, {"w:footer", &setup.footer},
+            } {
+                if n, err := strconv.Atoi(attrValue(extra, margin.name)); err == nil {
+                    *margin.target = math.Abs(float64(n)) / 20
+                }
+            }
+        }
+    }
+    return setup
+}
+
+
+func loadDocumentPart(doc Document, rID string) *documentPart {
+    src := doc.getSourcePackage()
+    rel, ok := doc.getRelationship(rID)
+    if src == nil || !ok || rel.TargetMode == "External" {
+        return nil
+    }
+    name := src.resolveTarget(rel.Target)
+    data, ok := src.parts[name]
+    if !ok {
+        return nil
+    }
+    root, _, err := newDocumentReader(nil).parseTree(data)
+    if err != nil {
+        return nil
+    }
+
+    part := &documentPart{name: name, blocks: newDocumentReader(nil).readBlocks(root.elements())}
+    if data, ok := src.parts[path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")]; ok {
+        var rels relationships
+        if err := xml.Unmarshal(data, &rels); err == nil {
+            part.rels = rels.Relationships
+        }
+    }
+    return part
+}
+
+
+func (p *documentPart) relationship(doc Document, rID string) (relationship, bool) {
+    if p == nil {
+        return doc.getRelationship(rID)
+    }
+    for _, rel := range p.rels {
+        if rel.ID == rID {
+            return rel, true
+        }
+    }
+    return relationship{}, false
+}
+
+
+func (p *documentPart) imageData(doc Document, rID string) (string, []byte, bool) {
+    if p == nil {
+        return doc.getImageData(rID)
+    }
+    rel, ok := p.relationship(doc, rID)
+    src := doc.getSourcePackage()
+    if !ok || src == nil || rel.TargetMode == "External" {
+        return "", nil, false
+    }
+    name := path.Join(path.Dir(p.name), rel.Target)
+    data, ok := src.parts[name]
+    return name, data, ok
+}
+
+
+
+
+func Walk(v Visitor, node Node) {
+    if v = v.Visit(node); v == nil {
+        return
+    }
+
+    switch n := node.(type) {
+    case *Body:
+        for _, block := range n.Blocks() {
+            Walk(v, block)
+        }
+    case *Paragraph:
+        for _, run := range n.Runs() {
+            Walk(v, run)
+        }
+    case *Table:
+        for _, row := range n.Rows() {
+            Walk(v, row)
+        }
+    case *TableRow:
+        for _, cell := range n.Cells() {
+            Walk(v, cell)
+        }
+    case *TableCell:
+        for _, block := range n.Blocks() {
+            Walk(v, block)
+        }
+    }
+
+    v.Visit(nil)
+}
+
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+    if f(node) {
+        return f
+    }
+    return nil
+}
+
+
+func Inspect(node Node, f func(Node) bool) {
+    Walk(inspector(f), node)
+}
@@ -0,0 +1,231 @@
+package docx
+
+import (
+    "path/filepath"
+    "testing"
+)
+
+
+func reopenDocument(t *testing.T, doc Document) *DocxDocument {
+    t.Helper()
+    filename := filepath.Join(t.TempDir(), "reopened.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    opened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return opened
+}
+
+
+func listTexts(t *testing.T, l blockList) []string {
+    t.Helper()
+    texts := []string{}
+    for _, block := range l.Blocks() {
+        switch v := block.(type) {
+        case *Paragraph:
+            texts = append(texts, v.Text())
+        case *Table:
+            texts = append(texts, "table")
+        case *SectionBreak:
+            texts = append(texts, "break")
+        }
+    }
+    return texts
+}
+
+
+func equalStrings(a []string, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+
+func TestBodyIndexesSkipContentControls(t *testing.T) {
+    doc := NewDocxDocument()
+    if _, err := doc.AddContentControl(StyleNormal, ContentControlOptions{Tag: "intro"}); err != nil {
+        t.Fatal(err)
+    }
+    doc.AddText(StyleHeading1, "H1")
+    doc.AddText(StyleNormal, "body")
+    body := doc.Body()
+
+    if got := body.Len(); got != 2 {
+        t.Fatalf("Len() = %d, want 2", got)
+    }
+    for i, block := range body.Blocks() {
+        if body.Block(i) != nil && body.Block(i).block() != block.block() {
+            t.Errorf("Block(%d) does not match Blocks()[%d]", i, i)
+        }
+        if got := body.IndexOf(block); got != i {
+            t.Errorf("IndexOf(Blocks()[%d]) = %d", i, got)
+        }
+    }
+
+    for i, block := range body.Blocks() {
+        if p, ok := block.(*Paragraph); ok && p.Style() == StyleHeading1 {
+            disclaimer, err := body.InsertParagraph(i+1, StyleNormal)
+            if err != nil {
+                t.Fatal(err)
+            }
+            disclaimer.AddRun("DISCLAIMER")
+            break
+        }
+    }
+    if got, want := listTexts(t, body.blockList), []string{"H1", "DISCLAIMER", "body"}; !equalStrings(got, want) {
+        t.Fatalf("blocks = %q, want %q", got, want)
+    }
+    if _, ok := doc.content[0].(*containerElement); !ok {
+        t.Errorf("content control moved from the start of the body")
+    }
+
+    if err := body.Move(2, 0); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := listTexts(t, body.blockList), []string{"body", "H1", "DISCLAIMER"}; !equalStrings(got, want) {
+        t.Errorf("after Move blocks = %q, want %q", got, want)
+    }
+    if _, err := body.Remove(2); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := listTexts(t, body.blockList), []string{"body", "H1"}; !equalStrings(got, want) {
+        t.Errorf("after Remove blocks = %q, want %q", got, want)
+    }
+    if _, err := body.Remove(2); err == nil {
+        t.Errorf("Remove past the last block succeeded")
+    }
+}
+
+
+func TestTableCellBlockRules(t *testing.T) {
+    doc := NewDocxDocument()
+    table := doc.AddTable(1, 2)
+    cell := table.Cell(0, 0)
+
+    if _, err := cell.InsertSectionBreak(0, SectionNextPage); err == nil {
+        t.Errorf("InsertSectionBreak into a cell succeeded")
+    }
+    brk, err := doc.AddSectionBreak(SectionContinuous)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := doc.Body().Remove(doc.Body().IndexOf(brk)); err != nil {
+        t.Fatal(err)
+    }
+    if err := cell.InsertBlock(0, brk); err == nil {
+        t.Errorf("InsertBlock of a section break into a cell succeeded")
+    }
+
+    cell.AddText(StyleNormal, "first")
+    if _, err := cell.InsertTable(1, 1, 1); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := cell.Remove(0); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := listTexts(t, cell.blockList), []string{"table", ""}; !equalStrings(got, want) {
+        t.Errorf("after removing the paragraph cell blocks = %q, want %q", got, want)
+    }
+    other := table.Cell(0, 1)
+    other.AddText(StyleNormal, "only")
+    if _, err := other.Remove(0); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := listTexts(t, other.blockList), []string{""}; !equalStrings(got, want) {
+        t.Errorf("after removing the only paragraph cell blocks = %q, want %q", got, want)
+    }
+
+    para := doc.AddParagraph(StyleNormal)
+    if err := table.Cell(0, 1).InsertBlock(0, para); err == nil {
+        t.Errorf("InsertBlock of a block attached to the body succeeded")
+    }
+    if err := doc.Body().RemoveBlock(para); err != nil {
+        t.Fatal(err)
+    }
+    if err := table.Cell(0, 1).InsertBlock(0, para); err != nil {
+        t.Errorf("InsertBlock of a removed block failed: %v", err)
+    }
+    if err := doc.Body().InsertBlock(0, para); err == nil {
+        t.Errorf("InsertBlock of a block attached to a cell succeeded")
+    }
+}
+
+
+func TestSectionBreakTypeStartsNextSection(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "one")
+    first, err := doc.AddSectionBreak(SectionContinuous)
+    if err != nil {
+        t.Fatal(err)
+    }
+    doc.AddText(StyleNormal, "two")
+
+    if got := sectionBreakType(doc.sectPr); got != SectionContinuous {
+        t.Errorf("final section type = %q, want %q", got, SectionContinuous)
+    }
+    if got := sectionBreakType(first.data.Properties.SectPr); got != SectionNextPage {
+        t.Errorf("first section type = %q, want %q", got, SectionNextPage)
+    }
+    if got := first.Type(); got != SectionContinuous {
+        t.Errorf("Type() = %q, want %q", got, SectionContinuous)
+    }
+    layout := newPDFLayout(doc)
+    layout.layout()
+    if len(layout.pages) != 1 {
+        t.Errorf("continuous break laid out on %d pages, want 1", len(layout.pages))
+    }
+
+    second, err := doc.AddSectionBreak(SectionOddPage)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := sectionBreakType(second.data.Properties.SectPr); got != SectionContinuous {
+        t.Errorf("middle section type = %q, want %q", got, SectionContinuous)
+    }
+    if got := sectionBreakType(doc.sectPr); got != SectionOddPage {
+        t.Errorf("final section type = %q, want %q", got, SectionOddPage)
+    }
+    if err := first.SetType(SectionEvenPage); err != nil {
+        t.Fatal(err)
+    }
+    if got := sectionBreakType(second.data.Properties.SectPr); got != SectionEvenPage {
+        t.Errorf("after SetType middle section type = %q, want %q", got, SectionEvenPage)
+    }
+    if first.Type() != SectionEvenPage || second.Type() != SectionOddPage {
+        t.Errorf("Type() = %q, %q, want %q, %q", first.Type(), second.Type(), SectionEvenPage, SectionOddPage)
+    }
+
+    body := doc.Body()
+    if _, err := body.Remove(body.IndexOf(second)); err != nil {
+        t.Fatal(err)
+    }
+    if got := sectionBreakType(doc.sectPr); got != SectionEvenPage {
+        t.Errorf("after Remove final section type = %q, want %q", got, SectionEvenPage)
+    }
+    if err := body.InsertBlock(body.Len(), second); err != nil {
+        t.Fatal(err)
+    }
+    if got := sectionBreakType(doc.sectPr); got != SectionOddPage {
+        t.Errorf("after InsertBlock final section type = %q, want %q", got, SectionOddPage)
+    }
+
+    opened := reopenDocument(t, doc)
+    types := []string{}
+    for _, block := range opened.Body().Blocks() {
+        if brk, ok := block.(*SectionBreak); ok {
+            types = append(types, brk.Type())
+        }
+    }
+    if want := []string{SectionEvenPage, SectionOddPage}; !equalStrings(types, want) {
+        t.Errorf("reopened break types = %q, want %q", types, want)
+    }
+}
@@ -0,0 +1,112 @@
+package docx
+
+import (
+    "encoding/xml"
+    "fmt"
+    "regexp"
+)
+
+var bookmarkNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,39}$`)
+
+
+type bookmarkStart struct {
+    XMLName xml.Name `xml:"w:bookmarkStart"`
+    ID      uint     `xml:"w:id,attr"`
+    Name    string   `xml:"w:name,attr"`
+}
+
+type bookmarkEnd struct {
+    XMLName xml.Name `xml:"w:bookmarkEnd"`
+    ID      uint     `xml:"w:id,attr"`
+}
+
+
+type bookmarkState struct {
+    start  *bookmarkStart
+    closed bool
+}
+
+
+func (d *DocxDocument) StartBookmark(name string) error {
+    if !bookmarkNamePattern.MatchString(name) {
+        return fmt.Errorf("invalid bookmark name %q: must start with a letter or underscore and contain at most 40 letters, digits or underscores", name)
+    }
+    if _, exists := d.bookmarks[name]; exists {
+        return fmt.Errorf("bookmark %q already exists", name)
+    }
+
+    start := &bookmarkStart{ID: d.bookmarkCounter, Name: name}
+    d.bookmarkCounter++
+    d.bookmarks[name] = &bookmarkState{start: start}
+
+
+    d.pendingBookmarks = append(d.pendingBookmarks, start)
+    return nil
+}
+
+
+func (d *DocxDocument) EndBookmark(name string) error {
+    state, ok := d.bookmarks[name]
+    if !ok {
+        return fmt.Errorf("bookmark %q was never started", name)
+    }
+    if state.closed {
+        return fmt.Errorf("bookmark %q is already closed", name)
+    }
+    state.closed = true
+
+    content := []interface{}{}
+    for i, pending := range d.pendingBookmarks {
+        if pending == state.start {
+            d.pendingBookmarks = append(d.pendingBookmarks[:i], d.pendingBookmarks[i+1:]...)
+            content = append(content, state.start)
+            break
+        }
+    }
+    content = append(content, &bookmarkEnd{ID: state.start.ID})
+
+    lastPara := d.lastParagraph()
+    lastPara.Content = append(lastPara.Content, content...)
+    return nil
+}
+
+
+func (d *DocxDocument) takePendingBookmarks() []interface{} {
+    pending := d.pendingBookmarks
+    d.pendingBookmarks = []interface{}{}
+    return pending
+}
+
+
+func (d *DocxDocument) bookmarkTexts() map[string]string {
+    texts := make(map[string]string)
+    open := make(map[uint]string)
+    for _, para := range d.paragraphs() {
+        for _, c := range para.Content {
+            switch v := c.(type) {
+            case *bookmarkStart:
+                open[v.ID] = v.Name
+                texts[v.Name] = ""
+            case *bookmarkEnd:
+                delete(open, v.ID)
+            case *paragraphRun:
+                if v.Text == nil {
+                    continue
+                }
+                for _, name := range open {
+                    texts[name] += v.Text.Text
+                }
+            case *simpleField:
+                for _, run := range v.Runs {
+                    if run.Text == nil {
+                        continue
+                    }
+                    for _, name := range open {
+                        texts[name] += run.Text.Text
+                    }
+                }
+            }
+        }
+    }
+    return texts
+}
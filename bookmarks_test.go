@@ -0,0 +1,82 @@
+package docx
+
+import (
+    "testing"
+)
+
+
+func TestBookmarksAndCrossReferences(t *testing.T) {
+    doc := NewDocxDocument()
+    if err := doc.StartBookmark("step_3"); err != nil {
+        t.Fatal(err)
+    }
+    doc.AddText(StyleHeading2, "Replace the filter")
+    if err := doc.EndBookmark("step_3"); err != nil {
+        t.Fatal(err)
+    }
+    doc.AddText(StyleNormal, "See ")
+    doc.AddRef(StyleNormal, "step_3", RefHyperlink)
+    doc.AddText(StyleNormal, " on page ")
+    doc.AddPageRef(StyleNormal, "step_3", RefHyperlink)
+    doc.AddText(StyleNormal, ".")
+    doc.AddParagraph(StyleNormal).AddRun("Dangling: ")
+    doc.AddRef(StyleNormal, "missing")
+
+    for _, name := range []string{"", "3rd_step", "has space", "a_name_that_is_far_too_long_for_word_1234"} {
+        if err := doc.StartBookmark(name); err == nil {
+            t.Errorf("StartBookmark(%q) succeeded", name)
+        }
+    }
+    if err := doc.StartBookmark("step_3"); err == nil {
+        t.Error("StartBookmark accepted a duplicate name")
+    }
+    if err := doc.EndBookmark("step_3"); err == nil {
+        t.Error("EndBookmark closed a bookmark twice")
+    }
+    if err := doc.EndBookmark("never"); err == nil {
+        t.Error("EndBookmark closed a bookmark that was never started")
+    }
+
+    reopened := reopenDocument(t, doc)
+    paras := reopened.paragraphs()
+    if got := listParagraphTexts(paras); !equalStrings(got, []string{"Replace the filter", "See Replace the filter on page .", "Dangling: "}) {
+        t.Errorf("paragraph texts = %q", got)
+    }
+    if text := reopened.bookmarkTexts()["step_3"]; text != "Replace the filter" {
+        t.Errorf("bookmark text = %q", text)
+    }
+    markers := []string{}
+    for _, c := range paras[0].Content {
+        switch v := c.(type) {
+        case *bookmarkStart:
+            markers = append(markers, "start "+v.Name)
+        case *paragraphRun:
+            markers = append(markers, "run")
+        case *bookmarkEnd:
+            markers = append(markers, "end")
+        }
+    }
+    if !equalStrings(markers, []string{"start step_3", "run", "end"}) {
+        t.Errorf("heading content = %q, want the bookmark around the run", markers)
+    }
+
+    fields := []string{}
+    for _, para := range paras {
+        for _, field := range paragraphFields(para) {
+            fields = append(fields, field.instruction+"|"+field.begin.Dirty)
+        }
+    }
+    want := []string{`REF step_3 \h|`, `PAGEREF step_3 \h|true`, `REF missing|true`}
+    if !equalStrings(fields, want) {
+        t.Errorf("fields = %q, want %q", fields, want)
+    }
+}
+
+
+func listParagraphTexts(paras []*paragraphData) []string {
+    texts := []string{}
+    for _, para := range paras {
+        texts = append(texts, para.text())
+    }
+    return texts
+}
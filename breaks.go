@@ -0,0 +1,76 @@
+package docx
+
+import "encoding/xml"
+
+const (
+    BreakPage         = "page"
+    BreakColumn       = "column"
+    BreakTextWrapping = "textWrapping"
+)
+
+const (
+    BreakClearNone  = "none"
+    BreakClearLeft  = "left"
+    BreakClearRight = "right"
+    BreakClearAll   = "all"
+)
+
+
+type runBreak struct {
+    XMLName xml.Name `xml:"w:br"`
+    Type    string   `xml:"w:type,attr,omitempty"`
+    Clear   string   `xml:"w:clear,attr,omitempty"`
+}
+
+type runTab struct {
+    XMLName xml.Name `xml:"w:tab"`
+}
+
+
+func (d *DocxDocument) lastParagraph() *paragraphData {
+    if len(d.content) > 0 {
+        if para, ok := d.content[len(d.content)-1].(*paragraphData); ok {
+            return para
+        }
+    }
+    para := &paragraphData{}
+    d.content = append(d.content, para)
+    return para
+}
+
+
+func (d *DocxDocument) appendToLastParagraph(run *paragraphRun) {
+    lastPara := d.lastParagraph()
+    lastPara.Content = append(lastPara.Content, d.takePendingBookmarks()...)
+    lastPara.Content = append(lastPara.Content, run)
+}
+
+
+func (d *DocxDocument) AddLineBreak() {
+    d.appendToLastParagraph(&paragraphRun{Break: &runBreak{}})
+}
+
+
+func (d *DocxDocument) AddPageBreak() {
+    d.appendToLastParagraph(&paragraphRun{Break: &runBreak{Type: BreakPage}})
+}
+
+
+func (d *DocxDocument) AddColumnBreak() {
+    d.appendToLastParagraph(&paragraphRun{Break: &runBreak{Type: BreakColumn}})
+}
+
+
+func (d *DocxDocument) AddTextWrappingBreak(clear string) {
+    switch clear {
+    case BreakClearLeft, BreakClearRight, BreakClearAll:
+    default:
+        clear = BreakClearNone
+    }
+    d.appendToLastParagraph(&paragraphRun{Break: &runBreak{Type: BreakTextWrapping, Clear: clear}})
+}
+
+
+func (d *DocxDocument) AddTab() {
+    d.appendToLastParagraph(&paragraphRun{Tab: &runTab{}})
+}
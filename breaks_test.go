@@ -0,0 +1,66 @@
+package docx
+
+import (
+    "testing"
+)
+
+
+func TestBreaksAndTabsRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddLineBreak()
+    doc.AddText(StyleNormal, "Name:")
+    doc.AddTab()
+    doc.AddText(StyleNormal, "Ada")
+    doc.AddLineBreak()
+    doc.AddText(StyleNormal, "Role:")
+    doc.AddTextWrappingBreak("sideways")
+    doc.AddTextWrappingBreak(BreakClearLeft)
+    doc.AddColumnBreak()
+    doc.AddPageBreak()
+
+    para := doc.AddParagraph(StyleNormal)
+    para.AddRun("Left")
+    para.AddTab()
+    if _, err := para.AddBreak(BreakPage); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := para.AddBreak(""); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := para.AddBreak("section"); err == nil {
+        t.Error("AddBreak accepted an unknown break type")
+    }
+
+    reopened := reopenDocument(t, doc)
+    paras := reopened.paragraphs()
+    if got := listParagraphTexts(paras); !equalStrings(got, []string{"\nName:\tAda\nRole:\n\n", "Left\t\n"}) {
+        t.Errorf("paragraph texts = %q", got)
+    }
+    want := [][]string{
+        {"br", "text", "tab", "text", "br", "text", "br textWrapping none", "br textWrapping left", "br column", "br page"},
+        {"text", "tab", "br page", "br"},
+    }
+    for i, para := range paras {
+        got := []string{}
+        for _, run := range para.runs() {
+            switch {
+            case run.Break != nil:
+                desc := "br"
+                if run.Break.Type != "" {
+                    desc += " " + run.Break.Type
+                }
+                if run.Break.Clear != "" {
+                    desc += " " + run.Break.Clear
+                }
+                got = append(got, desc)
+            case run.Tab != nil:
+                got = append(got, "tab")
+            case run.Text != nil:
+                got = append(got, "text")
+            }
+        }
+        if !equalStrings(got, want[i]) {
+            t.Errorf("paragraph %d runs = %q, want %q", i, got, want[i])
+        }
+    }
+}
@@ -0,0 +1,313 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+)
+
+const (
+    algorithmC14N                  = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+    algorithmC14NComments          = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315#WithComments"
+    algorithmRelationshipTransform = "http://schemas.openxmlformats.org/package/2006/RelationshipTransform"
+)
+
+
+type c14nNode struct {
+    name     xml.Name
+    attrs    []xml.Attr
+    children []interface{}
+    parent   *c14nNode
+}
+
+
+func parseC14N(data []byte) (*c14nNode, error) {
+    decoder := xml.NewDecoder(bytes.NewReader(data))
+    var root, current *c14nNode
+    for {
+        token, err := decoder.RawToken()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+        switch t := token.(type) {
+        case xml.StartElement:
+            node := &c14nNode{name: t.Name, attrs: append([]xml.Attr{}, t.Attr...), parent: current}
+            if current == nil {
+                if root != nil {
+                    return nil, fmt.Errorf("multiple root elements")
+                }
+                root = node
+            } else {
+                current.children = append(current.children, node)
+            }
+            current = node
+        case xml.EndElement:
+            if current == nil {
+                return nil, fmt.Errorf("unexpected end element %s", t.Name.Local)
+            }
+            if t.Name != current.name {
+                return nil, fmt.Errorf("element %s closed by %s", current.name.Local, t.Name.Local)
+            }
+            current = current.parent
+        case xml.CharData:
+            if current != nil {
+                current.children = append(current.children, string(t))
+            }
+        case xml.Comment:
+            if current != nil {
+                current.children = append(current.children, xml.Comment(append([]byte{}, t...)))
+            }
+        case xml.ProcInst:
+            if current != nil {
+                current.children = append(current.children, xml.ProcInst{Target: t.Target, Inst: append([]byte{}, t.Inst...)})
+            }
+        }
+    }
+    if root == nil {
+        return nil, fmt.Errorf("document has no root element")
+    }
+    if current != nil {
+        return nil, fmt.Errorf("element %s is not closed", current.name.Local)
+    }
+    return root, nil
+}
+
+
+func (n *c14nNode) attr(name string) string {
+    for _, a := range n.attrs {
+        if a.Name.Space == "" && a.Name.Local == name {
+            return a.Value
+        }
+    }
+    return ""
+}
+
+
+func (n *c14nNode) elements(local string) []*c14nNode {
+    result := []*c14nNode{}
+    for _, child := range n.children {
+        if el, ok := child.(*c14nNode); ok && (local == "" || el.name.Local == local) {
+            result = append(result, el)
+        }
+    }
+    return result
+}
+
+
+func (n *c14nNode) element(local string) *c14nNode {
+    if elements := n.elements(local); len(elements) > 0 {
+        return elements[0]
+    }
+    return nil
+}
+
+
+func (n *c14nNode) text() string {
+    var sb strings.Builder
+    for _, child := range n.children {
+        switch c := child.(type) {
+        case string:
+            sb.WriteString(c)
+        case *c14nNode:
+            sb.WriteString(c.text())
+        }
+    }
+    return sb.String()
+}
+
+
+func (n *c14nNode) findID(id string) *c14nNode {
+    if n.attr("Id") == id {
+        return n
+    }
+    for _, el := range n.elements("") {
+        if found := el.findID(id); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
+
+func (n *c14nNode) namespaces() map[string]string {
+    scope := make(map[string]string)
+    chain := []*c14nNode{}
+    for el := n; el != nil; el = el.parent {
+        chain = append(chain, el)
+    }
+    for i := len(chain) - 1; i >= 0; i-- {
+        for _, a := range chain[i].attrs {
+            switch {
+            case a.Name.Space == "" && a.Name.Local == "xmlns":
+                scope[""] = a.Value
+            case a.Name.Space == "xmlns":
+                scope[a.Name.Local] = a.Value
+            }
+        }
+    }
+    return scope
+}
+
+
+
+
+func canonicalize(n *c14nNode, withComments bool) []byte {
+    var buf bytes.Buffer
+    writeCanonical(&buf, n, map[string]string{}, withComments)
+    return buf.Bytes()
+}
+
+
+func writeCanonical(buf *bytes.Buffer, n *c14nNode, rendered map[string]string, withComments bool) {
+    scope := n.namespaces()
+    prefixes := []string{}
+    for prefix, uri := range scope {
+        if prefix == "xml" {
+            continue
+        }
+        if rendered[prefix] != uri {
+            prefixes = append(prefixes, prefix)
+        }
+    }
+    if _, ok := scope[""]; !ok && rendered[""] != "" {
+        prefixes = append(prefixes, "")
+    }
+    sort.Strings(prefixes)
+
+    inner := make(map[string]string, len(rendered))
+    for prefix, uri := range rendered {
+        inner[prefix] = uri
+    }
+
+    buf.WriteString("<" + c14nQName(n.name))
+    for _, prefix := range prefixes {
+        inner[prefix] = scope[prefix]
+        if prefix == "" {
+            buf.WriteString(` xmlns="` + c14nEscape(scope[""], true) + `"`)
+        } else {
+            buf.WriteString(" xmlns:" + prefix + `="` + c14nEscape(scope[prefix], true) + `"`)
+        }
+    }
+
+    type canonicalAttr struct {
+        uri   string
+        name  xml.Name
+        value string
+    }
+    attrs := []canonicalAttr{}
+    for _, a := range n.attrs {
+        if (a.Name.Space == "" && a.Name.Local == "xmlns") || a.Name.Space == "xmlns" {
+            continue
+        }
+        uri := ""
+        switch a.Name.Space {
+        case "":
+        case "xml":
+            uri = namespaceXML
+        default:
+            uri = scope[a.Name.Space]
+        }
+        attrs = append(attrs, canonicalAttr{uri: uri, name: a.Name, value: a.Value})
+    }
+    sort.SliceStable(attrs, func(i, j int) bool {
+        if attrs[i].uri != attrs[j].uri {
+            return attrs[i].uri < attrs[j].uri
+        }
+        return attrs[i].name.Local < attrs[j].name.Local
+    })
+    for _, a := range attrs {
+        buf.WriteString(" " + c14nQName(a.name) + `="` + c14nEscape(a.value, true) + `"`)
+    }
+    buf.WriteString(">")
+
+    for _, child := range n.children {
+        switch c := child.(type) {
+        case string:
+            buf.WriteString(c14nEscape(c, false))
+        case *c14nNode:
+            writeCanonical(buf, c, inner, withComments)
+        case xml.Comment:
+            if withComments {
+                buf.WriteString("<!--" + string(c) + "-->")
+            }
+        case xml.ProcInst:
+            buf.WriteString("<?" + c.Target)
+            if len(c.Inst) > 0 {
+                buf.WriteString(" " + string(c.Inst))
+            }
+            buf.WriteString("?>")
+        }
+    }
+    buf.WriteString("</" + c14nQName(n.name) + ">")
+}
+
+
+func c14nQName(name xml.Name) string {
+    if name.Space == "" {
+        return name.Local
+    }
+    return name.Space + ":" + name.Local
+}
+
+
+func c14nEscape(s string, attribute bool) string {
+    var sb strings.Builder
+    for _, r := range s {
+        switch {
+        case r == '&':
+            sb.WriteString("&amp;")
+        case r == '<':
+            sb.WriteString("&lt;")
+        case r == '>' && !attribute:
+            sb.WriteString("&gt;")
+        case r == '"' && attribute:
+            sb.WriteString("&quot;")
+        case r == '\t' && attribute:
+            sb.WriteString("&#x9;")
+        case r == '\n' && attribute:
+            sb.WriteString("&#xA;")
+        case r == '\r':
+            sb.WriteString("&#xD;")
+        default:
+            sb.WriteRune(r)
+        }
+    }
+    return sb.String()
+}
+
+
+
+
+func relationshipTransform(data []byte, sourceIDs map[string]bool, sourceTypes map[string]bool) ([]byte, error) {
+    var rels relationships
+    if err := xml.Unmarshal(data, &rels); err != nil {
+        return nil, err
+    }
+    selected := []relationship{}
+    for _, rel := range rels.Relationships {
+        if sourceIDs[rel.ID] || sourceTypes[rel.Type] {
+            if rel.TargetMode == "" {
+                rel.TargetMode = "Internal"
+            }
+            selected = append(selected, rel)
+        }
+    }
+    sort.SliceStable(selected, func(i, j int) bool {
+        return selected[i].ID < selected[j].ID
+    })
+
+    var buf bytes.Buffer
+    buf.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+    for _, rel := range selected {
+        buf.WriteString(`<Relationship Id="` + c14nEscape(rel.ID, true) + `" Target="` + c14nEscape(rel.Target, true) +
+            `" TargetMode="` + c14nEscape(rel.TargetMode, true) + `" Type="` + c14nEscape(rel.Type, true) + `"></Relationship>`)
+    }
+    buf.WriteString("</Relationships>")
+    return buf.Bytes(), nil
+}
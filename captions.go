@@ -0,0 +1,151 @@
+package docx
+
+import (
+    "fmt"
+    "strings"
+)
+
+const (
+    CaptionFigure = "Figure"
+    CaptionTable  = "Table"
+)
+
+
+type tableOfFigures struct {
+    label string
+    begin *fieldChar
+}
+
+
+func (d *DocxDocument) AddCaption(label string, text string, formatOptions ...string) error {
+    if strings.TrimSpace(label) == "" || strings.ContainsAny(label, " \t\"\\") {
+        return fmt.Errorf("invalid caption label %q", label)
+    }
+    if len(d.content) == 0 {
+        return fmt.Errorf("caption %q has no preceding image or table to attach to", label)
+    }
+    switch target := d.content[len(d.content)-1].(type) {
+    case *tableData:
+        for _, para := range collectParagraphs([]interface{}{target}, nil) {
+            if para.Properties == nil {
+                para.Properties = &paragraphProperties{}
+            }
+            para.Properties.KeepNext = &onOffProperty{}
+        }
+    case *paragraphData:
+        hasDrawing := false
+        for _, r := range target.runs() {
+            if r.hasDrawing() {
+                hasDrawing = true
+                break
+            }
+        }
+        if !hasDrawing {
+            return fmt.Errorf("caption %q has no preceding image or table to attach to", label)
+        }
+
+
+        if target.Properties == nil {
+            target.Properties = &paragraphProperties{}
+        }
+        target.Properties.KeepNext = &onOffProperty{}
+    default:
+        return fmt.Errorf("caption %q has no preceding image or table to attach to", label)
+    }
+
+    runProps := newRunProperties(formatOptions)
+    content := d.takePendingBookmarks()
+    content = append(content, &paragraphRun{
+        Properties: runProps,
+        Text:       &paragraphRunText{Text: label + " ", Space: "preserve"},
+    })
+    seq := newFieldRuns(SeqField(label, FieldArabic), "", runProps)
+    d.seqFields[seq[0].FieldChar] = true
+    content = append(content, runsToContent(seq)...)
+    if text != "" {
+        content = append(content, &paragraphRun{
+            Properties: newRunProperties(formatOptions),
+            Text:       &paragraphRunText{Text: ": " + text, Space: "preserve"},
+        })
+    }
+
+    d.content = append(d.content, &paragraphData{
+        Properties: &paragraphProperties{Style: &paragraphStyle{Val: StyleCaption}},
+        Content:    content,
+    })
+    d.resolveSeqFields()
+    return nil
+}
+
+
+func (d *DocxDocument) AddTableOfFigures(label string) {
+    runs := newFieldRuns(fmt.Sprintf(`TOC \h \z \c %s`, fieldArgument(label)), "", nil)
+    runs[0].FieldChar.Dirty = "true"
+    d.tablesOfFigures = append(d.tablesOfFigures, tableOfFigures{label: label, begin: runs[0].FieldChar})
+
+    d.content = append(d.content, &paragraphData{
+        Properties: &paragraphProperties{Style: &paragraphStyle{Val: StyleTableOfFigures}},
+        Content:    append(d.takePendingBookmarks(), runsToContent(runs)...),
+    })
+}
+
+
+func (d *DocxDocument) captionTexts(label string) []string {
+    captions := []string{}
+    for _, para := range d.paragraphs() {
+        if para.Properties == nil || para.Properties.Style == nil || para.Properties.Style.Val != StyleCaption {
+            continue
+        }
+        for _, field := range paragraphFields(para) {
+            tokens := splitFieldInstruction(field.instruction)
+            if len(tokens) >= 2 && strings.EqualFold(tokens[0], "SEQ") && tokens[1] == label {
+                captions = append(captions, para.text())
+                break
+            }
+        }
+    }
+    return captions
+}
+
+
+func (d *DocxDocument) resolveTablesOfFigures() {
+    for _, tof := range d.tablesOfFigures {
+        entries := []interface{}{}
+        for i, caption := range d.captionTexts(tof.label) {
+            if i > 0 {
+                entries = append(entries, &paragraphRun{Break: &runBreak{}})
+            }
+            entries = append(entries, &paragraphRun{Text: &paragraphRunText{Text: caption, Space: "preserve"}})
+        }
+        if len(entries) == 0 {
+            entries = append(entries, &paragraphRun{Text: &paragraphRunText{Text: "No table of figures entries found.", Space: "preserve"}})
+        }
+
+        for _, para := range d.paragraphs() {
+            separate, end := -1, -1
+            found := false
+            for j, c := range para.Content {
+                run, ok := c.(*paragraphRun)
+                if !ok || run.FieldChar == nil {
+                    continue
+                }
+                if run.FieldChar == tof.begin {
+                    found = true
+                } else if found && separate < 0 && run.FieldChar.Type == fieldCharSeparate {
+                    separate = j
+                } else if found && separate >= 0 && run.FieldChar.Type == fieldCharEnd {
+                    end = j
+                    break
+                }
+            }
+            if separate < 0 || end < 0 {
+                continue
+            }
+
+            content := append([]interface{}{}, para.Content[:separate+1]...)
+            content = append(content, entries...)
+            para.Content = append(content, para.Content[end:]...)
+            break
+        }
+    }
+}
@@ -0,0 +1,461 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "sort"
+    "strings"
+    "unicode/utf16"
+)
+
+const (
+    cfbSectorSize     = 512
+    cfbMiniSectorSize = 64
+    cfbMiniCutoff     = 4096
+    cfbDirEntrySize   = 128
+    cfbHeaderDIFAT    = 109
+    cfbFreeSector     = 0xFFFFFFFF
+    cfbEndOfChain     = 0xFFFFFFFE
+    cfbFATSector      = 0xFFFFFFFD
+    cfbDIFATSector    = 0xFFFFFFFC
+    cfbNoStream       = 0xFFFFFFFF
+)
+
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+
+type cfbEntry struct {
+    name     string
+    data     []byte
+    storage  bool
+    children []*cfbEntry
+    id       uint32
+    start    uint32
+    left     uint32
+    right    uint32
+    child    uint32
+}
+
+
+func isCompoundFile(data []byte) bool {
+    return bytes.HasPrefix(data, cfbSignature)
+}
+
+
+func cfbLess(a string, b string) bool {
+    ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+    if len(ua) != len(ub) {
+        return len(ua) < len(ub)
+    }
+    return strings.ToUpper(a) < strings.ToUpper(b)
+}
+
+
+
+
+func writeCompoundFile(streams map[string][]byte) ([]byte, error) {
+    root := &cfbEntry{name: "Root Entry", storage: true}
+    names := []string{}
+    for name := range streams {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        parent := root
+        segments := strings.Split(name, "/")
+        for i, segment := range segments {
+            if len(utf16.Encode([]rune(segment))) > 31 {
+                return nil, fmt.Errorf("compound file entry name too long: %s", segment)
+            }
+            var child *cfbEntry
+            for _, c := range parent.children {
+                if c.name == segment {
+                    child = c
+                }
+            }
+            if child == nil {
+                child = &cfbEntry{name: segment, storage: i < len(segments)-1}
+                parent.children = append(parent.children, child)
+            }
+            if child.storage != (i < len(segments)-1) {
+                return nil, fmt.Errorf("compound file entry %s is both a stream and a storage", name)
+            }
+            parent = child
+        }
+        parent.data = streams[name]
+    }
+
+    entries := []*cfbEntry{root}
+    for i := 0; i < len(entries); i++ {
+        sort.Slice(entries[i].children, func(a, b int) bool {
+            return cfbLess(entries[i].children[a].name, entries[i].children[b].name)
+        })
+        for _, child := range entries[i].children {
+            child.id = uint32(len(entries))
+            entries = append(entries, child)
+        }
+    }
+
+    var miniStream []byte
+    miniFAT := []uint32{}
+    large := []*cfbEntry{}
+    for _, entry := range entries[1:] {
+        if entry.storage {
+            continue
+        }
+        if len(entry.data) >= cfbMiniCutoff {
+            large = append(large, entry)
+            continue
+        }
+        if len(entry.data) == 0 {
+            entry.start = cfbEndOfChain
+            continue
+        }
+        entry.start = uint32(len(miniFAT))
+        count := (len(entry.data) + cfbMiniSectorSize - 1) / cfbMiniSectorSize
+        for i := 0; i < count; i++ {
+            miniFAT = append(miniFAT, uint32(len(miniFAT)+1))
+        }
+        miniFAT[len(miniFAT)-1] = cfbEndOfChain
+        miniStream = append(miniStream, entry.data...)
+        miniStream = append(miniStream, make([]byte, count*cfbMiniSectorSize-len(entry.data))...)
+    }
+
+    sectors := func(n int) int {
+        return (n + cfbSectorSize - 1) / cfbSectorSize
+    }
+    fat := []uint32{}
+    var body bytes.Buffer
+    chain := func(data []byte) uint32 {
+        if len(data) == 0 {
+            return cfbEndOfChain
+        }
+        start := uint32(len(fat))
+        count := sectors(len(data))
+        for i := 0; i < count; i++ {
+            fat = append(fat, uint32(len(fat)+1))
+        }
+        fat[len(fat)-1] = cfbEndOfChain
+        body.Write(data)
+        body.Write(make([]byte, count*cfbSectorSize-len(data)))
+        return start
+    }
+
+    for _, entry := range large {
+        entry.start = chain(entry.data)
+    }
+    root.start = chain(miniStream)
+    root.data = miniStream
+
+    miniFATStart := uint32(cfbEndOfChain)
+    if len(miniFAT) > 0 {
+        var buf bytes.Buffer
+        for _, next := range miniFAT {
+            binary.Write(&buf, binary.LittleEndian, next)
+        }
+        for buf.Len()%cfbSectorSize != 0 {
+            binary.Write(&buf, binary.LittleEndian, uint32(cfbFreeSector))
+        }
+        miniFATStart = chain(buf.Bytes())
+    }
+
+    for _, entry := range entries {
+        entry.left, entry.right = cfbNoStream, cfbNoStream
+    }
+    for _, entry := range entries {
+        entry.child = cfbSiblingTree(entry.children)
+    }
+    var dir bytes.Buffer
+    for _, entry := range entries {
+        writeCFBDirEntry(&dir, entry)
+    }
+    for dir.Len()%cfbSectorSize != 0 {
+        writeCFBDirEntry(&dir, nil)
+    }
+    dirStart := chain(dir.Bytes())
+
+    perSector := cfbSectorSize / 4
+    fatSectors, difatSectors := 0, 0
+    for {
+        needed := (len(fat) + fatSectors + difatSectors + perSector - 1) / perSector
+        difat := 0
+        if needed > cfbHeaderDIFAT {
+            difat = (needed - cfbHeaderDIFAT + perSector - 2) / (perSector - 1)
+        }
+        if needed == fatSectors && difat == difatSectors {
+            break
+        }
+        fatSectors, difatSectors = needed, difat
+    }
+    fatStart := len(fat)
+    for i := 0; i < fatSectors; i++ {
+        fat = append(fat, cfbFATSector)
+    }
+    difatStart := len(fat)
+    for i := 0; i < difatSectors; i++ {
+        fat = append(fat, cfbDIFATSector)
+    }
+    for len(fat)%perSector != 0 {
+        fat = append(fat, cfbFreeSector)
+    }
+
+    var out bytes.Buffer
+    out.Write(cfbSignature)
+    out.Write(make([]byte, 16))
+    binary.Write(&out, binary.LittleEndian, []uint16{0x003E, 0x0003, 0xFFFE, 9, 6})
+    out.Write(make([]byte, 6))
+    binary.Write(&out, binary.LittleEndian, []uint32{0, uint32(fatSectors), dirStart, 0, cfbMiniCutoff, miniFATStart, uint32((len(miniFAT) + perSector - 1) / perSector)})
+    if difatSectors > 0 {
+        binary.Write(&out, binary.LittleEndian, []uint32{uint32(difatStart), uint32(difatSectors)})
+    } else {
+        binary.Write(&out, binary.LittleEndian, []uint32{cfbEndOfChain, 0})
+    }
+    for i := 0; i < cfbHeaderDIFAT; i++ {
+        if i < fatSectors {
+            binary.Write(&out, binary.LittleEndian, uint32(fatStart+i))
+        } else {
+            binary.Write(&out, binary.LittleEndian, uint32(cfbFreeSector))
+        }
+    }
+
+    out.Write(body.Bytes())
+    binary.Write(&out, binary.LittleEndian, fat)
+    for i := 0; i < difatSectors; i++ {
+        for j := 0; j < perSector-1; j++ {
+            n := cfbHeaderDIFAT + i*(perSector-1) + j
+            if n < fatSectors {
+                binary.Write(&out, binary.LittleEndian, uint32(fatStart+n))
+            } else {
+                binary.Write(&out, binary.LittleEndian, uint32(cfbFreeSector))
+            }
+        }
+        if i < difatSectors-1 {
+            binary.Write(&out, binary.LittleEndian, uint32(difatStart+i+1))
+        } else {
+            binary.Write(&out, binary.LittleEndian, uint32(cfbEndOfChain))
+        }
+    }
+    return out.Bytes(), nil
+}
+
+
+func writeCFBDirEntry(buf *bytes.Buffer, entry *cfbEntry) {
+    record := make([]byte, cfbDirEntrySize)
+    binary.LittleEndian.PutUint32(record[68:], cfbNoStream)
+    binary.LittleEndian.PutUint32(record[72:], cfbNoStream)
+    binary.LittleEndian.PutUint32(record[76:], cfbNoStream)
+    if entry != nil {
+        binary.LittleEndian.PutUint32(record[68:], entry.left)
+        binary.LittleEndian.PutUint32(record[72:], entry.right)
+        binary.LittleEndian.PutUint32(record[76:], entry.child)
+        name := utf16.Encode([]rune(entry.name))
+        for i, c := range name {
+            binary.LittleEndian.PutUint16(record[i*2:], c)
+        }
+        binary.LittleEndian.PutUint16(record[64:], uint16(len(name)*2+2))
+        switch {
+        case entry.id == 0:
+            record[66] = 5
+        case entry.storage:
+            record[66] = 1
+        default:
+            record[66] = 2
+        }
+        record[67] = 1
+        if !entry.storage || entry.id == 0 {
+            binary.LittleEndian.PutUint32(record[116:], entry.start)
+            binary.LittleEndian.PutUint64(record[120:], uint64(len(entry.data)))
+        }
+    }
+    buf.Write(record)
+}
+
+
+func cfbSiblingTree(siblings []*cfbEntry) uint32 {
+    if len(siblings) == 0 {
+        return cfbNoStream
+    }
+    mid := len(siblings) / 2
+    siblings[mid].left = cfbSiblingTree(siblings[:mid])
+    siblings[mid].right = cfbSiblingTree(siblings[mid+1:])
+    return siblings[mid].id
+}
+
+
+
+
+func readCompoundFile(data []byte) (map[string][]byte, error) {
+    if len(data) < cfbSectorSize || !isCompoundFile(data) {
+        return nil, fmt.Errorf("not a compound file")
+    }
+    sectorSize := 1 << binary.LittleEndian.Uint16(data[30:])
+    miniSectorSize := 1 << binary.LittleEndian.Uint16(data[32:])
+    if sectorSize != 512 && sectorSize != 4096 {
+        return nil, fmt.Errorf("unsupported compound file sector size %d", sectorSize)
+    }
+    fatCount := int(binary.LittleEndian.Uint32(data[44:]))
+    dirStart := binary.LittleEndian.Uint32(data[48:])
+    miniCutoff := binary.LittleEndian.Uint32(data[56:])
+    miniFATStart := binary.LittleEndian.Uint32(data[60:])
+    difatNext := binary.LittleEndian.Uint32(data[68:])
+    sectorCount := (len(data) - 1) / sectorSize
+
+    sector := func(n uint32) ([]byte, error) {
+        if int(n) >= sectorCount {
+            return nil, fmt.Errorf("compound file sector %d out of range", n)
+        }
+        offset := (int(n) + 1) * sectorSize
+        end := offset + sectorSize
+        if end > len(data) {
+            return append(append([]byte{}, data[offset:]...), make([]byte, end-len(data))...), nil
+        }
+        return data[offset:end], nil
+    }
+
+    fatSectors := []uint32{}
+    for i := 0; i < cfbHeaderDIFAT && len(fatSectors) < fatCount; i++ {
+        fatSectors = append(fatSectors, binary.LittleEndian.Uint32(data[76+i*4:]))
+    }
+    for visited := 0; difatNext != cfbEndOfChain && difatNext != cfbFreeSector && len(fatSectors) < fatCount; visited++ {
+        if visited > sectorCount {
+            return nil, fmt.Errorf("compound file DIFAT chain is cyclic")
+        }
+        buf, err := sector(difatNext)
+        if err != nil {
+            return nil, err
+        }
+        per := sectorSize/4 - 1
+        for i := 0; i < per && len(fatSectors) < fatCount; i++ {
+            fatSectors = append(fatSectors, binary.LittleEndian.Uint32(buf[i*4:]))
+        }
+        difatNext = binary.LittleEndian.Uint32(buf[per*4:])
+    }
+    fat := []uint32{}
+    for _, n := range fatSectors {
+        buf, err := sector(n)
+        if err != nil {
+            return nil, err
+        }
+        for i := 0; i < sectorSize; i += 4 {
+            fat = append(fat, binary.LittleEndian.Uint32(buf[i:]))
+        }
+    }
+
+    readChain := func(start uint32) ([]byte, error) {
+        var out []byte
+        for n, visited := start, 0; n != cfbEndOfChain; visited++ {
+            if int(n) >= len(fat) || visited > len(fat) {
+                return nil, fmt.Errorf("invalid compound file sector chain")
+            }
+            buf, err := sector(n)
+            if err != nil {
+                return nil, err
+            }
+            out = append(out, buf...)
+            n = fat[n]
+        }
+        return out, nil
+    }
+
+    dir, err := readChain(dirStart)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read compound file directory: %w", err)
+    }
+    if len(dir) < cfbDirEntrySize {
+        return nil, fmt.Errorf("compound file has no root entry")
+    }
+    entryCount := uint32(len(dir) / cfbDirEntrySize)
+    entry := func(id uint32) []byte {
+        return dir[id*cfbDirEntrySize : (id+1)*cfbDirEntrySize]
+    }
+    size := func(record []byte) uint64 {
+        if sectorSize == 512 {
+            return uint64(binary.LittleEndian.Uint32(record[120:]))
+        }
+        return binary.LittleEndian.Uint64(record[120:])
+    }
+
+    root := entry(0)
+    miniStream, err := readChain(binary.LittleEndian.Uint32(root[116:]))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read compound file mini stream: %w", err)
+    }
+    miniFATData := []byte{}
+    if miniFATStart != cfbEndOfChain {
+        if miniFATData, err = readChain(miniFATStart); err != nil {
+            return nil, fmt.Errorf("failed to read compound file mini FAT: %w", err)
+        }
+    }
+    miniFAT := make([]uint32, len(miniFATData)/4)
+    for i := range miniFAT {
+        miniFAT[i] = binary.LittleEndian.Uint32(miniFATData[i*4:])
+    }
+
+    readStream := func(record []byte) ([]byte, error) {
+        start, length := binary.LittleEndian.Uint32(record[116:]), size(record)
+        var out []byte
+        if length < uint64(miniCutoff) {
+            for n, visited := start, 0; n != cfbEndOfChain && uint64(len(out)) < length; visited++ {
+                offset := int(n) * miniSectorSize
+                if int(n) >= len(miniFAT) || visited > len(miniFAT) || offset+miniSectorSize > len(miniStream) {
+                    return nil, fmt.Errorf("invalid compound file mini sector chain")
+                }
+                out = append(out, miniStream[offset:offset+miniSectorSize]...)
+                n = miniFAT[n]
+            }
+        } else {
+            if out, err = readChain(start); err != nil {
+                return nil, err
+            }
+        }
+        if uint64(len(out)) < length {
+            return nil, fmt.Errorf("compound file stream is truncated")
+        }
+        return out[:length], nil
+    }
+
+    streams := make(map[string][]byte)
+    visited := make(map[uint32]bool)
+    var walk func(id uint32, prefix string) error
+    walk = func(id uint32, prefix string) error {
+        if id == cfbNoStream {
+            return nil
+        }
+        if id >= entryCount || visited[id] {
+            return fmt.Errorf("invalid compound file directory tree")
+        }
+        visited[id] = true
+        record := entry(id)
+        nameLength := int(binary.LittleEndian.Uint16(record[64:]))/2 - 1
+        if nameLength < 0 || nameLength > 31 {
+            return fmt.Errorf("invalid compound file entry name")
+        }
+        units := make([]uint16, nameLength)
+        for i := range units {
+            units[i] = binary.LittleEndian.Uint16(record[i*2:])
+        }
+        name := prefix + string(utf16.Decode(units))
+        switch record[66] {
+        case 1:
+            if err := walk(binary.LittleEndian.Uint32(record[76:]), name+"/"); err != nil {
+                return err
+            }
+        case 2:
+            stream, err := readStream(record)
+            if err != nil {
+                return fmt.Errorf("failed to read compound file stream %s: %w", name, err)
+            }
+            streams[name] = stream
+        }
+        if err := walk(binary.LittleEndian.Uint32(record[68:]), prefix); err != nil {
+            return err
+        }
+        return walk(binary.LittleEndian.Uint32(record[72:]), prefix)
+    }
+    visited[0] = true
+    if err := walk(binary.LittleEndian.Uint32(root[76:]), ""); err != nil {
+        return nil, err
+    }
+    return streams, nil
+}
@@ -0,0 +1,838 @@
+package docx
+
+import (
+    "encoding/xml"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    ControlPlainText = "plainText"
+    ControlRichText  = "richText"
+    ControlDropDown  = "dropDownList"
+    ControlComboBox  = "comboBox"
+    ControlDate      = "date"
+    ControlCheckbox  = "checkbox"
+    ControlPicture   = "picture"
+)
+
+const (
+    LockNone    = "unlocked"
+    LockControl = "sdtLocked"
+    LockContent = "contentLocked"
+    LockAll     = "sdtContentLocked"
+)
+
+const (
+    namespaceW14      = "http://schemas.microsoft.com/office/word/2010/wordml"
+    defaultDateFormat = "M/d/yyyy"
+    checkboxFont      = "MS Gothic"
+    checkboxChecked   = "2612"
+    checkboxUnchecked = "2610"
+    controlDateLayout = "2006-01-02T15:04:05Z"
+)
+
+var defaultPlaceholders = map[string]string{
+    ControlPlainText: "Click or tap here to enter text.",
+    ControlRichText:  "Click or tap here to enter text.",
+    ControlDropDown:  "Choose an item.",
+    ControlComboBox:  "Choose an item.",
+    ControlDate:      "Click or tap to enter a date.",
+    ControlPicture:   "Click or tap to insert a picture.",
+}
+
+var wordDateTokens = []struct {
+    word   string
+    layout string
+}{
+    {"yyyy", "2006"}, {"yy", "06"}, {"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+    {"dddd", "Monday"}, {"ddd", "Mon"}, {"dd", "02"}, {"d", "2"}, {"HH", "15"}, {"hh", "03"}, {"h", "3"},
+    {"mm", "04"}, {"ss", "05"}, {"am/pm", "pm"}, {"AM/PM", "PM"},
+}
+
+
+type ContentControlItem struct {
+    DisplayText string
+    Value       string
+}
+
+
+type ContentControlOptions struct {
+    Type        string
+    Tag         string
+    Alias       string
+    Value       string
+    Placeholder string
+    Lock        string
+    Items       []ContentControlItem
+    Date        time.Time
+    DateFormat  string
+    Checked     bool
+    ImagePath   string
+}
+
+
+type ContentControl struct {
+    doc   *DocxDocument
+    data  *containerElement
+    block bool
+}
+
+
+
+
+func (d *DocxDocument) AddContentControl(style string, options ContentControlOptions) (*ContentControl, error) {
+    control, items, err := d.newContentControl(options)
+    if err != nil {
+        return nil, err
+    }
+    para := &paragraphData{
+        Properties: newParagraphProperties(style),
+        Content:    append(d.takePendingBookmarks(), items...),
+    }
+    control.content().Content = []interface{}{para}
+    control.block = true
+    d.content = append(d.content, control.data)
+    return control, nil
+}
+
+
+func (p *Paragraph) AddContentControl(options ContentControlOptions) (*ContentControl, error) {
+    control, items, err := p.doc.newContentControl(options)
+    if err != nil {
+        return nil, err
+    }
+    control.content().Content = items
+    p.append(control.data)
+    return control, nil
+}
+
+
+func (d *DocxDocument) newContentControl(options ContentControlOptions) (*ContentControl, []interface{}, error) {
+    if options.Type == "" {
+        options.Type = ControlPlainText
+    }
+    switch options.Lock {
+    case "", LockNone, LockControl, LockContent, LockAll:
+    default:
+        return nil, nil, fmt.Errorf("unsupported content control lock: %s", options.Lock)
+    }
+
+    props := &rawXML{XMLName: xml.Name{Local: "w:sdtPr"}}
+    if options.Alias != "" {
+        props.Children = append(props.Children, newValueElement("w:alias", options.Alias))
+    }
+    if options.Tag != "" {
+        props.Children = append(props.Children, newValueElement("w:tag", options.Tag))
+    }
+    props.Children = append(props.Children, newValueElement("w:id", strconv.Itoa(d.nextContentControlID())))
+    if options.Lock != "" {
+        props.Children = append(props.Children, newValueElement("w:lock", options.Lock))
+    }
+
+    text := options.Value
+    var typeElement *rawXML
+    var items []interface{}
+    switch options.Type {
+    case ControlPlainText:
+        typeElement = &rawXML{XMLName: xml.Name{Local: "w:text"}}
+    case ControlRichText:
+    case ControlDropDown, ControlComboBox:
+        typeElement = &rawXML{XMLName: xml.Name{Local: "w:" + options.Type}}
+        matched := text == ""
+        for _, item := range options.Items {
+            if item.DisplayText == "" {
+                item.DisplayText = item.Value
+            }
+            if item.Value == "" {
+                item.Value = item.DisplayText
+            }
+            if item.DisplayText == "" {
+                return nil, nil, fmt.Errorf("content control list items need a display text or a value")
+            }
+            if !matched && (text == item.Value || text == item.DisplayText) {
+                text, matched = item.DisplayText, true
+            }
+            typeElement.Children = append(typeElement.Children, &rawXML{
+                XMLName: xml.Name{Local: "w:listItem"},
+                Attrs: []xml.Attr{
+                    {Name: xml.Name{Local: "w:displayText"}, Value: item.DisplayText},
+                    {Name: xml.Name{Local: "w:value"}, Value: item.Value},
+                },
+            })
+        }
+        if !matched && options.Type == ControlDropDown {
+            return nil, nil, fmt.Errorf("value %q is not one of the drop-down list items", text)
+        }
+    case ControlDate:
+        if options.DateFormat == "" {
+            options.DateFormat = defaultDateFormat
+        }
+        typeElement = newDateElement(options.Date, options.DateFormat)
+        text = ""
+        if !options.Date.IsZero() {
+            text = formatWordDate(options.Date, options.DateFormat)
+        }
+    case ControlCheckbox:
+        typeElement = newCheckboxElement(options.Checked)
+        items = []interface{}{newCheckboxRun(options.Checked, nil)}
+    case ControlPicture:
+        typeElement = &rawXML{XMLName: xml.Name{Local: "w:picture"}}
+        text = ""
+        if options.ImagePath != "" {
+            run, err := d.newImageRun(options.ImagePath)
+            if err != nil {
+                return nil, nil, err
+            }
+            items = []interface{}{run}
+        }
+    default:
+        return nil, nil, fmt.Errorf("unsupported content control type: %s", options.Type)
+    }
+
+    if items == nil && text != "" {
+        items = []interface{}{&paragraphRun{Text: &paragraphRunText{Text: text, Space: "preserve"}}}
+    }
+    if items == nil {
+        placeholder := options.Placeholder
+        if placeholder == "" {
+            placeholder = defaultPlaceholders[options.Type]
+        }
+        props.Children = append(props.Children, &rawXML{XMLName: xml.Name{Local: "w:showingPlcHdr"}})
+        items = []interface{}{&paragraphRun{
+            Properties: &runProperties{Style: &runStyle{Val: StylePlaceholderText}},
+            Text:       &paragraphRunText{Text: placeholder, Space: "preserve"},
+        }}
+    }
+    if typeElement != nil {
+        props.Children = append(props.Children, typeElement)
+    }
+
+    sdt := &containerElement{
+        XMLName: xml.Name{Local: "w:sdt"},
+        Content: []interface{}{props, &containerElement{XMLName: xml.Name{Local: "w:sdtContent"}}},
+    }
+    return &ContentControl{doc: d, data: sdt}, items, nil
+}
+
+
+func newValueElement(name string, value string) *rawXML {
+    return &rawXML{XMLName: xml.Name{Local: name}, Attrs: []xml.Attr{{Name: xml.Name{Local: "w:val"}, Value: value}}}
+}
+
+
+func newDateElement(date time.Time, format string) *rawXML {
+    el := &rawXML{XMLName: xml.Name{Local: "w:date"}}
+    if !date.IsZero() {
+        el.Attrs = []xml.Attr{{Name: xml.Name{Local: "w:fullDate"}, Value: date.Format(controlDateLayout)}}
+    }
+    el.Children = []interface{}{
+        newValueElement("w:dateFormat", format),
+        newValueElement("w:lid", "en-US"),
+        newValueElement("w:storeMappedDataAs", "dateTime"),
+        newValueElement("w:calendar", "gregorian"),
+    }
+    return el
+}
+
+
+func newCheckboxElement(checked bool) *rawXML {
+    state := func(name string, char string) *rawXML {
+        return &rawXML{XMLName: xml.Name{Local: name}, Attrs: []xml.Attr{
+            {Name: xml.Name{Local: "w14:val"}, Value: char},
+            {Name: xml.Name{Local: "w14:font"}, Value: checkboxFont},
+        }}
+    }
+    value := "0"
+    if checked {
+        value = "1"
+    }
+    return &rawXML{
+        XMLName: xml.Name{Local: "w14:checkbox"},
+        Attrs:   []xml.Attr{{Name: xml.Name{Local: "xmlns:w14"}, Value: namespaceW14}},
+        Children: []interface{}{
+            &rawXML{XMLName: xml.Name{Local: "w14:checked"}, Attrs: []xml.Attr{{Name: xml.Name{Local: "w14:val"}, Value: value}}},
+            state("w14:checkedState", checkboxChecked),
+            state("w14:uncheckedState", checkboxUnchecked),
+        },
+    }
+}
+
+
+func newCheckboxRun(checked bool, checkbox *rawXML) *paragraphRun {
+    char, font := checkboxUnchecked, checkboxFont
+    stateName := "uncheckedState"
+    if checked {
+        char, stateName = checkboxChecked, "checkedState"
+    }
+    if checkbox != nil {
+        if state := childByLocalName(checkbox, stateName); state != nil {
+            if value, ok := attrByLocalName(state, "val"); ok {
+                char = value
+            }
+            if value, ok := attrByLocalName(state, "font"); ok {
+                font = value
+            }
+        }
+    }
+    code, err := strconv.ParseUint(char, 16, 32)
+    if err != nil {
+        code = 0x2610
+    }
+    return &paragraphRun{
+        Properties: &runProperties{Fonts: &runFonts{ASCII: font, HAnsi: font, EastAsia: font}},
+        Text:       &paragraphRunText{Text: string(rune(code))},
+    }
+}
+
+
+func formatWordDate(date time.Time, format string) string {
+    var layout strings.Builder
+    for i := 0; i < len(format); {
+        if format[i] == '\'' {
+            end := strings.IndexByte(format[i+1:], '\'')
+            if end < 0 {
+                end = len(format) - i - 1
+            }
+            layout.WriteString(format[i+1 : i+1+end])
+            i += end + 2
+            continue
+        }
+        matched := false
+        for _, token := range wordDateTokens {
+            if strings.HasPrefix(format[i:], token.word) {
+                layout.WriteString(token.layout)
+                i += len(token.word)
+                matched = true
+                break
+            }
+        }
+        if !matched {
+            layout.WriteByte(format[i])
+            i++
+        }
+    }
+    return date.Format(layout.String())
+}
+
+
+func (d *DocxDocument) nextContentControlID() int {
+    used := make(map[int]bool)
+    controls := d.ContentControls()
+    for _, control := range controls {
+        if el := control.property("id"); el != nil {
+            if id, err := strconv.Atoi(attrValue(el, "w:val")); err == nil {
+                used[id] = true
+            }
+        }
+    }
+    id := len(controls) + 1
+    for used[id] {
+        id++
+    }
+    return id
+}
+
+
+
+
+func (d *DocxDocument) ContentControls() []*ContentControl {
+    return d.collectContentControls(d.content, true, []*ContentControl{})
+}
+
+
+func (d *DocxDocument) collectContentControls(items []interface{}, block bool, controls []*ContentControl) []*ContentControl {
+    for _, item := range items {
+        switch v := item.(type) {
+        case *paragraphData:
+            controls = d.collectContentControls(v.Content, false, controls)
+        case *tableData:
+            for _, row := range v.Rows {
+                for _, cell := range row.Cells {
+                    controls = d.collectContentControls(cell.Blocks, true, controls)
+                }
+            }
+        case *containerElement:
+            if v.XMLName.Local == "w:sdt" {
+                controls = append(controls, &ContentControl{doc: d, data: v, block: block})
+            }
+            controls = d.collectContentControls(v.Content, block, controls)
+        }
+    }
+    return controls
+}
+
+
+func (d *DocxDocument) ContentControl(tag string) *ContentControl {
+    for _, control := range d.ContentControls() {
+        if control.Tag() == tag {
+            return control
+        }
+    }
+    return nil
+}
+
+
+func (d *DocxDocument) ContentControlValues() map[string]string {
+    values := make(map[string]string)
+    for _, control := range d.ContentControls() {
+        key := control.Tag()
+        if key == "" {
+            key = control.Alias()
+        }
+        if _, ok := values[key]; key != "" && !ok {
+            values[key] = control.Value()
+        }
+    }
+    return values
+}
+
+
+
+
+func (c *ContentControl) properties() *rawXML {
+    for _, item := range c.data.Content {
+        if el, ok := item.(*rawXML); ok && el.XMLName.Local == "w:sdtPr" {
+            return el
+        }
+    }
+    props := &rawXML{XMLName: xml.Name{Local: "w:sdtPr"}}
+    c.data.Content = append([]interface{}{props}, c.data.Content...)
+    return props
+}
+
+
+func (c *ContentControl) content() *containerElement {
+    for _, item := range c.data.Content {
+        if el, ok := item.(*containerElement); ok && el.XMLName.Local == "w:sdtContent" {
+            return el
+        }
+    }
+    content := &containerElement{XMLName: xml.Name{Local: "w:sdtContent"}}
+    c.data.Content = append(c.data.Content, content)
+    return content
+}
+
+
+func (c *ContentControl) property(local string) *rawXML {
+    return childByLocalName(c.properties(), local)
+}
+
+
+func childByLocalName(el *rawXML, local string) *rawXML {
+    for _, child := range el.elements() {
+        if localName(child.XMLName.Local) == local {
+            return child
+        }
+    }
+    return nil
+}
+
+
+func attrByLocalName(el *rawXML, local string) (string, bool) {
+    for _, a := range el.Attrs {
+        if localName(a.Name.Local) == local {
+            return a.Value, true
+        }
+    }
+    return "", false
+}
+
+
+func localName(name string) string {
+    if i := strings.IndexByte(name, ':'); i >= 0 {
+        return name[i+1:]
+    }
+    return name
+}
+
+
+func (c *ContentControl) Type() string {
+    for _, el := range c.properties().elements() {
+        switch name := localName(el.XMLName.Local); name {
+        case "text":
+            return ControlPlainText
+        case "richText", "dropDownList", "comboBox", "date", "checkbox", "picture", "equation", "citation",
+            "bibliography", "group", "docPartObj", "docPartList":
+            return name
+        }
+    }
+    return ControlRichText
+}
+
+
+func (c *ContentControl) Tag() string {
+    if el := c.property("tag"); el != nil {
+        return attrValue(el, "w:val")
+    }
+    return ""
+}
+
+
+func (c *ContentControl) Alias() string {
+    if el := c.property("alias"); el != nil {
+        return attrValue(el, "w:val")
+    }
+    return ""
+}
+
+
+func (c *ContentControl) Lock() string {
+    if el := c.property("lock"); el != nil {
+        return attrValue(el, "w:val")
+    }
+    return LockNone
+}
+
+
+func (c *ContentControl) SetLock(lock string) error {
+    switch lock {
+    case "", LockNone:
+        c.removeProperty("lock")
+        return nil
+    case LockControl, LockContent, LockAll:
+    default:
+        return fmt.Errorf("unsupported content control lock: %s", lock)
+    }
+    if el := c.property("lock"); el != nil {
+        el.Attrs = []xml.Attr{{Name: xml.Name{Local: "w:val"}, Value: lock}}
+        return nil
+    }
+    c.insertProperty(newValueElement("w:lock", lock), "alias", "tag", "id")
+    return nil
+}
+
+
+func (c *ContentControl) removeProperty(local string) {
+    props := c.properties()
+    children := []interface{}{}
+    for _, child := range props.Children {
+        if el, ok := child.(*rawXML); ok && localName(el.XMLName.Local) == local {
+            continue
+        }
+        children = append(children, child)
+    }
+    props.Children = children
+}
+
+
+func (c *ContentControl) insertProperty(el *rawXML, after ...string) {
+    props := c.properties()
+    index := 0
+    for i, child := range props.Children {
+        if existing, ok := child.(*rawXML); ok {
+            name := localName(existing.XMLName.Local)
+            for _, a := range append([]string{"rPr"}, after...) {
+                if name == a {
+                    index = i + 1
+                }
+            }
+        }
+    }
+    props.Children = append(props.Children[:index], append([]interface{}{el}, props.Children[index:]...)...)
+}
+
+
+func (c *ContentControl) ShowingPlaceholder() bool {
+    return c.property("showingPlcHdr") != nil
+}
+
+
+func (c *ContentControl) Paragraphs() []*Paragraph {
+    paras := []*Paragraph{}
+    if !c.block {
+        return paras
+    }
+    for _, para := range collectParagraphs(c.content().Content, nil) {
+        paras = append(paras, &Paragraph{doc: c.doc, data: para})
+    }
+    return paras
+}
+
+
+func (c *ContentControl) Runs() []*Run {
+    runs := []*Run{}
+    for _, run := range c.runs() {
+        runs = append(runs, &Run{data: run})
+    }
+    return runs
+}
+
+
+func (c *ContentControl) runs() []*paragraphRun {
+    runs := []*paragraphRun{}
+    for _, para := range collectParagraphs(c.content().Content, nil) {
+        runs = collectRuns(para.Content, runs)
+    }
+    return collectRuns(c.content().Content, runs)
+}
+
+
+func (c *ContentControl) Text() string {
+    if c.block {
+        return strings.Join(blockTexts(c.content().Content, []string{}), "\n")
+    }
+    var sb strings.Builder
+    writeContentText(&sb, c.content().Content)
+    return sb.String()
+}
+
+
+func (c *ContentControl) Value() string {
+    switch c.Type() {
+    case ControlCheckbox:
+        return strconv.FormatBool(c.Checked())
+    case ControlPicture:
+        return ""
+    }
+    if c.ShowingPlaceholder() {
+        return ""
+    }
+    return c.Text()
+}
+
+
+func (c *ContentControl) Items() []ContentControlItem {
+    items := []ContentControlItem{}
+    list := c.property("dropDownList")
+    if list == nil {
+        list = c.property("comboBox")
+    }
+    if list == nil {
+        return items
+    }
+    for _, el := range list.elements() {
+        if el.XMLName.Local == "w:listItem" {
+            items = append(items, ContentControlItem{DisplayText: attrValue(el, "w:displayText"), Value: attrValue(el, "w:value")})
+        }
+    }
+    return items
+}
+
+
+func (c *ContentControl) SelectedItem() (ContentControlItem, bool) {
+    if c.ShowingPlaceholder() {
+        return ContentControlItem{}, false
+    }
+    text := c.Text()
+    for _, item := range c.Items() {
+        if item.DisplayText == text {
+            return item, true
+        }
+    }
+    return ContentControlItem{}, false
+}
+
+
+func (c *ContentControl) Checked() bool {
+    checkbox := c.property("checkbox")
+    if checkbox == nil {
+        return false
+    }
+    if el := childByLocalName(checkbox, "checked"); el != nil {
+        value, ok := attrByLocalName(el, "val")
+        return !ok || value == "1" || value == "true" || value == "on"
+    }
+    return false
+}
+
+
+func (c *ContentControl) Date() (time.Time, bool) {
+    el := c.property("date")
+    if el == nil {
+        return time.Time{}, false
+    }
+    value, ok := el.attr("w:fullDate")
+    if !ok {
+        return time.Time{}, false
+    }
+    date, err := time.Parse(time.RFC3339, value)
+    if err != nil {
+        return time.Time{}, false
+    }
+    return date, true
+}
+
+
+func (c *ContentControl) Image() (string, []byte, bool) {
+    for _, run := range c.runs() {
+        if rID, _ := drawingImage(run); rID != "" {
+            return c.doc.getImageData(rID)
+        }
+    }
+    return "", nil, false
+}
+
+
+
+
+func (c *ContentControl) SetValue(value string) error {
+    stored, err := c.showValue(value)
+    if err != nil {
+        return err
+    }
+    return c.storeBoundValue(stored)
+}
+
+
+func (c *ContentControl) showValue(value string) (string, error) {
+    stored := value
+    switch c.Type() {
+    case ControlCheckbox:
+        checked, err := strconv.ParseBool(value)
+        if err != nil {
+            return "", fmt.Errorf("invalid checkbox value %q: %w", value, err)
+        }
+        return strconv.FormatBool(checked), c.showChecked(checked)
+    case ControlDate:
+        for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05"} {
+            if date, err := time.Parse(layout, value); err == nil {
+                return date.Format(controlDateLayout), c.showDate(date)
+            }
+        }
+        return "", fmt.Errorf("invalid date %q: use the YYYY-MM-DD format", value)
+    case ControlPicture:
+        return "", fmt.Errorf("picture content controls hold an image: use SetImage")
+    case ControlDropDown, ControlComboBox:
+        matched := false
+        for _, item := range c.Items() {
+            if value == item.Value || value == item.DisplayText {
+                value, stored, matched = item.DisplayText, item.Value, true
+                break
+            }
+        }
+        if !matched && c.Type() == ControlDropDown {
+            return "", fmt.Errorf("value %q is not one of the drop-down list items", value)
+        }
+    }
+    c.setContent(&paragraphRun{Properties: c.valueProperties(), Text: &paragraphRunText{Text: value, Space: "preserve"}})
+    return stored, nil
+}
+
+
+func (c *ContentControl) SetChecked(checked bool) error {
+    if err := c.showChecked(checked); err != nil {
+        return err
+    }
+    return c.storeBoundValue(strconv.FormatBool(checked))
+}
+
+
+func (c *ContentControl) showChecked(checked bool) error {
+    checkbox := c.property("checkbox")
+    if checkbox == nil {
+        return fmt.Errorf("content control is not a checkbox")
+    }
+    value := "0"
+    if checked {
+        value = "1"
+    }
+    prefix := strings.TrimSuffix(checkbox.XMLName.Local, "checkbox")
+    el := childByLocalName(checkbox, "checked")
+    if el == nil {
+        el = &rawXML{XMLName: xml.Name{Local: prefix + "checked"}}
+        checkbox.Children = append([]interface{}{el}, checkbox.Children...)
+    }
+    el.Attrs = []xml.Attr{{Name: xml.Name{Local: prefix + "val"}, Value: value}}
+
+    run := newCheckboxRun(checked, checkbox)
+    for _, existing := range c.runs() {
+        if existing.Text != nil {
+            existing.Text.Text = run.Text.Text
+            if existing.Properties == nil {
+                existing.Properties = run.Properties
+            }
+            c.removeProperty("showingPlcHdr")
+            return nil
+        }
+    }
+    c.setContent(run)
+    return nil
+}
+
+
+func (c *ContentControl) SetDate(date time.Time) error {
+    if err := c.showDate(date); err != nil {
+        return err
+    }
+    return c.storeBoundValue(date.Format(controlDateLayout))
+}
+
+
+func (c *ContentControl) showDate(date time.Time) error {
+    el := c.property("date")
+    if el == nil {
+        return fmt.Errorf("content control is not a date picker")
+    }
+    format := defaultDateFormat
+    if formatElement := childByLocalName(el, "dateFormat"); formatElement != nil && attrValue(formatElement, "w:val") != "" {
+        format = attrValue(formatElement, "w:val")
+    }
+    attrs := []xml.Attr{{Name: xml.Name{Local: "w:fullDate"}, Value: date.Format(controlDateLayout)}}
+    for _, a := range el.Attrs {
+        if a.Name.Local != "w:fullDate" {
+            attrs = append(attrs, a)
+        }
+    }
+    el.Attrs = attrs
+    c.setContent(&paragraphRun{Properties: c.valueProperties(), Text: &paragraphRunText{Text: formatWordDate(date, format), Space: "preserve"}})
+    return nil
+}
+
+
+func (c *ContentControl) showPlaceholder() {
+    if c.ShowingPlaceholder() {
+        return
+    }
+    c.setContent(&paragraphRun{
+        Properties: &runProperties{Style: &runStyle{Val: StylePlaceholderText}},
+        Text:       &paragraphRunText{Text: defaultPlaceholders[c.Type()], Space: "preserve"},
+    })
+    c.insertProperty(&rawXML{XMLName: xml.Name{Local: "w:showingPlcHdr"}}, "alias", "tag", "id", "lock", "placeholder", "temporary")
+}
+
+
+func (c *ContentControl) SetImage(filePath string) error {
+    if c.Type() != ControlPicture {
+        return fmt.Errorf("content control is not a picture control")
+    }
+    run, err := c.doc.newImageRun(filePath)
+    if err != nil {
+        return err
+    }
+    c.setContent(run)
+    return nil
+}
+
+
+func (c *ContentControl) valueProperties() *runProperties {
+    runs := c.runs()
+    if len(runs) == 0 {
+        return nil
+    }
+    props := cloneRunProperties(runs[0].Properties)
+    if props != nil && props.Style != nil && props.Style.Val == StylePlaceholderText {
+        props.Style = nil
+    }
+    return props
+}
+
+
+func (c *ContentControl) setContent(items ...interface{}) {
+    c.removeProperty("showingPlcHdr")
+    content := c.content()
+    if !c.block {
+        content.Content = items
+        return
+    }
+    for _, block := range content.Content {
+        if para, ok := block.(*paragraphData); ok {
+            para.Content = items
+            content.Content = []interface{}{para}
+            return
+        }
+    }
+    content.Content = []interface{}{&paragraphData{Content: items}}
+}
@@ -0,0 +1,106 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+
+func TestContentControlRoundTrip(t *testing.T) {
+    png, err := base64.StdEncoding.DecodeString(testPNG)
+    if err != nil {
+        t.Fatal(err)
+    }
+    image := filepath.Join(t.TempDir(), "photo.png")
+    if err := os.WriteFile(image, png, 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    doc := NewDocxDocument()
+    options := []ContentControlOptions{
+        {Type: ControlPlainText, Tag: "name", Alias: "Full name", Lock: LockControl},
+        {Type: ControlRichText, Tag: "notes", Value: "Prefers email"},
+        {Type: ControlDropDown, Tag: "team", Value: "eng", Items: []ContentControlItem{{"Engineering", "eng"}, {"Sales", "sales"}}},
+        {Type: ControlComboBox, Tag: "city", Items: []ContentControlItem{{DisplayText: "Oslo"}}},
+        {Type: ControlDate, Tag: "start", Date: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), DateFormat: "yyyy-MM-dd"},
+        {Type: ControlCheckbox, Tag: "remote"},
+        {Type: ControlPicture, Tag: "photo", ImagePath: image},
+    }
+    for _, o := range options {
+        if _, err := doc.AddContentControl(StyleNormal, o); err != nil {
+            t.Fatalf("AddContentControl(%s): %v", o.Type, err)
+        }
+    }
+    inline, err := doc.AddParagraph(StyleNormal).AddContentControl(ContentControlOptions{Type: ControlCheckbox, Tag: "consent", Checked: true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if inline.Text() != "☒" {
+        t.Errorf("checked checkbox shows %q", inline.Text())
+    }
+    if _, err := doc.AddContentControl(StyleNormal, ContentControlOptions{Type: ControlDropDown, Value: "hr", Items: []ContentControlItem{{"Sales", "sales"}}}); err == nil {
+        t.Error("AddContentControl accepted a drop-down value that is not an item")
+    }
+    if _, err := doc.AddContentControl(StyleNormal, ContentControlOptions{Lock: "everything"}); err == nil {
+        t.Error("AddContentControl accepted an unknown lock")
+    }
+
+    reopened := reopenDocument(t, doc)
+    want := map[string]string{
+        "name": "", "notes": "Prefers email", "team": "Engineering", "city": "", "start": "2024-05-06",
+        "remote": "false", "photo": "", "consent": "true",
+    }
+    got := reopened.ContentControlValues()
+    for tag, value := range want {
+        if got[tag] != value {
+            t.Errorf("value of %s = %q, want %q", tag, got[tag], value)
+        }
+    }
+    if len(got) != len(want) {
+        t.Errorf("got values for %d controls, want %d: %v", len(got), len(want), got)
+    }
+
+    name := reopened.ContentControl("name")
+    if name.Type() != ControlPlainText || name.Alias() != "Full name" || name.Lock() != LockControl || !name.ShowingPlaceholder() {
+        t.Errorf("name control: type=%s alias=%q lock=%s placeholder=%v", name.Type(), name.Alias(), name.Lock(), name.ShowingPlaceholder())
+    }
+    if item, ok := reopened.ContentControl("team").SelectedItem(); !ok || item.Value != "eng" {
+        t.Errorf("team selection = %v, %v", item, ok)
+    }
+    if date, ok := reopened.ContentControl("start").Date(); !ok || !date.Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)) {
+        t.Errorf("start date = %v, %v", date, ok)
+    }
+    if _, data, ok := reopened.ContentControl("photo").Image(); !ok || !bytes.Equal(data, png) {
+        t.Errorf("photo control lost its image")
+    }
+
+    if err := reopened.ContentControl("name").SetValue("Grace Hopper"); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.ContentControl("team").SetValue("sales"); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.ContentControl("team").SetValue("hr"); err == nil {
+        t.Error("SetValue accepted a drop-down value that is not an item")
+    }
+    if err := reopened.ContentControl("city").SetValue("Bergen"); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.ContentControl("start").SetValue("2025-01-31"); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.ContentControl("remote").SetChecked(true); err != nil {
+        t.Fatal(err)
+    }
+
+    got = reopenDocument(t, reopened).ContentControlValues()
+    for tag, value := range map[string]string{"name": "Grace Hopper", "team": "Sales", "city": "Bergen", "start": "2025-01-31", "remote": "true"} {
+        if got[tag] != value {
+            t.Errorf("value of %s after filling = %q, want %q", tag, got[tag], value)
+        }
+    }
+}
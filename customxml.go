@@ -0,0 +1,502 @@
+package docx
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/xml"
+    "fmt"
+    "path"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+const (
+    relTypeCustomXML          = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml"
+    relTypeCustomXMLProps     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXmlProps"
+    contentTypeCustomXMLProps = "application/vnd.openxmlformats-officedocument.customXmlProperties+xml"
+    namespaceCustomXML        = "http://schemas.openxmlformats.org/officeDocument/2006/customXml"
+)
+
+var prefixMappingPattern = regexp.MustCompile(`xmlns:([\w.-]+)\s*=\s*(?:'([^']*)'|"([^"]*)")`)
+
+var xpathPrefixPattern = regexp.MustCompile(`([\w.-]+):[\w.*-]`)
+
+var xpathStepPattern = regexp.MustCompile(`^(?:([\w.-]+):)?([\w.-]+|\*)(?:\[(\d+)\])?$`)
+
+
+type customXMLProperties struct {
+    XMLName    xml.Name            `xml:"ds:datastoreItem"`
+    ItemID     string              `xml:"ds:itemID,attr"`
+    XmlnsDS    string              `xml:"xmlns:ds,attr"`
+    SchemaRefs customXMLSchemaRefs `xml:"ds:schemaRefs"`
+}
+
+type customXMLSchemaRefs struct {
+    Refs []customXMLSchemaRef `xml:"ds:schemaRef"`
+}
+
+type customXMLSchemaRef struct {
+    URI string `xml:"ds:uri,attr"`
+}
+
+
+type CustomXMLPart struct {
+    doc       *DocxDocument
+    id        string
+    name      string
+    propsName string
+    data      []byte
+    schemas   []string
+    rel       *relationship
+    changed   bool
+}
+
+
+type DataBinding struct {
+    StoreItemID    string
+    XPath          string
+    PrefixMappings string
+}
+
+
+
+
+func (d *DocxDocument) AddCustomXMLPart(data []byte, schemaURIs ...string) (*CustomXMLPart, error) {
+    if _, err := parseC14N(data); err != nil {
+        return nil, fmt.Errorf("invalid custom XML: %w", err)
+    }
+    guid := make([]byte, 16)
+    if _, err := rand.Read(guid); err != nil {
+        return nil, fmt.Errorf("failed to generate custom XML part ID: %w", err)
+    }
+    guid[6] = guid[6]&0x0f | 0x40
+    guid[8] = guid[8]&0x3f | 0x80
+
+    n := 1
+    for d.customXMLPartTaken(n) {
+        n++
+    }
+    part := &CustomXMLPart{
+        doc:       d,
+        id:        formatGUID(guid),
+        name:      "customXml/item" + strconv.Itoa(n) + ".xml",
+        propsName: "customXml/itemProps" + strconv.Itoa(n) + ".xml",
+        data:      append([]byte{}, data...),
+        schemas:   append([]string{}, schemaURIs...),
+        changed:   true,
+    }
+    documentDir := "word"
+    if d.source != nil {
+        documentDir = d.source.documentDir()
+    }
+    target := part.name
+    if documentDir != "." && documentDir != "" {
+        target = strings.Repeat("../", strings.Count(documentDir, "/")+1) + part.name
+    }
+    part.rel = &relationship{ID: d.nextRID(), Type: relTypeCustomXML, Target: target}
+    d.customXML = append(d.customXML, part)
+    return part, nil
+}
+
+
+func (d *DocxDocument) customXMLPartTaken(n int) bool {
+    for _, name := range []string{"customXml/item" + strconv.Itoa(n) + ".xml", "customXml/itemProps" + strconv.Itoa(n) + ".xml"} {
+        if d.source != nil {
+            if _, ok := d.source.parts[name]; ok {
+                return true
+            }
+        }
+        for _, part := range d.customXML {
+            if part.name == name || part.propsName == name {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+
+func formatGUID(guid []byte) string {
+    h := strings.ToUpper(hex.EncodeToString(guid))
+    return "{" + h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32] + "}"
+}
+
+
+func (d *DocxDocument) loadCustomXML() {
+    for _, rel := range d.source.documentRels {
+        if rel.Type != relTypeCustomXML || rel.TargetMode == "External" {
+            continue
+        }
+        name := d.source.resolveTarget(rel.Target)
+        data, ok := d.source.parts[name]
+        if !ok {
+            continue
+        }
+        part := &CustomXMLPart{doc: d, name: name, data: data}
+        if relsData, ok := d.source.parts[relationshipsPartName(name)]; ok {
+            var rels relationships
+            if err := xml.Unmarshal(relsData, &rels); err == nil {
+                for _, propsRel := range rels.Relationships {
+                    if propsRel.Type == relTypeCustomXMLProps {
+                        part.propsName = path.Join(path.Dir(name), propsRel.Target)
+                    }
+                }
+            }
+        }
+        if props, ok := d.source.parts[part.propsName]; ok && part.propsName != "" {
+            if root, _, err := newDocumentReader(nil).parseTree(props); err == nil {
+                part.id, _ = attrByLocalName(root, "itemID")
+                if refs := childByLocalName(root, "schemaRefs"); refs != nil {
+                    for _, ref := range refs.elements() {
+                        if uri, ok := attrByLocalName(ref, "uri"); ok {
+                            part.schemas = append(part.schemas, uri)
+                        }
+                    }
+                }
+            }
+        }
+        d.customXML = append(d.customXML, part)
+    }
+}
+
+
+func (d *DocxDocument) CustomXMLParts() []*CustomXMLPart {
+    return append([]*CustomXMLPart{}, d.customXML...)
+}
+
+
+func (d *DocxDocument) CustomXMLPart(id string) *CustomXMLPart {
+    for _, part := range d.customXML {
+        if part.id != "" && strings.EqualFold(part.id, id) {
+            return part
+        }
+    }
+    return nil
+}
+
+
+func (d *DocxDocument) customXMLParts() ([]packagePart, error) {
+    parts := []packagePart{}
+    for _, part := range d.customXML {
+        if !part.changed {
+            continue
+        }
+        parts = append(parts, packagePart{name: part.name, data: part.data, rel: part.rel})
+        if part.rel == nil {
+            continue
+        }
+
+        props := customXMLProperties{ItemID: part.id, XmlnsDS: namespaceCustomXML}
+        for _, uri := range part.schemas {
+            props.SchemaRefs.Refs = append(props.SchemaRefs.Refs, customXMLSchemaRef{URI: uri})
+        }
+        data, err := encodeXMLPart(props)
+        if err != nil {
+            return nil, fmt.Errorf("failed to encode %s: %w", part.propsName, err)
+        }
+        parts = append(parts, packagePart{name: part.propsName, contentType: contentTypeCustomXMLProps, data: data})
+        rels, err := encodeXMLPart(relationships{
+            Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
+            Relationships: []relationship{{ID: "rId1", Type: relTypeCustomXMLProps, Target: path.Base(part.propsName)}},
+        })
+        if err != nil {
+            return nil, fmt.Errorf("failed to encode %s: %w", relationshipsPartName(part.name), err)
+        }
+        parts = append(parts, packagePart{name: relationshipsPartName(part.name), data: rels})
+    }
+    return parts, nil
+}
+
+
+
+
+func (p *CustomXMLPart) ID() string {
+    return p.id
+}
+
+
+func (p *CustomXMLPart) SchemaURIs() []string {
+    return append([]string{}, p.schemas...)
+}
+
+
+func (p *CustomXMLPart) Data() []byte {
+    return append([]byte{}, p.data...)
+}
+
+
+func (p *CustomXMLPart) SetData(data []byte) error {
+    if _, err := parseC14N(data); err != nil {
+        return fmt.Errorf("invalid custom XML: %w", err)
+    }
+    p.data = append([]byte{}, data...)
+    p.changed = true
+    p.refreshBindings()
+    return nil
+}
+
+
+func (p *CustomXMLPart) namespaceMappings() map[string]string {
+    mappings := make(map[string]string)
+    root, err := parseC14N(p.data)
+    if err != nil {
+        return mappings
+    }
+    for prefix, uri := range root.namespaces() {
+        if prefix != "" {
+            mappings[prefix] = uri
+        }
+    }
+    if uri, ok := root.namespaces()[""]; ok && mappings["ns0"] == "" {
+        mappings["ns0"] = uri
+    }
+    return mappings
+}
+
+
+func (p *CustomXMLPart) Value(xpath string) (string, error) {
+    root, err := parseC14N(p.data)
+    if err != nil {
+        return "", fmt.Errorf("invalid custom XML: %w", err)
+    }
+    node, attr, err := selectXPath(root, xpath, p.namespaceMappings())
+    if err != nil {
+        return "", err
+    }
+    if attr != nil {
+        return attr.Value, nil
+    }
+    return node.text(), nil
+}
+
+
+func (p *CustomXMLPart) SetValue(xpath string, value string) error {
+    if err := p.setValue(xpath, p.namespaceMappings(), value); err != nil {
+        return err
+    }
+    p.refreshBindings()
+    return nil
+}
+
+
+func (p *CustomXMLPart) setValue(xpath string, mappings map[string]string, value string) error {
+    root, err := parseC14N(p.data)
+    if err != nil {
+        return fmt.Errorf("invalid custom XML: %w", err)
+    }
+    node, attr, err := selectXPath(root, xpath, mappings)
+    if err != nil {
+        return err
+    }
+    if attr != nil {
+        attr.Value = value
+    } else {
+        if len(node.elements("")) > 0 {
+            return fmt.Errorf("XPath %s selects an element with child elements", xpath)
+        }
+        node.children = []interface{}{value}
+    }
+    p.data = append([]byte(xml.Header), canonicalize(root, true)...)
+    p.changed = true
+    return nil
+}
+
+
+func (p *CustomXMLPart) refreshBindings() {
+    for _, control := range p.doc.ContentControls() {
+        binding, ok := control.DataBinding()
+        if !ok || !strings.EqualFold(binding.StoreItemID, p.id) {
+            continue
+        }
+        control.refreshBinding(p, binding)
+    }
+}
+
+
+
+
+func parsePrefixMappings(mappings string) map[string]string {
+    result := make(map[string]string)
+    for _, m := range prefixMappingPattern.FindAllStringSubmatch(mappings, -1) {
+        result[m[1]] = m[2] + m[3]
+    }
+    return result
+}
+
+
+func formatPrefixMappings(mappings map[string]string, xpath string) string {
+    parts := []string{}
+    for _, m := range xpathPrefixPattern.FindAllStringSubmatch(xpath, -1) {
+        entry := "xmlns:" + m[1] + "='" + mappings[m[1]] + "'"
+        if mappings[m[1]] != "" && !containsString(parts, entry) {
+            parts = append(parts, entry)
+        }
+    }
+    return strings.Join(parts, " ")
+}
+
+
+func selectXPath(root *c14nNode, xpath string, mappings map[string]string) (*c14nNode, *xml.Attr, error) {
+    if !strings.HasPrefix(xpath, "/") || strings.HasPrefix(xpath, "//") {
+        return nil, nil, fmt.Errorf("unsupported XPath %s: only absolute paths are supported", xpath)
+    }
+    steps := strings.Split(strings.TrimPrefix(xpath, "/"), "/")
+    if n := len(steps); n > 0 && steps[n-1] == "text()" {
+        steps = steps[:n-1]
+    }
+
+    parent := &c14nNode{children: []interface{}{root}}
+    node := (*c14nNode)(nil)
+    for i, step := range steps {
+        if strings.HasPrefix(step, "@") && i == len(steps)-1 && node != nil {
+            prefix, local, found := strings.Cut(strings.TrimPrefix(step, "@"), ":")
+            if !found {
+                prefix, local = "", prefix
+            }
+            for j, a := range node.attrs {
+                uri := ""
+                if a.Name.Space != "" {
+                    uri = node.namespaces()[a.Name.Space]
+                }
+                if a.Name.Local == local && uri == mappings[prefix] && a.Name.Space != "xmlns" && a.Name.Local != "xmlns" {
+                    return node, &node.attrs[j], nil
+                }
+            }
+            return nil, nil, fmt.Errorf("XPath %s matches no node", xpath)
+        }
+
+        m := xpathStepPattern.FindStringSubmatch(step)
+        if m == nil {
+            return nil, nil, fmt.Errorf("unsupported XPath step %q in %s", step, xpath)
+        }
+        uri, ok := mappings[m[1]]
+        if m[1] != "" && !ok {
+            return nil, nil, fmt.Errorf("XPath %s uses undeclared prefix %s", xpath, m[1])
+        }
+        position := 1
+        if m[3] != "" {
+            position, _ = strconv.Atoi(m[3])
+        }
+        node = nil
+        for _, child := range parent.elements("") {
+            if m[2] != "*" && (child.name.Local != m[2] || child.namespaces()[child.name.Space] != uri) {
+                continue
+            }
+            if position--; position == 0 {
+                node = child
+                break
+            }
+        }
+        if node == nil {
+            return nil, nil, fmt.Errorf("XPath %s matches no node", xpath)
+        }
+        parent = node
+    }
+    if node == nil {
+        return nil, nil, fmt.Errorf("XPath %s matches no node", xpath)
+    }
+    return node, nil, nil
+}
+
+
+
+
+func (c *ContentControl) Bind(part *CustomXMLPart, xpath string) error {
+    if part == nil || part.doc != c.doc {
+        return fmt.Errorf("custom XML part does not belong to this document")
+    }
+    switch c.Type() {
+    case ControlPlainText, ControlDropDown, ControlComboBox, ControlDate, ControlCheckbox:
+    default:
+        return fmt.Errorf("%s content controls cannot be bound to custom XML", c.Type())
+    }
+    if part.id == "" {
+        return fmt.Errorf("custom XML part has no item ID")
+    }
+    mappings := part.namespaceMappings()
+    root, err := parseC14N(part.data)
+    if err != nil {
+        return fmt.Errorf("invalid custom XML: %w", err)
+    }
+    if _, _, err := selectXPath(root, xpath, mappings); err != nil {
+        return err
+    }
+
+    binding := DataBinding{StoreItemID: part.id, XPath: xpath, PrefixMappings: formatPrefixMappings(mappings, xpath)}
+    el := &rawXML{XMLName: xml.Name{Local: "w:dataBinding"}}
+    if binding.PrefixMappings != "" {
+        el.Attrs = append(el.Attrs, xml.Attr{Name: xml.Name{Local: "w:prefixMappings"}, Value: binding.PrefixMappings})
+    }
+    el.Attrs = append(el.Attrs,
+        xml.Attr{Name: xml.Name{Local: "w:xpath"}, Value: binding.XPath},
+        xml.Attr{Name: xml.Name{Local: "w:storeItemID"}, Value: binding.StoreItemID},
+    )
+    c.removeProperty("dataBinding")
+    c.insertProperty(el, "alias", "tag", "id", "lock", "placeholder", "temporary", "showingPlcHdr")
+    c.refreshBinding(part, binding)
+    return nil
+}
+
+
+func (c *ContentControl) Unbind() {
+    c.removeProperty("dataBinding")
+}
+
+
+func (c *ContentControl) DataBinding() (DataBinding, bool) {
+    el := c.property("dataBinding")
+    if el == nil {
+        return DataBinding{}, false
+    }
+    return DataBinding{
+        StoreItemID:    attrValue(el, "w:storeItemID"),
+        XPath:          attrValue(el, "w:xpath"),
+        PrefixMappings: attrValue(el, "w:prefixMappings"),
+    }, true
+}
+
+
+func (c *ContentControl) boundPart() (*CustomXMLPart, DataBinding, bool) {
+    binding, ok := c.DataBinding()
+    if !ok {
+        return nil, binding, false
+    }
+    part := c.doc.CustomXMLPart(binding.StoreItemID)
+    return part, binding, part != nil
+}
+
+
+func (c *ContentControl) refreshBinding(part *CustomXMLPart, binding DataBinding) {
+    root, err := parseC14N(part.data)
+    if err != nil {
+        return
+    }
+    node, attr, err := selectXPath(root, binding.XPath, parsePrefixMappings(binding.PrefixMappings))
+    if err != nil {
+        return
+    }
+    value := node.text()
+    if attr != nil {
+        value = attr.Value
+    }
+    if value == "" && c.Type() != ControlCheckbox {
+        c.showPlaceholder()
+        return
+    }
+    if _, err := c.showValue(value); err != nil {
+        c.setContent(&paragraphRun{Properties: c.valueProperties(), Text: &paragraphRunText{Text: value, Space: "preserve"}})
+    }
+}
+
+
+func (c *ContentControl) storeBoundValue(value string) error {
+    part, binding, ok := c.boundPart()
+    if !ok {
+        return nil
+    }
+    if err := part.setValue(binding.XPath, parsePrefixMappings(binding.PrefixMappings), value); err != nil {
+        return fmt.Errorf("failed to update bound custom XML: %w", err)
+    }
+    return nil
+}
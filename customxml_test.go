@@ -0,0 +1,164 @@
+package docx
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+const orderXML = `<?xml version="1.0" encoding="UTF-8"?>
+<o:order xmlns:o="urn:acme:order" id="A-1"><o:customer>Ada</o:customer><o:items><o:item>Pen</o:item><o:item>Ink</o:item></o:items><o:rush>true</o:rush><o:due>2024-05-06</o:due></o:order>`
+
+
+func TestCustomXMLDataBindingRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    for _, malformed := range []string{"<order><unclosed></order>", "<order><open>", "</order>", ""} {
+        if _, err := doc.AddCustomXMLPart([]byte(malformed)); err == nil {
+            t.Errorf("AddCustomXMLPart accepted %q", malformed)
+        }
+    }
+    part, err := doc.AddCustomXMLPart([]byte(orderXML), "urn:acme:order")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    bindings := []struct {
+        options ContentControlOptions
+        xpath   string
+        text    string
+    }{
+        {ContentControlOptions{Tag: "customer"}, "/o:order/o:customer", "Ada"},
+        {ContentControlOptions{Tag: "item"}, "/o:order/o:items/o:item[2]", "Ink"},
+        {ContentControlOptions{Tag: "order"}, "/o:order/@id", "A-1"},
+        {ContentControlOptions{Tag: "rush", Type: ControlCheckbox}, "/o:order/o:rush", "☒"},
+        {ContentControlOptions{Tag: "due", Type: ControlDate, DateFormat: "d MMMM yyyy"}, "/o:order/o:due/text()", "6 May 2024"},
+    }
+    for _, b := range bindings {
+        control, err := doc.AddContentControl(StyleNormal, b.options)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if err := control.Bind(part, b.xpath); err != nil {
+            t.Fatalf("Bind(%s): %v", b.xpath, err)
+        }
+        if control.Text() != b.text {
+            t.Errorf("control bound to %s shows %q, want %q", b.xpath, control.Text(), b.text)
+        }
+    }
+
+    rich, err := doc.AddContentControl(StyleNormal, ContentControlOptions{Type: ControlRichText})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := rich.Bind(part, "/o:order/o:customer"); err == nil {
+        t.Error("Bind accepted a rich text control")
+    }
+    plain := doc.ContentControl("customer")
+    for _, xpath := range []string{"/o:order/o:missing", "//o:customer", "/x:order", "o:order"} {
+        if err := plain.Bind(part, xpath); err == nil {
+            t.Errorf("Bind(%s) succeeded", xpath)
+        }
+    }
+    if err := plain.Bind(part, "/o:order/o:customer"); err != nil {
+        t.Fatal(err)
+    }
+
+    dir := t.TempDir()
+    filename := filepath.Join(dir, "bound.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, parts, err := readPackageParts(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    xmlText(t, "customXml/itemProps1.xml", parts["customXml/itemProps1.xml"])
+    if !strings.Contains(string(parts["customXml/itemProps1.xml"]), `itemID="`+part.ID()+`"`) ||
+        !strings.Contains(string(parts["customXml/itemProps1.xml"]), `uri="urn:acme:order"`) {
+        t.Errorf("item properties = %s", parts["customXml/itemProps1.xml"])
+    }
+    if !strings.Contains(string(parts["word/_rels/document.xml.rels"]), `Target="../customXml/item1.xml"`) {
+        t.Error("document relationships do not reference the custom XML item")
+    }
+    if !strings.Contains(string(parts["customXml/_rels/item1.xml.rels"]), `Target="itemProps1.xml"`) {
+        t.Error("custom XML item is not related to its properties part")
+    }
+
+    reopened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    loaded := reopened.CustomXMLPart(part.ID())
+    if loaded == nil || len(reopened.CustomXMLParts()) != 1 {
+        t.Fatalf("reopened document has %d custom XML parts", len(reopened.CustomXMLParts()))
+    }
+    if !equalStrings(loaded.SchemaURIs(), []string{"urn:acme:order"}) {
+        t.Errorf("SchemaURIs() = %q", loaded.SchemaURIs())
+    }
+    binding, ok := reopened.ContentControl("item").DataBinding()
+    if !ok || binding.XPath != "/o:order/o:items/o:item[2]" || binding.PrefixMappings != "xmlns:o='urn:acme:order'" || !strings.EqualFold(binding.StoreItemID, part.ID()) {
+        t.Errorf("DataBinding() = %+v, %v", binding, ok)
+    }
+
+    if err := reopened.ContentControl("customer").SetValue("Grace"); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.ContentControl("rush").SetChecked(false); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.ContentControl("due").SetValue("2025-01-31"); err != nil {
+        t.Fatal(err)
+    }
+    if err := loaded.SetValue("/o:order/o:items/o:item[2]", "Quill"); err != nil {
+        t.Fatal(err)
+    }
+    if got := reopened.ContentControl("item").Text(); got != "Quill" {
+        t.Errorf("control shows %q after its custom XML changed", got)
+    }
+    if err := loaded.SetValue("/o:order/o:items", "x"); err == nil {
+        t.Error("SetValue replaced an element with child elements")
+    }
+
+    updated := reopenDocument(t, reopened)
+    values := updated.ContentControlValues()
+    for tag, want := range map[string]string{"customer": "Grace", "item": "Quill", "order": "A-1", "rush": "false", "due": "31 January 2025"} {
+        if values[tag] != want {
+            t.Errorf("value of %s = %q, want %q", tag, values[tag], want)
+        }
+    }
+    stored := updated.CustomXMLPart(part.ID())
+    for xpath, want := range map[string]string{
+        "/o:order/o:customer":        "Grace",
+        "/o:order/o:items/o:item[2]": "Quill",
+        "/o:order/o:rush":            "false",
+        "/o:order/o:due":             time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC).Format(controlDateLayout),
+    } {
+        if got, err := stored.Value(xpath); err != nil || got != want {
+            t.Errorf("custom XML %s = %q, %v; want %q", xpath, got, err, want)
+        }
+    }
+}
+
+
+func TestCustomXMLDefaultNamespace(t *testing.T) {
+    doc := NewDocxDocument()
+    part, err := doc.AddCustomXMLPart([]byte(`<root xmlns="urn:plain"><value>1</value><value>2</value></root>`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, err := part.Value("/ns0:root/ns0:value[2]"); err != nil || got != "2" {
+        t.Errorf("Value() = %q, %v", got, err)
+    }
+    if _, err := part.Value("/root/value"); err == nil {
+        t.Error("unprefixed steps matched elements in the default namespace")
+    }
+    if err := part.SetData([]byte("not xml <")); err == nil {
+        t.Error("SetData accepted malformed XML")
+    }
+}
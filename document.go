@@ -10,17 +10,31 @@ import (
     _ "image/png"
     "io"
     "os"
+    "path"
+    "regexp"
     "strings"
 )
 
 const (
-    StyleNormal   = "Normal"
-    StyleHeading1 = "Heading1"
-    StyleHeading2 = "Heading2"
-    StyleHeading3 = "Heading3"
-    StyleHeading4 = "Heading4"
-    FormatBold    = "Bold"
-    FormatItalic  = "Italic"
+    StyleNormal          = "Normal"
+    StyleHeading1        = "Heading1"
+    StyleHeading2        = "Heading2"
+    StyleHeading3        = "Heading3"
+    StyleHeading4        = "Heading4"
+    StyleHeading5        = "Heading5"
+    StyleHeading6        = "Heading6"
+    StyleCaption         = "Caption"
+    StyleTableOfFigures  = "TableofFigures"
+    StyleListParagraph   = "ListParagraph"
+    StyleQuote           = "Quote"
+    StyleSourceCode      = "SourceCode"
+    StyleVerbatimChar    = "VerbatimChar"
+    StyleHyperlink       = "Hyperlink"
+    StylePlaceholderText = "PlaceholderText"
+    FormatBold           = "Bold"
+    FormatItalic         = "Italic"
+    FormatUnderline      = "Underline"
+    FormatStrike         = "Strike"
 )
 
 const emusPerPixel = 9525
@@ -33,11 +47,62 @@ type italicProperty struct {
     XMLName xml.Name `xml:"w:i"`
 }
 
+type runFonts struct {
+    XMLName  xml.Name   `xml:"w:rFonts"`
+    ASCII    string     `xml:"w:ascii,attr,omitempty"`
+    HAnsi    string     `xml:"w:hAnsi,attr,omitempty"`
+    EastAsia string     `xml:"w:eastAsia,attr,omitempty"`
+    CS       string     `xml:"w:cs,attr,omitempty"`
+    Attrs    []xml.Attr `xml:",any,attr"`
+}
+
+type colorProperty struct {
+    XMLName xml.Name   `xml:"w:color"`
+    Val     string     `xml:"w:val,attr"`
+    Attrs   []xml.Attr `xml:",any,attr"`
+}
+
+type halfPointProperty struct {
+    Val int `xml:"w:val,attr"`
+}
+
+type underlineProperty struct {
+    XMLName xml.Name   `xml:"w:u"`
+    Val     string     `xml:"w:val,attr"`
+    Attrs   []xml.Attr `xml:",any,attr"`
+}
+
+type runStyle struct {
+    XMLName xml.Name `xml:"w:rStyle"`
+    Val     string   `xml:"w:val,attr"`
+}
+
 type runProperties struct {
-    XMLName xml.Name        `xml:"w:rPr"`
-    Bold    *boldProperty   `xml:"w:b,omitempty"`
-    Italic  *italicProperty `xml:"w:i,omitempty"`
+    XMLName   xml.Name           `xml:"w:rPr"`
+    Style     *runStyle          `xml:"w:rStyle,omitempty"`
+    Fonts     *runFonts          `xml:"w:rFonts,omitempty"`
+    Bold      *boldProperty      `xml:"w:b,omitempty"`
+    Italic    *italicProperty    `xml:"w:i,omitempty"`
+    Strike    *onOffProperty     `xml:"w:strike,omitempty"`
+    Color     *colorProperty     `xml:"w:color,omitempty"`
+    Size      *halfPointProperty `xml:"w:sz,omitempty"`
+    SizeCs    *halfPointProperty `xml:"w:szCs,omitempty"`
+    Underline *underlineProperty `xml:"w:u,omitempty"`
+    Extra     []*rawXML          `xml:",any"`
+}
 
+
+var runPropertiesOrder = []string{
+    "w:rStyle", "w:rFonts", "w:b", "w:bCs", "w:i", "w:iCs", "w:caps", "w:smallCaps", "w:strike", "w:dstrike",
+    "w:outline", "w:shadow", "w:emboss", "w:imprint", "w:noProof", "w:snapToGrid", "w:vanish", "w:webHidden",
+    "w:color", "w:spacing", "w:w", "w:kern", "w:position", "w:sz", "w:szCs", "w:highlight", "w:u", "w:effect",
+    "w:bdr", "w:shd", "w:fitText", "w:vertAlign", "w:rtl", "w:cs", "w:em", "w:lang", "w:eastAsianLayout",
+    "w:specVanish", "w:oMath", "w:rPrChange",
+}
+
+
+func (p *runProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    return marshalOrdered(e, start, p, runPropertiesOrder)
 }
 
 type paragraphRunText struct {
@@ -48,10 +113,29 @@ type paragraphRunText struct {
 
 type paragraphRun struct {
     XMLName    xml.Name          `xml:"w:r"`
+    Attrs      []xml.Attr        `xml:",any,attr"`
     Properties *runProperties    `xml:"w:rPr,omitempty"`
-    Break      *struct{ XMLName xml.Name `xml:"w:br"` } `xml:"w:br,omitempty"`
+    Break      *runBreak         `xml:"w:br,omitempty"`
+    Tab        *runTab           `xml:"w:tab,omitempty"`
     Drawing    *Drawing          `xml:"w:drawing,omitempty"`
     Text       *paragraphRunText `xml:"w:t,omitempty"`
+    InstrText  *instrText        `xml:"w:instrText,omitempty"`
+    FieldChar  *fieldChar        `xml:"w:fldChar,omitempty"`
+    Extra      []*rawXML         `xml:",any"`
+}
+
+
+func (r *paragraphRun) hasDrawing() bool {
+    if r.Drawing != nil {
+        return true
+    }
+    for _, extra := range r.Extra {
+        switch extra.XMLName.Local {
+        case "w:drawing", "w:pict", "w:object", "mc:AlternateContent":
+            return true
+        }
+    }
+    return false
 }
 
 type paragraphStyle struct {
@@ -59,22 +143,98 @@ type paragraphStyle struct {
     Val     string   `xml:"w:val,attr"`
 }
 
+type justification struct {
+    XMLName xml.Name `xml:"w:jc"`
+    Val     string   `xml:"w:val,attr"`
+}
+
 type paragraphProperties struct {
-    XMLName xml.Name        `xml:"w:pPr"`
-    Style   *paragraphStyle `xml:"w:pStyle,omitempty"`
+    XMLName       xml.Name             `xml:"w:pPr"`
+    Style         *paragraphStyle      `xml:"w:pStyle,omitempty"`
+    KeepNext      *onOffProperty       `xml:"w:keepNext,omitempty"`
+    Numbering     *numberingProperties `xml:"w:numPr,omitempty"`
+    Tabs          *tabStops            `xml:"w:tabs,omitempty"`
+    Justification *justification       `xml:"w:jc,omitempty"`
+    Extra         []*rawXML            `xml:",any"`
+}
 
+
+var paragraphPropertiesOrder = []string{
+    "w:pStyle", "w:keepNext", "w:keepLines", "w:pageBreakBefore", "w:framePr", "w:widowControl", "w:numPr",
+    "w:suppressLineNumbers", "w:pBdr", "w:shd", "w:tabs", "w:suppressAutoHyphens", "w:kinsoku", "w:wordWrap",
+    "w:overflowPunct", "w:topLinePunct", "w:autoSpaceDE", "w:autoSpaceDN", "w:bidi", "w:adjustRightInd",
+    "w:snapToGrid", "w:spacing", "w:ind", "w:contextualSpacing", "w:mirrorIndents", "w:suppressOverlap", "w:jc",
+    "w:textDirection", "w:textAlignment", "w:textboxTightWrap", "w:outlineLvl", "w:divId", "w:cnfStyle",
+    "w:rPr", "w:sectPr", "w:pPrChange",
+}
+
+
+func (p *paragraphProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    return marshalOrdered(e, start, p, paragraphPropertiesOrder)
 }
 
 type paragraphData struct {
     XMLName    xml.Name             `xml:"w:p"`
+    Attrs      []xml.Attr           `xml:",any,attr"`
     Properties *paragraphProperties `xml:"w:pPr,omitempty"`
-    Runs       []paragraphRun       `xml:"w:r,omitempty"`
+    Content    []interface{}        `xml:",any,omitempty"`
+}
+
+
+func (p *paragraphData) runs() []*paragraphRun {
+    return collectRuns(p.Content, []*paragraphRun{})
+}
+
+
+func collectRuns(content []interface{}, runs []*paragraphRun) []*paragraphRun {
+    for _, c := range content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            runs = append(runs, v)
+        case *containerElement:
+            runs = collectRuns(v.Content, runs)
+        }
+    }
+    return runs
+}
+
+
+func (p *paragraphData) text() string {
+    var sb strings.Builder
+    writeContentText(&sb, p.Content)
+    return sb.String()
+}
+
+
+func writeContentText(sb *strings.Builder, content []interface{}) {
+    for _, c := range content {
+        switch v := c.(type) {
+        case *containerElement:
+            writeContentText(sb, v.Content)
+        case *paragraphRun:
+            if v.Tab != nil {
+                sb.WriteString("\t")
+            }
+            if v.Break != nil && (v.Break.Type == "" || v.Break.Type == BreakTextWrapping) {
+                sb.WriteString("\n")
+            }
+            if v.Text != nil {
+                sb.WriteString(v.Text.Text)
+            }
+        case *simpleField:
+            for _, run := range v.Runs {
+                if run.Text != nil {
+                    sb.WriteString(run.Text.Text)
+                }
+            }
+        }
+    }
 }
 
 type documentBodyData struct {
-    XMLName    xml.Name        `xml:"w:body"`
-    Paragraphs []paragraphData `xml:"w:p"`
-    SectPr     *sectPr         `xml:"w:sectPr"`
+    XMLName xml.Name      `xml:"w:body"`
+    Blocks  []interface{} `xml:",any"`
+    SectPr  *sectPr       `xml:"w:sectPr,omitempty"`
 }
 
 
@@ -82,6 +242,7 @@ type pgSz struct {
     XMLName xml.Name `xml:"w:pgSz"`
     W       uint     `xml:"w:w,attr"`
     H       uint     `xml:"w:h,attr"`
+    Orient  string   `xml:"w:orient,attr,omitempty"`
 }
 
 type pgMar struct {
@@ -95,18 +256,46 @@ type pgMar struct {
     Gutter  uint     `xml:"w:gutter,attr"`
 }
 
+type sectionType struct {
+    XMLName xml.Name `xml:"w:type"`
+    Val     string   `xml:"w:val,attr"`
+}
+
+type sectionColumns struct {
+    XMLName xml.Name `xml:"w:cols"`
+    Space   uint     `xml:"w:space,attr,omitempty"`
+    Num     uint     `xml:"w:num,attr,omitempty"`
+}
+
+type documentGrid struct {
+    XMLName   xml.Name `xml:"w:docGrid"`
+    Type      string   `xml:"w:type,attr,omitempty"`
+    LinePitch uint     `xml:"w:linePitch,attr,omitempty"`
+    CharSpace int      `xml:"w:charSpace,attr,omitempty"`
+}
+
 type sectPr struct {
-    XMLName xml.Name `xml:"w:sectPr"`
-    PgSz    pgSz     `xml:"w:pgSz"`
-    PgMar   pgMar    `xml:"w:pgMar"`
-    Cols    struct {
-        XMLName xml.Name `xml:"w:cols"`
-        Space   uint     `xml:"w:space,attr"`
-    } `xml:"w:cols"`
-    DocGrid struct {
-        XMLName   xml.Name `xml:"w:docGrid"`
-        LinePitch uint     `xml:"w:linePitch,attr"`
-    } `xml:"w:docGrid"`
+    XMLName xml.Name        `xml:"w:sectPr"`
+    Attrs   []xml.Attr      `xml:",any,attr"`
+    Type    *sectionType    `xml:"w:type,omitempty"`
+    PgSz    *pgSz           `xml:"w:pgSz,omitempty"`
+    PgMar   *pgMar          `xml:"w:pgMar,omitempty"`
+    Cols    *sectionColumns `xml:"w:cols,omitempty"`
+    DocGrid *documentGrid   `xml:"w:docGrid,omitempty"`
+    Extra   []*rawXML       `xml:",any"`
+}
+
+
+var sectionPropertiesOrder = []string{
+    "w:headerReference", "w:footerReference", "w:footnotePr", "w:endnotePr", "w:type", "w:pgSz", "w:pgMar",
+    "w:paperSrc", "w:pgBorders", "w:lnNumType", "w:pgNumType", "w:cols", "w:formProt", "w:vAlign",
+    "w:noEndnote", "w:titlePg", "w:textDirection", "w:bidi", "w:rtlGutter", "w:docGrid", "w:printerSettings",
+    "w:sectPrChange",
+}
+
+
+func (s *sectPr) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    return marshalOrdered(e, start, s, sectionPropertiesOrder)
 }
 
 
@@ -117,6 +306,7 @@ type xmlRootDocument struct {
     XmlnsPic string          `xml:"xmlns:pic,attr"`
     XmlnsR  string           `xml:"xmlns:r,attr"`
     XmlnsW  string           `xml:"xmlns:w,attr"`
+    Attrs   []xml.Attr       `xml:",any,attr"`
 
     Body documentBodyData `xml:"w:body"`
 }
@@ -125,32 +315,128 @@ type xmlRootDocument struct {
 type Document interface {
     AddText(style string, textData string, formatOptions ...string)
     AddNewLine()
+    AddParagraph(style string) *Paragraph
+    AddTable(rows int, cols int) *Table
+    AddList(ordered bool) *List
+    AddHyperlink(style string, text string, target string, formatOptions ...string)
+    AddSectionBreak(breakType string) (*SectionBreak, error)
+    Body() *Body
     AddImage(filepath string) error
+    StartBookmark(name string) error
+    EndBookmark(name string) error
+    AddRef(style string, bookmark string, options ...string)
+    AddPageRef(style string, bookmark string, options ...string)
+    AddField(style string, instruction string, result string, formatOptions ...string)
+    AddSimpleField(style string, instruction string, result string, formatOptions ...string)
+    SetUpdateFieldsOnOpen(update bool)
+    SetCompatibilityMode(mode int) error
+    SetDefaultTabStop(twips int) error
+    SetEvenAndOddHeaders(enabled bool)
+    SetTrackRevisions(enabled bool)
+    SetZoom(percent int) error
+    SetProofState(spelling string, grammar string) error
+    SetDocumentVariable(name string, value string) error
+    DocumentVariable(name string) (string, bool)
+    RemoveDocumentVariable(name string)
+    EmbedFont(family string, style string, filePath string) error
+    EmbedFontData(family string, style string, data []byte) error
+    Protect(mode string, password string) error
+    Unprotect()
+    AddContentControl(style string, options ContentControlOptions) (*ContentControl, error)
+    ContentControls() []*ContentControl
+    ContentControl(tag string) *ContentControl
+    ContentControlValues() map[string]string
+    AddCustomXMLPart(data []byte, schemaURIs ...string) (*CustomXMLPart, error)
+    CustomXMLParts() []*CustomXMLPart
+    CustomXMLPart(id string) *CustomXMLPart
+    AddFormField(style string, options FormFieldOptions) (*FormField, error)
+    FormFields() []*FormField
+    FormField(name string) *FormField
+    FormFieldValues() map[string]string
+    Properties() DocumentProperties
+    SetProperties(props DocumentProperties)
+    CustomProperties() []CustomProperty
+    CustomProperty(name string) (interface{}, bool)
+    SetCustomProperty(name string, value interface{}) error
+    RemoveCustomProperty(name string)
+    AddCaption(label string, text string, formatOptions ...string) error
+    AddTableOfFigures(label string)
+    AddLineBreak()
+    AddPageBreak()
+    AddColumnBreak()
+    AddTextWrappingBreak(clear string)
+    AddTab()
+    SetTabStops(stops ...TabStop) error
+    Replace(old string, new string, formatOptions ...string) int
+    Text() string
+    ReplaceRegexp(pattern *regexp.Regexp, replacement string, formatOptions ...string) int
     renderContent(w io.Writer) error
+    renderSettings(w io.Writer) error
     getImages() map[string][]byte
     getImageContentTypes() map[string]string
     getImageRelationships() []relationship
+    getHyperlinkRelationships() []relationship
+    renderParts() ([]packagePart, error)
+    getSourcePackage() *sourcePackage
+    getContent() []interface{}
+    renderedContent() []interface{}
+    getRelationship(rID string) (relationship, bool)
+    getImageData(rID string) (string, []byte, bool)
+    getRelatedPart(relType string) ([]byte, bool)
+    getListLevel(p *paragraphData) (int, bool, bool)
+    getListFormat(p *paragraphData, level int, ordered bool) (int, string, int)
+    getSectionProperties() *sectPr
 }
 
 
 type DocxDocument struct {
-    content           []paragraphData
+    content           []interface{}
+    sectPr            *sectPr
     images            map[string][]byte
     imageContentTypes map[string]string
     imageRels         []relationship
     imageCounter      uint
     lastRID           int
+    bookmarks         map[string]*bookmarkState
+    pendingBookmarks  []interface{}
+    bookmarkCounter   uint
+    refFields         []refField
+    seqFields         map[*fieldChar]bool
+    tablesOfFigures   []tableOfFigures
+    settings          documentSettings
+    source            *sourcePackage
+    numberingFormats  map[int]map[int]string
+    numbering         *listNumbering
+    lastAbstractNumID int
+    lastNumID         int
+    hyperlinkRels     []relationship
+    metadata          documentMetadata
+    fonts             []*embeddedFont
+    fontTableRel      *relationship
+    customXML         []*CustomXMLPart
 }
 
 
 func NewDocxDocument() *DocxDocument {
     return &DocxDocument{
-        content:           []paragraphData{},
+        content:           []interface{}{},
+        sectPr:            newSectionProperties(),
         images:            make(map[string][]byte),
         imageContentTypes: make(map[string]string),
         imageRels:         []relationship{},
         imageCounter:      0,
-        lastRID:           1,
+        lastRID:           2,
+        bookmarks:         make(map[string]*bookmarkState),
+        pendingBookmarks:  []interface{}{},
+        bookmarkCounter:   0,
+        refFields:         []refField{},
+        seqFields:         make(map[*fieldChar]bool),
+        tablesOfFigures:   []tableOfFigures{},
+        numberingFormats:  make(map[int]map[int]string),
+        lastAbstractNumID: -1,
+        lastNumID:         0,
+        hyperlinkRels:     []relationship{},
+        settings:          newDocumentSettings(),
     }
 }
 
@@ -161,10 +447,8 @@ func (d *DocxDocument) nextRID() string {
 }
 
 
-func (d *DocxDocument) AddText(style string, textData string, formatOptions ...string) {
+func newRunProperties(formatOptions []string) *runProperties {
     runProps := runProperties{}
-    runText := paragraphRunText{Text: textData, Space: "preserve"}
-    var finalRunProps *runProperties
     hasFormatting := false
     for _, opt := range formatOptions {
         if opt == FormatBold {
@@ -175,34 +459,56 @@ func (d *DocxDocument) AddText(style string, textData string, formatOptions ...s
             runProps.Italic = &italicProperty{}
             hasFormatting = true
         }
+        if opt == FormatUnderline {
+            runProps.Underline = &underlineProperty{Val: "single"}
+            hasFormatting = true
+        }
+        if opt == FormatStrike {
+            runProps.Strike = &onOffProperty{}
+            hasFormatting = true
+        }
     }
-    if hasFormatting {
-        finalRunProps = &runProps
+    if !hasFormatting {
+        return nil
     }
+    return &runProps
+}
 
+
+func (d *DocxDocument) AddText(style string, textData string, formatOptions ...string) {
+    runText := paragraphRunText{Text: textData, Space: "preserve"}
     run := paragraphRun{
-        Properties: finalRunProps,
+        Properties: newRunProperties(formatOptions),
         Text:       &runText,
     }
 
+    d.appendContent(style, &run)
+}
+
+
+func (d *DocxDocument) appendContent(style string, children ...interface{}) {
+    content := append(d.takePendingBookmarks(), children...)
 
     canAppend := false
+    lastPara, lastIsParagraph := (*paragraphData)(nil), false
     if len(d.content) > 0 {
-        lastParaIndex := len(d.content) - 1
-        lastPara := &d.content[lastParaIndex]
+        lastPara, lastIsParagraph = d.content[len(d.content)-1].(*paragraphData)
+    }
+    if lastIsParagraph {
+        lastRuns := lastPara.runs()
 
 
 
 
         hasDrawing := false
-        for _, r := range lastPara.Runs {
-            if r.Drawing != nil {
+        for _, r := range lastRuns {
+            if r.hasDrawing() {
                 hasDrawing = true
                 break
             }
         }
 
-        if !hasDrawing && (len(lastPara.Runs) > 0 || lastPara.Properties != nil) {
+        if !hasDrawing && (len(lastRuns) > 0 || lastPara.Properties != nil) {
             lastStyle := StyleNormal
             if lastPara.Properties != nil && lastPara.Properties.Style != nil {
                 lastStyle = lastPara.Properties.Style.Val
@@ -220,71 +526,92 @@ func (d *DocxDocument) AddText(style string, textData string, formatOptions ...s
     }
 
     if canAppend {
-        lastPara := &d.content[len(d.content)-1]
-        lastPara.Runs = append(lastPara.Runs, run)
+        lastPara.Content = append(lastPara.Content, content...)
     } else {
-
-        paraProps := paragraphProperties{}
-        var finalParaProps *paragraphProperties
+        para := &paragraphData{
+            Properties: newParagraphProperties(style),
+            Content:    content,
+        }
+        d.content = append(d.content, para)
+    }
+}
 
 
-        validStyle := style
-        styleIsSet := false
-        switch style {
-        case StyleHeading1, StyleHeading2, StyleHeading3, StyleHeading4:
-            paraProps.Style = &paragraphStyle{Val: style}
-            finalParaProps = &paraProps
-            styleIsSet = true
-        case StyleNormal, "":
-            validStyle = StyleNormal
+func newParagraphProperties(style string) *paragraphProperties {
+    paraProps := paragraphProperties{}
+    var finalParaProps *paragraphProperties
 
 
-            if style == StyleNormal {
-                paraProps.Style = &paragraphStyle{Val: StyleNormal}
-                finalParaProps = &paraProps
-                styleIsSet = true
-            }
-        default:
 
-            if strings.TrimSpace(style) != "" {
-                paraProps.Style = &paragraphStyle{Val: style}
-                finalParaProps = &paraProps
-                styleIsSet = true
-            }
-        }
+    validStyle := style
+    styleIsSet := false
+    switch style {
+    case StyleHeading1, StyleHeading2, StyleHeading3, StyleHeading4, StyleHeading5, StyleHeading6:
+        paraProps.Style = &paragraphStyle{Val: style}
+        finalParaProps = &paraProps
+        styleIsSet = true
+    case StyleNormal, "":
+        validStyle = StyleNormal
 
 
-        if !styleIsSet && validStyle != StyleNormal {
+        if style == StyleNormal {
             paraProps.Style = &paragraphStyle{Val: StyleNormal}
             finalParaProps = &paraProps
+            styleIsSet = true
         }
+    default:
 
-        para := paragraphData{
-            Properties: finalParaProps,
-            Runs:       []paragraphRun{run},
+        if strings.TrimSpace(style) != "" {
+            paraProps.Style = &paragraphStyle{Val: style}
+            finalParaProps = &paraProps
+            styleIsSet = true
         }
-        d.content = append(d.content, para)
     }
+
+
+    if !styleIsSet && validStyle != StyleNormal {
+        paraProps.Style = &paragraphStyle{Val: StyleNormal}
+        finalParaProps = &paraProps
+    }
+
+    return finalParaProps
 }
 
 
 func (d *DocxDocument) AddNewLine() {
 
 
-    d.content = append(d.content, paragraphData{})
+    d.content = append(d.content, &paragraphData{})
 }
 
 
 func (d *DocxDocument) AddImage(filePath string) error {
-    d.imageCounter++
-    imgID := d.imageCounter
-    uniquePicID := imgID
-    rID := d.nextRID()
+    imgRun, err := d.newImageRun(filePath)
+    if err != nil {
+        return err
+    }
 
+    para := &paragraphData{Content: append(d.takePendingBookmarks(), imgRun)}
+    d.content = append(d.content, para)
+
+    return nil
+}
+
+
+func (d *DocxDocument) newImageRun(filePath string) (*paragraphRun, error) {
     imgBytes, err := os.ReadFile(filePath)
     if err != nil {
-        return fmt.Errorf("failed to read image file %s: %w", filePath, err)
+        return nil, fmt.Errorf("failed to read image file %s: %w", filePath, err)
     }
+    return d.newImageRunFromData(imgBytes, filePath)
+}
+
+
+func (d *DocxDocument) newImageRunFromData(imgBytes []byte, filePath string) (*paragraphRun, error) {
+    d.imageCounter++
+    imgID := d.imageCounter
+    uniquePicID := imgID
+    rID := d.nextRID()
 
 
     imgDataReader := bytes.NewReader(imgBytes)
@@ -295,13 +622,13 @@ func (d *DocxDocument) AddImage(filePath string) error {
         _, _ = imgDataReader.Seek(0, io.SeekStart)
         _, format, err = image.Decode(imgDataReader)
         if err != nil {
-            return fmt.Errorf("failed to decode image config or data for %s: %w", filePath, err)
+            return nil, fmt.Errorf("failed to decode image config or data for %s: %w", filePath, err)
         }
 
         _, _ = imgDataReader.Seek(0, io.SeekStart)
         imgConfig, _, err = image.DecodeConfig(imgDataReader)
         if err != nil {
-            return fmt.Errorf("failed to get image config after successful decode for %s: %w", filePath, err)
+            return nil, fmt.Errorf("failed to get image config after successful decode for %s: %w", filePath, err)
         }
     }
 
@@ -318,7 +645,7 @@ func (d *DocxDocument) AddImage(filePath string) error {
         contentType = "image/gif"
         imgExt = ".gif"
     default:
-        return fmt.Errorf("unsupported image format: %s for file %s", format, filePath)
+        return nil, fmt.Errorf("unsupported image format: %s for file %s", format, filePath)
     }
 
 
@@ -416,14 +743,23 @@ func (d *DocxDocument) AddImage(filePath string) error {
 
     imgRun := paragraphRun{Drawing: &drawing}
 
-    para := paragraphData{Runs: []paragraphRun{imgRun}}
-    d.content = append(d.content, para)
+    return &imgRun, nil
+}
 
-    return nil
+
+func newSectionProperties() *sectPr {
+    return &sectPr{
+        PgSz:    &pgSz{W: 12240, H: 15840},
+        PgMar:   &pgMar{Top: 1440, Right: 1440, Bottom: 1440, Left: 1440, Header: 720, Footer: 720, Gutter: 0},
+        Cols:    &sectionColumns{Space: 720},
+        DocGrid: &documentGrid{LinePitch: 360},
+    }
 }
 
 
 func (d *DocxDocument) renderContent(w io.Writer) error {
+    d.resolveFields()
+    normalizeTableCells(d.content)
 
     doc := xmlRootDocument{
         XmlnsWp:  "http:
@@ -432,22 +768,14 @@ func (d *DocxDocument) renderContent(w io.Writer) error {
         XmlnsR:   "http:
         XmlnsW:   "http:
         Body: documentBodyData{
-            Paragraphs: d.content,
-
-            SectPr: &sectPr{
-                PgSz: pgSz{W: 12240, H: 15840},
-                PgMar: pgMar{Top: 1440, Right: 1440, Bottom: 1440, Left: 1440, Header: 720, Footer: 720, Gutter: 0},
-                Cols: struct {
-                    XMLName xml.Name `xml:"w:cols"`
-                    Space   uint     `xml:"w:space,attr"`
-                }{Space: 720},
-                DocGrid: struct {
-                    XMLName   xml.Name `xml:"w:docGrid"`
-                    LinePitch uint     `xml:"w:linePitch,attr"`
-                }{LinePitch: 360},
-            },
+            Blocks: d.content,
+
+            SectPr: d.sectPr,
         },
     }
+    if d.source != nil {
+        doc.Attrs = d.source.rootAttrs
+    }
 
 
     _, err := w.Write([]byte(xml.Header))
@@ -467,6 +795,47 @@ func (d *DocxDocument) renderContent(w io.Writer) error {
 }
 
 
+func (d *DocxDocument) renderParts() ([]packagePart, error) {
+    parts := []packagePart{}
+    if d.numbering != nil {
+        data, err := d.renderNumbering()
+        if err != nil {
+            return nil, err
+        }
+        parts = append(parts, packagePart{
+            name:        d.numbering.partName,
+            contentType: contentTypeNumbering,
+            data:        data,
+            rel:         d.numbering.rel,
+        })
+    }
+
+    settings, err := d.settingsPart()
+    if err != nil {
+        return nil, err
+    }
+    parts = append(parts, settings...)
+
+    fonts, err := d.fontTableParts(parts)
+    if err != nil {
+        return nil, err
+    }
+    parts = append(parts, fonts...)
+
+    properties, err := d.propertyParts()
+    if err != nil {
+        return nil, err
+    }
+    parts = append(parts, properties...)
+
+    customXML, err := d.customXMLParts()
+    if err != nil {
+        return nil, err
+    }
+    return append(parts, customXML...), nil
+}
+
+
 func (d *DocxDocument) getImages() map[string][]byte {
     return d.images
 }
@@ -479,3 +848,79 @@ func (d *DocxDocument) getImageRelationships() []relationship {
     return d.imageRels
 }
 
+
+func (d *DocxDocument) getContent() []interface{} {
+    return d.content
+}
+
+
+func (d *DocxDocument) renderedContent() []interface{} {
+    d.resolveFields()
+    return d.content
+}
+
+
+func (d *DocxDocument) getRelationship(rID string) (relationship, bool) {
+    for _, rel := range append(append([]relationship{}, d.imageRels...), d.hyperlinkRels...) {
+        if rel.ID == rID {
+            return rel, true
+        }
+    }
+    if d.source != nil {
+        for _, rel := range d.source.documentRels {
+            if rel.ID == rID {
+                return rel, true
+            }
+        }
+    }
+    return relationship{}, false
+}
+
+
+func (d *DocxDocument) getImageData(rID string) (string, []byte, bool) {
+    rel, ok := d.getRelationship(rID)
+    if !ok || rel.TargetMode == "External" {
+        return "", nil, false
+    }
+    name := path.Base(rel.Target)
+    if data, ok := d.images[name]; ok && rel.Target == "media/"+name {
+        return name, data, true
+    }
+    if d.source != nil {
+        if data, ok := d.source.parts[d.source.resolveTarget(rel.Target)]; ok {
+            return name, data, true
+        }
+    }
+    return "", nil, false
+}
+
+
+func (d *DocxDocument) getRelatedPart(relType string) ([]byte, bool) {
+    if d.source == nil {
+        if relType == relTypeStyles {
+            return []byte(defaultStylesXML), true
+        }
+        return nil, false
+    }
+    name := d.source.relationshipTarget(relType)
+    if name == "" {
+        return nil, false
+    }
+    data, ok := d.source.parts[name]
+    return data, ok
+}
+
+
+func (d *DocxDocument) getListLevel(p *paragraphData) (int, bool, bool) {
+    return d.listLevel(p)
+}
+
+
+func (d *DocxDocument) getListFormat(p *paragraphData, level int, ordered bool) (int, string, int) {
+    return d.listFormat(p, level, ordered)
+}
+
+
+func (d *DocxDocument) getSectionProperties() *sectPr {
+    return d.sectPr
+}
@@ -0,0 +1,536 @@
+package docx
+
+import (
+    "bytes"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha1"
+    "crypto/sha256"
+    "crypto/sha512"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/xml"
+    "fmt"
+    "hash"
+    "io"
+    "os"
+    "unicode/utf16"
+)
+
+const (
+    encryptionNamespace         = "http://schemas.microsoft.com/office/2006/encryption"
+    passwordKeyEncryptorURI     = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+    encryptionSpinCount         = 100000
+    encryptionMaxSpinCount      = 10000000
+    encryptionMaxSaltSize       = 65536
+    encryptionSegmentSize       = 4096
+    encryptionSaltSize          = 16
+    encryptionBlockSize         = 16
+    encryptionKeyBits           = 256
+    encryptionHashSize          = 64
+    encryptionTransformID       = "{FF9A3F03-56EF-4613-BDD5-5A41C1D07246}"
+    encryptionTransformName     = "Microsoft.Container.EncryptionTransform"
+    encryptionDataSpaceFeature  = "Microsoft.Container.DataSpaces"
+    encryptionDataSpaceName     = "StrongEncryptionDataSpace"
+    encryptionTransformInfoName = "StrongEncryptionTransform"
+)
+
+var (
+    blockKeyVerifierInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+    blockKeyVerifierValue = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+    blockKeyEncryptedKey  = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+    blockKeyIntegrityKey  = []byte{0x5f, 0xb2, 0xad, 0x01, 0x0c, 0xb9, 0xe1, 0xf6}
+    blockKeyIntegrityHMAC = []byte{0xa0, 0x67, 0x7f, 0x02, 0xb2, 0x2c, 0x84, 0x33}
+)
+
+
+type agileCipherParams struct {
+    SaltSize        int    `xml:"saltSize,attr"`
+    BlockSize       int    `xml:"blockSize,attr"`
+    KeyBits         int    `xml:"keyBits,attr"`
+    HashSize        int    `xml:"hashSize,attr"`
+    CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+    CipherChaining  string `xml:"cipherChaining,attr"`
+    HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+    SaltValue       string `xml:"saltValue,attr"`
+}
+
+type agilePasswordKey struct {
+    SpinCount int `xml:"spinCount,attr"`
+    agileCipherParams
+    EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+    EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+    EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+type agileDataIntegrity struct {
+    EncryptedHmacKey   string `xml:"encryptedHmacKey,attr"`
+    EncryptedHmacValue string `xml:"encryptedHmacValue,attr"`
+}
+
+type agileEncryption struct {
+    XMLName       xml.Name            `xml:"encryption"`
+    Xmlns         string              `xml:"xmlns,attr"`
+    XmlnsP        string              `xml:"xmlns:p,attr"`
+    KeyData       agileCipherParams   `xml:"keyData"`
+    DataIntegrity agileDataIntegrity  `xml:"dataIntegrity"`
+    KeyEncryptors []agileKeyEncryptor `xml:"keyEncryptors>keyEncryptor"`
+}
+
+type agileKeyEncryptor struct {
+    URI          string           `xml:"uri,attr"`
+    EncryptedKey agilePasswordKey `xml:"p:encryptedKey"`
+}
+
+
+type agileEncryptionInfo struct {
+    KeyData       agileCipherParams   `xml:"keyData"`
+    DataIntegrity *agileDataIntegrity `xml:"dataIntegrity"`
+    KeyEncryptors []struct {
+        URI          string            `xml:"uri,attr"`
+        EncryptedKey *agilePasswordKey `xml:"encryptedKey"`
+    } `xml:"keyEncryptors>keyEncryptor"`
+}
+
+
+
+
+func OpenEncryptedDocxDocument(filename string, password string) (*DocxDocument, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
+    }
+    defer file.Close()
+
+    info, err := file.Stat()
+    if err != nil {
+        return nil, fmt.Errorf("failed to stat file %s: %w", filename, err)
+    }
+    return ReadEncryptedDocxDocument(file, info.Size(), password)
+}
+
+
+func ReadEncryptedDocxDocument(r io.ReaderAt, size int64, password string) (*DocxDocument, error) {
+    data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read encrypted package: %w", err)
+    }
+    if !isCompoundFile(data) {
+        return ReadDocxDocument(bytes.NewReader(data), size)
+    }
+
+    plain, err := decryptPackage(data, password)
+    if err != nil {
+        return nil, err
+    }
+    return ReadDocxDocument(bytes.NewReader(plain), int64(len(plain)))
+}
+
+
+
+
+func encryptPackage(data []byte, password string) ([]byte, error) {
+    random := func(n int) ([]byte, error) {
+        b := make([]byte, n)
+        if _, err := rand.Read(b); err != nil {
+            return nil, fmt.Errorf("failed to generate encryption key material: %w", err)
+        }
+        return b, nil
+    }
+    keySalt, err := random(encryptionSaltSize)
+    if err != nil {
+        return nil, err
+    }
+    passwordSalt, err := random(encryptionSaltSize)
+    if err != nil {
+        return nil, err
+    }
+    secretKey, err := random(encryptionKeyBits / 8)
+    if err != nil {
+        return nil, err
+    }
+    verifierInput, err := random(encryptionSaltSize)
+    if err != nil {
+        return nil, err
+    }
+    hmacKey, err := random(encryptionHashSize)
+    if err != nil {
+        return nil, err
+    }
+
+    params := agileCipherParams{
+        SaltSize:        encryptionSaltSize,
+        BlockSize:       encryptionBlockSize,
+        KeyBits:         encryptionKeyBits,
+        HashSize:        encryptionHashSize,
+        CipherAlgorithm: "AES",
+        CipherChaining:  "ChainingModeCBC",
+        HashAlgorithm:   "SHA512",
+    }
+    keyData := params
+    keyData.SaltValue = base64.StdEncoding.EncodeToString(keySalt)
+    passwordKey := agilePasswordKey{SpinCount: encryptionSpinCount, agileCipherParams: params}
+    passwordKey.SaltValue = base64.StdEncoding.EncodeToString(passwordSalt)
+
+    newHash := sha512.New
+    iterated := agileIteratedHash(newHash, password, passwordSalt, encryptionSpinCount)
+    encrypt := func(blockKey []byte, iv []byte, plain []byte) (string, error) {
+        key := agileFit(agileHash(newHash, iterated, blockKey), encryptionKeyBits/8, 0x36)
+        encrypted, err := agileCBC(key, iv, plain, true)
+        return base64.StdEncoding.EncodeToString(encrypted), err
+    }
+    if passwordKey.EncryptedVerifierHashInput, err = encrypt(blockKeyVerifierInput, passwordSalt, verifierInput); err != nil {
+        return nil, err
+    }
+    if passwordKey.EncryptedVerifierHashValue, err = encrypt(blockKeyVerifierValue, passwordSalt, agileHash(newHash, verifierInput)); err != nil {
+        return nil, err
+    }
+    if passwordKey.EncryptedKeyValue, err = encrypt(blockKeyEncryptedKey, passwordSalt, secretKey); err != nil {
+        return nil, err
+    }
+
+    var encrypted bytes.Buffer
+    binary.Write(&encrypted, binary.LittleEndian, uint64(len(data)))
+    for i := 0; i*encryptionSegmentSize < len(data); i++ {
+        end := (i + 1) * encryptionSegmentSize
+        if end > len(data) {
+            end = len(data)
+        }
+        segment, err := agileCBC(secretKey, agileSegmentIV(newHash, keySalt, i, encryptionBlockSize), data[i*encryptionSegmentSize:end], true)
+        if err != nil {
+            return nil, err
+        }
+        encrypted.Write(segment)
+    }
+
+    mac := hmac.New(newHash, hmacKey)
+    mac.Write(encrypted.Bytes())
+    integrity := agileDataIntegrity{}
+    hmacKeyIV := agileFit(agileHash(newHash, keySalt, blockKeyIntegrityKey), encryptionBlockSize, 0x36)
+    hmacValueIV := agileFit(agileHash(newHash, keySalt, blockKeyIntegrityHMAC), encryptionBlockSize, 0x36)
+    encryptedKey, err := agileCBC(secretKey, hmacKeyIV, hmacKey, true)
+    if err != nil {
+        return nil, err
+    }
+    encryptedValue, err := agileCBC(secretKey, hmacValueIV, mac.Sum(nil), true)
+    if err != nil {
+        return nil, err
+    }
+    integrity.EncryptedHmacKey = base64.StdEncoding.EncodeToString(encryptedKey)
+    integrity.EncryptedHmacValue = base64.StdEncoding.EncodeToString(encryptedValue)
+
+    info := agileEncryption{
+        Xmlns:         encryptionNamespace,
+        XmlnsP:        passwordKeyEncryptorURI,
+        KeyData:       keyData,
+        DataIntegrity: integrity,
+        KeyEncryptors: []agileKeyEncryptor{{URI: passwordKeyEncryptorURI, EncryptedKey: passwordKey}},
+    }
+    var infoStream bytes.Buffer
+    binary.Write(&infoStream, binary.LittleEndian, []uint16{4, 4})
+    binary.Write(&infoStream, binary.LittleEndian, uint32(0x40))
+    infoStream.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n")
+    if err := xml.NewEncoder(&infoStream).Encode(info); err != nil {
+        return nil, fmt.Errorf("failed to encode encryption info: %w", err)
+    }
+
+    streams := encryptionDataSpaces()
+    streams["EncryptionInfo"] = infoStream.Bytes()
+    streams["EncryptedPackage"] = encrypted.Bytes()
+    data, err = writeCompoundFile(streams)
+    if err != nil {
+        return nil, fmt.Errorf("failed to write encrypted package: %w", err)
+    }
+    return data, nil
+}
+
+
+func decryptPackage(data []byte, password string) ([]byte, error) {
+    streams, err := readCompoundFile(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read encrypted package: %w", err)
+    }
+    infoStream, ok := streams["EncryptionInfo"]
+    if !ok || len(infoStream) < 8 {
+        return nil, fmt.Errorf("encrypted package has no EncryptionInfo stream")
+    }
+    encrypted, ok := streams["EncryptedPackage"]
+    if !ok || len(encrypted) < 8 {
+        return nil, fmt.Errorf("encrypted package has no EncryptedPackage stream")
+    }
+    major, minor := binary.LittleEndian.Uint16(infoStream), binary.LittleEndian.Uint16(infoStream[2:])
+    if major != 4 || minor != 4 {
+        return nil, fmt.Errorf("unsupported encryption version %d.%d: only agile encryption is supported", major, minor)
+    }
+
+    var info agileEncryptionInfo
+    if err := xml.Unmarshal(infoStream[8:], &info); err != nil {
+        return nil, fmt.Errorf("failed to parse encryption info: %w", err)
+    }
+    var passwordKey *agilePasswordKey
+    for _, encryptor := range info.KeyEncryptors {
+        if encryptor.URI == passwordKeyEncryptorURI && encryptor.EncryptedKey != nil {
+            passwordKey = encryptor.EncryptedKey
+        }
+    }
+    if passwordKey == nil {
+        return nil, fmt.Errorf("encrypted package has no password key encryptor")
+    }
+    for _, params := range []agileCipherParams{info.KeyData, passwordKey.agileCipherParams} {
+        if err := validateAgileParams(params); err != nil {
+            return nil, err
+        }
+    }
+    if passwordKey.SpinCount < 0 || passwordKey.SpinCount > encryptionMaxSpinCount {
+        return nil, fmt.Errorf("invalid encryption spin count %d: must be between 0 and %d", passwordKey.SpinCount, encryptionMaxSpinCount)
+    }
+
+    decode := func(value string) []byte {
+        b, _ := base64.StdEncoding.DecodeString(value)
+        return b
+    }
+    newHash, err := agileHashFunc(passwordKey.HashAlgorithm)
+    if err != nil {
+        return nil, err
+    }
+    passwordSalt := decode(passwordKey.SaltValue)
+    iv := agileFit(passwordSalt, passwordKey.BlockSize, 0x36)
+    iterated := agileIteratedHash(newHash, password, passwordSalt, passwordKey.SpinCount)
+    decrypt := func(blockKey []byte, value string) ([]byte, error) {
+        key := agileFit(agileHash(newHash, iterated, blockKey), passwordKey.KeyBits/8, 0x36)
+        return agileCBC(key, iv, decode(value), false)
+    }
+    verifierInput, err := decrypt(blockKeyVerifierInput, passwordKey.EncryptedVerifierHashInput)
+    if err != nil {
+        return nil, err
+    }
+    verifierValue, err := decrypt(blockKeyVerifierValue, passwordKey.EncryptedVerifierHashValue)
+    if err != nil {
+        return nil, err
+    }
+    if len(verifierInput) < passwordKey.SaltSize {
+        return nil, fmt.Errorf("invalid verifier length")
+    }
+    expected := agileHash(newHash, verifierInput[:passwordKey.SaltSize])
+    if len(verifierValue) < len(expected) || !hmac.Equal(verifierValue[:len(expected)], expected) {
+        return nil, fmt.Errorf("incorrect password for encrypted package")
+    }
+    secretKey, err := decrypt(blockKeyEncryptedKey, passwordKey.EncryptedKeyValue)
+    if err != nil {
+        return nil, err
+    }
+    if len(secretKey) < info.KeyData.KeyBits/8 {
+        return nil, fmt.Errorf("invalid encrypted key length")
+    }
+    secretKey = secretKey[:info.KeyData.KeyBits/8]
+
+    keyHash, err := agileHashFunc(info.KeyData.HashAlgorithm)
+    if err != nil {
+        return nil, err
+    }
+    keySalt := decode(info.KeyData.SaltValue)
+    if info.DataIntegrity != nil {
+        hmacKey, err := agileCBC(secretKey, agileFit(agileHash(keyHash, keySalt, blockKeyIntegrityKey), info.KeyData.BlockSize, 0x36), decode(info.DataIntegrity.EncryptedHmacKey), false)
+        if err != nil {
+            return nil, err
+        }
+        hmacValue, err := agileCBC(secretKey, agileFit(agileHash(keyHash, keySalt, blockKeyIntegrityHMAC), info.KeyData.BlockSize, 0x36), decode(info.DataIntegrity.EncryptedHmacValue), false)
+        if err != nil {
+            return nil, err
+        }
+        hashSize := info.KeyData.HashSize
+        if len(hmacKey) < hashSize || len(hmacValue) < hashSize {
+            return nil, fmt.Errorf("invalid data integrity values")
+        }
+        mac := hmac.New(keyHash, hmacKey[:hashSize])
+        mac.Write(encrypted)
+        if !hmac.Equal(mac.Sum(nil), hmacValue[:hashSize]) {
+            return nil, fmt.Errorf("encrypted package failed the integrity check")
+        }
+    }
+
+    size := binary.LittleEndian.Uint64(encrypted)
+    payload := encrypted[8:]
+    var plain bytes.Buffer
+    for i := 0; i*encryptionSegmentSize < len(payload); i++ {
+        end := (i + 1) * encryptionSegmentSize
+        if end > len(payload) {
+            end = len(payload)
+        }
+        segment, err := agileCBC(secretKey, agileSegmentIV(keyHash, keySalt, i, info.KeyData.BlockSize), payload[i*encryptionSegmentSize:end], false)
+        if err != nil {
+            return nil, err
+        }
+        plain.Write(segment)
+    }
+    if uint64(plain.Len()) < size {
+        return nil, fmt.Errorf("encrypted package is truncated")
+    }
+    return plain.Bytes()[:size], nil
+}
+
+
+
+
+func validateAgileParams(params agileCipherParams) error {
+    if params.CipherAlgorithm != "AES" || params.CipherChaining != "ChainingModeCBC" {
+        return fmt.Errorf("unsupported cipher %s/%s", params.CipherAlgorithm, params.CipherChaining)
+    }
+    switch params.KeyBits {
+    case 128, 192, 256:
+    default:
+        return fmt.Errorf("invalid encryption key size %d bits: AES keys are 128, 192 or 256 bits", params.KeyBits)
+    }
+    if params.BlockSize != aes.BlockSize {
+        return fmt.Errorf("invalid encryption block size %d: AES blocks are %d bytes", params.BlockSize, aes.BlockSize)
+    }
+    if params.SaltSize < 1 || params.SaltSize > encryptionMaxSaltSize {
+        return fmt.Errorf("invalid encryption salt size %d: must be between 1 and %d", params.SaltSize, encryptionMaxSaltSize)
+    }
+    salt, err := base64.StdEncoding.DecodeString(params.SaltValue)
+    if err != nil {
+        return fmt.Errorf("invalid encryption salt value: %w", err)
+    }
+    if len(salt) != params.SaltSize {
+        return fmt.Errorf("encryption salt is %d bytes, but the salt size is %d", len(salt), params.SaltSize)
+    }
+    newHash, err := agileHashFunc(params.HashAlgorithm)
+    if err != nil {
+        return err
+    }
+    if size := newHash().Size(); params.HashSize != size {
+        return fmt.Errorf("invalid hash size %d for %s: must be %d", params.HashSize, params.HashAlgorithm, size)
+    }
+    return nil
+}
+
+
+func agileHashFunc(name string) (func() hash.Hash, error) {
+    switch name {
+    case "SHA1", "SHA-1":
+        return sha1.New, nil
+    case "SHA256":
+        return sha256.New, nil
+    case "SHA384":
+        return sha512.New384, nil
+    case "SHA512":
+        return sha512.New, nil
+    }
+    return nil, fmt.Errorf("unsupported hash algorithm %s", name)
+}
+
+
+func agileHash(newHash func() hash.Hash, parts ...[]byte) []byte {
+    h := newHash()
+    for _, part := range parts {
+        h.Write(part)
+    }
+    return h.Sum(nil)
+}
+
+
+func agileIteratedHash(newHash func() hash.Hash, password string, salt []byte, spinCount int) []byte {
+    encoded := []byte{}
+    for _, c := range utf16.Encode([]rune(password)) {
+        encoded = append(encoded, byte(c), byte(c>>8))
+    }
+    result := agileHash(newHash, salt, encoded)
+    iterator := make([]byte, 4)
+    for i := 0; i < spinCount; i++ {
+        binary.LittleEndian.PutUint32(iterator, uint32(i))
+        result = agileHash(newHash, iterator, result)
+    }
+    return result
+}
+
+
+func agileSegmentIV(newHash func() hash.Hash, salt []byte, segment int, blockSize int) []byte {
+    index := make([]byte, 4)
+    binary.LittleEndian.PutUint32(index, uint32(segment))
+    return agileFit(agileHash(newHash, salt, index), blockSize, 0x36)
+}
+
+
+func agileFit(b []byte, size int, pad byte) []byte {
+    if len(b) >= size {
+        return b[:size]
+    }
+    fitted := append([]byte{}, b...)
+    for len(fitted) < size {
+        fitted = append(fitted, pad)
+    }
+    return fitted
+}
+
+
+func agileCBC(key []byte, iv []byte, data []byte, encrypt bool) ([]byte, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create cipher: %w", err)
+    }
+    if len(iv) != block.BlockSize() {
+        return nil, fmt.Errorf("invalid initialization vector length %d", len(iv))
+    }
+    out := append([]byte{}, data...)
+    if encrypt {
+        for len(out)%block.BlockSize() != 0 {
+            out = append(out, 0)
+        }
+        cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, out)
+        return out, nil
+    }
+    if len(out)%block.BlockSize() != 0 {
+        return nil, fmt.Errorf("encrypted data is not a multiple of the block size")
+    }
+    cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, out)
+    return out, nil
+}
+
+
+
+
+func encryptionDataSpaces() map[string][]byte {
+    lengthPrefixed := func(buf *bytes.Buffer, s string) {
+        units := utf16.Encode([]rune(s))
+        binary.Write(buf, binary.LittleEndian, uint32(len(units)*2))
+        binary.Write(buf, binary.LittleEndian, units)
+        if len(units)%2 != 0 {
+            buf.Write([]byte{0, 0})
+        }
+    }
+    versions := []uint16{1, 0, 1, 0, 1, 0}
+
+    var version bytes.Buffer
+    lengthPrefixed(&version, encryptionDataSpaceFeature)
+    binary.Write(&version, binary.LittleEndian, versions)
+
+    var entry bytes.Buffer
+    binary.Write(&entry, binary.LittleEndian, []uint32{1, 0})
+    lengthPrefixed(&entry, "EncryptedPackage")
+    lengthPrefixed(&entry, encryptionDataSpaceName)
+    var dataSpaceMap bytes.Buffer
+    binary.Write(&dataSpaceMap, binary.LittleEndian, []uint32{8, 1, uint32(entry.Len() + 4)})
+    dataSpaceMap.Write(entry.Bytes())
+
+    var definition bytes.Buffer
+    binary.Write(&definition, binary.LittleEndian, []uint32{8, 1})
+    lengthPrefixed(&definition, encryptionTransformInfoName)
+
+    var transformID bytes.Buffer
+    lengthPrefixed(&transformID, encryptionTransformID)
+    var primary bytes.Buffer
+    binary.Write(&primary, binary.LittleEndian, []uint32{uint32(transformID.Len() + 8), 1})
+    primary.Write(transformID.Bytes())
+    lengthPrefixed(&primary, encryptionTransformName)
+    binary.Write(&primary, binary.LittleEndian, versions)
+    binary.Write(&primary, binary.LittleEndian, []uint32{0, 0, 0, 4})
+
+    return map[string][]byte{
+        "\x06DataSpaces/Version":                                                       version.Bytes(),
+        "\x06DataSpaces/DataSpaceMap":                                                  dataSpaceMap.Bytes(),
+        "\x06DataSpaces/DataSpaceInfo/" + encryptionDataSpaceName:                      definition.Bytes(),
+        "\x06DataSpaces/TransformInfo/" + encryptionTransformInfoName + "/\x06Primary": primary.Bytes(),
+    }
+}
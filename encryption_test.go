@@ -0,0 +1,119 @@
+package docx
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+
+func tamperEncryptionInfo(t *testing.T, data []byte, old string, new string, n int) []byte {
+    t.Helper()
+    streams, err := readCompoundFile(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    info := streams["EncryptionInfo"]
+    if !bytes.Contains(info, []byte(old)) {
+        t.Fatalf("EncryptionInfo does not contain %q", old)
+    }
+    streams["EncryptionInfo"] = bytes.Replace(info, []byte(old), []byte(new), n)
+    tampered, err := writeCompoundFile(streams)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return tampered
+}
+
+
+func TestDecryptRejectsHostileParameters(t *testing.T) {
+    encrypted, err := encryptPackage([]byte("PK\x03\x04 package"), "secret")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name string
+        old  string
+        new  string
+        n    int
+        err  string
+    }{
+        {"negative salt size", `saltSize="16"`, `saltSize="-1"`, -1, "salt size"},
+        {"oversized salt size", `saltSize="16"`, `saltSize="100000"`, -1, "salt size"},
+        {"salt size mismatch", `saltSize="16"`, `saltSize="32"`, 1, "salt"},
+        {"negative key bits", `keyBits="256"`, `keyBits="-8"`, -1, "key size"},
+        {"odd key bits", `keyBits="256"`, `keyBits="1000"`, 1, "key size"},
+        {"negative hash size", `hashSize="64"`, `hashSize="-1"`, -1, "hash size"},
+        {"oversized hash size", `hashSize="64"`, `hashSize="1000"`, 1, "hash size"},
+        {"block size", `blockSize="16"`, `blockSize="0"`, 1, "block size"},
+        {"huge spin count", `spinCount="100000"`, `spinCount="2000000000"`, 1, "spin count"},
+        {"negative spin count", `spinCount="100000"`, `spinCount="-1"`, 1, "spin count"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            tampered := tamperEncryptionInfo(t, encrypted, tt.old, tt.new, tt.n)
+            _, err := decryptPackage(tampered, "secret")
+            if err == nil || !strings.Contains(err.Error(), tt.err) {
+                t.Fatalf("decryptPackage() error = %v, want %q", err, tt.err)
+            }
+        })
+    }
+}
+
+
+func TestEncryptionRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Board minutes")
+    writer := NewZipDocxWriter()
+    writer.Password = "correct horse"
+    filename := filepath.Join(t.TempDir(), "encrypted.docx")
+    if err := writer.WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !isCompoundFile(data) || bytes.Contains(data, []byte("Board minutes")) {
+        t.Fatalf("written file is not an encrypted compound file")
+    }
+
+    opened, err := OpenEncryptedDocxDocument(filename, "correct horse")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := opened.Text(); !strings.Contains(got, "Board minutes") {
+        t.Errorf("Text() = %q, want it to contain %q", got, "Board minutes")
+    }
+    if _, err := OpenEncryptedDocxDocument(filename, "Correct horse"); err == nil || !strings.Contains(err.Error(), "incorrect password") {
+        t.Errorf("wrong password error = %v", err)
+    }
+}
+
+
+func TestDecryptKnownAnswer(t *testing.T) {
+    data, err := os.ReadFile(filepath.Join("testdata", "agile-encrypted.docx"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    doc, err := ReadEncryptedDocxDocument(bytes.NewReader(data), int64(len(data)), "P\u00e4ssw\u00f6rd-2024")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, want := doc.Text(), "Known answer fixture"; strings.TrimSpace(got) != want {
+        t.Errorf("Text() = %q, want %q", got, want)
+    }
+    for _, password := range []string{"", "Passwort-2024", "P\u00c4ssw\u00f6rd-2024"} {
+        if _, err := decryptPackage(data, password); err == nil || !strings.Contains(err.Error(), "incorrect password") {
+            t.Errorf("decryptPackage(%q) error = %v", password, err)
+        }
+    }
+    tampered := append([]byte{}, data...)
+    tampered[len(tampered)-600] ^= 0x01
+    if _, err := decryptPackage(tampered, "P\u00e4ssw\u00f6rd-2024"); err == nil || !strings.Contains(err.Error(), "integrity") {
+        t.Errorf("tampered package error = %v", err)
+    }
+}
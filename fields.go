@@ -0,0 +1,487 @@
+package docx
+
+import (
+    "encoding/xml"
+    "strconv"
+    "strings"
+)
+
+const (
+    FieldMergeFormat = `\* MERGEFORMAT`
+    FieldUpper       = `\* Upper`
+    FieldLower       = `\* Lower`
+    FieldCaps        = `\* Caps`
+    FieldArabic      = `\* ARABIC`
+    FieldRoman       = `\* roman`
+    FieldAlphabetic  = `\* alphabetic`
+)
+
+const (
+    SeqRepeat = `\c`
+    SeqHide   = `\h`
+    SeqNext   = `\n`
+)
+
+const (
+    RefHyperlink        = `\h`
+    RefParagraphNumber  = `\r`
+    RefFullContext      = `\w`
+    RefRelativePosition = `\p`
+)
+
+const (
+    fieldCharBegin    = "begin"
+    fieldCharSeparate = "separate"
+    fieldCharEnd      = "end"
+)
+
+
+type fieldChar struct {
+    XMLName xml.Name  `xml:"w:fldChar"`
+    Type    string    `xml:"w:fldCharType,attr"`
+    Lock    string    `xml:"w:fldLock,attr,omitempty"`
+    Dirty   string    `xml:"w:dirty,attr,omitempty"`
+    Extra   []*rawXML `xml:",any"`
+}
+
+type instrText struct {
+    XMLName xml.Name `xml:"w:instrText"`
+    Space   string   `xml:"xml:space,attr,omitempty"`
+    Text    string   `xml:",chardata"`
+}
+
+
+type simpleField struct {
+    XMLName xml.Name        `xml:"w:fldSimple"`
+    Instr   string          `xml:"w:instr,attr"`
+    Dirty   string          `xml:"w:dirty,attr,omitempty"`
+    Runs    []*paragraphRun `xml:"w:r"`
+}
+
+
+type refField struct {
+    bookmark string
+    begin    *fieldChar
+    result   *paragraphRunText
+}
+
+
+func newFieldRuns(instruction string, result string, props *runProperties) []*paragraphRun {
+    withProps := func(run paragraphRun) *paragraphRun {
+        if props != nil {
+            p := *props
+            run.Properties = &p
+        }
+        return &run
+    }
+
+    return []*paragraphRun{
+        withProps(paragraphRun{FieldChar: &fieldChar{Type: fieldCharBegin}}),
+        withProps(paragraphRun{InstrText: &instrText{Space: "preserve", Text: " " + instruction + " "}}),
+        withProps(paragraphRun{FieldChar: &fieldChar{Type: fieldCharSeparate}}),
+        withProps(paragraphRun{Text: &paragraphRunText{Space: "preserve", Text: result}}),
+        withProps(paragraphRun{FieldChar: &fieldChar{Type: fieldCharEnd}}),
+    }
+}
+
+
+func runsToContent(runs []*paragraphRun) []interface{} {
+    content := make([]interface{}, 0, len(runs))
+    for _, run := range runs {
+        content = append(content, run)
+    }
+    return content
+}
+
+
+func fieldArgument(arg string) string {
+    if arg != "" && !strings.ContainsAny(arg, " \t\"\\") {
+        return arg
+    }
+    arg = strings.ReplaceAll(arg, `\`, `\\`)
+    arg = strings.ReplaceAll(arg, `"`, `\"`)
+    return `"` + arg + `"`
+}
+
+
+func fieldInstruction(code string, args []string, switches []string) string {
+    parts := []string{code}
+    for _, arg := range args {
+        parts = append(parts, fieldArgument(arg))
+    }
+    parts = append(parts, switches...)
+    return strings.Join(parts, " ")
+}
+
+
+func (d *DocxDocument) AddField(style string, instruction string, result string, formatOptions ...string) {
+    runs := newFieldRuns(strings.TrimSpace(instruction), result, newRunProperties(formatOptions))
+    seq := false
+    if tokens := splitFieldInstruction(instruction); result == "" && len(tokens) >= 2 && strings.EqualFold(tokens[0], "SEQ") {
+        seq = true
+        d.seqFields[runs[0].FieldChar] = true
+    } else if result == "" {
+        runs[0].FieldChar.Dirty = "true"
+    }
+
+    d.appendContent(style, runsToContent(runs)...)
+    if seq {
+        d.resolveSeqFields()
+    }
+}
+
+
+func (d *DocxDocument) AddSimpleField(style string, instruction string, result string, formatOptions ...string) {
+    field := simpleField{
+        Instr: " " + strings.TrimSpace(instruction) + " ",
+        Runs: []*paragraphRun{{
+            Properties: newRunProperties(formatOptions),
+            Text:       &paragraphRunText{Space: "preserve", Text: result},
+        }},
+    }
+    if result == "" {
+        field.Dirty = "true"
+    }
+
+    d.appendContent(style, &field)
+}
+
+
+func DateField(format string) string {
+    if format == "" {
+        return "DATE"
+    }
+    return `DATE \@ ` + fieldArgument(format)
+}
+
+
+func TimeField(format string) string {
+    if format == "" {
+        return "TIME"
+    }
+    return `TIME \@ ` + fieldArgument(format)
+}
+
+
+func AuthorField() string {
+    return "AUTHOR"
+}
+
+
+func TitleField() string {
+    return "TITLE"
+}
+
+
+func FileNameField() string {
+    return "FILENAME"
+}
+
+
+func PageField() string {
+    return "PAGE"
+}
+
+
+func NumPagesField() string {
+    return "NUMPAGES"
+}
+
+
+func DocPropertyField(name string) string {
+    return fieldInstruction("DOCPROPERTY", []string{name}, nil)
+}
+
+
+func DocVariableField(name string) string {
+    return fieldInstruction("DOCVARIABLE", []string{name}, nil)
+}
+
+
+func SeqField(identifier string, switches ...string) string {
+    return fieldInstruction("SEQ", []string{identifier}, switches)
+}
+
+
+func MergeField(name string, switches ...string) string {
+    if len(switches) == 0 {
+        switches = []string{FieldMergeFormat}
+    }
+    return fieldInstruction("MERGEFIELD", []string{name}, switches)
+}
+
+
+func IfField(left string, operator string, right string, trueText string, falseText string) string {
+    return "IF " + fieldArgument(left) + " " + operator + " " + fieldArgument(right) + " " +
+        fieldArgument(trueText) + " " + fieldArgument(falseText)
+}
+
+
+func splitFieldOptions(options []string) (formatOptions []string, switches []string) {
+    for _, opt := range options {
+        if strings.HasPrefix(opt, `\`) {
+            switches = append(switches, opt)
+        } else {
+            formatOptions = append(formatOptions, opt)
+        }
+    }
+    return formatOptions, switches
+}
+
+
+func (d *DocxDocument) AddRef(style string, bookmark string, options ...string) {
+    formatOptions, switches := splitFieldOptions(options)
+    instruction := fieldInstruction("REF", []string{bookmark}, switches)
+    runs := newFieldRuns(instruction, "", newRunProperties(formatOptions))
+
+
+    resolvable := true
+    for _, sw := range switches {
+        if sw != RefHyperlink {
+            resolvable = false
+        }
+    }
+    if resolvable {
+        d.refFields = append(d.refFields, refField{bookmark: bookmark, begin: runs[0].FieldChar, result: runs[3].Text})
+    } else {
+        runs[0].FieldChar.Dirty = "true"
+    }
+
+    d.appendContent(style, runsToContent(runs)...)
+}
+
+
+func (d *DocxDocument) AddPageRef(style string, bookmark string, options ...string) {
+    formatOptions, switches := splitFieldOptions(options)
+    instruction := fieldInstruction("PAGEREF", []string{bookmark}, switches)
+    runs := newFieldRuns(instruction, "", newRunProperties(formatOptions))
+    runs[0].FieldChar.Dirty = "true"
+
+    d.appendContent(style, runsToContent(runs)...)
+}
+
+
+type complexField struct {
+    begin       *fieldChar
+    instruction string
+    result      []*paragraphRun
+}
+
+
+func paragraphFields(p *paragraphData) []complexField {
+    fields := []complexField{}
+    depth := 0
+    inResult := false
+    var current complexField
+    for _, run := range p.runs() {
+        if run.FieldChar != nil {
+            switch run.FieldChar.Type {
+            case fieldCharBegin:
+                depth++
+                if depth == 1 {
+                    current = complexField{begin: run.FieldChar}
+                    inResult = false
+                }
+            case fieldCharSeparate:
+                if depth == 1 {
+                    inResult = true
+                }
+            case fieldCharEnd:
+                if depth == 1 {
+                    current.instruction = strings.TrimSpace(current.instruction)
+                    fields = append(fields, current)
+                }
+                if depth > 0 {
+                    depth--
+                }
+            }
+            continue
+        }
+        if depth != 1 {
+            continue
+        }
+        if run.InstrText != nil && !inResult {
+            current.instruction += run.InstrText.Text
+        } else if inResult {
+            current.result = append(current.result, run)
+        }
+    }
+    return fields
+}
+
+
+func splitFieldInstruction(instruction string) []string {
+    tokens := []string{}
+    var sb strings.Builder
+    inQuotes := false
+    hasToken := false
+    for i := 0; i < len(instruction); i++ {
+        c := instruction[i]
+        switch {
+        case c == '\\' && inQuotes && i+1 < len(instruction) && (instruction[i+1] == '"' || instruction[i+1] == '\\'):
+            i++
+            sb.WriteByte(instruction[i])
+        case c == '"':
+            inQuotes = !inQuotes
+            hasToken = true
+        case (c == ' ' || c == '\t') && !inQuotes:
+            if hasToken {
+                tokens = append(tokens, sb.String())
+                sb.Reset()
+                hasToken = false
+            }
+        default:
+            sb.WriteByte(c)
+            hasToken = true
+        }
+    }
+    if hasToken {
+        tokens = append(tokens, sb.String())
+    }
+    return tokens
+}
+
+
+func setFieldResult(result []*paragraphRun, text string) {
+    for _, run := range result {
+        if run.Text != nil {
+            run.Text.Text = text
+            return
+        }
+    }
+}
+
+
+func (d *DocxDocument) resolveFields() {
+    d.resolveSeqFields()
+    d.resolveRefFields()
+    d.resolveTablesOfFigures()
+}
+
+
+func (d *DocxDocument) resolveSeqFields() {
+    if len(d.seqFields) == 0 {
+        return
+    }
+    d.numberSeqFields(func(field complexField, value string) {
+        if d.seqFields[field.begin] {
+            setFieldResult(field.result, value)
+        }
+    })
+}
+
+
+func (d *DocxDocument) numberSeqFields(visit func(field complexField, value string)) {
+    counters := make(map[string]int)
+    last := make(map[string]int)
+    headings := make([]int, 10)
+    for i, para := range d.paragraphs() {
+        if level := paragraphHeadingLevel(para); level > 0 {
+            headings[level] = i + 1
+        }
+        for _, field := range paragraphFields(para) {
+            tokens := splitFieldInstruction(field.instruction)
+            if len(tokens) < 2 || !strings.EqualFold(tokens[0], "SEQ") {
+                continue
+            }
+
+            identifier := tokens[1]
+            repeat, hidden, restart := false, false, false
+            reset, format := 0, ""
+            for j := 2; j < len(tokens); j++ {
+                switch strings.ToLower(tokens[j]) {
+                case SeqRepeat:
+                    repeat = true
+                case SeqHide:
+                    hidden = true
+                case `\r`, `\s`, `\*`:
+                    if j+1 >= len(tokens) {
+                        continue
+                    }
+                    j++
+                    n, err := strconv.Atoi(tokens[j])
+                    switch {
+                    case tokens[j-1] == `\*`:
+                        format = tokens[j]
+                    case err != nil:
+                    case strings.ToLower(tokens[j-1]) == `\r`:
+                        reset = n
+                    default:
+                        for level := 1; level <= n && level < len(headings); level++ {
+                            if headings[level] > last[identifier] {
+                                restart = true
+                            }
+                        }
+                    }
+                }
+            }
+            if restart {
+                counters[identifier] = 0
+            }
+            if reset > 0 {
+                counters[identifier] = reset - 1
+            }
+            if !repeat {
+                counters[identifier]++
+            }
+            last[identifier] = i + 1
+
+            value := formatSeqNumber(counters[identifier], format)
+            if hidden {
+                value = ""
+            }
+            visit(field, value)
+        }
+    }
+}
+
+
+func formatSeqNumber(n int, format string) string {
+    upper := format != "" && strings.ToUpper(format[:1]) == format[:1]
+    switch strings.ToLower(format) {
+    case "roman":
+        if upper {
+            return formatListNumber(n, "upperRoman")
+        }
+        return formatListNumber(n, "lowerRoman")
+    case "alphabetic":
+        if upper {
+            return formatListNumber(n, "upperLetter")
+        }
+        return formatListNumber(n, "lowerLetter")
+    }
+    return strconv.Itoa(n)
+}
+
+
+func paragraphHeadingLevel(para *paragraphData) int {
+    if para.Properties == nil || para.Properties.Style == nil {
+        return 0
+    }
+    level, ok := strings.CutPrefix(para.Properties.Style.Val, "Heading")
+    if !ok {
+        return 0
+    }
+    n, err := strconv.Atoi(level)
+    if err != nil || n < 1 || n > 9 {
+        return 0
+    }
+    return n
+}
+
+
+func (d *DocxDocument) resolveRefFields() {
+    if len(d.refFields) == 0 {
+        return
+    }
+    texts := d.bookmarkTexts()
+    for _, ref := range d.refFields {
+        text, ok := texts[ref.bookmark]
+        if !ok {
+            ref.begin.Dirty = "true"
+            continue
+        }
+        ref.begin.Dirty = ""
+        ref.result.Text = text
+    }
+}
@@ -0,0 +1,158 @@
+package docx
+
+import (
+    "strings"
+    "testing"
+)
+
+
+func TestSeqFieldNumbering(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, "Chapter one")
+    doc.AddField(StyleNormal, SeqField("Figure", `\* ROMAN`, `\s 1`), "")
+    doc.AddField(StyleNormal, SeqField("Figure", `\* ROMAN`, `\s 1`), "")
+    doc.AddField(StyleNormal, SeqField("Figure", SeqRepeat), "")
+    doc.AddText(StyleHeading2, "Section")
+    doc.AddField(StyleNormal, SeqField("Figure", `\* ROMAN`, `\s 1`), "")
+    doc.AddText(StyleHeading1, "Chapter two")
+    doc.AddField(StyleNormal, SeqField("Figure", `\* ROMAN`, `\s 1`), "")
+    doc.AddField(StyleNormal, SeqField("Note", FieldAlphabetic), "")
+    doc.AddField(StyleNormal, SeqField("Note", `\r 27`, FieldAlphabetic), "")
+    doc.AddField(StyleNormal, SeqField("Note", SeqHide), "")
+    doc.AddField(StyleNormal, SeqField("Note"), "")
+
+    want := []string{"Chapter one", "I|II|2", "Section", "III", "Chapter two", "I|a|aa||29"}
+    got := []string{}
+    for _, para := range doc.paragraphs() {
+        values := []string{}
+        for _, field := range paragraphFields(para) {
+            values = append(values, runsText(field.result))
+        }
+        if len(values) == 0 {
+            values = append(values, para.text())
+        }
+        got = append(got, strings.Join(values, "|"))
+    }
+    if !equalStrings(got, want) {
+        t.Errorf("field results = %q, want %q", got, want)
+    }
+}
+
+
+func runsText(runs []*paragraphRun) string {
+    var sb strings.Builder
+    for _, run := range runs {
+        if run.Text != nil {
+            sb.WriteString(run.Text.Text)
+        }
+    }
+    return sb.String()
+}
+
+
+func TestSeqFieldsInOpenedDocumentsKeepTheirResults(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, "Chapter one")
+    doc.AddField(StyleNormal, SeqField("Figure", `\* ROMAN`, `\s 1`), "I")
+    doc.AddText(StyleHeading1, "Chapter two")
+    doc.AddField(StyleNormal, SeqField("Figure", `\* ROMAN`, `\s 1`), "I")
+    doc.AddTable(1, 1)
+    if err := doc.AddCaption(CaptionTable, "Totals"); err != nil {
+        t.Fatal(err)
+    }
+    doc.AddField(StyleNormal, SeqField("Listing"), "7")
+
+    want := []string{"Chapter one", "I", "Chapter two", "I", "", "Table 1: Totals", "7"}
+    opened := reopenDocument(t, doc)
+    for round := 0; round < 2; round++ {
+        if got := strings.Split(opened.Text(), "\n"); !equalStrings(got, want) {
+            t.Fatalf("round %d: Text() = %q, want %q", round, got, want)
+        }
+        opened = reopenDocument(t, opened)
+    }
+
+    opened.AddTable(1, 1)
+    if err := opened.AddCaption(CaptionTable, "Averages"); err != nil {
+        t.Fatal(err)
+    }
+    want = append(want, "", "Table 2: Averages")
+    if got := strings.Split(reopenDocument(t, opened).Text(), "\n"); !equalStrings(got, want) {
+        t.Errorf("after AddCaption Text() = %q, want %q", got, want)
+    }
+}
+
+
+func TestFieldInstructionHelpers(t *testing.T) {
+    tests := []struct {
+        got  string
+        want string
+    }{
+        {DateField(""), "DATE"},
+        {DateField("d MMMM yyyy"), `DATE \@ "d MMMM yyyy"`},
+        {TimeField("HH:mm"), `TIME \@ HH:mm`},
+        {AuthorField(), "AUTHOR"},
+        {TitleField(), "TITLE"},
+        {FileNameField(), "FILENAME"},
+        {PageField(), "PAGE"},
+        {NumPagesField(), "NUMPAGES"},
+        {DocPropertyField("Client Name"), `DOCPROPERTY "Client Name"`},
+        {DocVariableField("Build"), "DOCVARIABLE Build"},
+        {SeqField("Figure", FieldRoman), `SEQ Figure \* roman`},
+        {MergeField("First"), `MERGEFIELD First \* MERGEFORMAT`},
+        {MergeField("First", FieldUpper), `MERGEFIELD First \* Upper`},
+        {IfField("a", "=", "", `say "hi"`, `C:\temp`), `IF a = "" "say \"hi\"" "C:\\temp"`},
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("instruction = %s, want %s", tt.got, tt.want)
+        }
+    }
+    tokens := splitFieldInstruction(IfField("a", "=", "", `say "hi"`, `C:\temp`))
+    if !equalStrings(tokens, []string{"IF", "a", "=", "", `say "hi"`, `C:\temp`}) {
+        t.Errorf("splitFieldInstruction() = %q", tokens)
+    }
+}
+
+
+func TestComplexAndSimpleFieldsRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Page ")
+    doc.AddField(StyleNormal, PageField(), "1", FormatBold)
+    doc.AddText(StyleNormal, " of ")
+    doc.AddField(StyleNormal, "  "+NumPagesField()+" ", "")
+    doc.AddParagraph(StyleNormal).AddRun("By ")
+    doc.AddSimpleField(StyleNormal, AuthorField(), "Ada")
+    doc.AddSimpleField(StyleNormal, TitleField(), "", FormatItalic)
+
+    reopened := reopenDocument(t, doc)
+    paras := reopened.paragraphs()
+    if got := listParagraphTexts(paras); !equalStrings(got, []string{"Page 1 of ", "By Ada"}) {
+        t.Errorf("paragraph texts = %q", got)
+    }
+    fields := paragraphFields(paras[0])
+    if len(fields) != 2 {
+        t.Fatalf("got %d complex fields, want 2", len(fields))
+    }
+    if fields[0].instruction != "PAGE" || fields[0].begin.Dirty != "" || runsText(fields[0].result) != "1" {
+        t.Errorf("PAGE field = %q, dirty %q, result %q", fields[0].instruction, fields[0].begin.Dirty, runsText(fields[0].result))
+    }
+    if props := fields[0].result[0].Properties; props == nil || props.Bold == nil {
+        t.Error("PAGE field result lost its formatting")
+    }
+    if fields[1].instruction != "NUMPAGES" || fields[1].begin.Dirty != "true" {
+        t.Errorf("NUMPAGES field = %q, dirty %q", fields[1].instruction, fields[1].begin.Dirty)
+    }
+
+    simple := []string{}
+    for _, c := range paras[1].Content {
+        if field, ok := c.(*simpleField); ok {
+            simple = append(simple, field.Instr+"|"+field.Dirty+"|"+runsText(field.Runs))
+            if field.Dirty != "" && (field.Runs[0].Properties == nil || field.Runs[0].Properties.Italic == nil) {
+                t.Error("TITLE field result lost its formatting")
+            }
+        }
+    }
+    if !equalStrings(simple, []string{" AUTHOR ||Ada", " TITLE |true|"}) {
+        t.Errorf("simple fields = %q", simple)
+    }
+}
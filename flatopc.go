@@ -0,0 +1,143 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/xml"
+    "fmt"
+    "html"
+    "io"
+    "os"
+    "path"
+    "sort"
+    "strings"
+)
+
+
+const flatOPCLineLength = 76
+
+
+type FlatOPCWriter struct{}
+
+
+func NewFlatOPCWriter() *FlatOPCWriter {
+    return &FlatOPCWriter{}
+}
+
+
+func (fw *FlatOPCWriter) WriteDocument(filename string, doc Document) error {
+    var buf bytes.Buffer
+    if err := fw.WriteFlatOPC(&buf, doc); err != nil {
+        return err
+    }
+
+    err := os.WriteFile(filename, buf.Bytes(), 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+    return nil
+}
+
+
+func (fw *FlatOPCWriter) WriteFlatOPC(w io.Writer, doc Document) error {
+    pkg := &flatPackage{parts: make(map[string]*bytes.Buffer)}
+    if err := NewZipDocxWriter().writePackage(pkg, doc); err != nil {
+        return err
+    }
+
+    var contentTypes types
+    if data, ok := pkg.parts["[Content_Types].xml"]; ok {
+        if err := xml.Unmarshal(data.Bytes(), &contentTypes); err != nil {
+            return fmt.Errorf("failed to parse [Content_Types].xml: %w", err)
+        }
+    }
+
+    names := []string{}
+    for _, name := range pkg.order {
+        if name != "[Content_Types].xml" {
+            names = append(names, name)
+        }
+    }
+    sort.SliceStable(names, func(i, j int) bool {
+        if (names[i] == "_rels/.rels") != (names[j] == "_rels/.rels") {
+            return names[i] == "_rels/.rels"
+        }
+        return names[i] < names[j]
+    })
+
+    var sb strings.Builder
+    sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+    sb.WriteString(`<?mso-application progid="Word.Document"?>` + "\n")
+    sb.WriteString(`<pkg:package xmlns:pkg="http://schemas.microsoft.com/office/2006/xmlPackage">` + "\n")
+    for _, name := range names {
+        contentType := flatPartContentType(contentTypes, name)
+        data := pkg.parts[name].Bytes()
+        sb.WriteString(`<pkg:part pkg:name="` + html.EscapeString("/"+name) + `" pkg:contentType="` + html.EscapeString(contentType) + `"`)
+        if strings.HasSuffix(contentType, "xml") {
+            sb.WriteString(">\n<pkg:xmlData>\n")
+            sb.WriteString(flatXMLBody(data))
+            sb.WriteString("\n</pkg:xmlData>\n</pkg:part>\n")
+            continue
+        }
+        sb.WriteString(` pkg:compression="store">` + "\n<pkg:binaryData>")
+        encoded := base64.StdEncoding.EncodeToString(data)
+        for len(encoded) > flatOPCLineLength {
+            sb.WriteString(encoded[:flatOPCLineLength] + "\n")
+            encoded = encoded[flatOPCLineLength:]
+        }
+        sb.WriteString(encoded + "</pkg:binaryData>\n</pkg:part>\n")
+    }
+    sb.WriteString("</pkg:package>\n")
+
+    _, err := io.WriteString(w, sb.String())
+    if err != nil {
+        return fmt.Errorf("failed to write flat OPC package: %w", err)
+    }
+    return nil
+}
+
+
+type flatPackage struct {
+    parts map[string]*bytes.Buffer
+    order []string
+}
+
+
+func (p *flatPackage) Create(name string) (io.Writer, error) {
+    if buf, ok := p.parts[name]; ok {
+        buf.Reset()
+        return buf, nil
+    }
+    buf := &bytes.Buffer{}
+    p.parts[name] = buf
+    p.order = append(p.order, name)
+    return buf, nil
+}
+
+
+func flatPartContentType(contentTypes types, name string) string {
+    for _, o := range contentTypes.Overrides {
+        if strings.EqualFold(strings.TrimPrefix(o.PartName, "/"), name) {
+            return o.ContentType
+        }
+    }
+    ext := strings.TrimPrefix(path.Ext(name), ".")
+    for _, d := range contentTypes.Defaults {
+        if strings.EqualFold(d.Extension, ext) {
+            return d.ContentType
+        }
+    }
+    return "application/octet-stream"
+}
+
+
+func flatXMLBody(data []byte) string {
+    text := strings.TrimPrefix(string(data), "\uFEFF")
+    text = strings.TrimSpace(text)
+    if strings.HasPrefix(text, "<?xml") {
+        if end := strings.Index(text, "?>"); end >= 0 {
+            text = strings.TrimSpace(text[end+2:])
+        }
+    }
+    return text
+}
@@ -0,0 +1,96 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/xml"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "testing"
+)
+
+
+type flatTestPackage struct {
+    XMLName xml.Name `xml:"package"`
+    Parts   []struct {
+        Name        string `xml:"name,attr"`
+        ContentType string `xml:"contentType,attr"`
+        Compression string `xml:"compression,attr"`
+        XMLData     struct {
+            Inner []byte `xml:",innerxml"`
+        } `xml:"xmlData"`
+        BinaryData string `xml:"binaryData"`
+    } `xml:"part"`
+}
+
+
+func TestFlatOPCWriterWritesEveryPart(t *testing.T) {
+    doc := newExportDocument(t)
+    var buf bytes.Buffer
+    if err := NewFlatOPCWriter().WriteFlatOPC(&buf, doc); err != nil {
+        t.Fatal(err)
+    }
+    xmlText(t, "flat OPC package", buf.Bytes())
+    if !bytes.Contains(buf.Bytes(), []byte(`<?mso-application progid="Word.Document"?>`)) {
+        t.Error("package has no mso-application processing instruction")
+    }
+    var pkg flatTestPackage
+    if err := xml.Unmarshal(buf.Bytes(), &pkg); err != nil {
+        t.Fatal(err)
+    }
+    if len(pkg.Parts) == 0 || pkg.Parts[0].Name != "/_rels/.rels" {
+        t.Fatalf("first part is not /_rels/.rels")
+    }
+
+    filename := filepath.Join(t.TempDir(), "export.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    zipNames, zipParts, err := readPackageParts(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []string{}
+    for _, name := range zipNames {
+        if name != "[Content_Types].xml" {
+            want = append(want, "/"+name)
+        }
+    }
+
+    names := []string{}
+    png, _ := base64.StdEncoding.DecodeString(testPNG)
+    for _, part := range pkg.Parts {
+        names = append(names, part.Name)
+        if part.ContentType == "" || part.ContentType == "application/octet-stream" {
+            t.Errorf("%s has content type %q", part.Name, part.ContentType)
+        }
+        if strings.HasSuffix(part.ContentType, "xml") {
+            text := xmlText(t, part.Name, part.XMLData.Inner)
+            if part.Name == "/word/document.xml" && !strings.Contains(text, exportHeading) {
+                t.Errorf("document part does not contain the heading text")
+            }
+            continue
+        }
+        decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(part.BinaryData), ""))
+        if err != nil {
+            t.Errorf("%s binary data: %v", part.Name, err)
+        }
+        if part.Compression != "store" || !bytes.Equal(decoded, zipParts[strings.TrimPrefix(part.Name, "/")]) {
+            t.Errorf("%s does not hold the stored part data", part.Name)
+        }
+        if strings.HasPrefix(part.Name, "/word/media/") && !bytes.Equal(decoded, png) {
+            t.Errorf("%s does not hold the embedded image", part.Name)
+        }
+    }
+    sort.Strings(names)
+    sort.Strings(want)
+    if !equalStrings(names, want) {
+        t.Errorf("parts = %q, want %q", names, want)
+    }
+}
@@ -0,0 +1,345 @@
+package docx
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/xml"
+    "fmt"
+    "os"
+    "path"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+const (
+    FontRegular    = "regular"
+    FontBold       = "bold"
+    FontItalic     = "italic"
+    FontBoldItalic = "boldItalic"
+)
+
+const (
+    relTypeFontTable       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/fontTable"
+    relTypeFont            = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/font"
+    contentTypeFontTable   = "application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml"
+    contentTypeObfuscated  = "application/vnd.openxmlformats-officedocument.obfuscatedFont"
+    obfuscatedHeaderLength = 32
+)
+
+var fontEmbedElements = map[string]string{
+    FontRegular:    "w:embedRegular",
+    FontBold:       "w:embedBold",
+    FontItalic:     "w:embedItalic",
+    FontBoldItalic: "w:embedBoldItalic",
+}
+
+var fontTableOrder = []string{
+    "w:altName", "w:panose1", "w:charset", "w:family", "w:notTrueType", "w:pitch", "w:sig",
+    "w:embedRegular", "w:embedBold", "w:embedItalic", "w:embedBoldItalic",
+}
+
+
+type embeddedFont struct {
+    family string
+    style  string
+    data   []byte
+    key    string
+}
+
+
+
+
+func (d *DocxDocument) EmbedFont(family string, style string, filePath string) error {
+    data, err := os.ReadFile(filePath)
+    if err != nil {
+        return fmt.Errorf("failed to read font file %s: %w", filePath, err)
+    }
+    return d.EmbedFontData(family, style, data)
+}
+
+
+func (d *DocxDocument) EmbedFontData(family string, style string, data []byte) error {
+    if strings.TrimSpace(family) == "" {
+        return fmt.Errorf("font family cannot be empty")
+    }
+    if style == "" {
+        style = FontRegular
+    }
+    if _, ok := fontEmbedElements[style]; !ok {
+        return fmt.Errorf("unsupported font style: %s", style)
+    }
+    if len(data) < obfuscatedHeaderLength {
+        return fmt.Errorf("font data for %s is too short", family)
+    }
+    switch string(data[:4]) {
+    case "\x00\x01\x00\x00", "OTTO", "true":
+    default:
+        return fmt.Errorf("unsupported font data for %s: expected a TrueType or OpenType font", family)
+    }
+
+    font := &embeddedFont{family: family, style: style, data: data, key: fontKey(family, style, data)}
+    d.settings.set("w:embedTrueTypeFonts")
+    d.settings.EmbedTrueTypeFonts = &onOffProperty{}
+    for i, existing := range d.fonts {
+        if existing.family == family && existing.style == style {
+            d.fonts[i] = font
+            return nil
+        }
+    }
+    d.fonts = append(d.fonts, font)
+    return nil
+}
+
+
+func fontKey(family string, style string, data []byte) string {
+    sum := sha256.Sum256(append([]byte(family+"\x00"+style+"\x00"), data...))
+    guid := sum[:16]
+    guid[6] = guid[6]&0x0f | 0x40
+    guid[8] = guid[8]&0x3f | 0x80
+    return formatGUID(guid)
+}
+
+
+func obfuscateFont(data []byte, key string) []byte {
+    digits := strings.NewReplacer("{", "", "}", "", "-", "").Replace(key)
+    guid, _ := hex.DecodeString(digits)
+    obfuscated := append([]byte{}, data...)
+    for i := 0; i < obfuscatedHeaderLength && i < len(obfuscated); i++ {
+        obfuscated[i] ^= guid[len(guid)-1-i%len(guid)]
+    }
+    return obfuscated
+}
+
+
+
+
+func (d *DocxDocument) usedFonts(parts []packagePart) ([]string, error) {
+    sources := [][]byte{}
+    var buf bytes.Buffer
+    if err := d.renderContent(&buf); err != nil {
+        return nil, err
+    }
+    sources = append(sources, buf.Bytes())
+    for _, part := range parts {
+        sources = append(sources, part.data)
+    }
+    if d.source == nil {
+        sources = append(sources, []byte(defaultStylesXML))
+    } else {
+        for _, name := range d.source.partNames {
+            if path.Dir(name) == d.source.documentDir() && path.Ext(name) == ".xml" && name != d.source.documentPart {
+                sources = append(sources, d.source.parts[name])
+            }
+        }
+    }
+
+    seen := make(map[string]bool)
+    fonts := []string{}
+    for _, data := range sources {
+        decoder := xml.NewDecoder(bytes.NewReader(data))
+        for {
+            token, err := decoder.Token()
+            if err != nil {
+                break
+            }
+            start, ok := token.(xml.StartElement)
+            if !ok || start.Name.Local != "rFonts" {
+                continue
+            }
+            for _, a := range start.Attr {
+                switch a.Name.Local {
+                case "ascii", "hAnsi", "eastAsia", "cs":
+                    if a.Value != "" && !seen[a.Value] {
+                        seen[a.Value] = true
+                        fonts = append(fonts, a.Value)
+                    }
+                }
+            }
+        }
+    }
+    for _, font := range d.fonts {
+        if !seen[font.family] {
+            seen[font.family] = true
+            fonts = append(fonts, font.family)
+        }
+    }
+    sort.Strings(fonts)
+    return fonts, nil
+}
+
+
+func (d *DocxDocument) fontTableParts(parts []packagePart) ([]packagePart, error) {
+    fonts, err := d.usedFonts(parts)
+    if err != nil {
+        return nil, err
+    }
+
+    name := "word/fontTable.xml"
+    var root *rawXML
+    var rels []relationship
+    if d.source != nil {
+        name = path.Join(d.source.documentDir(), "fontTable.xml")
+        if target := d.source.relationshipTarget(relTypeFontTable); target != "" {
+            name = target
+        }
+        if data, ok := d.source.parts[name]; ok {
+            reader := newDocumentReader(nil)
+            tree, rootAttrs, err := reader.parseTree(data)
+            if err != nil {
+                return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+            }
+            if tree.XMLName.Local != "w:fonts" {
+                return nil, fmt.Errorf("unexpected font table root element %s", tree.XMLName.Local)
+            }
+            tree.Attrs = append(reader.namespaceDeclarations(), rootAttrs...)
+            root = tree
+        }
+        if data, ok := d.source.parts[relationshipsPartName(name)]; ok {
+            var existing relationships
+            if err := xml.Unmarshal(data, &existing); err != nil {
+                return nil, fmt.Errorf("failed to parse %s: %w", relationshipsPartName(name), err)
+            }
+            rels = existing.Relationships
+        }
+    }
+
+    changed := root == nil || len(d.fonts) > 0
+    if root == nil {
+        root = &rawXML{XMLName: xml.Name{Local: "w:fonts"}, Attrs: []xml.Attr{
+            {Name: xml.Name{Local: "xmlns:r"}, Value: namespaceR},
+            {Name: xml.Name{Local: "xmlns:w"}, Value: namespaceW},
+        }}
+    }
+    entries := make(map[string]*rawXML)
+    for _, el := range root.elements() {
+        if fontName, ok := el.attr("w:name"); ok && el.XMLName.Local == "w:font" {
+            entries[fontName] = el
+        }
+    }
+    for _, font := range fonts {
+        if entries[font] == nil {
+            entries[font] = newFontElement(font)
+            root.Children = append(root.Children, entries[font])
+            changed = true
+        }
+    }
+    if !changed {
+        return nil, nil
+    }
+
+    result := []packagePart{}
+    dir := path.Dir(name)
+    for _, font := range d.fonts {
+        partName := ""
+        for n := 1; partName == ""; n++ {
+            candidate := path.Join(dir, "fonts", "font"+strconv.Itoa(n)+".odttf")
+            taken := false
+            if d.source != nil {
+                _, taken = d.source.parts[candidate]
+            }
+            for _, part := range result {
+                taken = taken || part.name == candidate
+            }
+            if !taken {
+                partName = candidate
+            }
+        }
+        rel := relationship{ID: nextRelationshipID(rels), Type: relTypeFont, Target: strings.TrimPrefix(partName, dir+"/")}
+        rels = append(rels, rel)
+        result = append(result, packagePart{name: partName, contentType: contentTypeObfuscated, data: obfuscateFont(font.data, font.key)})
+
+        entry := entries[font.family]
+        element := fontEmbedElements[font.style]
+        children := []interface{}{}
+        for _, child := range entry.Children {
+            if el, ok := child.(*rawXML); ok && el.XMLName.Local == element {
+                continue
+            }
+            children = append(children, child)
+        }
+        entry.Children = append(children, &rawXML{XMLName: xml.Name{Local: element}, Attrs: []xml.Attr{
+            {Name: xml.Name{Local: "r:id"}, Value: rel.ID},
+            {Name: xml.Name{Local: "w:fontKey"}, Value: font.key},
+        }})
+        sortFontElement(entry)
+    }
+
+    var buf bytes.Buffer
+    buf.WriteString(xml.Header)
+    encoder := xml.NewEncoder(&buf)
+    encoder.Indent("", "  ")
+    if err := encoder.Encode(root); err != nil {
+        return nil, fmt.Errorf("failed to encode font table: %w", err)
+    }
+    table := packagePart{name: name, contentType: contentTypeFontTable, data: buf.Bytes()}
+    if d.source == nil || d.source.relationshipTarget(relTypeFontTable) == "" {
+        if d.fontTableRel == nil {
+            d.fontTableRel = &relationship{ID: d.nextRID(), Type: relTypeFontTable, Target: "fontTable.xml"}
+        }
+        table.rel = d.fontTableRel
+    }
+    result = append([]packagePart{table}, result...)
+
+    if len(rels) > 0 {
+        data, err := encodeXMLPart(relationships{Xmlns: "http://schemas.openxmlformats.org/package/2006/relationships", Relationships: rels})
+        if err != nil {
+            return nil, fmt.Errorf("failed to encode %s: %w", relationshipsPartName(name), err)
+        }
+        result = append(result, packagePart{name: relationshipsPartName(name), data: data})
+    }
+    return result, nil
+}
+
+
+func relationshipsPartName(name string) string {
+    return path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")
+}
+
+
+func newFontElement(name string) *rawXML {
+    class := fontFamilyClass(name)
+    pitch := "variable"
+    if class == "modern" {
+        pitch = "fixed"
+    }
+    value := func(local string, val string) *rawXML {
+        return &rawXML{XMLName: xml.Name{Local: local}, Attrs: []xml.Attr{{Name: xml.Name{Local: "w:val"}, Value: val}}}
+    }
+    return &rawXML{
+        XMLName:  xml.Name{Local: "w:font"},
+        Attrs:    []xml.Attr{{Name: xml.Name{Local: "w:name"}, Value: name}},
+        Children: []interface{}{value("w:charset", "00"), value("w:family", class), value("w:pitch", pitch)},
+    }
+}
+
+
+func sortFontElement(font *rawXML) {
+    rank := func(child interface{}) int {
+        if el, ok := child.(*rawXML); ok {
+            for i, name := range fontTableOrder {
+                if el.XMLName.Local == name {
+                    return i
+                }
+            }
+        }
+        return len(fontTableOrder)
+    }
+    sort.SliceStable(font.Children, func(i, j int) bool {
+        return rank(font.Children[i]) < rank(font.Children[j])
+    })
+}
+
+
+func nextRelationshipID(rels []relationship) string {
+    next := 0
+    for _, rel := range rels {
+        if m := relationshipIDPattern.FindStringSubmatch(rel.ID); m != nil {
+            if n, err := strconv.Atoi(m[1]); err == nil && n > next {
+                next = n
+            }
+        }
+    }
+    return fmt.Sprintf("rId%d", next+1)
+}
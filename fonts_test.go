@@ -0,0 +1,161 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/hex"
+    "encoding/xml"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+
+func TestObfuscateFontKnownAnswer(t *testing.T) {
+    data := make([]byte, 40)
+    copy(data, "\x00\x01\x00\x00")
+    got := obfuscateFont(data, "{00112233-4455-6677-8899-AABBCCDDEEFF}")
+    key, _ := hex.DecodeString("FFEEDDCCBBAA99887766554433221100")
+    want := append([]byte{}, data...)
+    for i := 0; i < 32; i++ {
+        want[i] ^= key[i%16]
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("obfuscateFont() = % x, want % x", got, want)
+    }
+    if !bytes.Equal(obfuscateFont(got, "{00112233-4455-6677-8899-AABBCCDDEEFF}"), data) {
+        t.Error("obfuscating twice does not restore the font")
+    }
+}
+
+
+func deobfuscateFont(t *testing.T, data []byte, fontKey string) []byte {
+    t.Helper()
+    digits := strings.NewReplacer("{", "", "}", "", "-", "").Replace(fontKey)
+    if len(digits) != 32 {
+        t.Fatalf("font key %q is not a GUID", fontKey)
+    }
+    key := make([]byte, 16)
+    for i := range key {
+        pair := digits[len(digits)-2*i-2 : len(digits)-2*i]
+        b, err := hex.DecodeString(pair)
+        if err != nil {
+            t.Fatalf("font key %q: %v", fontKey, err)
+        }
+        key[i] = b[0]
+    }
+    font := append([]byte{}, data...)
+    for i := 0; i < 32 && i < len(font); i++ {
+        font[i] ^= key[i%16]
+    }
+    return font
+}
+
+
+func embeddedFontParts(t *testing.T, filename string) (map[string][]byte, map[string][]byte) {
+    t.Helper()
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, parts, err := readPackageParts(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var rels relationships
+    if err := xml.Unmarshal(parts["word/_rels/fontTable.xml.rels"], &rels); err != nil {
+        t.Fatalf("failed to parse font table relationships: %v", err)
+    }
+    targets := make(map[string]string)
+    for _, rel := range rels.Relationships {
+        targets[rel.ID] = path.Join("word", rel.Target)
+    }
+
+    fonts := make(map[string][]byte)
+    decoder := xml.NewDecoder(bytes.NewReader(parts["word/fontTable.xml"]))
+    family := ""
+    for {
+        token, err := decoder.Token()
+        if err != nil {
+            break
+        }
+        start, ok := token.(xml.StartElement)
+        if !ok {
+            continue
+        }
+        attrs := make(map[string]string)
+        for _, a := range start.Attr {
+            attrs[a.Name.Local] = a.Value
+        }
+        switch {
+        case start.Name.Local == "font":
+            family = attrs["name"]
+        case strings.HasPrefix(start.Name.Local, "embed"):
+            part, ok := parts[targets[attrs["id"]]]
+            if !ok {
+                t.Fatalf("%s of %s points at missing part %q", start.Name.Local, family, targets[attrs["id"]])
+            }
+            fonts[family+"/"+start.Name.Local] = deobfuscateFont(t, part, attrs["fontKey"])
+        }
+    }
+    return fonts, parts
+}
+
+
+func TestEmbeddedFontRoundTrip(t *testing.T) {
+    regular := append([]byte("\x00\x01\x00\x00"), bytes.Repeat([]byte("regular glyphs "), 8)...)
+    bold := append([]byte("OTTO"), bytes.Repeat([]byte("bold glyphs "), 8)...)
+
+    doc := NewDocxDocument()
+    doc.AddParagraph(StyleNormal).AddRun("Branded").SetFont("Corporate Sans")
+    if err := doc.EmbedFontData("Corporate Sans", FontRegular, regular); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.EmbedFontData("Corporate Sans", FontBold, bold); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.EmbedFontData("Corporate Sans", "condensed", regular); err == nil {
+        t.Error("EmbedFontData accepted an unknown style")
+    }
+    if err := doc.EmbedFontData("Corporate Sans", FontRegular, []byte("<svg>not a font at all, really</svg>")); err == nil {
+        t.Error("EmbedFontData accepted data that is not a font")
+    }
+
+    dir := t.TempDir()
+    filename := filepath.Join(dir, "fonts.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    fonts, parts := embeddedFontParts(t, filename)
+    if !bytes.Equal(fonts["Corporate Sans/embedRegular"], regular) {
+        t.Error("regular font does not de-obfuscate to the embedded data")
+    }
+    if !bytes.Equal(fonts["Corporate Sans/embedBold"], bold) {
+        t.Error("bold font does not de-obfuscate to the embedded data")
+    }
+    for name, data := range parts {
+        if strings.HasSuffix(name, ".odttf") && (bytes.Equal(data[:32], regular[:32]) || bytes.Equal(data[:32], bold[:32])) {
+            t.Errorf("%s is stored without obfuscation", name)
+        }
+    }
+    if !bytes.Contains(parts["word/settings.xml"], []byte("embedTrueTypeFonts")) {
+        t.Error("settings do not enable embedTrueTypeFonts")
+    }
+    if !bytes.Contains(parts["[Content_Types].xml"], []byte(contentTypeObfuscated)) {
+        t.Error("content types do not declare the obfuscated font parts")
+    }
+
+    reopened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resaved := filepath.Join(dir, "resaved.docx")
+    if err := NewZipDocxWriter().WriteDocument(resaved, reopened); err != nil {
+        t.Fatal(err)
+    }
+    fonts, _ = embeddedFontParts(t, resaved)
+    if !bytes.Equal(fonts["Corporate Sans/embedRegular"], regular) || !bytes.Equal(fonts["Corporate Sans/embedBold"], bold) {
+        t.Errorf("embedded fonts lost after reopening: %d fonts", len(fonts))
+    }
+}
@@ -0,0 +1,503 @@
+package docx
+
+import (
+    "encoding/xml"
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+const (
+    FormFieldText     = "FORMTEXT"
+    FormFieldCheckbox = "FORMCHECKBOX"
+    FormFieldDropDown = "FORMDROPDOWN"
+)
+
+const (
+    formFieldNameLimit   = 20
+    formFieldEntryLimit  = 25
+    formFieldHelpLimit   = 255
+    formFieldStatusLimit = 138
+    formFieldPlaceholder = "\u2002\u2002\u2002\u2002\u2002"
+)
+
+var formFieldNamePrefixes = map[string]string{
+    FormFieldText:     "Text",
+    FormFieldCheckbox: "Check",
+    FormFieldDropDown: "Dropdown",
+}
+
+
+type FormFieldOptions struct {
+    Type       string
+    Name       string
+    Default    string
+    MaxLength  int
+    Checked    bool
+    Size       float64
+    Items      []string
+    HelpText   string
+    StatusText string
+}
+
+
+type FormField struct {
+    doc    *DocxDocument
+    kind   string
+    begin  *fieldChar
+    result []*paragraphRun
+}
+
+
+
+
+func (d *DocxDocument) AddFormField(style string, options FormFieldOptions) (*FormField, error) {
+    field, items, err := d.newFormField(options)
+    if err != nil {
+        return nil, err
+    }
+    d.content = append(d.content, &paragraphData{
+        Properties: newParagraphProperties(style),
+        Content:    append(d.takePendingBookmarks(), items...),
+    })
+    return field, nil
+}
+
+
+func (p *Paragraph) AddFormField(options FormFieldOptions) (*FormField, error) {
+    field, items, err := p.doc.newFormField(options)
+    if err != nil {
+        return nil, err
+    }
+    p.append(items...)
+    return field, nil
+}
+
+
+func (d *DocxDocument) newFormField(options FormFieldOptions) (*FormField, []interface{}, error) {
+    if options.Type == "" {
+        options.Type = FormFieldText
+    }
+    prefix, ok := formFieldNamePrefixes[options.Type]
+    if !ok {
+        return nil, nil, fmt.Errorf("unsupported form field type: %s", options.Type)
+    }
+    if options.Name == "" {
+        options.Name = d.nextFormFieldName(prefix)
+    }
+    if len(options.Name) > formFieldNameLimit || !bookmarkNamePattern.MatchString(options.Name) {
+        return nil, nil, fmt.Errorf("invalid form field name %q: must start with a letter or underscore and contain at most %d letters, digits or underscores", options.Name, formFieldNameLimit)
+    }
+    if _, exists := d.bookmarks[options.Name]; exists {
+        return nil, nil, fmt.Errorf("form field name %q is already used by a bookmark", options.Name)
+    }
+    if utf8.RuneCountInString(options.HelpText) > formFieldHelpLimit {
+        return nil, nil, fmt.Errorf("form field help text is longer than %d characters", formFieldHelpLimit)
+    }
+    if utf8.RuneCountInString(options.StatusText) > formFieldStatusLimit {
+        return nil, nil, fmt.Errorf("form field status text is longer than %d characters", formFieldStatusLimit)
+    }
+
+    data := &rawXML{XMLName: xml.Name{Local: "w:ffData"}}
+    data.Children = append(data.Children,
+        newValueElement("w:name", options.Name),
+        &rawXML{XMLName: xml.Name{Local: "w:enabled"}},
+        newValueElement("w:calcOnExit", "0"),
+    )
+    if options.HelpText != "" {
+        data.Children = append(data.Children, newFormFieldText("w:helpText", options.HelpText))
+    }
+    if options.StatusText != "" {
+        data.Children = append(data.Children, newFormFieldText("w:statusText", options.StatusText))
+    }
+
+    result := options.Default
+    switch options.Type {
+    case FormFieldText:
+        if options.MaxLength < 0 {
+            return nil, nil, fmt.Errorf("invalid form field max length: %d", options.MaxLength)
+        }
+        if options.MaxLength > 0 && utf8.RuneCountInString(options.Default) > options.MaxLength {
+            return nil, nil, fmt.Errorf("default text %q is longer than the max length %d", options.Default, options.MaxLength)
+        }
+        input := &rawXML{XMLName: xml.Name{Local: "w:textInput"}}
+        if options.Default != "" {
+            input.Children = append(input.Children, newValueElement("w:default", options.Default))
+        }
+        if options.MaxLength > 0 {
+            input.Children = append(input.Children, newValueElement("w:maxLength", strconv.Itoa(options.MaxLength)))
+        }
+        data.Children = append(data.Children, input)
+        if result == "" {
+            result = formFieldPlaceholder
+        }
+    case FormFieldCheckbox:
+        checkbox := &rawXML{XMLName: xml.Name{Local: "w:checkBox"}}
+        if options.Size < 0 {
+            return nil, nil, fmt.Errorf("invalid checkbox size: %g", options.Size)
+        }
+        if options.Size > 0 {
+            checkbox.Children = append(checkbox.Children, newValueElement("w:size", strconv.Itoa(int(options.Size*2+0.5))))
+        } else {
+            checkbox.Children = append(checkbox.Children, &rawXML{XMLName: xml.Name{Local: "w:sizeAuto"}})
+        }
+        checkbox.Children = append(checkbox.Children, newValueElement("w:default", formFieldBool(options.Checked)))
+        data.Children = append(data.Children, checkbox)
+    case FormFieldDropDown:
+        if len(options.Items) == 0 {
+            return nil, nil, fmt.Errorf("drop-down form fields need at least one item")
+        }
+        if len(options.Items) > formFieldEntryLimit {
+            return nil, nil, fmt.Errorf("drop-down form fields hold at most %d items", formFieldEntryLimit)
+        }
+        selected := -1
+        list := &rawXML{XMLName: xml.Name{Local: "w:ddList"}}
+        for i, item := range options.Items {
+            if item == "" {
+                return nil, nil, fmt.Errorf("drop-down form field items cannot be empty")
+            }
+            if selected < 0 && (item == options.Default || options.Default == "") {
+                selected = i
+            }
+        }
+        if selected < 0 {
+            return nil, nil, fmt.Errorf("default %q is not one of the drop-down items", options.Default)
+        }
+        list.Children = append(list.Children, newValueElement("w:default", strconv.Itoa(selected)))
+        for _, item := range options.Items {
+            list.Children = append(list.Children, newValueElement("w:listEntry", item))
+        }
+        data.Children = append(data.Children, list)
+        result = options.Items[selected]
+    }
+
+    runs := newFieldRuns(options.Type, result, nil)
+    runs[0].FieldChar.Extra = []*rawXML{data}
+    field := &FormField{doc: d, kind: options.Type, begin: runs[0].FieldChar, result: runs[3:4]}
+    if options.Type == FormFieldCheckbox {
+        runs = []*paragraphRun{runs[0], runs[1], runs[4]}
+        field.result = nil
+    }
+
+    start := &bookmarkStart{ID: d.bookmarkCounter, Name: options.Name}
+    d.bookmarkCounter++
+    d.bookmarks[options.Name] = &bookmarkState{start: start, closed: true}
+
+    items := append([]interface{}{start}, runsToContent(runs)...)
+    items = append(items, &bookmarkEnd{ID: start.ID})
+    return field, items, nil
+}
+
+
+func newFormFieldText(name string, text string) *rawXML {
+    return &rawXML{XMLName: xml.Name{Local: name}, Attrs: []xml.Attr{
+        {Name: xml.Name{Local: "w:type"}, Value: "text"},
+        {Name: xml.Name{Local: "w:val"}, Value: text},
+    }}
+}
+
+
+func formFieldBool(value bool) string {
+    if value {
+        return "1"
+    }
+    return "0"
+}
+
+
+func (d *DocxDocument) nextFormFieldName(prefix string) string {
+    used := make(map[string]bool)
+    for _, field := range d.FormFields() {
+        used[field.Name()] = true
+    }
+    for n := 1; ; n++ {
+        name := prefix + strconv.Itoa(n)
+        if _, exists := d.bookmarks[name]; !exists && !used[name] {
+            return name
+        }
+    }
+}
+
+
+
+
+func (d *DocxDocument) FormFields() []*FormField {
+    fields := []*FormField{}
+    for _, para := range d.paragraphs() {
+        for _, field := range paragraphFields(para) {
+            tokens := splitFieldInstruction(field.instruction)
+            if len(tokens) == 0 {
+                continue
+            }
+            kind := strings.ToUpper(tokens[0])
+            if _, ok := formFieldNamePrefixes[kind]; ok {
+                fields = append(fields, &FormField{doc: d, kind: kind, begin: field.begin, result: field.result})
+            }
+        }
+    }
+    return fields
+}
+
+
+func (d *DocxDocument) FormField(name string) *FormField {
+    for _, field := range d.FormFields() {
+        if field.Name() == name {
+            return field
+        }
+    }
+    return nil
+}
+
+
+func (d *DocxDocument) FormFieldValues() map[string]string {
+    values := make(map[string]string)
+    for _, field := range d.FormFields() {
+        if _, ok := values[field.Name()]; field.Name() != "" && !ok {
+            values[field.Name()] = field.Value()
+        }
+    }
+    return values
+}
+
+
+
+
+func (f *FormField) data() *rawXML {
+    for _, el := range f.begin.Extra {
+        if localName(el.XMLName.Local) == "ffData" {
+            return el
+        }
+    }
+    return nil
+}
+
+
+func (f *FormField) setting(local string) *rawXML {
+    data := f.data()
+    if data == nil {
+        return nil
+    }
+    return childByLocalName(data, local)
+}
+
+
+func (f *FormField) typeSettings() *rawXML {
+    switch f.kind {
+    case FormFieldCheckbox:
+        return f.setting("checkBox")
+    case FormFieldDropDown:
+        return f.setting("ddList")
+    }
+    return f.setting("textInput")
+}
+
+
+func (f *FormField) typeSetting(local string) (string, bool) {
+    settings := f.typeSettings()
+    if settings == nil {
+        return "", false
+    }
+    el := childByLocalName(settings, local)
+    if el == nil {
+        return "", false
+    }
+    value, _ := attrByLocalName(el, "val")
+    return value, true
+}
+
+
+func (f *FormField) Type() string {
+    return f.kind
+}
+
+
+func (f *FormField) Name() string {
+    if el := f.setting("name"); el != nil {
+        value, _ := attrByLocalName(el, "val")
+        return value
+    }
+    return ""
+}
+
+
+func (f *FormField) Enabled() bool {
+    el := f.setting("enabled")
+    if el == nil {
+        return f.data() == nil
+    }
+    value, ok := attrByLocalName(el, "val")
+    return !ok || value == "1" || value == "true" || value == "on"
+}
+
+
+func (f *FormField) MaxLength() int {
+    value, _ := f.typeSetting("maxLength")
+    n, _ := strconv.Atoi(value)
+    return n
+}
+
+
+func (f *FormField) Items() []string {
+    items := []string{}
+    if f.kind != FormFieldDropDown {
+        return items
+    }
+    if list := f.typeSettings(); list != nil {
+        for _, el := range list.elements() {
+            if localName(el.XMLName.Local) == "listEntry" {
+                value, _ := attrByLocalName(el, "val")
+                items = append(items, value)
+            }
+        }
+    }
+    return items
+}
+
+
+func (f *FormField) selectedIndex() int {
+    value, ok := f.typeSetting("result")
+    if !ok {
+        value, _ = f.typeSetting("default")
+    }
+    n, _ := strconv.Atoi(value)
+    return n
+}
+
+
+func (f *FormField) Default() string {
+    value, ok := f.typeSetting("default")
+    switch f.kind {
+    case FormFieldCheckbox:
+        return strconv.FormatBool(ok && (value == "1" || value == "true" || value == "on"))
+    case FormFieldDropDown:
+        n, _ := strconv.Atoi(value)
+        if items := f.Items(); n >= 0 && n < len(items) {
+            return items[n]
+        }
+        return ""
+    }
+    return value
+}
+
+
+func (f *FormField) Checked() bool {
+    if f.kind != FormFieldCheckbox {
+        return false
+    }
+    value, ok := f.typeSetting("checked")
+    if !ok {
+        return f.Default() == "true"
+    }
+    return value == "" || value == "1" || value == "true" || value == "on"
+}
+
+
+func (f *FormField) Value() string {
+    switch f.kind {
+    case FormFieldCheckbox:
+        return strconv.FormatBool(f.Checked())
+    case FormFieldDropDown:
+        if items := f.Items(); len(items) > 0 {
+            if n := f.selectedIndex(); n >= 0 && n < len(items) {
+                return items[n]
+            }
+        }
+    }
+    var sb strings.Builder
+    for _, run := range f.result {
+        if run.Text != nil {
+            sb.WriteString(run.Text.Text)
+        }
+    }
+    if sb.String() == formFieldPlaceholder {
+        return ""
+    }
+    return sb.String()
+}
+
+
+
+
+func (f *FormField) SetValue(value string) error {
+    switch f.kind {
+    case FormFieldCheckbox:
+        checked, err := strconv.ParseBool(value)
+        if err != nil {
+            return fmt.Errorf("invalid checkbox value %q: %w", value, err)
+        }
+        return f.SetChecked(checked)
+    case FormFieldDropDown:
+        for i, item := range f.Items() {
+            if item == value {
+                f.setTypeSetting("result", strconv.Itoa(i), true)
+                f.setResult(value)
+                return nil
+            }
+        }
+        return fmt.Errorf("value %q is not one of the drop-down items", value)
+    }
+
+    if n := f.MaxLength(); n > 0 && utf8.RuneCountInString(value) > n {
+        return fmt.Errorf("value %q is longer than the max length %d", value, n)
+    }
+    if value == "" {
+        value = formFieldPlaceholder
+    }
+    if !f.setResult(value) {
+        return fmt.Errorf("form field %q has no result text to fill", f.Name())
+    }
+    return nil
+}
+
+
+func (f *FormField) SetChecked(checked bool) error {
+    if f.kind != FormFieldCheckbox {
+        return fmt.Errorf("form field %q is not a checkbox", f.Name())
+    }
+    f.setTypeSetting("checked", formFieldBool(checked), false)
+    return nil
+}
+
+
+func (f *FormField) setTypeSetting(local string, value string, first bool) {
+    data := f.data()
+    if data == nil {
+        data = &rawXML{XMLName: xml.Name{Local: "w:ffData"}}
+        f.begin.Extra = append(f.begin.Extra, data)
+    }
+    settings := f.typeSettings()
+    if settings == nil {
+        name := map[string]string{FormFieldText: "w:textInput", FormFieldCheckbox: "w:checkBox", FormFieldDropDown: "w:ddList"}[f.kind]
+        settings = &rawXML{XMLName: xml.Name{Local: name}}
+        data.Children = append(data.Children, settings)
+    }
+    if el := childByLocalName(settings, local); el != nil {
+        el.Attrs = []xml.Attr{{Name: xml.Name{Local: "w:val"}, Value: value}}
+        return
+    }
+    el := newValueElement("w:"+local, value)
+    if first {
+        settings.Children = append([]interface{}{el}, settings.Children...)
+    } else {
+        settings.Children = append(settings.Children, el)
+    }
+}
+
+
+func (f *FormField) setResult(text string) bool {
+    filled := false
+    for _, run := range f.result {
+        if run.Text == nil {
+            continue
+        }
+        if filled {
+            run.Text = nil
+            continue
+        }
+        run.Text.Text = text
+        run.Text.Space = "preserve"
+        filled = true
+    }
+    return filled
+}
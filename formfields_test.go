@@ -0,0 +1,104 @@
+package docx
+
+import (
+    "strings"
+    "testing"
+)
+
+
+func TestFormFieldRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    options := []FormFieldOptions{
+        {Type: FormFieldText, Name: "FullName", MaxLength: 30, HelpText: "First and last name"},
+        {Type: FormFieldText, Name: "City", Default: "Oslo"},
+        {Type: FormFieldCheckbox, Name: "Remote", Checked: true, Size: 10},
+        {Type: FormFieldDropDown, Name: "Team", Items: []string{"Engineering", "Sales", "Support"}, Default: "Sales"},
+    }
+    for _, o := range options {
+        if _, err := doc.AddFormField(StyleNormal, o); err != nil {
+            t.Fatalf("AddFormField(%s): %v", o.Name, err)
+        }
+    }
+    unnamed, err := doc.AddParagraph(StyleNormal).AddFormField(FormFieldOptions{Type: FormFieldCheckbox})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if unnamed.Name() != "Check1" {
+        t.Errorf("generated name = %q, want Check1", unnamed.Name())
+    }
+
+    invalid := []FormFieldOptions{
+        {Name: "1st"},
+        {Name: "FullName"},
+        {Name: "Short", Default: "too long", MaxLength: 3},
+        {Type: FormFieldDropDown, Name: "Empty"},
+        {Type: FormFieldDropDown, Name: "Unknown", Items: []string{"A"}, Default: "B"},
+        {Type: "FORMSIGNATURE", Name: "Sign"},
+    }
+    for _, o := range invalid {
+        if _, err := doc.AddFormField(StyleNormal, o); err == nil {
+            t.Errorf("AddFormField(%+v) succeeded", o)
+        }
+    }
+
+    reopened := reopenDocument(t, doc)
+    want := map[string]string{"FullName": "", "City": "Oslo", "Remote": "true", "Team": "Sales", "Check1": "false"}
+    got := reopened.FormFieldValues()
+    if len(got) != len(want) {
+        t.Errorf("got values for %d form fields, want %d: %v", len(got), len(want), got)
+    }
+    for name, value := range want {
+        if got[name] != value {
+            t.Errorf("value of %s = %q, want %q", name, got[name], value)
+        }
+    }
+
+    name := reopened.FormField("FullName")
+    if name.Type() != FormFieldText || name.MaxLength() != 30 || !name.Enabled() {
+        t.Errorf("FullName: type=%s maxLength=%d enabled=%v", name.Type(), name.MaxLength(), name.Enabled())
+    }
+    team := reopened.FormField("Team")
+    if items := team.Items(); !equalStrings(items, []string{"Engineering", "Sales", "Support"}) || team.Default() != "Sales" {
+        t.Errorf("Team: items=%v default=%q", items, team.Default())
+    }
+
+    if err := name.SetValue("Grace Hopper"); err != nil {
+        t.Fatal(err)
+    }
+    if err := name.SetValue("A name that is much longer than thirty characters"); err == nil {
+        t.Error("SetValue accepted text longer than the max length")
+    }
+    if err := reopened.FormField("City").SetValue(""); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.FormField("Remote").SetValue("false"); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.FormField("Check1").SetChecked(true); err != nil {
+        t.Fatal(err)
+    }
+    if err := team.SetValue("Support"); err != nil {
+        t.Fatal(err)
+    }
+    if err := team.SetValue("Marketing"); err == nil {
+        t.Error("SetValue accepted a drop-down value that is not an item")
+    }
+    if err := team.SetChecked(true); err == nil {
+        t.Error("SetChecked succeeded on a drop-down")
+    }
+
+    filled := reopenDocument(t, reopened)
+    want = map[string]string{"FullName": "Grace Hopper", "City": "", "Remote": "false", "Team": "Support", "Check1": "true"}
+    got = filled.FormFieldValues()
+    for name, value := range want {
+        if got[name] != value {
+            t.Errorf("value of %s after filling = %q, want %q", name, got[name], value)
+        }
+    }
+    if text := filled.Text(); !strings.Contains(text, "Grace Hopper") || !strings.Contains(text, "Support") {
+        t.Errorf("document text %q does not show the filled results", text)
+    }
+    if filled.FormField("Team").Default() != "Sales" {
+        t.Errorf("filling the drop-down changed its default to %q", filled.FormField("Team").Default())
+    }
+}
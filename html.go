@@ -0,0 +1,730 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "fmt"
+    "html"
+    "io"
+    "mime"
+    "net/url"
+    "os"
+    "path"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+
+type HTMLWriter struct {
+    MediaDir       string
+    InlineImages   bool
+    Title          string
+    StylesheetFile string
+}
+
+
+func NewHTMLWriter() *HTMLWriter {
+    return &HTMLWriter{MediaDir: "media"}
+}
+
+
+func (hw *HTMLWriter) mediaDir() string {
+    if hw.MediaDir == "" {
+        return "media"
+    }
+    return hw.MediaDir
+}
+
+
+func (hw *HTMLWriter) WriteDocument(filename string, doc Document) error {
+    renderer := newHTMLRenderer(doc, hw)
+    var buf bytes.Buffer
+    if err := renderer.render(&buf); err != nil {
+        return err
+    }
+
+    err := os.WriteFile(filename, buf.Bytes(), 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+
+    if hw.StylesheetFile != "" {
+        cssPath := filepath.Join(filepath.Dir(filename), filepath.FromSlash(hw.StylesheetFile))
+        err = os.WriteFile(cssPath, []byte(renderer.sheet.css()), 0644)
+        if err != nil {
+            return fmt.Errorf("failed to write stylesheet %s: %w", cssPath, err)
+        }
+    }
+
+    if len(renderer.media) == 0 {
+        return nil
+    }
+    mediaDir := filepath.Join(filepath.Dir(filename), filepath.FromSlash(hw.mediaDir()))
+    err = os.MkdirAll(mediaDir, 0755)
+    if err != nil {
+        return fmt.Errorf("failed to create media directory %s: %w", mediaDir, err)
+    }
+    for name, data := range renderer.media {
+        mediaPath := filepath.Join(mediaDir, name)
+        err = os.WriteFile(mediaPath, data, 0644)
+        if err != nil {
+            return fmt.Errorf("failed to write image %s: %w", mediaPath, err)
+        }
+    }
+    return nil
+}
+
+
+func (hw *HTMLWriter) WriteHTML(w io.Writer, doc Document) error {
+    return newHTMLRenderer(doc, hw).render(w)
+}
+
+
+func (hw *HTMLWriter) WriteStylesheet(w io.Writer, doc Document) error {
+    return loadStyleSheet(doc).writeCSS(w)
+}
+
+
+type htmlRenderer struct {
+    doc    Document
+    writer *HTMLWriter
+    sheet  *styleSheet
+    media  map[string][]byte
+    title  string
+}
+
+
+type htmlSpan struct {
+    text      string
+    bold      bool
+    italic    bool
+    underline bool
+    strike    bool
+    code      bool
+    class     string
+    style     string
+    raw       bool
+}
+
+
+func newHTMLRenderer(doc Document, writer *HTMLWriter) *htmlRenderer {
+    return &htmlRenderer{doc: doc, writer: writer, sheet: loadStyleSheet(doc), media: make(map[string][]byte)}
+}
+
+
+func (r *htmlRenderer) render(w io.Writer) error {
+    var body strings.Builder
+    r.blocks(&body, r.doc.renderedContent())
+
+    title := r.writer.Title
+    if title == "" {
+        title = r.title
+    }
+
+    var sb strings.Builder
+    sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+    sb.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+    if r.writer.StylesheetFile != "" {
+        sb.WriteString("<link rel=\"stylesheet\" href=\"" + html.EscapeString(r.writer.StylesheetFile) + "\">\n")
+    } else {
+        sb.WriteString("<style>\n" + r.sheet.css() + "</style>\n")
+    }
+    sb.WriteString("</head>\n<body>\n")
+    sb.WriteString(body.String())
+    sb.WriteString("</body>\n</html>\n")
+
+    _, err := io.WriteString(w, sb.String())
+    if err != nil {
+        return fmt.Errorf("failed to write html: %w", err)
+    }
+    return nil
+}
+
+
+func (r *htmlRenderer) blocks(sb *strings.Builder, content []interface{}) {
+    lists := []bool{}
+    closeLists := func(depth int) {
+        for len(lists) > depth {
+            if lists[len(lists)-1] {
+                sb.WriteString("</li>\n</ol>\n")
+            } else {
+                sb.WriteString("</li>\n</ul>\n")
+            }
+            lists = lists[:len(lists)-1]
+        }
+    }
+
+    for i := 0; i < len(content); i++ {
+        p, ok := content[i].(*paragraphData)
+        if !ok {
+            closeLists(0)
+            switch v := content[i].(type) {
+            case *tableData:
+                r.table(sb, v)
+            case *containerElement:
+                r.blocks(sb, v.Content)
+            }
+            continue
+        }
+
+        if level, ordered, ok := r.doc.getListLevel(p); ok {
+            if len(lists) > level+1 {
+                closeLists(level + 1)
+            }
+            if len(lists) == level+1 {
+                if lists[level] != ordered {
+                    closeLists(level)
+                } else {
+                    sb.WriteString("</li>\n<li>")
+                }
+            }
+            for len(lists) < level+1 {
+                if ordered && len(lists) == level {
+                    sb.WriteString("<ol>\n<li>")
+                } else {
+                    sb.WriteString("<ul>\n<li>")
+                }
+                lists = append(lists, ordered && len(lists) == level)
+            }
+            sb.WriteString(r.inline(p.Content))
+            continue
+        }
+        if len(lists) > 0 && paragraphStyleID(p) == StyleListParagraph {
+            sb.WriteString("\n<p>" + r.inline(p.Content) + "</p>")
+            continue
+        }
+        closeLists(0)
+
+        switch style := paragraphStyleID(p); {
+        case style == StyleSourceCode:
+            lines := []string{}
+            for ; i < len(content); i++ {
+                next, ok := content[i].(*paragraphData)
+                if !ok || paragraphStyleID(next) != StyleSourceCode {
+                    break
+                }
+                lines = append(lines, html.EscapeString(next.text()))
+            }
+            i--
+            sb.WriteString("<pre class=\"" + cssClassName(style) + "\"><code>" + strings.Join(lines, "\n") + "</code></pre>\n")
+        case style == StyleQuote:
+            sb.WriteString("<blockquote class=\"" + cssClassName(style) + "\">\n")
+            for ; i < len(content); i++ {
+                next, ok := content[i].(*paragraphData)
+                if !ok || paragraphStyleID(next) != StyleQuote {
+                    break
+                }
+                sb.WriteString("<p" + r.paragraphStyle(next) + ">" + r.inline(next.Content) + "</p>\n")
+            }
+            i--
+            sb.WriteString("</blockquote>\n")
+        case isFigure(p):
+            sb.WriteString("<figure>\n" + r.inline(p.Content) + "\n")
+            if i+1 < len(content) {
+                if next, ok := content[i+1].(*paragraphData); ok && paragraphStyleID(next) == StyleCaption {
+                    sb.WriteString("<figcaption class=\"" + cssClassName(StyleCaption) + "\">" + r.inline(next.Content) + "</figcaption>\n")
+                    i++
+                }
+            }
+            sb.WriteString("</figure>\n")
+        default:
+            r.paragraph(sb, p, style)
+        }
+    }
+    closeLists(0)
+}
+
+
+func paragraphStyleID(p *paragraphData) string {
+    if p.Properties != nil && p.Properties.Style != nil {
+        return p.Properties.Style.Val
+    }
+    return ""
+}
+
+
+func isFigure(p *paragraphData) bool {
+    hasImage := false
+    for _, run := range p.runs() {
+        switch {
+        case run.hasDrawing():
+            if rID, _ := drawingImage(run); rID != "" {
+                hasImage = true
+            }
+        case run.Text != nil && strings.TrimSpace(run.Text.Text) != "":
+            return false
+        }
+    }
+    return hasImage
+}
+
+
+func (r *htmlRenderer) paragraph(sb *strings.Builder, p *paragraphData, style string) {
+    tag := "p"
+    if level := headingLevel(style); level > 0 {
+        tag = "h" + strconv.Itoa(level)
+        if r.title == "" {
+            r.title = strings.Join(strings.Fields(p.text()), " ")
+        }
+    }
+    if style == "" {
+        style = r.sheet.defaultParagraph
+    }
+
+    attrs := ""
+    if style != "" {
+        attrs = " class=\"" + cssClassName(style) + "\""
+    }
+    sb.WriteString("<" + tag + attrs + r.paragraphStyle(p) + ">" + r.inline(p.Content) + "</" + tag + ">\n")
+}
+
+
+
+
+func (r *htmlRenderer) paragraphStyle(p *paragraphData) string {
+    if p.Properties == nil {
+        return ""
+    }
+    props := styleProperties{}
+    r.sheet.readParagraphProperties(&rawXML{Children: rawChildren(p.Properties.Extra)}, props)
+    if p.Properties.Justification != nil {
+        props["align"] = p.Properties.Justification.Val
+    }
+    return htmlStyleAttr(paragraphCSS(props))
+}
+
+
+func rawChildren(elements []*rawXML) []interface{} {
+    children := []interface{}{}
+    for _, el := range elements {
+        children = append(children, el)
+    }
+    return children
+}
+
+
+func htmlStyleAttr(declarations []string) string {
+    if len(declarations) == 0 {
+        return ""
+    }
+    return " style=\"" + html.EscapeString(strings.Join(declarations, " ")) + "\""
+}
+
+
+func (r *htmlRenderer) inline(content []interface{}) string {
+    return formatHTMLSpans(r.spans(content, []htmlSpan{}))
+}
+
+
+func (r *htmlRenderer) spans(content []interface{}, spans []htmlSpan) []htmlSpan {
+    for _, c := range content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            spans = r.runSpans(v, spans)
+        case *simpleField:
+            for _, run := range v.Runs {
+                spans = r.runSpans(run, spans)
+            }
+        case *bookmarkStart:
+            if v.Name != "" && !strings.HasPrefix(v.Name, "_GoBack") {
+                spans = append(spans, htmlSpan{text: "<a id=\"" + html.EscapeString(v.Name) + "\"></a>", raw: true})
+            }
+        case *containerElement:
+            if v.XMLName.Local != "w:hyperlink" {
+                spans = r.spans(v.Content, spans)
+                continue
+            }
+            inner := r.inline(v.Content)
+            target, title := "", ""
+            for _, a := range v.Attrs {
+                switch a.Name.Local {
+                case "r:id":
+                    if rel, ok := r.doc.getRelationship(a.Value); ok {
+                        target = rel.Target
+                    }
+                case "w:anchor":
+                    if target == "" {
+                        target = "#" + a.Value
+                    }
+                case "w:tooltip":
+                    title = " title=\"" + html.EscapeString(a.Value) + "\""
+                }
+            }
+            if target == "" || !safeHTMLLink(target) {
+                spans = append(spans, htmlSpan{text: inner, raw: true})
+            } else {
+                spans = append(spans, htmlSpan{text: "<a href=\"" + html.EscapeString(target) + "\"" + title + ">" + inner + "</a>", raw: true})
+            }
+        }
+    }
+    return spans
+}
+
+
+func safeHTMLLink(target string) bool {
+    if strings.HasPrefix(target, "#") {
+        return true
+    }
+    if strings.TrimSpace(target) != target || strings.IndexFunc(target, unicode.IsControl) >= 0 {
+        return false
+    }
+    u, err := url.Parse(target)
+    if err != nil {
+        return false
+    }
+    switch strings.ToLower(u.Scheme) {
+    case "http", "https", "mailto", "":
+        return true
+    }
+    return false
+}
+
+
+func (r *htmlRenderer) runSpans(run *paragraphRun, spans []htmlSpan) []htmlSpan {
+    span := htmlSpan{}
+    if props := run.Properties; props != nil {
+        span.bold = props.Bold != nil
+        span.italic = props.Italic != nil
+        span.strike = props.Strike != nil && props.Strike.Val != "0" && props.Strike.Val != "false"
+        span.underline = props.Underline != nil && props.Underline.Val != "none"
+        if props.Style != nil {
+            switch props.Style.Val {
+            case StyleVerbatimChar:
+                span.code = true
+            case StyleHyperlink:
+            default:
+                span.class = cssClassName(props.Style.Val)
+            }
+        }
+
+        direct := styleProperties{}
+        r.sheet.readRunProperties(&rawXML{Children: rawChildren(props.Extra)}, direct)
+        if props.Fonts != nil && props.Fonts.ASCII != "" {
+            direct["font"] = props.Fonts.ASCII
+        }
+        if props.Color != nil {
+            direct["color"] = props.Color.Val
+        }
+        if props.Size != nil {
+            direct["size"] = strconv.Itoa(props.Size.Val)
+        }
+        span.style = strings.Join(runCSS(direct), " ")
+    }
+
+    switch {
+    case run.Text != nil:
+        span.text = html.EscapeString(run.Text.Text)
+    case run.Tab != nil:
+        span.text = "&emsp;"
+    case run.Break != nil && (run.Break.Type == "" || run.Break.Type == BreakTextWrapping):
+        return append(spans, htmlSpan{text: "<br>", raw: true})
+    case run.hasDrawing():
+        if image := r.image(run); image != "" {
+            return append(spans, htmlSpan{text: image, raw: true})
+        }
+        return spans
+    default:
+        return spans
+    }
+    return append(spans, span)
+}
+
+
+func (r *htmlRenderer) image(run *paragraphRun) string {
+    rID, alt := drawingImage(run)
+    if rID == "" {
+        return ""
+    }
+    name, data, ok := r.doc.getImageData(rID)
+    if !ok {
+        return ""
+    }
+
+    src := path.Join(r.writer.mediaDir(), name)
+    if r.writer.InlineImages {
+        contentType := mime.TypeByExtension(path.Ext(name))
+        if contentType == "" {
+            contentType = "application/octet-stream"
+        }
+        src = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+    } else {
+        r.media[name] = data
+    }
+
+    attrs := ""
+    if cx, cy := drawingExtent(run); cx > 0 && cy > 0 {
+        attrs = fmt.Sprintf(" width=\"%d\" height=\"%d\"", cx/emusPerPixel, cy/emusPerPixel)
+    }
+    return "<img src=\"" + html.EscapeString(src) + "\" alt=\"" + html.EscapeString(alt) + "\"" + attrs + ">"
+}
+
+
+func drawingExtent(run *paragraphRun) (int64, int64) {
+    if run.Drawing != nil {
+        return run.Drawing.Inline.Extent.Cx, run.Drawing.Inline.Extent.Cy
+    }
+    for _, extra := range run.Extra {
+        if ext := extra.find("wp:extent"); ext != nil {
+            cx, _ := strconv.ParseInt(attrValue(ext, "cx"), 10, 64)
+            cy, _ := strconv.ParseInt(attrValue(ext, "cy"), 10, 64)
+            return cx, cy
+        }
+    }
+    return 0, 0
+}
+
+
+func (r *htmlRenderer) table(sb *strings.Builder, t *tableData) {
+    class := "docx-table"
+    if t.Properties != nil && t.Properties.Style != nil && t.Properties.Style.Val != "" {
+        class += " " + cssClassName(t.Properties.Style.Val)
+    }
+    sb.WriteString("<table class=\"" + class + "\">\n")
+
+    header := 0
+    for header < len(t.Rows) && t.Rows[header].Properties != nil && t.Rows[header].Properties.Header != nil {
+        header++
+    }
+    for i, row := range t.Rows {
+        switch {
+        case i == 0 && header > 0:
+            sb.WriteString("<thead>\n")
+        case i == header:
+            sb.WriteString("<tbody>\n")
+        }
+
+        col := 0
+        sb.WriteString("<tr>\n")
+        for _, cell := range row.Cells {
+            span, merge := cellSpan(cell)
+            if merge == "continue" {
+                col += span
+                continue
+            }
+            tag := "td"
+            if i < header {
+                tag = "th"
+            }
+            attrs := ""
+            if span > 1 {
+                attrs += " colspan=\"" + strconv.Itoa(span) + "\""
+            }
+            if merge == "restart" {
+                if rows := rowSpan(t.Rows, i, col); rows > 1 {
+                    attrs += " rowspan=\"" + strconv.Itoa(rows) + "\""
+                }
+            }
+            attrs += htmlStyleAttr(cellCSS(cell))
+
+            var inner strings.Builder
+            r.blocks(&inner, cell.Blocks)
+            sb.WriteString("<" + tag + attrs + ">" + strings.TrimSuffix(inner.String(), "\n") + "</" + tag + ">\n")
+            col += span
+        }
+        sb.WriteString("</tr>\n")
+
+        if i == header-1 {
+            sb.WriteString("</thead>\n")
+        }
+    }
+    if header < len(t.Rows) {
+        sb.WriteString("</tbody>\n")
+    }
+    sb.WriteString("</table>\n")
+}
+
+
+func cellSpan(cell *tableCellData) (int, string) {
+    span, merge := 1, ""
+    if cell.Properties == nil {
+        return span, merge
+    }
+    for _, extra := range cell.Properties.Extra {
+        switch extra.XMLName.Local {
+        case "w:gridSpan":
+            if n, ok := attrUint(extra, "w:val"); ok && n > 1 {
+                span = int(n)
+            }
+        case "w:vMerge":
+            merge = attrValue(extra, "w:val")
+            if merge == "" {
+                merge = "continue"
+            }
+        }
+    }
+    return span, merge
+}
+
+
+func rowSpan(rows []*tableRowData, start int, col int) int {
+    count := 1
+    for _, row := range rows[start+1:] {
+        c, merged := 0, false
+        for _, cell := range row.Cells {
+            span, merge := cellSpan(cell)
+            if c == col {
+                merged = merge == "continue"
+                break
+            }
+            c += span
+        }
+        if !merged {
+            break
+        }
+        count++
+    }
+    return count
+}
+
+
+func gridWidths(t *tableData, width float64) []float64 {
+    widths := []float64{}
+    for _, col := range t.Grid.Columns {
+        widths = append(widths, float64(col.W)/20)
+    }
+    if len(widths) > 0 {
+        return widths
+    }
+    cols := 0
+    for _, row := range t.Rows {
+        count := 0
+        for _, cell := range row.Cells {
+            span, _ := cellSpan(cell)
+            count += span
+        }
+        if count > cols {
+            cols = count
+        }
+    }
+    for i := 0; i < cols; i++ {
+        widths = append(widths, width/float64(cols))
+    }
+    return widths
+}
+
+
+func cellProperties(cell *tableCellData, table styleProperties, top bool, bottom bool, left bool, right bool) styleProperties {
+    props := styleProperties{"cell-left": "108", "cell-right": "108"}
+    for _, side := range []string{"top", "bottom", "left", "right"} {
+        if v := table["cell-"+side]; v != "" {
+            props["cell-"+side] = v
+        }
+    }
+    for _, edge := range []struct {
+        side  string
+        outer bool
+        inner string
+    }{{"top", top, "insideH"}, {"bottom", bottom, "insideH"}, {"left", left, "insideV"}, {"right", right, "insideV"}} {
+        if edge.outer {
+            props["border-"+edge.side] = table["border-"+edge.side]
+        } else {
+            props["border-"+edge.side] = table["border-"+edge.inner]
+        }
+    }
+    if cell.Properties != nil {
+        for _, extra := range cell.Properties.Extra {
+            switch extra.XMLName.Local {
+            case "w:tcBorders":
+                readBorders(extra, props)
+            case "w:shd":
+                props["shading"] = attrValue(extra, "w:fill")
+            case "w:vAlign":
+                props["vAlign"] = attrValue(extra, "w:val")
+            case "w:tcMar":
+                for _, side := range extra.elements() {
+                    name := strings.NewReplacer("start", "left", "end", "right").Replace(strings.TrimPrefix(side.XMLName.Local, "w:"))
+                    props["cell-"+name] = attrValue(side, "w:w")
+                }
+            }
+        }
+    }
+    return props
+}
+
+
+func cellCSS(cell *tableCellData) []string {
+    css := []string{}
+    if cell.Properties == nil {
+        return css
+    }
+    for _, extra := range cell.Properties.Extra {
+        switch extra.XMLName.Local {
+        case "w:shd":
+            if fill, ok := cssColor(attrValue(extra, "w:fill")); ok {
+                css = append(css, "background-color: "+fill+";")
+            }
+        case "w:vAlign":
+            switch attrValue(extra, "w:val") {
+            case "center":
+                css = append(css, "vertical-align: middle;")
+            case "bottom":
+                css = append(css, "vertical-align: bottom;")
+            }
+        }
+    }
+    return css
+}
+
+
+
+
+func formatHTMLSpans(spans []htmlSpan) string {
+    var sb strings.Builder
+    for i := 0; i < len(spans); {
+        span := spans[i]
+        if span.raw {
+            sb.WriteString(span.text)
+            i++
+            continue
+        }
+
+        text := span.text
+        j := i + 1
+        for ; j < len(spans) && !spans[j].raw && sameHTMLFormat(spans[j], span); j++ {
+            text += spans[j].text
+        }
+        i = j
+
+        open, close := "", ""
+        wrap := func(tag string, attrs string) {
+            open += "<" + tag + attrs + ">"
+            close = "</" + tag + ">" + close
+        }
+        if span.class != "" || span.style != "" {
+            attrs := ""
+            if span.class != "" {
+                attrs += " class=\"" + span.class + "\""
+            }
+            if span.style != "" {
+                attrs += " style=\"" + html.EscapeString(span.style) + "\""
+            }
+            wrap("span", attrs)
+        }
+        if span.bold {
+            wrap("strong", "")
+        }
+        if span.italic {
+            wrap("em", "")
+        }
+        if span.underline {
+            wrap("u", "")
+        }
+        if span.strike {
+            wrap("s", "")
+        }
+        if span.code {
+            wrap("code", "")
+        }
+        sb.WriteString(open + text + close)
+    }
+    return sb.String()
+}
+
+
+func sameHTMLFormat(a htmlSpan, b htmlSpan) bool {
+    a.text, b.text = "", ""
+    return a == b
+}
+
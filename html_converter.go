@@ -0,0 +1,792 @@
+package docx
+
+import (
+    "encoding/base64"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+var (
+    htmlWhitespacePattern = regexp.MustCompile(`[ \t\r\n\f]+`)
+    cssRGBPattern         = regexp.MustCompile(`^rgba?\(\s*(\d+)\s*,\s*(\d+)\s*,\s*(\d+)`)
+    cssLengthPattern      = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*(px|pt|em|rem|%)?$`)
+)
+
+var cssNamedColors = map[string]string{
+    "black": "000000", "white": "FFFFFF", "red": "FF0000", "green": "008000", "blue": "0000FF",
+    "yellow": "FFFF00", "gray": "808080", "grey": "808080", "orange": "FFA500", "purple": "800080",
+    "navy": "000080", "teal": "008080", "maroon": "800000", "olive": "808000", "silver": "C0C0C0",
+    "lime": "00FF00", "aqua": "00FFFF", "fuchsia": "FF00FF",
+}
+
+var cssFontSizeKeywords = map[string]float64{
+    "xx-small": 7, "x-small": 7.5, "small": 10, "medium": 12, "large": 13.5, "x-large": 18, "xx-large": 24,
+}
+
+
+type HTMLConverter struct {
+    BaseDir         string
+    AllowLocalFiles bool
+}
+
+
+func NewHTMLConverter() *HTMLConverter {
+    return &HTMLConverter{}
+}
+
+
+func (c *HTMLConverter) Convert(src string) (*DocxDocument, error) {
+    doc := NewDocxDocument()
+    if err := c.Append(doc, src); err != nil {
+        return nil, err
+    }
+    return doc, nil
+}
+
+
+func (c *HTMLConverter) ConvertFile(filename string) (*DocxDocument, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read html file %s: %w", filename, err)
+    }
+    converter := *c
+    if converter.BaseDir == "" {
+        converter.BaseDir = filepath.Dir(filename)
+    }
+    return converter.Convert(string(data))
+}
+
+
+func (c *HTMLConverter) Append(doc *DocxDocument, src string) error {
+    builder := &htmlBuilder{doc: doc, baseDir: c.BaseDir, allowLocalFiles: c.AllowLocalFiles}
+    if err := builder.nodes(parseHTML(src).children, htmlContext{target: doc.Body().blockList}); err != nil {
+        return err
+    }
+    builder.end()
+    return nil
+}
+
+
+type htmlBuilder struct {
+    doc             *DocxDocument
+    baseDir         string
+    allowLocalFiles bool
+    para            *Paragraph
+    link            *containerElement
+    lastRun         *paragraphRun
+    space           bool
+    listItem        bool
+}
+
+
+type htmlContext struct {
+    target    blockList
+    style     string
+    quote     bool
+    listLevel int
+    align     string
+    pre       bool
+    format    htmlFormat
+}
+
+
+type htmlFormat struct {
+    bold      bool
+    italic    bool
+    underline bool
+    strike    bool
+    code      bool
+    color     string
+    size      float64
+    font      string
+}
+
+
+func (b *htmlBuilder) paragraph(ctx htmlContext) *Paragraph {
+    if b.para != nil {
+        return b.para
+    }
+    style := ctx.style
+    if style == "" {
+        style = StyleNormal
+    }
+    switch {
+    case ctx.listLevel > 0 && style == StyleNormal:
+        style = StyleListParagraph
+    case ctx.quote && style == StyleNormal:
+        style = StyleQuote
+    }
+    b.para, _ = ctx.target.InsertParagraph(ctx.target.Len(), style)
+    if ctx.listLevel > 0 {
+        b.para.properties().Extra = append(b.para.properties().Extra, &rawXML{
+            XMLName: xml.Name{Local: "w:ind"},
+            Attrs:   []xml.Attr{{Name: xml.Name{Local: "w:left"}, Value: strconv.Itoa(720 * ctx.listLevel)}},
+        })
+    }
+    if ctx.align != "" {
+        _ = b.para.SetAlignment(ctx.align)
+    }
+    b.space = true
+    return b.para
+}
+
+
+
+
+func (b *htmlBuilder) end() {
+    if b.listItem {
+        return
+    }
+    if b.lastRun != nil && b.lastRun.Text != nil {
+        b.lastRun.Text.Text = strings.TrimRight(b.lastRun.Text.Text, " ")
+    }
+    b.para, b.link, b.lastRun = nil, nil, nil
+}
+
+
+func (b *htmlBuilder) appendRun(run *paragraphRun, ctx htmlContext) {
+    para := b.paragraph(ctx)
+    b.listItem = false
+    if b.link != nil {
+        b.link.Content = append(b.link.Content, run)
+    } else {
+        para.appendRun(run)
+    }
+    b.lastRun = run
+}
+
+
+func (b *htmlBuilder) nodes(nodes []*htmlNode, ctx htmlContext) error {
+    for _, n := range nodes {
+        if n.tag == "" {
+            b.text(n.text, ctx)
+            continue
+        }
+        if err := b.element(n, ctx); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+
+func (b *htmlBuilder) block(n *htmlNode, ctx htmlContext) error {
+    b.end()
+    err := b.nodes(n.children, ctx)
+    b.end()
+    return err
+}
+
+
+func (b *htmlBuilder) element(n *htmlNode, ctx htmlContext) error {
+    ctx = ctx.withAttributes(n)
+    switch n.tag {
+    case "head", "script", "style", "title", "template", "textarea", "select", "button":
+        return nil
+    case "h1", "h2", "h3", "h4", "h5", "h6":
+        if ctx.listLevel == 0 {
+            ctx.style = "Heading" + n.tag[1:]
+        }
+        return b.block(n, ctx)
+    case "figcaption", "caption":
+        ctx.style = StyleCaption
+        return b.block(n, ctx)
+    case "blockquote":
+        ctx.quote = true
+        return b.block(n, ctx)
+    case "pre":
+        ctx.pre = true
+        ctx.style = StyleSourceCode
+        ctx.format.code = false
+        return b.block(n, ctx)
+    case "ul", "ol":
+        b.end()
+        err := b.list(n, ctx)
+        b.end()
+        return err
+    case "table":
+        b.listItem = false
+        b.end()
+        err := b.table(n, ctx)
+        b.end()
+        return err
+    case "hr":
+        b.end()
+        addBottomBorder(b.paragraph(ctx))
+        b.end()
+        return nil
+    case "br":
+        b.appendRun(&paragraphRun{Break: &runBreak{}}, ctx)
+        b.space = true
+        return nil
+    case "img":
+        b.image(n, ctx)
+        return nil
+    case "a":
+        href := strings.TrimSpace(n.attr("href"))
+        if href == "" || b.link != nil {
+            return b.nodes(n.children, ctx)
+        }
+        link := b.doc.newHyperlink(href)
+        if title := n.attr("title"); title != "" {
+            link.Attrs = append(link.Attrs, xml.Attr{Name: xml.Name{Local: "w:tooltip"}, Value: title})
+        }
+        para := b.paragraph(ctx)
+        para.data.Content = append(para.data.Content, link)
+        b.link = link
+        err := b.nodes(n.children, ctx)
+        b.link = nil
+        return err
+    case "strong", "b":
+        ctx.format.bold = true
+    case "em", "i", "cite", "dfn", "var":
+        ctx.format.italic = true
+    case "u", "ins":
+        ctx.format.underline = true
+    case "s", "strike", "del":
+        ctx.format.strike = true
+    case "code", "kbd", "samp", "tt":
+        ctx.format.code = !ctx.pre
+    }
+    if htmlBlockElements[n.tag] {
+        return b.block(n, ctx)
+    }
+    return b.nodes(n.children, ctx)
+}
+
+
+
+
+func (ctx htmlContext) withAttributes(n *htmlNode) htmlContext {
+    if align := n.attr("align"); align != "" {
+        ctx.align = cssTextAlign(align)
+    }
+    if n.tag == "font" {
+        if color, ok := cssColorToHex(n.attr("color")); ok {
+            ctx.format.color = color
+        }
+        if face := n.attr("face"); face != "" {
+            ctx.format.font = cssFirstFontFamily(face)
+        }
+    }
+    for name, value := range parseInlineStyle(n.attr("style")) {
+        switch name {
+        case "color":
+            if color, ok := cssColorToHex(value); ok {
+                ctx.format.color = color
+            }
+        case "font-size":
+            if size, ok := cssFontSize(value, ctx.format.size); ok {
+                ctx.format.size = size
+            }
+        case "font-family":
+            ctx.format.font = cssFirstFontFamily(value)
+        case "font-weight":
+            weight, err := strconv.Atoi(value)
+            ctx.format.bold = value == "bold" || value == "bolder" || (err == nil && weight >= 600)
+        case "font-style":
+            ctx.format.italic = value == "italic" || value == "oblique"
+        case "text-decoration", "text-decoration-line":
+            ctx.format.underline = strings.Contains(value, "underline")
+            ctx.format.strike = strings.Contains(value, "line-through")
+        case "text-align":
+            ctx.align = cssTextAlign(value)
+        }
+    }
+    return ctx
+}
+
+
+func (b *htmlBuilder) text(text string, ctx htmlContext) {
+    if ctx.pre {
+        if b.para == nil {
+            text = strings.TrimPrefix(strings.TrimPrefix(text, "\r"), "\n")
+        }
+        for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+            if i > 0 {
+                b.appendRun(&paragraphRun{Break: &runBreak{}}, ctx)
+            }
+            if line != "" {
+                b.appendRun(b.newRun(line, ctx), ctx)
+            }
+        }
+        return
+    }
+
+    text = htmlWhitespacePattern.ReplaceAllString(text, " ")
+    if b.para == nil || b.space {
+        text = strings.TrimLeft(text, " ")
+    }
+    if text == "" {
+        return
+    }
+    b.appendRun(b.newRun(text, ctx), ctx)
+    b.space = strings.HasSuffix(text, " ")
+}
+
+
+func (b *htmlBuilder) newRun(text string, ctx htmlContext) *paragraphRun {
+    options := []string{}
+    if ctx.format.bold {
+        options = append(options, FormatBold)
+    }
+    if ctx.format.italic {
+        options = append(options, FormatItalic)
+    }
+    if ctx.format.underline {
+        options = append(options, FormatUnderline)
+    }
+    if ctx.format.strike {
+        options = append(options, FormatStrike)
+    }
+
+    var run *paragraphRun
+    if b.link != nil {
+        run = newHyperlinkRun(text, options)
+    } else {
+        run = &paragraphRun{
+            Properties: newRunProperties(options),
+            Text:       &paragraphRunText{Text: text, Space: "preserve"},
+        }
+    }
+    handle := &Run{data: run}
+    if ctx.format.code {
+        handle.SetStyle(StyleVerbatimChar)
+    }
+    if ctx.format.color != "" {
+        _ = handle.SetColor(ctx.format.color)
+    }
+    if ctx.format.size > 0 {
+        handle.SetFontSize(ctx.format.size)
+    }
+    if ctx.format.font != "" {
+        handle.SetFont(ctx.format.font)
+    }
+    return run
+}
+
+
+func (b *htmlBuilder) list(n *htmlNode, ctx htmlContext) error {
+    ordered := n.tag == "ol"
+    list := b.doc.AddList(ordered)
+    if start, err := strconv.Atoi(n.attr("start")); err == nil && ordered && start != 1 {
+        if err := list.SetStart(start); err != nil {
+            return err
+        }
+    }
+    level := ctx.listLevel
+    if level > maxListLevel {
+        level = maxListLevel
+    }
+
+    for _, item := range n.children {
+        if item.tag == "" {
+            continue
+        }
+        if item.tag == "ul" || item.tag == "ol" {
+            nested := ctx
+            nested.listLevel = ctx.listLevel + 1
+            if err := b.list(item, nested); err != nil {
+                return err
+            }
+            continue
+        }
+
+        b.end()
+        itemCtx := ctx.withAttributes(item)
+        para, _ := ctx.target.InsertParagraph(ctx.target.Len(), StyleListParagraph)
+        para.properties().Numbering = list.itemNumbering(level)
+        if itemCtx.align != "" {
+            _ = para.SetAlignment(itemCtx.align)
+        }
+        b.para, b.space, b.listItem = para, true, true
+
+        itemCtx.listLevel = ctx.listLevel + 1
+        if err := b.nodes(item.children, itemCtx); err != nil {
+            return err
+        }
+        b.listItem = false
+        b.end()
+    }
+    return nil
+}
+
+
+func htmlTableRows(n *htmlNode, rows []*htmlNode, header []bool, inHead bool) ([]*htmlNode, []bool) {
+    for _, child := range n.children {
+        switch child.tag {
+        case "tr":
+            rows = append(rows, child)
+            header = append(header, inHead)
+        case "thead":
+            rows, header = htmlTableRows(child, rows, header, true)
+        case "tbody", "tfoot":
+            rows, header = htmlTableRows(child, rows, header, false)
+        }
+    }
+    return rows, header
+}
+
+
+type htmlCellPlacement struct {
+    node    *htmlNode
+    span    int
+    rowSpan int
+    merged  bool
+}
+
+
+func (b *htmlBuilder) table(n *htmlNode, ctx htmlContext) error {
+    for _, child := range n.children {
+        if child.tag == "caption" {
+            if err := b.element(child, ctx); err != nil {
+                return err
+            }
+        }
+    }
+
+    rows, header := htmlTableRows(n, nil, nil, false)
+    grid := make([]map[int]*htmlCellPlacement, len(rows))
+    for i := range grid {
+        grid[i] = make(map[int]*htmlCellPlacement)
+    }
+    cols := 0
+    for r, row := range rows {
+        c := 0
+        for _, cell := range row.children {
+            if cell.tag != "td" && cell.tag != "th" {
+                continue
+            }
+            for grid[r][c] != nil {
+                c += grid[r][c].span
+            }
+            span, rowSpan := htmlSpanAttr(cell, "colspan"), htmlSpanAttr(cell, "rowspan")
+            if rowSpan > len(rows)-r {
+                rowSpan = len(rows) - r
+            }
+            grid[r][c] = &htmlCellPlacement{node: cell, span: span, rowSpan: rowSpan}
+            for rr := r + 1; rr < r+rowSpan; rr++ {
+                grid[rr][c] = &htmlCellPlacement{span: span, merged: true}
+            }
+            c += span
+        }
+        for c2, placed := range grid[r] {
+            if c2+placed.span > c {
+                c = c2 + placed.span
+            }
+        }
+        if c > cols {
+            cols = c
+        }
+    }
+    if len(rows) == 0 || cols == 0 {
+        return nil
+    }
+
+    table, err := ctx.target.InsertTable(ctx.target.Len(), len(rows), cols)
+    if err != nil {
+        return err
+    }
+    colWidth := table.data.Grid.Columns[0].W
+    headerRows := true
+    for r := range rows {
+        allHeader := true
+        cells := []*tableCellData{}
+        for c := 0; c < cols; {
+            placed := grid[r][c]
+            if placed == nil {
+                placed = &htmlCellPlacement{span: 1}
+            }
+            data := &tableCellData{Properties: &tableCellProperties{Width: &tableWidth{W: colWidth * placed.span, Type: "dxa"}}}
+            if placed.span > 1 {
+                data.Properties.Extra = append(data.Properties.Extra, &rawXML{
+                    XMLName: xml.Name{Local: "w:gridSpan"},
+                    Attrs:   []xml.Attr{{Name: xml.Name{Local: "w:val"}, Value: strconv.Itoa(placed.span)}},
+                })
+            }
+            switch {
+            case placed.merged:
+                data.Properties.Extra = append(data.Properties.Extra, &rawXML{XMLName: xml.Name{Local: "w:vMerge"}})
+            case placed.rowSpan > 1:
+                data.Properties.Extra = append(data.Properties.Extra, &rawXML{
+                    XMLName: xml.Name{Local: "w:vMerge"},
+                    Attrs:   []xml.Attr{{Name: xml.Name{Local: "w:val"}, Value: "restart"}},
+                })
+            }
+            cells = append(cells, data)
+            c += placed.span
+
+            if placed.node == nil {
+                continue
+            }
+            if placed.node.tag != "th" {
+                allHeader = false
+            }
+            cellCtx := htmlContext{target: newTableCell(b.doc, data).blockList, format: ctx.format}
+            cellCtx.format.bold = cellCtx.format.bold || placed.node.tag == "th"
+            cellCtx = cellCtx.withAttributes(rows[r]).withAttributes(placed.node)
+            b.end()
+            if err := b.nodes(placed.node.children, cellCtx); err != nil {
+                return err
+            }
+            b.end()
+        }
+        table.data.Rows[r].Cells = cells
+
+        headerRows = headerRows && (header[r] || allHeader)
+        if headerRows {
+            table.Rows()[r].SetHeader(true)
+        }
+    }
+    return nil
+}
+
+
+func htmlSpanAttr(n *htmlNode, name string) int {
+    span, err := strconv.Atoi(strings.TrimSpace(n.attr(name)))
+    if err != nil || span < 1 {
+        return 1
+    }
+    if span > 1000 {
+        return 1000
+    }
+    return span
+}
+
+
+func (b *htmlBuilder) readLocalImage(src string) ([]byte, error) {
+    if !b.allowLocalFiles {
+        return nil, fmt.Errorf("local image %q is not allowed: set AllowLocalFiles to embed local files", src)
+    }
+    target := strings.TrimPrefix(src, "file://")
+    if unescaped, err := url.PathUnescape(target); err == nil {
+        target = unescaped
+    }
+    name := filepath.FromSlash(target)
+    if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(target, "/") {
+        return nil, fmt.Errorf("image path %q must be relative to the base directory", src)
+    }
+    name = filepath.Clean(name)
+    if name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+        return nil, fmt.Errorf("image path %q is outside the base directory", src)
+    }
+
+    baseDir := b.baseDir
+    if baseDir == "" {
+        baseDir = "."
+    }
+    root, err := os.OpenRoot(baseDir)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open base directory %s: %w", baseDir, err)
+    }
+    defer root.Close()
+    file, err := root.Open(name)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read image file %s: %w", name, err)
+    }
+    defer file.Close()
+    data, err := io.ReadAll(file)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read image file %s: %w", name, err)
+    }
+    return data, nil
+}
+
+
+func decodeDataURI(src string) ([]byte, error) {
+    meta, payload, ok := strings.Cut(strings.TrimPrefix(src, "data:"), ",")
+    if !ok {
+        return nil, fmt.Errorf("invalid data URI")
+    }
+    if strings.HasSuffix(meta, ";base64") {
+        data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(payload), ""))
+        if err != nil {
+            return nil, fmt.Errorf("failed to decode data URI: %w", err)
+        }
+        return data, nil
+    }
+    unescaped, err := url.PathUnescape(payload)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode data URI: %w", err)
+    }
+    return []byte(unescaped), nil
+}
+
+
+func (b *htmlBuilder) image(n *htmlNode, ctx htmlContext) {
+    src := strings.TrimSpace(n.attr("src"))
+    alt := n.attr("alt")
+    if src == "" {
+        return
+    }
+    if strings.HasPrefix(src, "//") || (strings.Contains(src, "://") && !strings.HasPrefix(src, "file://")) {
+        text := alt
+        if text == "" {
+            text = src
+        }
+        if b.link != nil {
+            b.text(text, ctx)
+            return
+        }
+        para := b.paragraph(ctx)
+        para.data.Content = append(para.data.Content, b.doc.newHyperlink(src, newHyperlinkRun(text, nil)))
+        b.space = false
+        return
+    }
+
+    var data []byte
+    var err error
+    name := src
+    if strings.HasPrefix(src, "data:") {
+        name = "data URI"
+        data, err = decodeDataURI(src)
+    } else {
+        data, err = b.readLocalImage(src)
+    }
+    var run *paragraphRun
+    if err == nil {
+        run, err = b.doc.newImageRunFromData(data, name)
+    }
+    if err != nil {
+        b.text(alt, ctx)
+        return
+    }
+    inline := &run.Drawing.Inline
+    if alt != "" {
+        inline.DocPr.Descr = alt
+        inline.Graphic.GraphicData.Pic.NvPicPr.CNvPr.Descr = alt
+    }
+    inline.DocPr.Title = n.attr("title")
+
+    style := parseInlineStyle(n.attr("style"))
+    width, hasWidth := cssPixels(style["width"], n.attr("width"))
+    height, hasHeight := cssPixels(style["height"], n.attr("height"))
+    cx, cy := inline.Extent.Cx, inline.Extent.Cy
+    switch {
+    case hasWidth && hasHeight:
+        cx, cy = int64(width*emusPerPixel), int64(height*emusPerPixel)
+    case hasWidth && cx > 0:
+        cx, cy = int64(width*emusPerPixel), cy*int64(width*emusPerPixel)/cx
+    case hasHeight && cy > 0:
+        cx, cy = cx*int64(height*emusPerPixel)/cy, int64(height*emusPerPixel)
+    }
+    inline.Extent.Cx, inline.Extent.Cy = cx, cy
+    inline.Graphic.GraphicData.Pic.SpPr.Xfrm.Extents.Cx = cx
+    inline.Graphic.GraphicData.Pic.SpPr.Xfrm.Extents.Cy = cy
+
+    b.appendRun(run, ctx)
+    b.space = false
+}
+
+
+func cssPixels(values ...string) (float64, bool) {
+    for _, value := range values {
+        match := cssLengthPattern.FindStringSubmatch(strings.TrimSpace(value))
+        if match == nil {
+            continue
+        }
+        n, err := strconv.ParseFloat(match[1], 64)
+        if err != nil || n <= 0 {
+            continue
+        }
+        switch match[2] {
+        case "", "px":
+            return n, true
+        case "pt":
+            return n * 4 / 3, true
+        }
+    }
+    return 0, false
+}
+
+
+func cssTextAlign(value string) string {
+    switch strings.ToLower(strings.TrimSpace(value)) {
+    case "left", "start":
+        return AlignLeft
+    case "center", "middle":
+        return AlignCenter
+    case "right", "end":
+        return AlignRight
+    case "justify":
+        return AlignJustify
+    }
+    return ""
+}
+
+
+func cssColorToHex(value string) (string, bool) {
+    value = strings.ToLower(strings.TrimSpace(value))
+    if hex, ok := cssNamedColors[value]; ok {
+        return hex, true
+    }
+    if match := cssRGBPattern.FindStringSubmatch(value); match != nil {
+        hex := ""
+        for _, component := range match[1:] {
+            n, _ := strconv.Atoi(component)
+            if n > 255 {
+                n = 255
+            }
+            hex += fmt.Sprintf("%02X", n)
+        }
+        return hex, true
+    }
+    value = strings.TrimPrefix(value, "#")
+    if len(value) == 3 {
+        value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+    }
+    if len(value) != 6 || strings.Trim(value, "0123456789abcdef") != "" {
+        return "", false
+    }
+    return strings.ToUpper(value), true
+}
+
+
+func cssFontSize(value string, parent float64) (float64, bool) {
+    value = strings.ToLower(strings.TrimSpace(value))
+    if size, ok := cssFontSizeKeywords[value]; ok {
+        return size, true
+    }
+    match := cssLengthPattern.FindStringSubmatch(value)
+    if match == nil {
+        return 0, false
+    }
+    n, err := strconv.ParseFloat(match[1], 64)
+    if err != nil || n <= 0 {
+        return 0, false
+    }
+    if parent == 0 {
+        parent = 11
+    }
+    switch match[2] {
+    case "pt":
+        return n, true
+    case "px", "":
+        return n * 0.75, true
+    case "em":
+        return n * parent, true
+    case "rem":
+        return n * 12, true
+    case "%":
+        return n * parent / 100, true
+    }
+    return 0, false
+}
+
+
+func cssFirstFontFamily(value string) string {
+    family, _, _ := strings.Cut(value, ",")
+    family = strings.Trim(strings.TrimSpace(family), `"'`)
+    switch strings.ToLower(family) {
+    case "serif", "sans-serif", "monospace", "cursive", "fantasy", "system-ui":
+        return ""
+    }
+    return family
+}
@@ -0,0 +1,130 @@
+package docx
+
+import (
+    "encoding/base64"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+const testPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAIAAACQd1PeAAAADElEQVR4nGP4z8AAAAMBAQDJ/pLvAAAAAElFTkSuQmCC"
+
+
+func TestHTMLConverterLocalImages(t *testing.T) {
+    png, err := base64.StdEncoding.DecodeString(testPNG)
+    if err != nil {
+        t.Fatal(err)
+    }
+    dir := t.TempDir()
+    base := filepath.Join(dir, "base")
+    if err := os.MkdirAll(filepath.Join(base, "img"), 0o755); err != nil {
+        t.Fatal(err)
+    }
+    for _, name := range []string{filepath.Join(base, "img", "dot.png"), filepath.Join(dir, "secret.png")} {
+        if err := os.WriteFile(name, png, 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := os.Symlink(filepath.Join(dir, "secret.png"), filepath.Join(base, "link.png")); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name  string
+        src   string
+        allow bool
+        err   string
+    }{
+        {"relative path", "img/dot.png", true, ""},
+        {"file URL", "file://img/dot.png", true, ""},
+        {"local files disabled", "img/dot.png", false, "not allowed"},
+        {"parent directory", "../secret.png", true, "outside the base directory"},
+        {"cleaned parent directory", "img/../../secret.png", true, "outside the base directory"},
+        {"absolute path", filepath.ToSlash(filepath.Join(dir, "secret.png")), true, "must be relative"},
+        {"absolute file URL", "file://" + filepath.ToSlash(filepath.Join(dir, "secret.png")), true, "must be relative"},
+        {"symbolic link", "link.png", true, "failed to read image file"},
+        {"missing file disabled", "nope.png", false, "not allowed"},
+        {"missing file", "nope.png", true, "failed to read image file"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            builder := &htmlBuilder{baseDir: base, allowLocalFiles: tt.allow}
+            _, err := builder.readLocalImage(tt.src)
+            if tt.err == "" && err != nil {
+                t.Fatalf("readLocalImage() error = %v", err)
+            }
+            if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+                t.Fatalf("readLocalImage() error = %v, want %q", err, tt.err)
+            }
+
+            converter := &HTMLConverter{BaseDir: base, AllowLocalFiles: tt.allow}
+            doc, err := converter.Convert(`<p>before <img src="` + tt.src + `" alt="x"> after</p>`)
+            if err != nil {
+                t.Fatalf("Convert() error = %v", err)
+            }
+            images, want := countImages(doc), 1
+            if tt.err != "" {
+                want = 0
+                if got := doc.paragraphs()[0].text(); got != "before x after" {
+                    t.Errorf("rejected image rendered as %q, want its alt text", got)
+                }
+            }
+            if images != want {
+                t.Errorf("got %d images, want %d", images, want)
+            }
+        })
+    }
+}
+
+
+func countImages(doc *DocxDocument) int {
+    images := 0
+    for _, run := range collectRuns(doc.paragraphs()[0].Content, nil) {
+        if run.Drawing != nil {
+            images++
+        }
+    }
+    return images
+}
+
+
+func TestHTMLConverterImageFallbacks(t *testing.T) {
+    tests := []struct {
+        name   string
+        html   string
+        text   string
+        images int
+        link   string
+    }{
+        {"data URI", `<p>a <img src="data:image/png;base64,` + testPNG + `" alt="dot"> b</p>`, "a  b", 1, ""},
+        {"svg data URI", `<p>a <img src="data:image/svg+xml,%3Csvg%2F%3E" alt="logo"> b</p>`, "a logo b", 0, ""},
+        {"webp data URI", `<p>a <img src="data:image/webp;base64,UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==" alt="photo"> b</p>`, "a photo b", 0, ""},
+        {"invalid base64", `<p>a <img src="data:image/png;base64,%%%" alt="broken"> b</p>`, "a broken b", 0, ""},
+        {"unsupported without alt", `<p>a <img src="data:image/svg+xml,%3Csvg%2F%3E"> b</p>`, "a b", 0, ""},
+        {"remote", `<p><img src="https://example.com/a.png" alt="chart"></p>`, "chart", 0, "https://example.com/a.png"},
+        {"protocol-relative", `<p><img src="//cdn.example.com/a.png"></p>`, "//cdn.example.com/a.png", 0, "//cdn.example.com/a.png"},
+        {"empty src", `<p>a <img src="" alt="x"> b</p>`, "a b", 0, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            doc, err := NewHTMLConverter().Convert(tt.html)
+            if err != nil {
+                t.Fatalf("Convert() error = %v", err)
+            }
+            if got := doc.paragraphs()[0].text(); got != tt.text {
+                t.Errorf("Text() = %q, want %q", got, tt.text)
+            }
+            if got := countImages(doc); got != tt.images {
+                t.Errorf("got %d images, want %d", got, tt.images)
+            }
+            links := []string{}
+            for _, rel := range doc.getHyperlinkRelationships() {
+                links = append(links, rel.Target)
+            }
+            if want := []string{tt.link}; tt.link == "" && len(links) != 0 || tt.link != "" && !equalStrings(links, want) {
+                t.Errorf("got hyperlinks %q, want %q", links, tt.link)
+            }
+        })
+    }
+}
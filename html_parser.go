@@ -0,0 +1,227 @@
+package docx
+
+import (
+    "html"
+    "strings"
+)
+
+var htmlVoidElements = map[string]bool{
+    "area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+    "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+var htmlRawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+var htmlBlockElements = map[string]bool{
+    "address": true, "article": true, "aside": true, "blockquote": true, "body": true, "dd": true, "div": true,
+    "dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true, "form": true, "h1": true,
+    "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true,
+    "main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+
+type htmlNode struct {
+    tag      string
+    attrs    map[string]string
+    text     string
+    children []*htmlNode
+}
+
+
+func (n *htmlNode) attr(name string) string {
+    return n.attrs[name]
+}
+
+
+
+
+func parseHTML(src string) *htmlNode {
+    root := &htmlNode{tag: "#document"}
+    stack := []*htmlNode{root}
+    top := func() *htmlNode { return stack[len(stack)-1] }
+    closeTo := func(tag string, stopAt ...string) bool {
+        for i := len(stack) - 1; i > 0; i-- {
+            if stack[i].tag == tag {
+                stack = stack[:i]
+                return true
+            }
+            for _, stop := range stopAt {
+                if stack[i].tag == stop {
+                    return false
+                }
+            }
+        }
+        return false
+    }
+
+    for i := 0; i < len(src); {
+        if src[i] != '<' {
+            end := strings.IndexByte(src[i:], '<')
+            if end < 0 {
+                end = len(src) - i
+            }
+            parent := top()
+            parent.children = append(parent.children, &htmlNode{text: html.UnescapeString(src[i : i+end])})
+            i += end
+            continue
+        }
+
+        rest := src[i:]
+        switch {
+        case strings.HasPrefix(rest, "<!--"):
+            end := strings.Index(rest[4:], "-->")
+            if end < 0 {
+                return root
+            }
+            i += 4 + end + 3
+        case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+            end := strings.IndexByte(rest, '>')
+            if end < 0 {
+                return root
+            }
+            i += end + 1
+        case strings.HasPrefix(rest, "</"):
+            end := strings.IndexByte(rest, '>')
+            if end < 0 {
+                return root
+            }
+            tag := strings.ToLower(strings.TrimSpace(rest[2:end]))
+            switch tag {
+            case "li":
+                closeTo(tag, "ul", "ol")
+            case "td", "th", "tr":
+                closeTo(tag, "table")
+            default:
+                closeTo(tag)
+            }
+            i += end + 1
+        case len(rest) > 1 && isHTMLNameStart(rest[1]):
+            node, n, selfClosing := parseHTMLStartTag(rest)
+            i += n
+
+            switch {
+            case node.tag == "li":
+                closeTo("li", "ul", "ol")
+            case node.tag == "td" || node.tag == "th":
+                if !closeTo("td", "tr", "table") {
+                    closeTo("th", "tr", "table")
+                }
+            case node.tag == "tr":
+                closeTo("tr", "table")
+            case node.tag == "dt" || node.tag == "dd":
+                if !closeTo("dt", "dl") {
+                    closeTo("dd", "dl")
+                }
+            }
+            if htmlBlockElements[node.tag] && node.tag != "li" {
+                closeTo("p", "li", "td", "th", "blockquote", "div", "table")
+            }
+
+            parent := top()
+            parent.children = append(parent.children, node)
+            if htmlRawTextElements[node.tag] {
+                end := strings.Index(strings.ToLower(src[i:]), "</"+node.tag)
+                if end < 0 {
+                    end = len(src) - i
+                }
+                node.children = append(node.children, &htmlNode{text: src[i : i+end]})
+                i += end
+                continue
+            }
+            if !selfClosing && !htmlVoidElements[node.tag] {
+                stack = append(stack, node)
+            }
+        default:
+            parent := top()
+            parent.children = append(parent.children, &htmlNode{text: "<"})
+            i++
+        }
+    }
+    return root
+}
+
+
+func isHTMLNameStart(c byte) bool {
+    return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+
+func parseHTMLStartTag(src string) (*htmlNode, int, bool) {
+    node := &htmlNode{attrs: make(map[string]string)}
+    i := 1
+    for i < len(src) && !strings.ContainsRune(" \t\r\n/>", rune(src[i])) {
+        i++
+    }
+    node.tag = strings.ToLower(src[1:i])
+
+    for i < len(src) {
+        for i < len(src) && strings.ContainsRune(" \t\r\n", rune(src[i])) {
+            i++
+        }
+        switch {
+        case i >= len(src):
+            return node, i, false
+        case src[i] == '>':
+            return node, i + 1, false
+        case strings.HasPrefix(src[i:], "/>"):
+            return node, i + 2, true
+        case src[i] == '/':
+            i++
+            continue
+        }
+
+        start := i
+        for i < len(src) && !strings.ContainsRune(" \t\r\n=/>", rune(src[i])) {
+            i++
+        }
+        name := strings.ToLower(src[start:i])
+        for i < len(src) && strings.ContainsRune(" \t\r\n", rune(src[i])) {
+            i++
+        }
+        value := ""
+        if i < len(src) && src[i] == '=' {
+            i++
+            for i < len(src) && strings.ContainsRune(" \t\r\n", rune(src[i])) {
+                i++
+            }
+            if i < len(src) && (src[i] == '"' || src[i] == '\'') {
+                quote := src[i]
+                end := strings.IndexByte(src[i+1:], quote)
+                if end < 0 {
+                    end = len(src) - i - 1
+                }
+                value = src[i+1 : i+1+end]
+                i += end + 2
+            } else {
+                start := i
+                for i < len(src) && !strings.ContainsRune(" \t\r\n>", rune(src[i])) {
+                    i++
+                }
+                value = src[start:i]
+            }
+        }
+        if _, exists := node.attrs[name]; !exists && name != "" {
+            node.attrs[name] = html.UnescapeString(value)
+        }
+    }
+    if i > len(src) {
+        i = len(src)
+    }
+    return node, i, false
+}
+
+
+
+
+func parseInlineStyle(style string) map[string]string {
+    declarations := make(map[string]string)
+    for _, decl := range strings.Split(style, ";") {
+        name, value, ok := strings.Cut(decl, ":")
+        if !ok {
+            continue
+        }
+        value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+        declarations[strings.ToLower(strings.TrimSpace(name))] = value
+    }
+    return declarations
+}
@@ -0,0 +1,107 @@
+package docx
+
+import (
+    "bytes"
+    "html"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+
+func TestHTMLWriterHyperlinkSchemes(t *testing.T) {
+    tests := []struct {
+        target string
+        link   bool
+    }{
+        {"https://example.com/a?b=c", true},
+        {"http://example.com", true},
+        {"HTTPS://example.com", true},
+        {"mailto:someone@example.com", true},
+        {"javascript:alert(1)", false},
+        {"JavaScript:alert(1)", false},
+        {" javascript:alert(1)", false},
+        {"java\tscript:alert(1)", false},
+        {"data:text/html;base64,PHNjcmlwdD4=", false},
+        {"vbscript:msgbox", false},
+        {"file:///etc/passwd", false},
+        {"docs/a.html", true},
+        {"../x", true},
+        {"?q=1&r=2", true},
+        {"/root/page", true},
+        {"//cdn.example.com/a", true},
+        {"a.html\njavascript:alert(1)", false},
+        {"\x00javascript:alert(1)", false},
+    }
+    for _, tt := range tests {
+        doc := NewDocxDocument()
+        doc.AddHyperlink(StyleNormal, "click", tt.target)
+        var buf bytes.Buffer
+        if err := NewHTMLWriter().WriteHTML(&buf, doc); err != nil {
+            t.Fatalf("WriteHTML(%q): %v", tt.target, err)
+        }
+        out := buf.String()
+        if got := strings.Contains(out, "<a href="); got != tt.link {
+            t.Errorf("target %q rendered as link = %v, want %v", tt.target, got, tt.link)
+        }
+        if tt.link && !strings.Contains(out, `href="`+html.EscapeString(tt.target)+`"`) {
+            t.Errorf("target %q was not written escaped: %s", tt.target, out)
+        }
+        if !strings.Contains(out, "click") {
+            t.Errorf("target %q lost the link text", tt.target)
+        }
+    }
+}
+
+
+func TestSafeHTMLLinkAnchor(t *testing.T) {
+    if !safeHTMLLink("#section") {
+        t.Errorf("bookmark anchors must stay links")
+    }
+}
+
+
+func TestHTMLWriterEscapesStyleNames(t *testing.T) {
+    hostile := `&lt;/style&gt;&lt;script&gt;alert(1)&lt;/script&gt;`
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, "Title")
+    doc.AddText(StyleNormal, "body")
+    filename := filepath.Join(t.TempDir(), "hostile.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    rewritePart(t, filename, "word/styles.xml", func(data []byte) []byte {
+        return bytes.Replace(data, []byte(`<w:style w:type="paragraph" w:styleId="Heading1">`),
+            []byte(`<w:style w:type="character" w:styleId="Hostile"><w:rPr><w:rFonts w:ascii="`+hostile+`"/>`+
+                `<w:highlight w:val="red;}&lt;/style&gt;"/></w:rPr></w:style><w:style w:type="paragraph" w:styleId="Heading1">`), 1)
+    })
+    rewritePart(t, filename, "word/document.xml", func(data []byte) []byte {
+        return bytes.Replace(data, []byte(`<w:r>`), []byte(`<w:r><w:rPr><w:rFonts w:ascii="x&quot;; background: url(evil)"/></w:rPr>`), 1)
+    })
+    opened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    var buf bytes.Buffer
+    if err := NewHTMLWriter().WriteHTML(&buf, opened); err != nil {
+        t.Fatal(err)
+    }
+    out := buf.String()
+    for _, bad := range []string{"<script", "</style><", "red;}", "url(evil)"} {
+        if strings.Contains(out, bad) {
+            t.Errorf("exported html contains %q", bad)
+        }
+    }
+    if strings.Count(out, "</style>") != 1 {
+        t.Errorf("exported html has %d closing style tags, want 1", strings.Count(out, "</style>"))
+    }
+    if !strings.Contains(out, `\3c \2f style\3e \3c script\3e alert\28 1\29 `) || !strings.Contains(out, `url\28 evil\29`) {
+        t.Errorf("hostile font name was not CSS escaped")
+    }
+
+    sheet := loadStyleSheet(opened)
+    if css := sheet.css(); strings.Contains(css, "</") {
+        t.Errorf("css() output contains %q", "</")
+    }
+}
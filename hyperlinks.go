@@ -0,0 +1,58 @@
+package docx
+
+import (
+    "encoding/xml"
+    "strings"
+)
+
+const relTypeHyperlink = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+
+
+func (d *DocxDocument) AddHyperlink(style string, text string, target string, formatOptions ...string) {
+    run := newHyperlinkRun(text, formatOptions)
+    d.appendContent(style, d.newHyperlink(target, run))
+}
+
+
+func (p *Paragraph) AddHyperlink(text string, target string, formatOptions ...string) *Run {
+    run := newHyperlinkRun(text, formatOptions)
+    p.append(p.doc.newHyperlink(target, run))
+    return &Run{data: run}
+}
+
+
+func newHyperlinkRun(text string, formatOptions []string) *paragraphRun {
+    return &paragraphRun{
+        Properties: applyFormatOptions(&runProperties{Style: &runStyle{Val: StyleHyperlink}}, formatOptions),
+        Text:       &paragraphRunText{Text: text, Space: "preserve"},
+    }
+}
+
+
+
+
+func (d *DocxDocument) newHyperlink(target string, runs ...*paragraphRun) *containerElement {
+    link := &containerElement{XMLName: xml.Name{Local: "w:hyperlink"}}
+    if strings.HasPrefix(target, "#") {
+        link.Attrs = append(link.Attrs, xml.Attr{Name: xml.Name{Local: "w:anchor"}, Value: strings.TrimPrefix(target, "#")})
+    } else {
+        rID := d.nextRID()
+        d.hyperlinkRels = append(d.hyperlinkRels, relationship{
+            ID:         rID,
+            Type:       relTypeHyperlink,
+            Target:     target,
+            TargetMode: "External",
+        })
+        link.Attrs = append(link.Attrs, xml.Attr{Name: xml.Name{Local: "r:id"}, Value: rID})
+    }
+    link.Attrs = append(link.Attrs, xml.Attr{Name: xml.Name{Local: "w:history"}, Value: "1"})
+    for _, run := range runs {
+        link.Content = append(link.Content, run)
+    }
+    return link
+}
+
+
+func (d *DocxDocument) getHyperlinkRelationships() []relationship {
+    return d.hyperlinkRels
+}
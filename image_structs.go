@@ -8,6 +8,7 @@ type DocProperties struct {
     ID      uint     `xml:"id,attr"`
     Name    string   `xml:"name,attr"`
     Descr   string   `xml:"descr,attr,omitempty"`
+    Title   string   `xml:"title,attr,omitempty"`
 }
 
 
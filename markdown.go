@@ -0,0 +1,454 @@
+package docx
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "os"
+    "path"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+var markdownLineStartPattern = regexp.MustCompile(`^(#|>|-|\+|=|\d+[.)])`)
+
+
+type MarkdownWriter struct {
+    MediaDir string
+}
+
+
+func NewMarkdownWriter() *MarkdownWriter {
+    return &MarkdownWriter{MediaDir: "media"}
+}
+
+
+func (mw *MarkdownWriter) mediaDir() string {
+    if mw.MediaDir == "" {
+        return "media"
+    }
+    return mw.MediaDir
+}
+
+
+func (mw *MarkdownWriter) WriteDocument(filename string, doc Document) error {
+    renderer := newMarkdownRenderer(doc, mw.mediaDir())
+    var buf bytes.Buffer
+    if err := renderer.render(&buf); err != nil {
+        return err
+    }
+
+    err := os.WriteFile(filename, buf.Bytes(), 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+
+    if len(renderer.media) == 0 {
+        return nil
+    }
+    mediaDir := filepath.Join(filepath.Dir(filename), filepath.FromSlash(mw.mediaDir()))
+    err = os.MkdirAll(mediaDir, 0755)
+    if err != nil {
+        return fmt.Errorf("failed to create media directory %s: %w", mediaDir, err)
+    }
+    for name, data := range renderer.media {
+        mediaPath := filepath.Join(mediaDir, name)
+        err = os.WriteFile(mediaPath, data, 0644)
+        if err != nil {
+            return fmt.Errorf("failed to write image %s: %w", mediaPath, err)
+        }
+    }
+    return nil
+}
+
+
+func (mw *MarkdownWriter) WriteMarkdown(w io.Writer, doc Document) error {
+    return newMarkdownRenderer(doc, mw.mediaDir()).render(w)
+}
+
+
+type markdownRenderer struct {
+    doc      Document
+    mediaDir string
+    media    map[string][]byte
+    inTable  bool
+}
+
+
+type markdownBlock struct {
+    text     string
+    listItem bool
+}
+
+
+type markdownSpan struct {
+    text   string
+    bold   bool
+    italic bool
+    strike bool
+    raw    bool
+}
+
+
+func newMarkdownRenderer(doc Document, mediaDir string) *markdownRenderer {
+    return &markdownRenderer{doc: doc, mediaDir: mediaDir, media: make(map[string][]byte)}
+}
+
+
+func (r *markdownRenderer) render(w io.Writer) error {
+    var sb strings.Builder
+    blocks := r.blocks(r.doc.renderedContent(), []markdownBlock{})
+    for i, block := range blocks {
+        if i > 0 {
+            if block.listItem && blocks[i-1].listItem {
+                sb.WriteString("\n")
+            } else {
+                sb.WriteString("\n\n")
+            }
+        }
+        sb.WriteString(block.text)
+    }
+    if sb.Len() > 0 {
+        sb.WriteString("\n")
+    }
+
+    _, err := io.WriteString(w, sb.String())
+    if err != nil {
+        return fmt.Errorf("failed to write markdown: %w", err)
+    }
+    return nil
+}
+
+
+func (r *markdownRenderer) blocks(content []interface{}, blocks []markdownBlock) []markdownBlock {
+    for _, block := range content {
+        switch v := block.(type) {
+        case *paragraphData:
+            if b, ok := r.paragraph(v); ok {
+                blocks = append(blocks, b)
+            }
+        case *tableData:
+            if text := r.table(v); text != "" {
+                blocks = append(blocks, markdownBlock{text: text})
+            }
+        case *containerElement:
+            blocks = r.blocks(v.Content, blocks)
+        }
+    }
+    return blocks
+}
+
+
+func (r *markdownRenderer) paragraph(p *paragraphData) (markdownBlock, bool) {
+    style := StyleNormal
+    if p.Properties != nil && p.Properties.Style != nil {
+        style = p.Properties.Style.Val
+    }
+    level := headingLevel(style)
+    if style == StyleSourceCode {
+        code := strings.TrimRight(p.text(), "\n")
+        if code == "" {
+            return markdownBlock{}, false
+        }
+        fence := "```"
+        for strings.Contains(code, fence) {
+            fence += "`"
+        }
+        return markdownBlock{text: fence + "\n" + code + "\n" + fence}, true
+    }
+
+    text := strings.TrimSpace(formatMarkdownSpans(r.spans(p.Content, []markdownSpan{}), level > 0))
+    if text == "" {
+        return markdownBlock{}, false
+    }
+
+    if level > 0 {
+        return markdownBlock{text: strings.Repeat("#", level) + " " + text}, true
+    }
+    if listLevel, ordered, ok := r.doc.getListLevel(p); ok {
+        marker := "- "
+        if ordered {
+            marker = "1. "
+        }
+        return markdownBlock{text: strings.Repeat("    ", listLevel) + marker + text, listItem: true}, true
+    }
+    if markdownLineStartPattern.MatchString(text) {
+        text = `\` + text
+    }
+    if style == StyleQuote {
+        text = "> " + strings.ReplaceAll(text, "\n", "\n> ")
+    }
+    return markdownBlock{text: text}, true
+}
+
+
+func headingLevel(style string) int {
+    if style == "Title" {
+        return 1
+    }
+    if !strings.HasPrefix(style, "Heading") {
+        return 0
+    }
+    level, err := strconv.Atoi(strings.TrimPrefix(style, "Heading"))
+    if err != nil || level < 1 {
+        return 0
+    }
+    if level > 6 {
+        level = 6
+    }
+    return level
+}
+
+
+func (r *markdownRenderer) spans(content []interface{}, spans []markdownSpan) []markdownSpan {
+    for _, c := range content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            spans = r.runSpans(v, spans)
+        case *simpleField:
+            for _, run := range v.Runs {
+                spans = r.runSpans(run, spans)
+            }
+        case *containerElement:
+            if v.XMLName.Local != "w:hyperlink" {
+                spans = r.spans(v.Content, spans)
+                continue
+            }
+            inner := formatMarkdownSpans(r.spans(v.Content, []markdownSpan{}), false)
+            target := ""
+            for _, a := range v.Attrs {
+                switch a.Name.Local {
+                case "r:id":
+                    if rel, ok := r.doc.getRelationship(a.Value); ok {
+                        target = rel.Target
+                    }
+                case "w:anchor":
+                    if target == "" {
+                        target = "#" + a.Value
+                    }
+                }
+            }
+            if target == "" {
+                spans = append(spans, markdownSpan{text: inner, raw: true})
+            } else {
+                spans = append(spans, markdownSpan{text: "[" + inner + "](" + markdownURL(target) + ")", raw: true})
+            }
+        }
+    }
+    return spans
+}
+
+
+func (r *markdownRenderer) runSpans(run *paragraphRun, spans []markdownSpan) []markdownSpan {
+    span := markdownSpan{}
+    if props := run.Properties; props != nil {
+        span.bold = props.Bold != nil
+        span.italic = props.Italic != nil
+        span.strike = props.Strike != nil
+    }
+
+    switch {
+    case run.Text != nil && run.Properties != nil && run.Properties.Style != nil && run.Properties.Style.Val == StyleVerbatimChar:
+        return append(spans, markdownSpan{text: formatMarkdownCode(run.Text.Text), raw: true})
+    case run.Text != nil:
+        span.text = escapeMarkdown(run.Text.Text, r.inTable)
+    case run.Tab != nil:
+        span.text = "\t"
+    case run.Break != nil && (run.Break.Type == "" || run.Break.Type == BreakTextWrapping):
+        if r.inTable {
+            return append(spans, markdownSpan{text: "<br>", raw: true})
+        }
+        return append(spans, markdownSpan{text: "\\\n", raw: true})
+    case run.hasDrawing():
+        if image := r.image(run); image != "" {
+            return append(spans, markdownSpan{text: image, raw: true})
+        }
+        return spans
+    default:
+        return spans
+    }
+    return append(spans, span)
+}
+
+
+func (r *markdownRenderer) image(run *paragraphRun) string {
+    rID, alt := drawingImage(run)
+    if rID == "" {
+        return ""
+    }
+    name, data, ok := r.doc.getImageData(rID)
+    if !ok {
+        return ""
+    }
+    r.media[name] = data
+    return "![" + escapeMarkdown(alt, r.inTable) + "](" + markdownURL(path.Join(r.mediaDir, name)) + ")"
+}
+
+
+
+
+func drawingImage(run *paragraphRun) (rID string, alt string) {
+    if run.Drawing != nil {
+        inline := run.Drawing.Inline
+        return inline.Graphic.GraphicData.Pic.BlipFill.Blip.Embed, inline.DocPr.Descr
+    }
+    for _, extra := range run.Extra {
+        blip := extra.find("a:blip")
+        if blip == nil {
+            continue
+        }
+        rID, _ = blip.attr("r:embed")
+        if docPr := extra.find("wp:docPr"); docPr != nil {
+            alt, _ = docPr.attr("descr")
+            if alt == "" {
+                alt, _ = docPr.attr("title")
+            }
+        }
+        return rID, alt
+    }
+    return "", ""
+}
+
+
+func (r *markdownRenderer) table(t *tableData) string {
+    r.inTable = true
+    defer func() { r.inTable = false }()
+
+    rows := [][]string{}
+    cols := 0
+    for _, row := range t.Rows {
+        cells := []string{}
+        for _, cell := range row.Cells {
+            parts := []string{}
+            for _, block := range cell.Blocks {
+                switch v := block.(type) {
+                case *paragraphData:
+                    if text := strings.TrimSpace(formatMarkdownSpans(r.spans(v.Content, []markdownSpan{}), false)); text != "" {
+                        parts = append(parts, text)
+                    }
+                default:
+                    for _, line := range blockTexts([]interface{}{v}, []string{}) {
+                        if line = strings.TrimSpace(line); line != "" {
+                            parts = append(parts, escapeMarkdown(line, true))
+                        }
+                    }
+                }
+            }
+            cells = append(cells, strings.Join(parts, "<br>"))
+        }
+        if len(cells) > cols {
+            cols = len(cells)
+        }
+        rows = append(rows, cells)
+    }
+    if len(rows) == 0 || cols == 0 {
+        return ""
+    }
+
+    var sb strings.Builder
+    writeRow := func(cells []string) {
+        sb.WriteString("|")
+        for i := 0; i < cols; i++ {
+            cell := ""
+            if i < len(cells) {
+                cell = cells[i]
+            }
+            sb.WriteString(" " + cell + " |")
+        }
+    }
+    writeRow(rows[0])
+    sb.WriteString("\n|")
+    for i := 0; i < cols; i++ {
+        sb.WriteString(" --- |")
+    }
+    for _, row := range rows[1:] {
+        sb.WriteString("\n")
+        writeRow(row)
+    }
+    return sb.String()
+}
+
+
+
+
+func formatMarkdownSpans(spans []markdownSpan, plain bool) string {
+    var sb strings.Builder
+    for i := 0; i < len(spans); {
+        span := spans[i]
+        if span.raw || plain {
+            sb.WriteString(span.text)
+            i++
+            continue
+        }
+
+        text := span.text
+        j := i + 1
+        for ; j < len(spans) && !spans[j].raw && spans[j].bold == span.bold &&
+            spans[j].italic == span.italic && spans[j].strike == span.strike; j++ {
+            text += spans[j].text
+        }
+        i = j
+
+        marker := ""
+        if span.strike {
+            marker += "~~"
+        }
+        if span.bold {
+            marker += "**"
+        }
+        if span.italic {
+            marker += "*"
+        }
+        trimmed := strings.TrimSpace(text)
+        if marker == "" || trimmed == "" {
+            sb.WriteString(text)
+            continue
+        }
+        start := strings.Index(text, trimmed)
+        closing := []rune(marker)
+        for l, r := 0, len(closing)-1; l < r; l, r = l+1, r-1 {
+            closing[l], closing[r] = closing[r], closing[l]
+        }
+        sb.WriteString(text[:start] + marker + trimmed + string(closing) + text[start+len(trimmed):])
+    }
+    return sb.String()
+}
+
+
+func formatMarkdownCode(code string) string {
+    fence := "`"
+    for strings.Contains(code, fence) {
+        fence += "`"
+    }
+    if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+        code = " " + code + " "
+    }
+    return fence + code + fence
+}
+
+
+func escapeMarkdown(text string, inTable bool) string {
+    var sb strings.Builder
+    for _, c := range text {
+        switch c {
+        case '\\', '`', '*', '_', '[', ']', '<', '>':
+            sb.WriteRune('\\')
+        case '|':
+            if inTable {
+                sb.WriteRune('\\')
+            }
+        }
+        sb.WriteRune(c)
+    }
+    return sb.String()
+}
+
+
+func markdownURL(url string) string {
+    if strings.ContainsAny(url, " ()<>") {
+        return "<" + strings.ReplaceAll(strings.ReplaceAll(url, "<", "%3C"), ">", "%3E") + ">"
+    }
+    return url
+}
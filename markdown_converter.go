@@ -0,0 +1,320 @@
+package docx
+
+import (
+    "encoding/xml"
+    "fmt"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+
+type MarkdownConverter struct {
+    BaseDir string
+}
+
+
+func NewMarkdownConverter() *MarkdownConverter {
+    return &MarkdownConverter{}
+}
+
+
+func (c *MarkdownConverter) Convert(markdown string) (*DocxDocument, error) {
+    doc := NewDocxDocument()
+    if err := c.Append(doc, markdown); err != nil {
+        return nil, err
+    }
+    return doc, nil
+}
+
+
+func (c *MarkdownConverter) ConvertFile(filename string) (*DocxDocument, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read markdown file %s: %w", filename, err)
+    }
+    converter := *c
+    if converter.BaseDir == "" {
+        converter.BaseDir = filepath.Dir(filename)
+    }
+    return converter.Convert(string(data))
+}
+
+
+func (c *MarkdownConverter) Append(doc *DocxDocument, markdown string) error {
+    parser := newMarkdownParser()
+    elements := parser.parse(markdown)
+    builder := &markdownBuilder{doc: doc, parser: parser, baseDir: c.BaseDir}
+    return builder.blocks(elements, markdownContext{})
+}
+
+
+type markdownBuilder struct {
+    doc     *DocxDocument
+    parser  *markdownParser
+    baseDir string
+}
+
+
+type markdownContext struct {
+    quote     bool
+    listLevel int
+}
+
+
+func (b *markdownBuilder) paragraph(style string, ctx markdownContext) *Paragraph {
+    switch {
+    case ctx.listLevel > 0 && style == StyleNormal:
+        style = StyleListParagraph
+    case ctx.quote && style == StyleNormal:
+        style = StyleQuote
+    }
+    para := b.doc.AddParagraph(style)
+
+
+
+    if ctx.listLevel > 0 {
+        para.properties().Extra = append(para.properties().Extra, &rawXML{
+            XMLName: xml.Name{Local: "w:ind"},
+            Attrs:   []xml.Attr{{Name: xml.Name{Local: "w:left"}, Value: strconv.Itoa(720 * ctx.listLevel)}},
+        })
+    }
+    return para
+}
+
+
+func (b *markdownBuilder) blocks(elements []*markdownElement, ctx markdownContext) error {
+    for _, el := range elements {
+        var err error
+        switch el.kind {
+        case markdownParagraph:
+            err = b.inlines(b.paragraph(StyleNormal, ctx), b.parser.parseInlines(el.text, markdownFormat{}))
+        case markdownHeading:
+            style := fmt.Sprintf("Heading%d", el.level)
+            if ctx.listLevel > 0 {
+                style = StyleNormal
+            }
+            err = b.inlines(b.paragraph(style, ctx), b.parser.parseInlines(el.text, markdownFormat{}))
+        case markdownCode:
+            b.code(el.text, ctx)
+        case markdownQuote:
+            quoted := ctx
+            quoted.quote = true
+            err = b.blocks(el.children, quoted)
+        case markdownList:
+            err = b.list(el, ctx)
+        case markdownRule:
+            b.rule()
+        case markdownTable:
+            err = b.table(el)
+        }
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+
+func (b *markdownBuilder) code(text string, ctx markdownContext) {
+    para := b.paragraph(StyleSourceCode, ctx)
+    for i, line := range strings.Split(text, "\n") {
+        if i > 0 {
+            para.AddBreak("")
+        }
+        if line != "" {
+            para.AddRun(line)
+        }
+    }
+}
+
+
+func (b *markdownBuilder) rule() {
+    addBottomBorder(b.doc.AddParagraph(StyleNormal))
+}
+
+
+func addBottomBorder(para *Paragraph) {
+    border := &rawXML{XMLName: xml.Name{Local: "w:pBdr"}}
+    border.Children = append(border.Children, &rawXML{
+        XMLName: xml.Name{Local: "w:bottom"},
+        Attrs: []xml.Attr{
+            {Name: xml.Name{Local: "w:val"}, Value: "single"},
+            {Name: xml.Name{Local: "w:sz"}, Value: "6"},
+            {Name: xml.Name{Local: "w:space"}, Value: "1"},
+            {Name: xml.Name{Local: "w:color"}, Value: "auto"},
+        },
+    })
+    para.properties().Extra = append(para.properties().Extra, border)
+}
+
+
+func (b *markdownBuilder) list(el *markdownElement, ctx markdownContext) error {
+    list := b.doc.AddList(el.ordered)
+    if el.ordered && el.start != 1 {
+        if err := list.SetStart(el.start); err != nil {
+            return err
+        }
+    }
+    level := ctx.listLevel
+    if level > maxListLevel {
+        level = maxListLevel
+    }
+
+    for _, item := range el.items {
+        para, err := list.AddItem(level)
+        if err != nil {
+            return err
+        }
+        rest := item
+        if len(item) > 0 && (item[0].kind == markdownParagraph || item[0].kind == markdownHeading) {
+            if err := b.inlines(para, b.parser.parseInlines(item[0].text, markdownFormat{})); err != nil {
+                return err
+            }
+            rest = item[1:]
+        }
+        nested := ctx
+        nested.listLevel = ctx.listLevel + 1
+        if err := b.blocks(rest, nested); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+
+func (b *markdownBuilder) table(el *markdownElement) error {
+    cols := len(el.rows[0])
+    table := b.doc.AddTable(len(el.rows), cols)
+    table.Rows()[0].SetHeader(true)
+    for r, row := range el.rows {
+        for col := 0; col < cols; col++ {
+            para := table.Cell(r, col).AddParagraph("")
+            if col < len(el.align) && el.align[col] != "" {
+                if err := para.SetAlignment(el.align[col]); err != nil {
+                    return err
+                }
+            }
+            format := markdownFormat{bold: r == 0}
+            if err := b.inlines(para, b.parser.parseInlines(row[col], format)); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+
+func (b *markdownBuilder) inlines(para *Paragraph, inlines []markdownInline) error {
+    for _, in := range inlines {
+        switch in.kind {
+        case markdownText:
+            para.AddRun(in.text, in.format.options()...)
+        case markdownCodeSpan:
+            para.AddRun(in.text, in.format.options()...).SetStyle(StyleVerbatimChar)
+        case markdownBreak:
+            para.AddBreak("")
+        case markdownLink:
+            runs, err := b.linkRuns(in.children)
+            if err != nil {
+                return err
+            }
+            link := b.doc.newHyperlink(in.target, runs...)
+            if in.title != "" {
+                link.Attrs = append(link.Attrs, xml.Attr{Name: xml.Name{Local: "w:tooltip"}, Value: in.title})
+            }
+            para.data.Content = append(para.data.Content, link)
+        case markdownImage:
+            run, err := b.imageRun(in)
+            if err != nil {
+                return err
+            }
+            if run != nil {
+                para.appendRun(run)
+                continue
+            }
+            text := in.text
+            if text == "" {
+                text = in.target
+            }
+            para.data.Content = append(para.data.Content, b.doc.newHyperlink(in.target, newHyperlinkRun(text, in.format.options())))
+        }
+    }
+    return nil
+}
+
+
+func (b *markdownBuilder) linkRuns(inlines []markdownInline) ([]*paragraphRun, error) {
+    runs := []*paragraphRun{}
+    for _, in := range inlines {
+        switch in.kind {
+        case markdownText, markdownLink:
+            text := in.text
+            if in.kind == markdownLink {
+                text = plainMarkdownText(in.children)
+            }
+            runs = append(runs, newHyperlinkRun(text, in.format.options()))
+        case markdownCodeSpan:
+            run := newHyperlinkRun(in.text, in.format.options())
+            run.Properties.Style.Val = StyleVerbatimChar
+            runs = append(runs, run)
+        case markdownBreak:
+            runs = append(runs, &paragraphRun{Break: &runBreak{}})
+        case markdownImage:
+            run, err := b.imageRun(in)
+            if err != nil {
+                return nil, err
+            }
+            if run == nil {
+                run = newHyperlinkRun(in.text, in.format.options())
+            }
+            runs = append(runs, run)
+        }
+    }
+    return runs, nil
+}
+
+
+
+
+func (b *markdownBuilder) imageRun(in markdownInline) (*paragraphRun, error) {
+    if strings.Contains(in.target, "://") || strings.HasPrefix(in.target, "data:") {
+        return nil, nil
+    }
+    target := in.target
+    if unescaped, err := url.PathUnescape(target); err == nil {
+        target = unescaped
+    }
+    filePath := filepath.FromSlash(target)
+    if !filepath.IsAbs(filePath) && b.baseDir != "" {
+        filePath = filepath.Join(b.baseDir, filePath)
+    }
+
+    run, err := b.doc.newImageRun(filePath)
+    if err != nil {
+        return nil, err
+    }
+    if in.text != "" {
+        run.Drawing.Inline.DocPr.Descr = in.text
+        run.Drawing.Inline.Graphic.GraphicData.Pic.NvPicPr.CNvPr.Descr = in.text
+    }
+    run.Drawing.Inline.DocPr.Title = in.title
+    return run, nil
+}
+
+
+func (f markdownFormat) options() []string {
+    options := []string{}
+    if f.bold {
+        options = append(options, FormatBold)
+    }
+    if f.italic {
+        options = append(options, FormatItalic)
+    }
+    if f.strike {
+        options = append(options, FormatStrike)
+    }
+    return options
+}
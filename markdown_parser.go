@@ -0,0 +1,841 @@
+package docx
+
+import (
+    "html"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+const (
+    markdownParagraph = iota
+    markdownHeading
+    markdownCode
+    markdownQuote
+    markdownList
+    markdownRule
+    markdownTable
+)
+
+const (
+    markdownText = iota
+    markdownCodeSpan
+    markdownBreak
+    markdownLink
+    markdownImage
+)
+
+var (
+    markdownATXHeadingPattern     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+    markdownSetextPattern         = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+    markdownFencePattern          = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*(.*)$")
+    markdownRulePattern           = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+    markdownQuotePattern          = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+    markdownListPattern           = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])(?:([ \t]+)(.*))?$`)
+    markdownTableDelimiterPattern = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+    markdownReferencePattern      = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+    markdownEntityPattern         = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+    markdownAutolinkPattern       = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+    markdownEmailPattern          = regexp.MustCompile(`^<([^\s@<>\\]+@[A-Za-z0-9](?:[A-Za-z0-9.-]*[A-Za-z0-9])?)>`)
+    markdownLineBreakTagPattern   = regexp.MustCompile(`^<br\s*/?>`)
+    markdownBareURLPattern        = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]+`)
+)
+
+
+type markdownElement struct {
+    kind     int
+    level    int
+    text     string
+    children []*markdownElement
+    ordered  bool
+    start    int
+    items    [][]*markdownElement
+    align    []string
+    rows     [][]string
+}
+
+
+type markdownFormat struct {
+    bold   bool
+    italic bool
+    strike bool
+}
+
+
+type markdownInline struct {
+    kind     int
+    text     string
+    target   string
+    title    string
+    format   markdownFormat
+    children []markdownInline
+}
+
+
+type markdownReference struct {
+    target string
+    title  string
+}
+
+
+type markdownParser struct {
+    references map[string]markdownReference
+}
+
+
+func newMarkdownParser() *markdownParser {
+    return &markdownParser{references: make(map[string]markdownReference)}
+}
+
+
+func (p *markdownParser) parse(markdown string) []*markdownElement {
+    markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+    markdown = strings.ReplaceAll(markdown, "\r", "\n")
+    lines := strings.Split(markdown, "\n")
+    for i, line := range lines {
+        lines[i] = expandLeadingTabs(line)
+    }
+    return p.parseBlocks(lines)
+}
+
+
+func expandLeadingTabs(line string) string {
+    var sb strings.Builder
+    col := 0
+    for i, c := range line {
+        switch c {
+        case ' ':
+            sb.WriteByte(' ')
+            col++
+        case '\t':
+            n := 4 - col%4
+            sb.WriteString(strings.Repeat(" ", n))
+            col += n
+        default:
+            return sb.String() + line[i:]
+        }
+    }
+    return sb.String()
+}
+
+
+func indentWidth(line string) int {
+    return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+
+func stripIndent(line string, width int) string {
+    if indentWidth(line) < width {
+        return strings.TrimLeft(line, " ")
+    }
+    return line[width:]
+}
+
+
+func isBlank(line string) bool {
+    return strings.TrimSpace(line) == ""
+}
+
+
+
+
+func (p *markdownParser) parseBlocks(lines []string) []*markdownElement {
+    elements := []*markdownElement{}
+    para := []string{}
+    flush := func() {
+        if len(para) > 0 {
+            elements = append(elements, &markdownElement{kind: markdownParagraph, text: strings.Join(para, "\n")})
+            para = []string{}
+        }
+    }
+
+    for i := 0; i < len(lines); {
+        line := lines[i]
+        if isBlank(line) {
+            flush()
+            i++
+            continue
+        }
+
+        if m := markdownSetextPattern.FindStringSubmatch(line); m != nil && len(para) > 0 {
+            level := 2
+            if m[1][0] == '=' {
+                level = 1
+            }
+            text := strings.TrimSpace(strings.Join(para, "\n"))
+            para = []string{}
+            elements = append(elements, &markdownElement{kind: markdownHeading, level: level, text: text})
+            i++
+            continue
+        }
+
+        if len(para) == 0 && indentWidth(line) >= 4 {
+            code := []string{}
+            for ; i < len(lines) && (isBlank(lines[i]) || indentWidth(lines[i]) >= 4); i++ {
+                code = append(code, stripIndent(lines[i], 4))
+            }
+            for len(code) > 0 && isBlank(code[len(code)-1]) {
+                code = code[:len(code)-1]
+            }
+            elements = append(elements, &markdownElement{kind: markdownCode, text: strings.Join(code, "\n")})
+            continue
+        }
+
+        if m := markdownFencePattern.FindStringSubmatch(line); m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`")) {
+            flush()
+            i = p.parseFence(lines, i, len(m[1]), m[2], &elements)
+            continue
+        }
+
+        if m := markdownATXHeadingPattern.FindStringSubmatch(line); m != nil {
+            flush()
+            elements = append(elements, &markdownElement{kind: markdownHeading, level: len(m[1]), text: strings.TrimSpace(m[2])})
+            i++
+            continue
+        }
+
+        if markdownRulePattern.MatchString(line) {
+            flush()
+            elements = append(elements, &markdownElement{kind: markdownRule})
+            i++
+            continue
+        }
+
+        if markdownQuotePattern.MatchString(line) {
+            flush()
+            quoted := []string{}
+            for ; i < len(lines); i++ {
+                if m := markdownQuotePattern.FindStringSubmatch(lines[i]); m != nil {
+                    quoted = append(quoted, m[1])
+                    continue
+                }
+                if isBlank(lines[i]) || len(quoted) == 0 || isBlank(quoted[len(quoted)-1]) || p.startsBlock(lines[i]) {
+                    break
+                }
+                quoted = append(quoted, strings.TrimLeft(lines[i], " "))
+            }
+            elements = append(elements, &markdownElement{kind: markdownQuote, children: p.parseBlocks(quoted)})
+            continue
+        }
+
+        if m := markdownListPattern.FindStringSubmatch(line); m != nil && (len(para) == 0 || p.canInterruptParagraph(m)) {
+            flush()
+            var list *markdownElement
+            list, i = p.parseList(lines, i)
+            elements = append(elements, list)
+            continue
+        }
+
+        if i+1 < len(lines) && p.isTableStart(line, lines[i+1]) {
+            flush()
+            var table *markdownElement
+            table, i = p.parseTable(lines, i)
+            elements = append(elements, table)
+            continue
+        }
+
+        if m := markdownReferencePattern.FindStringSubmatch(line); m != nil && len(para) == 0 {
+            label := normalizeMarkdownLabel(m[1])
+            if _, exists := p.references[label]; !exists {
+                p.references[label] = markdownReference{target: m[2], title: m[3] + m[4] + m[5]}
+            }
+            i++
+            continue
+        }
+
+        para = append(para, strings.TrimLeft(line, " "))
+        i++
+    }
+    flush()
+    return elements
+}
+
+
+func (p *markdownParser) parseFence(lines []string, i int, indent int, fence string, elements *[]*markdownElement) int {
+    code := []string{}
+    for i++; i < len(lines); i++ {
+        trimmed := strings.TrimSpace(lines[i])
+        if indentWidth(lines[i]) < 4 && len(trimmed) >= len(fence) && strings.Trim(trimmed, fence[:1]) == "" {
+            i++
+            break
+        }
+        code = append(code, stripIndent(lines[i], indent))
+    }
+    *elements = append(*elements, &markdownElement{kind: markdownCode, text: strings.Join(code, "\n")})
+    return i
+}
+
+
+func (p *markdownParser) startsBlock(line string) bool {
+    if m := markdownListPattern.FindStringSubmatch(line); m != nil && p.canInterruptParagraph(m) {
+        return true
+    }
+    return markdownATXHeadingPattern.MatchString(line) || markdownFencePattern.MatchString(line) ||
+        markdownRulePattern.MatchString(line) || markdownQuotePattern.MatchString(line)
+}
+
+
+
+
+func (p *markdownParser) canInterruptParagraph(m []string) bool {
+    if strings.TrimSpace(m[4]) == "" {
+        return false
+    }
+    marker := m[2]
+    if marker[0] >= '0' && marker[0] <= '9' {
+        return marker[:len(marker)-1] == "1"
+    }
+    return true
+}
+
+
+func sameListMarker(a string, b string) bool {
+    aOrdered := a[0] >= '0' && a[0] <= '9'
+    bOrdered := b[0] >= '0' && b[0] <= '9'
+    if aOrdered != bOrdered {
+        return false
+    }
+    return a[len(a)-1] == b[len(b)-1]
+}
+
+
+func (p *markdownParser) parseList(lines []string, i int) (*markdownElement, int) {
+    first := markdownListPattern.FindStringSubmatch(lines[i])
+    marker := first[2]
+    list := &markdownElement{kind: markdownList, start: 1}
+    if marker[0] >= '0' && marker[0] <= '9' {
+        list.ordered = true
+        list.start, _ = strconv.Atoi(marker[:len(marker)-1])
+    }
+
+    for i < len(lines) {
+        m := markdownListPattern.FindStringSubmatch(lines[i])
+        if m == nil || !sameListMarker(m[2], marker) {
+            break
+        }
+        spacing := len(m[3])
+        if spacing == 0 || spacing > 4 || m[4] == "" {
+            spacing = 1
+        }
+        contentIndent := len(m[1]) + len(m[2]) + spacing
+        item := []string{""}
+        if len(m[3]) > 0 {
+            item = []string{strings.Repeat(" ", len(m[3])-spacing) + m[4]}
+        }
+
+        for i++; i < len(lines); i++ {
+            line := lines[i]
+            switch {
+            case isBlank(line):
+                item = append(item, "")
+                continue
+            case indentWidth(line) >= contentIndent:
+                item = append(item, line[contentIndent:])
+                continue
+            case !isBlank(item[len(item)-1]) && !p.startsBlock(line) && !markdownListPattern.MatchString(line):
+                item = append(item, strings.TrimLeft(line, " "))
+                continue
+            }
+            break
+        }
+        for len(item) > 0 && isBlank(item[len(item)-1]) {
+            item = item[:len(item)-1]
+        }
+        list.items = append(list.items, p.parseBlocks(item))
+    }
+    return list, i
+}
+
+
+func (p *markdownParser) isTableStart(header string, delimiter string) bool {
+    if !strings.Contains(header, "|") || !markdownTableDelimiterPattern.MatchString(delimiter) {
+        return false
+    }
+    return len(splitTableRow(header)) == len(splitTableRow(delimiter))
+}
+
+
+func (p *markdownParser) parseTable(lines []string, i int) (*markdownElement, int) {
+    header := splitTableRow(lines[i])
+    table := &markdownElement{kind: markdownTable, rows: [][]string{header}}
+    for _, cell := range splitTableRow(lines[i+1]) {
+        left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+        switch {
+        case left && right:
+            table.align = append(table.align, AlignCenter)
+        case right:
+            table.align = append(table.align, AlignRight)
+        case left:
+            table.align = append(table.align, AlignLeft)
+        default:
+            table.align = append(table.align, "")
+        }
+    }
+
+    for i += 2; i < len(lines) && !isBlank(lines[i]) && !p.startsBlock(lines[i]); i++ {
+        cells := splitTableRow(lines[i])
+        row := make([]string, len(header))
+        copy(row, cells)
+        table.rows = append(table.rows, row)
+    }
+    return table, i
+}
+
+
+func splitTableRow(line string) []string {
+    line = strings.TrimSpace(line)
+    line = strings.TrimPrefix(line, "|")
+    if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+        line = line[:len(line)-1]
+    }
+
+    cells := []string{}
+    var sb strings.Builder
+    for i := 0; i < len(line); i++ {
+        switch {
+        case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+            sb.WriteByte('|')
+            i++
+        case line[i] == '|':
+            cells = append(cells, strings.TrimSpace(sb.String()))
+            sb.Reset()
+        default:
+            sb.WriteByte(line[i])
+        }
+    }
+    return append(cells, strings.TrimSpace(sb.String()))
+}
+
+
+func normalizeMarkdownLabel(label string) string {
+    return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+
+func (p *markdownParser) parseInlines(s string, format markdownFormat) []markdownInline {
+    inlines := []markdownInline{}
+    var text strings.Builder
+    flush := func() {
+        if text.Len() > 0 {
+            inlines = append(inlines, markdownInline{kind: markdownText, text: text.String(), format: format})
+            text.Reset()
+        }
+    }
+    add := func(items ...markdownInline) {
+        flush()
+        inlines = append(inlines, items...)
+    }
+
+    for i := 0; i < len(s); {
+        c := s[i]
+        switch {
+        case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+            add(markdownInline{kind: markdownBreak})
+            i += 2
+            continue
+        case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+            text.WriteByte(s[i+1])
+            i += 2
+            continue
+        case c == '\n':
+            current := text.String()
+            trimmed := strings.TrimRight(current, " ")
+            text.Reset()
+            text.WriteString(trimmed)
+            if len(current)-len(trimmed) >= 2 {
+                add(markdownInline{kind: markdownBreak})
+            } else {
+                text.WriteByte(' ')
+            }
+            i++
+            continue
+        case c == '`':
+            if code, end, ok := parseCodeSpan(s, i); ok {
+                add(markdownInline{kind: markdownCodeSpan, text: code, format: format})
+                i = end
+                continue
+            }
+            n := runLength(s, i)
+            text.WriteString(s[i : i+n])
+            i += n
+            continue
+        case c == '!' && i+1 < len(s) && s[i+1] == '[':
+            if link, end, ok := p.parseLink(s, i+1, format); ok {
+                link.kind = markdownImage
+                link.text = plainMarkdownText(link.children)
+                link.children = nil
+                add(link)
+                i = end
+                continue
+            }
+        case c == '[':
+            if link, end, ok := p.parseLink(s, i, format); ok {
+                add(link)
+                i = end
+                continue
+            }
+        case c == '<':
+            if m := markdownAutolinkPattern.FindStringSubmatch(s[i:]); m != nil {
+                add(newMarkdownLink(m[1], m[1], format))
+                i += len(m[0])
+                continue
+            }
+            if m := markdownEmailPattern.FindStringSubmatch(s[i:]); m != nil {
+                add(newMarkdownLink("mailto:"+m[1], m[1], format))
+                i += len(m[0])
+                continue
+            }
+            if m := markdownLineBreakTagPattern.FindString(s[i:]); m != "" {
+                add(markdownInline{kind: markdownBreak})
+                i += len(m)
+                continue
+            }
+        case c == '&':
+            if m := markdownEntityPattern.FindString(s[i:]); m != "" {
+                text.WriteString(html.UnescapeString(m))
+                i += len(m)
+                continue
+            }
+        case c == 'h' || c == 'w':
+            if i == 0 || strings.ContainsRune(" \t\n(*_~", rune(s[i-1])) {
+                if m := markdownBareURLPattern.FindString(s[i:]); m != "" {
+                    m = trimBareURL(m)
+                    target := m
+                    if strings.HasPrefix(m, "www.") {
+                        target = "http://" + m
+                    }
+                    add(newMarkdownLink(target, m, format))
+                    i += len(m)
+                    continue
+                }
+            }
+        case c == '*' || c == '_' || c == '~':
+            if emphasis, end, ok := p.parseEmphasis(s, i, format); ok {
+                add(emphasis...)
+                i = end
+                continue
+            }
+            text.WriteByte(c)
+            i++
+            continue
+        }
+        text.WriteByte(c)
+        i++
+    }
+    flush()
+    return mergeMarkdownText(inlines)
+}
+
+
+func newMarkdownLink(target string, text string, format markdownFormat) markdownInline {
+    return markdownInline{
+        kind:     markdownLink,
+        target:   target,
+        children: []markdownInline{{kind: markdownText, text: text, format: format}},
+        format:   format,
+    }
+}
+
+
+func mergeMarkdownText(inlines []markdownInline) []markdownInline {
+    merged := []markdownInline{}
+    for _, in := range inlines {
+        if n := len(merged); n > 0 && in.kind == markdownText && merged[n-1].kind == markdownText && merged[n-1].format == in.format {
+            merged[n-1].text += in.text
+            continue
+        }
+        merged = append(merged, in)
+    }
+    return merged
+}
+
+
+func plainMarkdownText(inlines []markdownInline) string {
+    var sb strings.Builder
+    for _, in := range inlines {
+        switch in.kind {
+        case markdownText, markdownCodeSpan:
+            sb.WriteString(in.text)
+        case markdownLink:
+            sb.WriteString(plainMarkdownText(in.children))
+        case markdownImage:
+            sb.WriteString(in.text)
+        case markdownBreak:
+            sb.WriteString(" ")
+        }
+    }
+    return sb.String()
+}
+
+
+func isASCIIPunct(c byte) bool {
+    return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+
+func runLength(s string, i int) int {
+    n := 0
+    for i+n < len(s) && s[i+n] == s[i] {
+        n++
+    }
+    return n
+}
+
+
+func parseCodeSpan(s string, i int) (string, int, bool) {
+    n := runLength(s, i)
+    for j := i + n; j < len(s); {
+        if s[j] != '`' {
+            j++
+            continue
+        }
+        m := runLength(s, j)
+        if m == n {
+            code := strings.ReplaceAll(s[i+n:j], "\n", " ")
+            if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+                code = code[1 : len(code)-1]
+            }
+            return code, j + m, true
+        }
+        j += m
+    }
+    return "", 0, false
+}
+
+
+func trimBareURL(url string) string {
+    for len(url) > 0 {
+        last := url[len(url)-1]
+        switch {
+        case strings.IndexByte("?!.,:*_~'\"", last) >= 0:
+            url = url[:len(url)-1]
+        case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+            url = url[:len(url)-1]
+        default:
+            return url
+        }
+    }
+    return url
+}
+
+
+
+
+func (p *markdownParser) parseLink(s string, i int, format markdownFormat) (markdownInline, int, bool) {
+    closing := findLinkTextEnd(s, i)
+    if closing < 0 {
+        return markdownInline{}, 0, false
+    }
+    label := s[i+1 : closing]
+    link := markdownInline{kind: markdownLink, format: format}
+    end := closing + 1
+
+    switch {
+    case end < len(s) && s[end] == '(':
+        target, title, next, ok := parseLinkDestination(s, end+1)
+        if !ok {
+            return markdownInline{}, 0, false
+        }
+        link.target, link.title, end = target, title, next
+    default:
+        ref := label
+        if end+1 < len(s) && s[end] == '[' {
+            if refEnd := strings.IndexByte(s[end+1:], ']'); refEnd >= 0 {
+                if inner := s[end+1 : end+1+refEnd]; strings.TrimSpace(inner) != "" {
+                    ref = inner
+                }
+                end += refEnd + 2
+            }
+        }
+        reference, ok := p.references[normalizeMarkdownLabel(ref)]
+        if !ok {
+            return markdownInline{}, 0, false
+        }
+        link.target, link.title = reference.target, reference.title
+    }
+    link.children = p.parseInlines(label, format)
+    return link, end, true
+}
+
+
+func findLinkTextEnd(s string, i int) int {
+    depth := 0
+    for j := i; j < len(s); j++ {
+        switch s[j] {
+        case '\\':
+            j++
+        case '`':
+            if _, end, ok := parseCodeSpan(s, j); ok {
+                j = end - 1
+            }
+        case '[':
+            depth++
+        case ']':
+            depth--
+            if depth == 0 {
+                return j
+            }
+        }
+    }
+    return -1
+}
+
+
+func parseLinkDestination(s string, i int) (string, string, int, bool) {
+    skipSpace := func() {
+        for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+            i++
+        }
+    }
+    skipSpace()
+
+    var target strings.Builder
+    if i < len(s) && s[i] == '<' {
+        end := strings.IndexAny(s[i+1:], ">\n")
+        if end < 0 || s[i+1+end] != '>' {
+            return "", "", 0, false
+        }
+        target.WriteString(s[i+1 : i+1+end])
+        i += end + 2
+    } else {
+        depth := 0
+    loop:
+        for ; i < len(s); i++ {
+            switch c := s[i]; {
+            case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+                target.WriteByte(s[i+1])
+                i++
+            case c == '(':
+                depth++
+                target.WriteByte(c)
+            case c == ')':
+                if depth == 0 {
+                    break loop
+                }
+                depth--
+                target.WriteByte(c)
+            case c == ' ' || c == '\t' || c == '\n':
+                break loop
+            default:
+                target.WriteByte(c)
+            }
+        }
+    }
+
+    skipSpace()
+    title := ""
+    if i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+        closer := s[i]
+        if closer == '(' {
+            closer = ')'
+        }
+        end := strings.IndexByte(s[i+1:], closer)
+        if end < 0 {
+            return "", "", 0, false
+        }
+        title = s[i+1 : i+1+end]
+        i += end + 2
+        skipSpace()
+    }
+    if i >= len(s) || s[i] != ')' {
+        return "", "", 0, false
+    }
+    return html.UnescapeString(target.String()), title, i + 1, true
+}
+
+
+
+
+func (p *markdownParser) parseEmphasis(s string, i int, format markdownFormat) ([]markdownInline, int, bool) {
+    c := s[i]
+    n := runLength(s, i)
+    if i+n >= len(s) || isSpaceByte(s[i+n]) {
+        return nil, 0, false
+    }
+    if c == '_' && i > 0 && isWordByte(s[i-1]) {
+        return nil, 0, false
+    }
+
+    if c == '~' {
+        if n > 2 {
+            return nil, 0, false
+        }
+        closing := findEmphasisClose(s, i+n, c, n)
+        if closing < 0 {
+            return nil, 0, false
+        }
+        inner := format
+        inner.strike = true
+        return p.parseInlines(s[i+n:closing], inner), closing + n, true
+    }
+
+    for _, size := range []int{3, 2, 1} {
+        if n < size {
+            continue
+        }
+        closing := findEmphasisClose(s, i+size, c, size)
+        if closing < 0 {
+            if size == 1 || n < 3 {
+                break
+            }
+            continue
+        }
+        inner := format
+        inner.bold = inner.bold || size >= 2
+        inner.italic = inner.italic || size != 2
+        return p.parseInlines(s[i+size:closing], inner), closing + size, true
+    }
+    return nil, 0, false
+}
+
+
+
+
+func findEmphasisClose(s string, from int, c byte, size int) int {
+    for j := from; j < len(s); {
+        switch s[j] {
+        case '\\':
+            j += 2
+            continue
+        case '`':
+            if _, end, ok := parseCodeSpan(s, j); ok {
+                j = end
+                continue
+            }
+        case c:
+            m := runLength(s, j)
+            closable := j > from && !isSpaceByte(s[j-1])
+            if c == '_' && j+m < len(s) && isWordByte(s[j+m]) {
+                closable = false
+            }
+            if closable {
+                switch {
+                case m == size:
+                    return j
+                case size == 1 && m >= 3:
+                    return j + m - 1
+                case size == 2 && m >= 3:
+                    return j + m - 2
+                case size == 3 && m > 3:
+                    return j + m - 3
+                }
+            }
+            j += m
+            continue
+        }
+        j++
+    }
+    return -1
+}
+
+
+func isSpaceByte(c byte) bool {
+    return c == ' ' || c == '\t' || c == '\n'
+}
+
+
+func isWordByte(c byte) bool {
+    return c >= utf8.RuneSelf || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
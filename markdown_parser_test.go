@@ -0,0 +1,38 @@
+package docx
+
+import "testing"
+
+
+func TestParseListEmptyItems(t *testing.T) {
+    tests := []struct {
+        name     string
+        markdown string
+        ordered  bool
+        items    int
+    }{
+        {"bullet", "-\n", false, 1},
+        {"bullet without newline", "-", false, 1},
+        {"numbered", "1.", true, 1},
+        {"numbered with newline", "1.\n", true, 1},
+        {"empty then text", "-\n- two\n", false, 2},
+        {"text then empty", "1. one\n2.\n", true, 2},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            elements := newMarkdownParser().parse(tt.markdown)
+            if len(elements) != 1 || elements[0].kind != markdownList {
+                t.Fatalf("parse(%q) = %d elements, want one list", tt.markdown, len(elements))
+            }
+            list := elements[0]
+            if list.ordered != tt.ordered {
+                t.Errorf("ordered = %v, want %v", list.ordered, tt.ordered)
+            }
+            if len(list.items) != tt.items {
+                t.Errorf("got %d items, want %d", len(list.items), tt.items)
+            }
+            if _, err := NewMarkdownConverter().Convert(tt.markdown); err != nil {
+                t.Errorf("Convert(%q): %v", tt.markdown, err)
+            }
+        })
+    }
+}
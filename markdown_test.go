@@ -0,0 +1,130 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+
+func newMarkdownDocument(t *testing.T) *DocxDocument {
+    t.Helper()
+    dir := t.TempDir()
+    png, err := base64.StdEncoding.DecodeString(testPNG)
+    if err != nil {
+        t.Fatal(err)
+    }
+    imagePath := filepath.Join(dir, "chart.png")
+    if err := os.WriteFile(imagePath, png, 0644); err != nil {
+        t.Fatal(err)
+    }
+
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, "Report")
+    doc.AddText(StyleNormal, "Plain ")
+    doc.AddText(StyleNormal, "bold", FormatBold)
+    doc.AddText(StyleNormal, " and ")
+    doc.AddText(StyleNormal, "italic", FormatItalic)
+    doc.AddText(StyleNormal, " with *stars*")
+    doc.AddParagraph(StyleNormal).AddRun("# not a heading")
+
+    bullets := doc.AddList(false)
+    first, err := bullets.AddItem(0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    first.AddRun("Apples")
+    nested, err := bullets.AddItem(1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    nested.AddRun("Green")
+    numbers := doc.AddList(true)
+    step, err := numbers.AddItem(0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    step.AddRun("Step")
+
+    table := doc.AddTable(2, 2)
+    table.Cell(0, 0).AddText(StyleNormal, "Name", FormatBold)
+    table.Cell(0, 1).AddText(StyleNormal, "Qty")
+    table.Cell(1, 0).AddText(StyleNormal, "a|b")
+    table.Cell(1, 1).AddText(StyleNormal, "3")
+
+    doc.AddText(StyleHeading2, "Figures")
+    if err := doc.AddImage(imagePath); err != nil {
+        t.Fatal(err)
+    }
+    return doc
+}
+
+
+func TestDocumentText(t *testing.T) {
+    doc := newMarkdownDocument(t)
+    want := []string{"Report", "Plain bold and italic with *stars*", "# not a heading", "Apples", "Green", "Step", "Name\tQty", "a|b\t3", "Figures", ""}
+    if got := strings.Split(doc.Text(), "\n"); !equalStrings(got, want) {
+        t.Errorf("Text() = %q, want %q", got, want)
+    }
+}
+
+
+const wantMarkdown = `# Report
+
+Plain **bold** and *italic* with \*stars\*
+
+\# not a heading
+
+- Apples
+    - Green
+1. Step
+
+| **Name** | Qty |
+| --- | --- |
+| a\|b | 3 |
+
+## Figures
+
+![Inserted Picture](media/image1.png)
+`
+
+
+func TestMarkdownWriter(t *testing.T) {
+    doc := newMarkdownDocument(t)
+    var buf bytes.Buffer
+    if err := NewMarkdownWriter().WriteMarkdown(&buf, doc); err != nil {
+        t.Fatal(err)
+    }
+    if buf.String() != wantMarkdown {
+        t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", buf.String(), wantMarkdown)
+    }
+    buf.Reset()
+    if err := NewMarkdownWriter().WriteMarkdown(&buf, reopenDocument(t, doc)); err != nil {
+        t.Fatal(err)
+    }
+    if buf.String() != wantMarkdown {
+        t.Errorf("WriteMarkdown() of the reopened document =\n%s", buf.String())
+    }
+
+    dir := t.TempDir()
+    writer := NewMarkdownWriter()
+    writer.MediaDir = "assets/img"
+    filename := filepath.Join(dir, "report.md")
+    if err := writer.WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    written, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := strings.Replace(wantMarkdown, "(media/", "(assets/img/", 1); string(written) != want {
+        t.Errorf("WriteDocument() wrote\n%s", written)
+    }
+    png, _ := base64.StdEncoding.DecodeString(testPNG)
+    if image, err := os.ReadFile(filepath.Join(dir, "assets", "img", "image1.png")); err != nil || !bytes.Equal(image, png) {
+        t.Errorf("extracted image = %d bytes, %v", len(image), err)
+    }
+}
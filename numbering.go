@@ -0,0 +1,420 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "path"
+    "strconv"
+    "strings"
+)
+
+const (
+    relTypeNumbering     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+    contentTypeNumbering = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+    maxListLevel         = 8
+)
+
+var (
+    bulletListSymbols  = []string{"\u2022", "o", "\u25AA"}
+    orderedListFormats  = []string{"decimal", "lowerLetter", "lowerRoman"}
+)
+
+
+type numberProperty struct {
+    Val int `xml:"w:val,attr"`
+}
+
+type numberingProperties struct {
+    XMLName xml.Name        `xml:"w:numPr"`
+    Level   *numberProperty `xml:"w:ilvl,omitempty"`
+    ID      *numberProperty `xml:"w:numId,omitempty"`
+}
+
+
+type valueProperty struct {
+    Val string `xml:"w:val,attr"`
+}
+
+
+type levelIndentation struct {
+    XMLName xml.Name `xml:"w:ind"`
+    Left    int      `xml:"w:left,attr"`
+    Hanging int      `xml:"w:hanging,attr"`
+}
+
+type levelParagraphProperties struct {
+    XMLName     xml.Name         `xml:"w:pPr"`
+    Indentation levelIndentation `xml:"w:ind"`
+}
+
+type numberingLevel struct {
+    XMLName    xml.Name                 `xml:"w:lvl"`
+    Level      int                      `xml:"w:ilvl,attr"`
+    Start      numberProperty           `xml:"w:start"`
+    Format     valueProperty            `xml:"w:numFmt"`
+    Text       valueProperty            `xml:"w:lvlText"`
+    Alignment  valueProperty            `xml:"w:lvlJc"`
+    Properties levelParagraphProperties `xml:"w:pPr"`
+}
+
+type abstractNumbering struct {
+    XMLName        xml.Name         `xml:"w:abstractNum"`
+    ID             int              `xml:"w:abstractNumId,attr"`
+    MultiLevelType valueProperty    `xml:"w:multiLevelType"`
+    Levels         []numberingLevel `xml:"w:lvl"`
+}
+
+type levelOverride struct {
+    XMLName xml.Name       `xml:"w:lvlOverride"`
+    Level   int            `xml:"w:ilvl,attr"`
+    Start   numberProperty `xml:"w:startOverride"`
+}
+
+type numberingInstance struct {
+    XMLName       xml.Name        `xml:"w:num"`
+    ID            int             `xml:"w:numId,attr"`
+    AbstractNumID numberProperty  `xml:"w:abstractNumId"`
+    Overrides     []levelOverride `xml:"w:lvlOverride"`
+}
+
+type numberingDocument struct {
+    XMLName      xml.Name             `xml:"w:numbering"`
+    XmlnsW       string               `xml:"xmlns:w,attr"`
+    AbstractNums []*abstractNumbering `xml:"w:abstractNum"`
+    Nums         []*numberingInstance `xml:"w:num"`
+}
+
+
+type listNumbering struct {
+    bulletID  int
+    orderedID int
+    nums      []*numberingInstance
+    partName  string
+    rel       *relationship
+}
+
+
+type List struct {
+    doc     *DocxDocument
+    num     *numberingInstance
+    ordered bool
+}
+
+
+type numberingPart struct {
+    AbstractNums []struct {
+        ID     string `xml:"abstractNumId,attr"`
+        Levels []struct {
+            Level  int `xml:"ilvl,attr"`
+            Format struct {
+                Val string `xml:"val,attr"`
+            } `xml:"numFmt"`
+        } `xml:"lvl"`
+    } `xml:"abstractNum"`
+    Nums []struct {
+        ID            int `xml:"numId,attr"`
+        AbstractNumID struct {
+            Val string `xml:"val,attr"`
+        } `xml:"abstractNumId"`
+    } `xml:"num"`
+}
+
+
+
+
+func (d *DocxDocument) loadNumbering(data []byte) error {
+    var part numberingPart
+    if err := xml.Unmarshal(data, &part); err != nil {
+        return err
+    }
+
+    abstract := make(map[string]map[int]string)
+    for _, a := range part.AbstractNums {
+        levels := make(map[int]string)
+        for _, lvl := range a.Levels {
+            levels[lvl.Level] = lvl.Format.Val
+        }
+        abstract[a.ID] = levels
+        if id, err := strconv.Atoi(a.ID); err == nil && id > d.lastAbstractNumID {
+            d.lastAbstractNumID = id
+        }
+    }
+    for _, num := range part.Nums {
+        if levels, ok := abstract[num.AbstractNumID.Val]; ok {
+            d.numberingFormats[num.ID] = levels
+        }
+        if num.ID > d.lastNumID {
+            d.lastNumID = num.ID
+        }
+    }
+    return nil
+}
+
+
+
+
+func (d *DocxDocument) listLevel(p *paragraphData) (level int, ordered bool, ok bool) {
+    props := p.Properties
+    if props == nil {
+        return 0, false, false
+    }
+
+    if props.Numbering != nil && props.Numbering.ID != nil {
+        if props.Numbering.ID.Val == 0 {
+            return 0, false, false
+        }
+        if props.Numbering.Level != nil {
+            level = props.Numbering.Level.Val
+        }
+        format := "decimal"
+        if formats, found := d.numberingFormats[props.Numbering.ID.Val]; found {
+            if f, found := formats[level]; found {
+                format = f
+            }
+        }
+        if format == "none" {
+            return 0, false, false
+        }
+        return level, format != "bullet", true
+    }
+
+    if props.Style == nil {
+        return 0, false, false
+    }
+    style := props.Style.Val
+    for _, prefix := range []string{"ListBullet", "ListNumber"} {
+        if !strings.HasPrefix(style, prefix) {
+            continue
+        }
+        suffix := strings.TrimPrefix(style, prefix)
+        if suffix != "" {
+            n, err := strconv.Atoi(suffix)
+            if err != nil || n < 1 {
+                return 0, false, false
+            }
+            level = n - 1
+        }
+        return level, prefix == "ListNumber", true
+    }
+    return 0, false, false
+}
+
+
+func (d *DocxDocument) listFormat(p *paragraphData, level int, ordered bool) (numID int, format string, start int) {
+    format, start = "bullet", 1
+    if ordered {
+        format = "decimal"
+    }
+    if p.Properties == nil || p.Properties.Numbering == nil || p.Properties.Numbering.ID == nil {
+        return 0, format, start
+    }
+
+    numID = p.Properties.Numbering.ID.Val
+    if f, found := d.numberingFormats[numID][level]; found {
+        format = f
+    }
+    if d.numbering != nil {
+        for _, num := range d.numbering.nums {
+            if num.ID != numID {
+                continue
+            }
+            for _, override := range num.Overrides {
+                if override.Level == level {
+                    start = override.Start.Val
+                }
+            }
+        }
+    }
+    return numID, format, start
+}
+
+
+func (d *DocxDocument) listNumbering() *listNumbering {
+    if d.numbering != nil {
+        return d.numbering
+    }
+
+    numbering := &listNumbering{partName: "word/numbering.xml"}
+    d.lastAbstractNumID++
+    numbering.bulletID = d.lastAbstractNumID
+    d.lastAbstractNumID++
+    numbering.orderedID = d.lastAbstractNumID
+
+
+
+    switch {
+    case d.source == nil:
+        numbering.rel = &relationship{ID: d.nextRID(), Type: relTypeNumbering, Target: "numbering.xml"}
+    case d.source.relationshipTarget(relTypeNumbering) != "":
+        numbering.partName = d.source.relationshipTarget(relTypeNumbering)
+    default:
+        numbering.partName = path.Join(d.source.documentDir(), "numbering.xml")
+        numbering.rel = &relationship{ID: d.nextRID(), Type: relTypeNumbering, Target: "numbering.xml"}
+    }
+    d.numbering = numbering
+    return numbering
+}
+
+
+func (d *DocxDocument) AddList(ordered bool) *List {
+    numbering := d.listNumbering()
+    d.lastNumID++
+    num := &numberingInstance{ID: d.lastNumID, AbstractNumID: numberProperty{Val: numbering.bulletID}}
+    formats := make(map[int]string)
+    for level := 0; level <= maxListLevel; level++ {
+        formats[level] = "bullet"
+    }
+
+
+
+    if ordered {
+        num.AbstractNumID.Val = numbering.orderedID
+        for level := 0; level <= maxListLevel; level++ {
+            num.Overrides = append(num.Overrides, levelOverride{Level: level, Start: numberProperty{Val: 1}})
+            formats[level] = orderedListFormats[level%len(orderedListFormats)]
+        }
+    }
+    numbering.nums = append(numbering.nums, num)
+    d.numberingFormats[num.ID] = formats
+    return &List{doc: d, num: num, ordered: ordered}
+}
+
+
+func (l *List) Ordered() bool {
+    return l.ordered
+}
+
+
+func (l *List) SetStart(start int) error {
+    if !l.ordered {
+        return fmt.Errorf("cannot set the start number of a bulleted list")
+    }
+    if start < 0 {
+        return fmt.Errorf("invalid list start number: %d", start)
+    }
+    l.num.Overrides[0].Start.Val = start
+    return nil
+}
+
+
+func (l *List) AddItem(level int) (*Paragraph, error) {
+    if level < 0 || level > maxListLevel {
+        return nil, fmt.Errorf("unsupported list level %d: expected 0 to %d", level, maxListLevel)
+    }
+    para := l.doc.AddParagraph(StyleListParagraph)
+    para.properties().Numbering = l.itemNumbering(level)
+    return para, nil
+}
+
+
+func (l *List) itemNumbering(level int) *numberingProperties {
+    return &numberingProperties{
+        Level: &numberProperty{Val: level},
+        ID:    &numberProperty{Val: l.num.ID},
+    }
+}
+
+
+func newAbstractNumbering(id int, ordered bool) *abstractNumbering {
+    abstract := &abstractNumbering{ID: id, MultiLevelType: valueProperty{Val: "hybridMultilevel"}}
+    for level := 0; level <= maxListLevel; level++ {
+        lvl := numberingLevel{
+            Level:      level,
+            Start:      numberProperty{Val: 1},
+            Format:     valueProperty{Val: "bullet"},
+            Text:       valueProperty{Val: bulletListSymbols[level%len(bulletListSymbols)]},
+            Alignment:  valueProperty{Val: "left"},
+            Properties: levelParagraphProperties{Indentation: levelIndentation{Left: 720 * (level + 1), Hanging: 360}},
+        }
+        if ordered {
+            lvl.Format.Val = orderedListFormats[level%len(orderedListFormats)]
+            lvl.Text.Val = fmt.Sprintf("%%%d.", level+1)
+        }
+        abstract.Levels = append(abstract.Levels, lvl)
+    }
+    return abstract
+}
+
+
+func (d *DocxDocument) renderNumbering() ([]byte, error) {
+    numbering := d.numbering
+    abstracts := []*abstractNumbering{
+        newAbstractNumbering(numbering.bulletID, false),
+        newAbstractNumbering(numbering.orderedID, true),
+    }
+    if d.source != nil {
+        if data, ok := d.source.parts[numbering.partName]; ok {
+            return mergeNumbering(data, abstracts, numbering.nums)
+        }
+    }
+
+    var buf bytes.Buffer
+    buf.WriteString(xml.Header)
+    encoder := xml.NewEncoder(&buf)
+    encoder.Indent("", "  ")
+    err := encoder.Encode(numberingDocument{XmlnsW: namespaceW, AbstractNums: abstracts, Nums: numbering.nums})
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode numbering: %w", err)
+    }
+    return buf.Bytes(), nil
+}
+
+
+
+
+func mergeNumbering(data []byte, abstracts []*abstractNumbering, nums []*numberingInstance) ([]byte, error) {
+    reader := newDocumentReader(nil)
+    root, rootAttrs, err := reader.parseTree(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse numbering: %w", err)
+    }
+    if root.XMLName.Local != "w:numbering" {
+        return nil, fmt.Errorf("unexpected numbering root element %s", root.XMLName.Local)
+    }
+
+    insertAt := func(after string, before string) int {
+        pos := -1
+        for i, child := range root.Children {
+            el, ok := child.(*rawXML)
+            if !ok {
+                continue
+            }
+            if el.XMLName.Local == after {
+                pos = i + 1
+            } else if pos < 0 && el.XMLName.Local == before {
+                pos = i
+            }
+        }
+        if pos < 0 {
+            return len(root.Children)
+        }
+        return pos
+    }
+    insert := func(pos int, items []interface{}) {
+        updated := append([]interface{}{}, root.Children[:pos]...)
+        updated = append(updated, items...)
+        root.Children = append(updated, root.Children[pos:]...)
+    }
+
+    items := []interface{}{}
+    for _, abstract := range abstracts {
+        items = append(items, abstract)
+    }
+    insert(insertAt("w:abstractNum", "w:num"), items)
+    items = []interface{}{}
+    for _, num := range nums {
+        items = append(items, num)
+    }
+    insert(insertAt("w:num", "w:numIdMacAtCleanup"), items)
+    root.Attrs = append(reader.namespaceDeclarations(), rootAttrs...)
+
+    var buf bytes.Buffer
+    buf.WriteString(xml.Header)
+    encoder := xml.NewEncoder(&buf)
+    encoder.Indent("", "  ")
+    if err := encoder.Encode(root); err != nil {
+        return nil, fmt.Errorf("failed to encode numbering: %w", err)
+    }
+    return buf.Bytes(), nil
+}
@@ -0,0 +1,737 @@
+package docx
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "html"
+    "io"
+    "mime"
+    "os"
+    "path"
+    "strconv"
+    "strings"
+)
+
+const (
+    odtMimeType   = "application/vnd.oasis.opendocument.text"
+    odtNamespaces = ` xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+        ` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+        ` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+        ` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+        ` xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"` +
+        ` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"` +
+        ` xmlns:svg="urn:oasis:names:tc:opendocument:xmlns:svg-compatible:1.0"` +
+        ` xmlns:xlink="http://www.w3.org/1999/xlink"` +
+        ` xmlns:dc="http://purl.org/dc/elements/1.1/"` +
+        ` xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0"` +
+        ` office:version="1.3"`
+)
+
+
+type ODTWriter struct {
+    Title string
+}
+
+
+func NewODTWriter() *ODTWriter {
+    return &ODTWriter{}
+}
+
+
+func (ow *ODTWriter) WriteDocument(filename string, doc Document) error {
+    var buf bytes.Buffer
+    if err := ow.WriteODT(&buf, doc); err != nil {
+        return err
+    }
+
+    err := os.WriteFile(filename, buf.Bytes(), 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+    return nil
+}
+
+
+func (ow *ODTWriter) WriteODT(w io.Writer, doc Document) error {
+    r := newODTRenderer(doc)
+    r.render()
+
+    title := ow.Title
+    if title == "" {
+        title = r.title
+    }
+    return r.write(w, title)
+}
+
+
+type odtRenderer struct {
+    doc          Document
+    sheet        *styleSheet
+    content      *odtStyles
+    master       *odtStyles
+    styles       *odtStyles
+    lists        map[string]*odtList
+    listOrder    []*odtList
+    pictures     map[string]string
+    pictureOrder []string
+    media        map[string][]byte
+    layouts      strings.Builder
+    masters      strings.Builder
+    body         strings.Builder
+    part         *documentPart
+    pageMaster   string
+    width        float64
+    tables       int
+    frames       int
+    title        string
+    text         strings.Builder
+    segments     []string
+    span         string
+    space        bool
+    field        odtFieldState
+}
+
+
+type odtFieldState struct {
+    depth     int
+    instr     string
+    separated bool
+    skip      bool
+}
+
+
+func newODTRenderer(doc Document) *odtRenderer {
+    r := &odtRenderer{
+        doc:      doc,
+        sheet:    loadStyleSheet(doc),
+        content:  newODTStyles(""),
+        master:   newODTStyles("M"),
+        lists:    make(map[string]*odtList),
+        pictures: make(map[string]string),
+        media:    make(map[string][]byte),
+    }
+    r.styles = r.content
+    return r
+}
+
+
+
+
+func (r *odtRenderer) render() {
+    parts := map[string]*documentPart{}
+    sections := splitSections(r.doc.renderedContent(), r.doc.getSectionProperties())
+    for i, section := range sections {
+        if section.props != nil {
+            for _, extra := range section.props.Extra {
+                kind := strings.TrimSuffix(strings.TrimPrefix(extra.XMLName.Local, "w:"), "Reference")
+                if (kind == "header" || kind == "footer") && (attrValue(extra, "w:type") == "" || attrValue(extra, "w:type") == "default") {
+                    parts[kind] = loadDocumentPart(r.doc, attrValue(extra, "r:id"))
+                }
+            }
+        }
+
+        setup := newPageSetup(section.props)
+        if i == 0 || sectionBreakType(section.props) != SectionContinuous {
+            name := "Standard"
+            if i > 0 {
+                name = "Section" + strconv.Itoa(i+1)
+            }
+            r.masterPage(name, "pm"+strconv.Itoa(i+1), setup, parts["header"], parts["footer"])
+            if i > 0 {
+                r.pageMaster = name
+            }
+        }
+        r.width = setup.width - setup.left - setup.right
+        r.blocks(&r.body, section.blocks)
+    }
+}
+
+
+func (r *odtRenderer) masterPage(name string, layout string, setup pageSetup, header *documentPart, footer *documentPart) {
+    r.layouts.WriteString(r.pageLayout(layout, setup, header != nil, footer != nil))
+    r.masters.WriteString("<style:master-page" + odtAttr("style:name", name) + odtAttr("style:page-layout-name", layout) + ">\n")
+
+    r.styles = r.master
+    for _, hf := range []struct {
+        tag  string
+        part *documentPart
+    }{{"style:header", header}, {"style:footer", footer}} {
+        if hf.part == nil {
+            continue
+        }
+        r.part = hf.part
+        r.masters.WriteString("<" + hf.tag + ">\n")
+        r.blocks(&r.masters, hf.part.blocks)
+        r.masters.WriteString("</" + hf.tag + ">\n")
+    }
+    r.part = nil
+    r.styles = r.content
+    r.masters.WriteString("</style:master-page>\n")
+}
+
+
+func (r *odtRenderer) blocks(sb *strings.Builder, content []interface{}) {
+    lists := []*odtList{}
+    closeLists := func(depth int) {
+        for len(lists) > depth {
+            sb.WriteString("</text:list-item>\n</text:list>\n")
+            lists = lists[:len(lists)-1]
+        }
+    }
+
+    for _, block := range content {
+        p, ok := block.(*paragraphData)
+        if !ok {
+            closeLists(0)
+            switch v := block.(type) {
+            case *tableData:
+                r.table(sb, v)
+            case *containerElement:
+                r.blocks(sb, v.Content)
+            }
+            continue
+        }
+
+        level, ordered, ok := r.doc.getListLevel(p)
+        if !ok || r.part != nil {
+            if len(lists) == 0 || paragraphStyleID(p) != StyleListParagraph {
+                closeLists(0)
+            }
+            r.paragraph(sb, p)
+            continue
+        }
+        if level > maxListLevel {
+            level = maxListLevel
+        }
+        numID, format, start := r.doc.getListFormat(p, level, ordered)
+        list := r.list(numID, ordered)
+        list.level(level, format, start)
+
+        if len(lists) > level+1 {
+            closeLists(level + 1)
+        }
+        if len(lists) == level+1 {
+            if lists[level] == list {
+                sb.WriteString("</text:list-item>\n<text:list-item>\n")
+            } else {
+                closeLists(level)
+            }
+        }
+        for len(lists) < level+1 {
+            attrs := odtAttr("text:style-name", list.name)
+            if len(lists) == level {
+                if list.used {
+                    attrs += odtAttr("text:continue-numbering", "true")
+                }
+                list.used = true
+            }
+            sb.WriteString("<text:list" + attrs + ">\n<text:list-item>\n")
+            lists = append(lists, list)
+        }
+        r.paragraph(sb, p)
+    }
+    closeLists(0)
+}
+
+
+func (r *odtRenderer) list(numID int, ordered bool) *odtList {
+    key := strconv.Itoa(numID)
+    if numID == 0 {
+        key += "-" + strconv.FormatBool(ordered)
+    }
+    if list, ok := r.lists[key]; ok {
+        return list
+    }
+    list := &odtList{name: "L" + strconv.Itoa(len(r.listOrder)+1), levels: make(map[int]string)}
+    r.lists[key] = list
+    r.listOrder = append(r.listOrder, list)
+    return list
+}
+
+
+
+
+func (r *odtRenderer) paragraph(sb *strings.Builder, p *paragraphData) {
+    style := paragraphStyleID(p)
+    if style == "" {
+        style = r.sheet.defaultParagraph
+    }
+    tag, attrs := "text:p", ""
+    if level := headingLevel(style); level > 0 {
+        tag, attrs = "text:h", odtAttr("text:outline-level", strconv.Itoa(level))
+        if r.title == "" && r.part == nil {
+            r.title = strings.Join(strings.Fields(p.text()), " ")
+        }
+    }
+
+    r.text.Reset()
+    r.segments, r.span, r.space, r.field = nil, "", true, odtFieldState{}
+    r.inline(p.Content, false)
+    r.closeSpan()
+    segments := append(r.segments, r.text.String())
+
+    parent := r.namedStyle(style, "paragraph")
+    direct := r.sheet.directParagraphProperties(p.Properties)
+    for i, segment := range segments {
+        if i > 0 {
+            direct["pageBreakBefore"] = "true"
+        }
+        name := r.paragraphStyle(parent, direct)
+        styleAttr := ""
+        if name != "" {
+            styleAttr = odtAttr("text:style-name", name)
+        }
+        sb.WriteString("<" + tag + styleAttr + attrs + ">" + segment + "</" + tag + ">\n")
+    }
+}
+
+
+func (r *odtRenderer) paragraphStyle(parent string, direct styleProperties) string {
+    attrs, properties := "", odtParagraphProperties(direct)
+    if r.pageMaster != "" {
+        attrs = odtAttr("style:master-page-name", r.pageMaster)
+        r.pageMaster = ""
+    }
+    if attrs == "" && properties == "" {
+        return parent
+    }
+    if parent != "" {
+        attrs = odtAttr("style:parent-style-name", parent) + attrs
+    }
+    return r.styles.add("paragraph", "P", attrs, properties)
+}
+
+
+func (r *odtRenderer) runStyle(props *runProperties) string {
+    if props == nil {
+        return ""
+    }
+    parent := ""
+    if props.Style != nil {
+        parent = r.namedStyle(props.Style.Val, "character")
+    }
+    properties := odtTextProperties(r.sheet.directRunProperties(props))
+    if properties == "" {
+        return parent
+    }
+    attrs := ""
+    if parent != "" {
+        attrs = odtAttr("style:parent-style-name", parent)
+    }
+    return r.styles.add("text", "T", attrs, properties)
+}
+
+
+
+
+func (r *odtRenderer) inline(content []interface{}, link bool) {
+    for _, c := range content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            r.run(v, link)
+        case *simpleField:
+            if field := r.pageField(v.Instr); field != "" && len(v.Runs) > 0 {
+                r.openSpan(r.runStyle(v.Runs[0].Properties))
+                r.text.WriteString(field)
+                r.space = false
+                continue
+            }
+            for _, run := range v.Runs {
+                r.run(run, link)
+            }
+        case *bookmarkStart:
+            if v.Name != "" && !strings.HasPrefix(v.Name, "_GoBack") {
+                r.text.WriteString("<text:bookmark" + odtAttr("text:name", v.Name) + "/>")
+            }
+        case *containerElement:
+            if v.XMLName.Local != "w:hyperlink" {
+                r.inline(v.Content, link)
+                continue
+            }
+            target := ""
+            for _, a := range v.Attrs {
+                switch a.Name.Local {
+                case "r:id":
+                    if rel, ok := r.part.relationship(r.doc, a.Value); ok {
+                        target = rel.Target
+                    }
+                case "w:anchor":
+                    if target == "" {
+                        target = "#" + a.Value
+                    }
+                }
+            }
+            if target == "" {
+                r.inline(v.Content, link)
+                continue
+            }
+            r.closeSpan()
+            r.text.WriteString("<text:a" + odtAttr("xlink:type", "simple") + odtAttr("xlink:href", target) + ">")
+            r.inline(v.Content, true)
+            r.closeSpan()
+            r.text.WriteString("</text:a>")
+        }
+    }
+}
+
+
+func (r *odtRenderer) pageField(instr string) string {
+    fields := strings.Fields(instr)
+    if len(fields) == 0 {
+        return ""
+    }
+    switch strings.ToUpper(fields[0]) {
+    case "PAGE":
+        return `<text:page-number text:select-page="current">1</text:page-number>`
+    case "NUMPAGES":
+        return "<text:page-count>1</text:page-count>"
+    }
+    return ""
+}
+
+
+func (r *odtRenderer) run(run *paragraphRun, link bool) {
+    field := &r.field
+    if run.FieldChar != nil {
+        switch run.FieldChar.Type {
+        case fieldCharBegin:
+            field.depth++
+            if field.depth == 1 {
+                field.instr, field.separated = "", false
+            }
+        case fieldCharSeparate, fieldCharEnd:
+            if field.depth == 1 && !field.separated {
+                if text := r.pageField(field.instr); text != "" {
+                    r.openSpan(r.runStyle(run.Properties))
+                    r.text.WriteString(text)
+                    r.space = false
+                    field.skip = true
+                }
+                field.separated = run.FieldChar.Type == fieldCharSeparate
+            }
+            if run.FieldChar.Type == fieldCharEnd {
+                if field.depth > 0 {
+                    field.depth--
+                }
+                if field.depth == 0 {
+                    field.skip = false
+                }
+            }
+        }
+        return
+    }
+    if run.InstrText != nil {
+        if field.depth == 1 {
+            field.instr += run.InstrText.Text
+        }
+        return
+    }
+    if field.skip || (field.depth > 0 && !field.separated) {
+        return
+    }
+
+    style := r.runStyle(run.Properties)
+    switch {
+    case run.Text != nil:
+        r.openSpan(style)
+        r.writeText(run.Text.Text)
+        return
+    case run.Tab != nil:
+        r.openSpan(style)
+        r.text.WriteString("<text:tab/>")
+        r.space = false
+        return
+    case run.Break != nil && (run.Break.Type == BreakPage || run.Break.Type == BreakColumn) && !link && r.part == nil:
+        r.closeSpan()
+        r.segments = append(r.segments, r.text.String())
+        r.text.Reset()
+        r.space = true
+        return
+    case run.Break != nil:
+        r.openSpan(style)
+        r.text.WriteString("<text:line-break/>")
+        r.space = true
+        return
+    case run.hasDrawing():
+        if frame := r.image(run); frame != "" {
+            r.openSpan(style)
+            r.text.WriteString(frame)
+            r.space = false
+        }
+        return
+    }
+    for _, extra := range run.Extra {
+        switch extra.XMLName.Local {
+        case "w:tab", "w:ptab":
+            r.openSpan(style)
+            r.text.WriteString("<text:tab/>")
+            r.space = false
+        case "w:cr":
+            r.openSpan(style)
+            r.text.WriteString("<text:line-break/>")
+            r.space = true
+        case "w:noBreakHyphen":
+            r.openSpan(style)
+            r.writeText("-")
+        case "w:t":
+            r.openSpan(style)
+            r.writeText(extra.text())
+        }
+    }
+}
+
+
+func (r *odtRenderer) openSpan(style string) {
+    if style == r.span {
+        return
+    }
+    r.closeSpan()
+    if style != "" {
+        r.text.WriteString("<text:span" + odtAttr("text:style-name", style) + ">")
+    }
+    r.span = style
+}
+
+
+func (r *odtRenderer) closeSpan() {
+    if r.span != "" {
+        r.text.WriteString("</text:span>")
+    }
+    r.span = ""
+}
+
+
+func (r *odtRenderer) writeText(text string) {
+    for len(text) > 0 {
+        switch text[0] {
+        case ' ':
+            n := len(text) - len(strings.TrimLeft(text, " "))
+            text = text[n:]
+            if !r.space {
+                r.text.WriteString(" ")
+                n--
+            }
+            if n == 1 {
+                r.text.WriteString("<text:s/>")
+            } else if n > 1 {
+                r.text.WriteString("<text:s" + odtAttr("text:c", strconv.Itoa(n)) + "/>")
+            }
+            r.space = true
+            continue
+        case '\t':
+            r.text.WriteString("<text:tab/>")
+            r.space = false
+        case '\n':
+            r.text.WriteString("<text:line-break/>")
+            r.space = true
+        default:
+            n := strings.IndexAny(text, " \t\n")
+            if n < 0 {
+                n = len(text)
+            }
+            r.text.WriteString(html.EscapeString(text[:n]))
+            r.space = false
+            text = text[n:]
+            continue
+        }
+        text = text[1:]
+    }
+}
+
+
+func (r *odtRenderer) image(run *paragraphRun) string {
+    rID, alt := drawingImage(run)
+    cx, cy := drawingExtent(run)
+    if rID == "" || cx <= 0 || cy <= 0 {
+        return ""
+    }
+    name, data, ok := r.part.imageData(r.doc, rID)
+    if !ok {
+        return ""
+    }
+
+    href, ok := r.pictures[name]
+    if !ok {
+        href = "Pictures/" + path.Base(name)
+        if _, exists := r.media[href]; exists {
+            href = "Pictures/" + strconv.Itoa(len(r.pictureOrder)+1) + "_" + path.Base(name)
+        }
+        r.pictures[name] = href
+        r.media[href] = data
+        r.pictureOrder = append(r.pictureOrder, href)
+    }
+
+    r.frames++
+    frame := "<draw:frame" + odtAttr("draw:name", "Image"+strconv.Itoa(r.frames)) + odtAttr("text:anchor-type", "as-char") +
+        odtAttr("svg:width", odtLength(float64(cx)/12700)) + odtAttr("svg:height", odtLength(float64(cy)/12700)) + ">" +
+        "<draw:image" + odtAttr("xlink:href", href) + odtAttr("xlink:type", "simple") + odtAttr("xlink:show", "embed") +
+        odtAttr("xlink:actuate", "onLoad") + "/>"
+    if alt != "" {
+        frame += "<svg:desc>" + html.EscapeString(alt) + "</svg:desc>"
+    }
+    return frame + "</draw:frame>"
+}
+
+
+
+
+func (r *odtRenderer) table(sb *strings.Builder, t *tableData) {
+    props := styleProperties{}
+    if t.Properties != nil {
+        if t.Properties.Style != nil {
+            _, _, props = r.sheet.resolve(t.Properties.Style.Val)
+        }
+        r.sheet.readTableProperties(&rawXML{Children: rawChildren(t.Properties.Extra)}, props)
+    }
+
+    widths := gridWidths(t, r.width)
+    total := 0.0
+    for _, w := range widths {
+        total += w
+    }
+
+    attrs := ""
+    if r.pageMaster != "" {
+        attrs = odtAttr("style:master-page-name", r.pageMaster)
+        r.pageMaster = ""
+    }
+    r.tables++
+    style := r.styles.add("table", "Table", attrs, "<style:table-properties"+odtAttr("style:width", odtLength(total))+
+        odtAttr("table:align", "left")+"/>")
+    sb.WriteString("<table:table" + odtAttr("table:name", "Table"+strconv.Itoa(r.tables)) + odtAttr("table:style-name", style) + ">\n")
+    for _, w := range widths {
+        column := r.styles.add("table-column", "Column", "", "<style:table-column-properties"+odtAttr("style:column-width", odtLength(w))+"/>")
+        sb.WriteString("<table:table-column" + odtAttr("table:style-name", column) + "/>\n")
+    }
+
+    header := 0
+    for header < len(t.Rows) && t.Rows[header].Properties != nil && t.Rows[header].Properties.Header != nil &&
+        t.Rows[header].Properties.Header.Val != "0" && t.Rows[header].Properties.Header.Val != "false" {
+        header++
+    }
+    for i, row := range t.Rows {
+        if i == 0 && header > 0 {
+            sb.WriteString("<table:table-header-rows>\n")
+        }
+        sb.WriteString("<table:table-row>\n")
+        col := 0
+        for _, cell := range row.Cells {
+            span, merge := cellSpan(cell)
+            if merge == "continue" {
+                sb.WriteString(strings.Repeat("<table:covered-table-cell/>\n", span))
+                col += span
+                continue
+            }
+            rows := 1
+            if merge == "restart" {
+                rows = rowSpan(t.Rows, i, col)
+            }
+
+            attrs := odtAttr("table:style-name", r.cellStyle(cell, props, i == 0, i+rows == len(t.Rows), col == 0, col+span >= len(widths)))
+            if span > 1 {
+                attrs += odtAttr("table:number-columns-spanned", strconv.Itoa(span))
+            }
+            if rows > 1 {
+                attrs += odtAttr("table:number-rows-spanned", strconv.Itoa(rows))
+            }
+            sb.WriteString("<table:table-cell" + attrs + odtAttr("office:value-type", "string") + ">\n")
+            r.blocks(sb, cell.Blocks)
+            sb.WriteString("</table:table-cell>\n")
+            sb.WriteString(strings.Repeat("<table:covered-table-cell/>\n", span-1))
+            col += span
+        }
+        sb.WriteString("</table:table-row>\n")
+        if i == header-1 {
+            sb.WriteString("</table:table-header-rows>\n")
+        }
+    }
+    sb.WriteString("</table:table>\n")
+}
+
+
+func (r *odtRenderer) cellStyle(cell *tableCellData, table styleProperties, top bool, bottom bool, left bool, right bool) string {
+    return r.styles.add("table-cell", "Cell", "", odtCellProperties(cellProperties(cell, table, top, bottom, left, right)))
+}
+
+
+
+
+func (r *odtRenderer) write(w io.Writer, title string) error {
+    var content strings.Builder
+    content.WriteString(xml.Header + "<office:document-content" + odtNamespaces + ">\n<office:automatic-styles>\n")
+    content.WriteString(r.content.xml.String())
+    for _, list := range r.listOrder {
+        content.WriteString(list.xml())
+    }
+    content.WriteString("</office:automatic-styles>\n<office:body>\n<office:text>\n")
+    content.WriteString(r.body.String())
+    content.WriteString("</office:text>\n</office:body>\n</office:document-content>\n")
+
+    var styles strings.Builder
+    styles.WriteString(xml.Header + "<office:document-styles" + odtNamespaces + ">\n<office:styles>\n" + r.namedStyles() + "</office:styles>\n")
+    styles.WriteString("<office:automatic-styles>\n" + r.layouts.String() + r.master.xml.String() + "</office:automatic-styles>\n")
+    styles.WriteString("<office:master-styles>\n" + r.masters.String() + "</office:master-styles>\n</office:document-styles>\n")
+
+    meta := xml.Header + "<office:document-meta" + odtNamespaces + ">\n<office:meta>\n" +
+        "<meta:generator>github.com/jonelmawirat/docx</meta:generator>\n"
+    if title != "" {
+        meta += "<dc:title>" + html.EscapeString(title) + "</dc:title>\n"
+    }
+    meta += "</office:meta>\n</office:document-meta>\n"
+
+    var manifest strings.Builder
+    manifest.WriteString(xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.3">` + "\n")
+    manifest.WriteString(`<manifest:file-entry manifest:full-path="/" manifest:version="1.3" manifest:media-type="` + odtMimeType + `"/>` + "\n")
+    for _, name := range []string{"content.xml", "styles.xml", "meta.xml"} {
+        manifest.WriteString(`<manifest:file-entry manifest:full-path="` + name + `" manifest:media-type="text/xml"/>` + "\n")
+    }
+    for _, name := range r.pictureOrder {
+        mediaType := mime.TypeByExtension(path.Ext(name))
+        if mediaType == "" {
+            mediaType = "application/octet-stream"
+        }
+        manifest.WriteString("<manifest:file-entry" + odtAttr("manifest:full-path", name) + odtAttr("manifest:media-type", mediaType) + "/>\n")
+    }
+    manifest.WriteString("</manifest:manifest>\n")
+
+    zipWriter := zip.NewWriter(w)
+    partWriter, err := zipWriter.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+    if err != nil {
+        return fmt.Errorf("failed to create mimetype in odt: %w", err)
+    }
+    if _, err := io.WriteString(partWriter, odtMimeType); err != nil {
+        return fmt.Errorf("failed to write mimetype to odt: %w", err)
+    }
+
+    parts := []struct {
+        name string
+        data []byte
+    }{
+        {"content.xml", []byte(content.String())},
+        {"styles.xml", []byte(styles.String())},
+        {"meta.xml", []byte(meta)},
+        {"META-INF/manifest.xml", []byte(manifest.String())},
+    }
+    for _, name := range r.pictureOrder {
+        parts = append(parts, struct {
+            name string
+            data []byte
+        }{name, r.media[name]})
+    }
+    for _, part := range parts {
+        partWriter, err := zipWriter.Create(part.name)
+        if err != nil {
+            return fmt.Errorf("failed to create %s in odt: %w", part.name, err)
+        }
+        if _, err := partWriter.Write(part.data); err != nil {
+            return fmt.Errorf("failed to write %s to odt: %w", part.name, err)
+        }
+    }
+
+    if err := zipWriter.Close(); err != nil {
+        return fmt.Errorf("failed to finalize odt: %w", err)
+    }
+    return nil
+}
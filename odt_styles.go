@@ -0,0 +1,367 @@
+package docx
+
+import (
+    "html"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+
+var odtNumberFormats = map[string]string{
+    "decimal": "1", "lowerLetter": "a", "upperLetter": "A", "lowerRoman": "i", "upperRoman": "I",
+}
+
+
+type odtStyles struct {
+    prefix string
+    names  map[string]string
+    counts map[string]int
+    xml    strings.Builder
+}
+
+
+type odtList struct {
+    name   string
+    used   bool
+    levels map[int]string
+}
+
+
+func newODTStyles(prefix string) *odtStyles {
+    return &odtStyles{prefix: prefix, names: make(map[string]string), counts: make(map[string]int)}
+}
+
+
+func (s *odtStyles) add(family string, kind string, attrs string, properties string) string {
+    key := family + "|" + attrs + "|" + properties
+    if name, ok := s.names[key]; ok {
+        return name
+    }
+    s.counts[kind]++
+    name := s.prefix + kind + strconv.Itoa(s.counts[kind])
+    s.names[key] = name
+    s.xml.WriteString("<style:style" + odtAttr("style:name", name) + odtAttr("style:family", family) + attrs + ">" + properties + "</style:style>\n")
+    return name
+}
+
+
+
+
+func (l *odtList) level(level int, format string, start int) {
+    if _, ok := l.levels[level]; ok {
+        return
+    }
+    indent := odtAttr("text:list-tab-stop-position", odtLength(float64(36*(level+1)))) + odtAttr("fo:text-indent", "-18pt") +
+        odtAttr("fo:margin-left", odtLength(float64(36*(level+1))))
+    properties := `<style:list-level-properties text:list-level-position-and-space-mode="label-alignment">` +
+        `<style:list-level-label-alignment text:label-followed-by="listtab"` + indent + "/></style:list-level-properties>"
+
+    n := odtAttr("text:level", strconv.Itoa(level+1))
+    if format == "bullet" {
+        l.levels[level] = "<text:list-level-style-bullet" + n + odtAttr("text:bullet-char", bulletListSymbols[level%len(bulletListSymbols)]) + ">" +
+            properties + "</text:list-level-style-bullet>"
+        return
+    }
+    numFormat, ok := odtNumberFormats[format]
+    if !ok {
+        numFormat = "1"
+    }
+    l.levels[level] = "<text:list-level-style-number" + n + odtAttr("style:num-suffix", ".") + odtAttr("style:num-format", numFormat) +
+        odtAttr("text:start-value", strconv.Itoa(start)) + ">" + properties + "</text:list-level-style-number>"
+}
+
+
+func (l *odtList) xml() string {
+    levels := []int{}
+    for level := range l.levels {
+        levels = append(levels, level)
+    }
+    sort.Ints(levels)
+
+    var sb strings.Builder
+    sb.WriteString("<text:list-style" + odtAttr("style:name", l.name) + ">")
+    for _, level := range levels {
+        sb.WriteString(l.levels[level])
+    }
+    sb.WriteString("</text:list-style>\n")
+    return sb.String()
+}
+
+
+
+
+func odtAttr(name string, value string) string {
+    return " " + name + "=\"" + html.EscapeString(value) + "\""
+}
+
+
+func odtLength(points float64) string {
+    return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64) + "pt"
+}
+
+
+func odtElement(name string, attrs []string, children string) string {
+    if len(attrs) == 0 && children == "" {
+        return ""
+    }
+    if children == "" {
+        return "<" + name + strings.Join(attrs, "") + "/>"
+    }
+    return "<" + name + strings.Join(attrs, "") + ">" + children + "</" + name + ">"
+}
+
+
+
+
+func odtTextProperties(props styleProperties) string {
+    attrs := []string{}
+    if font := props["font"]; font != "" {
+        attrs = append(attrs, odtAttr("fo:font-family", "'"+strings.ReplaceAll(font, "'", "")+"'"),
+            odtAttr("style:font-family-generic", fontFamilyClass(font)))
+    }
+    if n, err := strconv.ParseFloat(props["size"], 64); err == nil {
+        attrs = append(attrs, odtAttr("fo:font-size", odtLength(n/2)))
+    }
+    if v, ok := props["b"]; ok {
+        attrs = append(attrs, odtAttr("fo:font-weight", map[bool]string{true: "bold", false: "normal"}[v == "true"]))
+    }
+    if v, ok := props["i"]; ok {
+        attrs = append(attrs, odtAttr("fo:font-style", map[bool]string{true: "italic", false: "normal"}[v == "true"]))
+    }
+    switch u := props["underline"]; {
+    case u == "none":
+        attrs = append(attrs, odtAttr("style:text-underline-style", "none"))
+    case u != "":
+        attrs = append(attrs, odtAttr("style:text-underline-style", "solid"), odtAttr("style:text-underline-width", "auto"),
+            odtAttr("style:text-underline-color", "font-color"))
+        if u == "double" {
+            attrs = append(attrs, odtAttr("style:text-underline-type", "double"))
+        }
+    }
+    switch {
+    case props["dstrike"] == "true":
+        attrs = append(attrs, odtAttr("style:text-line-through-style", "solid"), odtAttr("style:text-line-through-type", "double"))
+    case props["strike"] == "true":
+        attrs = append(attrs, odtAttr("style:text-line-through-style", "solid"))
+    case props["strike"] == "false":
+        attrs = append(attrs, odtAttr("style:text-line-through-style", "none"))
+    }
+    if props["caps"] == "true" {
+        attrs = append(attrs, odtAttr("fo:text-transform", "uppercase"))
+    }
+    if props["smallCaps"] == "true" {
+        attrs = append(attrs, odtAttr("fo:font-variant", "small-caps"))
+    }
+    if props["vanish"] == "true" {
+        attrs = append(attrs, odtAttr("text:display", "none"))
+    }
+    if color, ok := cssColor(props["color"]); ok {
+        attrs = append(attrs, odtAttr("fo:color", color))
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        attrs = append(attrs, odtAttr("fo:background-color", fill))
+    } else if color, ok := highlightColors[props["highlight"]]; ok {
+        attrs = append(attrs, odtAttr("fo:background-color", "#"+color))
+    }
+    switch props["vertAlign"] {
+    case "superscript":
+        attrs = append(attrs, odtAttr("style:text-position", "super 58%"))
+    case "subscript":
+        attrs = append(attrs, odtAttr("style:text-position", "sub 58%"))
+    }
+    return odtElement("style:text-properties", attrs, "")
+}
+
+
+func odtParagraphProperties(props styleProperties) string {
+    attrs := []string{}
+    switch props["align"] {
+    case "left", "start":
+        attrs = append(attrs, odtAttr("fo:text-align", "start"))
+    case "center":
+        attrs = append(attrs, odtAttr("fo:text-align", "center"))
+    case "right", "end":
+        attrs = append(attrs, odtAttr("fo:text-align", "end"))
+    case "both", "distribute":
+        attrs = append(attrs, odtAttr("fo:text-align", "justify"))
+    }
+    for _, side := range []struct{ prop, attr string }{
+        {"before", "fo:margin-top"}, {"after", "fo:margin-bottom"}, {"left", "fo:margin-left"}, {"right", "fo:margin-right"},
+    } {
+        if v, ok := twipsToPoints(props[side.prop]); ok {
+            attrs = append(attrs, odtAttr(side.attr, v))
+        }
+    }
+    if v, ok := twipsToPoints(props["hanging"]); ok {
+        attrs = append(attrs, odtAttr("fo:text-indent", "-"+v))
+    } else if v, ok := twipsToPoints(props["firstLine"]); ok {
+        attrs = append(attrs, odtAttr("fo:text-indent", v))
+    }
+    if line, err := strconv.ParseFloat(props["line"], 64); err == nil && line > 0 {
+        switch props["lineRule"] {
+        case "exact":
+            attrs = append(attrs, odtAttr("fo:line-height", odtLength(line/20)))
+        case "atLeast":
+            attrs = append(attrs, odtAttr("style:line-height-at-least", odtLength(line/20)))
+        default:
+            attrs = append(attrs, odtAttr("fo:line-height", strconv.FormatFloat(math.Round(line/240*100), 'f', -1, 64)+"%"))
+        }
+    }
+    if props["contextualSpacing"] == "true" {
+        attrs = append(attrs, odtAttr("style:contextual-spacing", "true"))
+    }
+    if props["keepNext"] == "true" {
+        attrs = append(attrs, odtAttr("fo:keep-with-next", "always"))
+    }
+    if props["keepLines"] == "true" {
+        attrs = append(attrs, odtAttr("fo:keep-together", "always"))
+    }
+    if props["pageBreakBefore"] == "true" {
+        attrs = append(attrs, odtAttr("fo:break-before", "page"))
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        attrs = append(attrs, odtAttr("fo:background-color", fill))
+    }
+    for _, side := range []string{"top", "left", "bottom", "right"} {
+        border, ok := cssBorder(props["border-"+side])
+        if !ok {
+            continue
+        }
+        attrs = append(attrs, odtAttr("fo:border-"+side, border))
+        if space, err := strconv.ParseFloat(strings.Split(props["border-"+side], " ")[2], 64); err == nil {
+            attrs = append(attrs, odtAttr("fo:padding-"+side, odtLength(space)))
+        }
+    }
+    return odtElement("style:paragraph-properties", attrs, odtTabStops(props["tabs"]))
+}
+
+
+func odtTabStops(value string) string {
+    var sb strings.Builder
+    for _, tab := range strings.Split(value, ",") {
+        parts := strings.Split(tab, ":")
+        if len(parts) != 3 {
+            continue
+        }
+        pos, ok := twipsToPoints(parts[1])
+        if !ok {
+            continue
+        }
+        attrs := odtAttr("style:position", pos)
+        switch parts[0] {
+        case "center":
+            attrs += odtAttr("style:type", "center")
+        case "right", "end":
+            attrs += odtAttr("style:type", "right")
+        case "decimal":
+            attrs += odtAttr("style:type", "char") + odtAttr("style:char", ".")
+        case "left", "start":
+        default:
+            continue
+        }
+        if leader, ok := tabLeaders[parts[2]]; ok {
+            attrs += odtAttr("style:leader-style", "solid") + odtAttr("style:leader-text", leader)
+        }
+        sb.WriteString("<style:tab-stop" + attrs + "/>")
+    }
+    if sb.Len() == 0 {
+        return ""
+    }
+    return "<style:tab-stops>" + sb.String() + "</style:tab-stops>"
+}
+
+
+func odtCellProperties(props styleProperties) string {
+    attrs := []string{}
+    for _, side := range []string{"top", "left", "bottom", "right"} {
+        if v, ok := twipsToPoints(props["cell-"+side]); ok {
+            attrs = append(attrs, odtAttr("fo:padding-"+side, v))
+        }
+        if border, ok := cssBorder(props["border-"+side]); ok {
+            attrs = append(attrs, odtAttr("fo:border-"+side, border))
+        } else {
+            attrs = append(attrs, odtAttr("fo:border-"+side, "none"))
+        }
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        attrs = append(attrs, odtAttr("fo:background-color", fill))
+    }
+    switch props["vAlign"] {
+    case "center":
+        attrs = append(attrs, odtAttr("style:vertical-align", "middle"))
+    case "bottom":
+        attrs = append(attrs, odtAttr("style:vertical-align", "bottom"))
+    }
+    return odtElement("style:table-cell-properties", attrs, "")
+}
+
+
+
+
+func (r *odtRenderer) pageLayout(name string, setup pageSetup, header bool, footer bool) string {
+    top, bottom := setup.top, setup.bottom
+    if header {
+        top = setup.header
+    }
+    if footer {
+        bottom = setup.footer
+    }
+    orientation := "portrait"
+    if setup.width > setup.height {
+        orientation = "landscape"
+    }
+
+    var sb strings.Builder
+    sb.WriteString("<style:page-layout" + odtAttr("style:name", name) + "><style:page-layout-properties" +
+        odtAttr("fo:page-width", odtLength(setup.width)) + odtAttr("fo:page-height", odtLength(setup.height)) +
+        odtAttr("style:print-orientation", orientation) + odtAttr("fo:margin-top", odtLength(top)) +
+        odtAttr("fo:margin-bottom", odtLength(bottom)) + odtAttr("fo:margin-left", odtLength(setup.left)) +
+        odtAttr("fo:margin-right", odtLength(setup.right)) + "/>")
+    if header {
+        sb.WriteString("<style:header-style><style:header-footer-properties" + odtAttr("fo:min-height", odtLength(math.Max(setup.top-setup.header, 0))) +
+            odtAttr("fo:margin-bottom", "0pt") + odtAttr("style:dynamic-spacing", "true") + "/></style:header-style>")
+    }
+    if footer {
+        sb.WriteString("<style:footer-style><style:header-footer-properties" + odtAttr("fo:min-height", odtLength(math.Max(setup.bottom-setup.footer, 0))) +
+            odtAttr("fo:margin-top", "0pt") + odtAttr("style:dynamic-spacing", "true") + "/></style:footer-style>")
+    }
+    sb.WriteString("</style:page-layout>\n")
+    return sb.String()
+}
+
+
+func (r *odtRenderer) namedStyles() string {
+    var sb strings.Builder
+    sb.WriteString(`<style:default-style style:family="paragraph">` + odtParagraphProperties(r.sheet.paragraphDefaults) +
+        odtTextProperties(r.sheet.runDefaults) + "</style:default-style>\n")
+    for _, id := range r.sheet.order {
+        style := r.sheet.styles[id]
+        family := map[string]string{"paragraph": "paragraph", "character": "text"}[style.kind]
+        if family == "" {
+            continue
+        }
+        attrs := odtAttr("style:name", cssClassName(id)) + odtAttr("style:display-name", id) + odtAttr("style:family", family)
+        if parent := r.namedStyle(style.basedOn, style.kind); parent != "" {
+            attrs += odtAttr("style:parent-style-name", parent)
+        }
+        properties := odtTextProperties(style.run)
+        if family == "paragraph" {
+            properties = odtParagraphProperties(style.paragraph) + properties
+            if level := headingLevel(id); level > 0 {
+                attrs += odtAttr("style:default-outline-level", strconv.Itoa(level))
+            }
+        }
+        sb.WriteString("<style:style" + attrs + ">" + properties + "</style:style>\n")
+    }
+    return sb.String()
+}
+
+
+func (r *odtRenderer) namedStyle(id string, kind string) string {
+    if style, ok := r.sheet.styles[id]; ok && style.kind == kind {
+        return cssClassName(id)
+    }
+    return ""
+}
@@ -0,0 +1,140 @@
+package docx
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/base64"
+    "encoding/xml"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+const exportHeading = `Q&A <draft> "v1" {braces} \ back`
+
+
+func newExportDocument(t *testing.T) *DocxDocument {
+    t.Helper()
+    png, err := base64.StdEncoding.DecodeString(testPNG)
+    if err != nil {
+        t.Fatal(err)
+    }
+    image := filepath.Join(t.TempDir(), "dot.png")
+    if err := os.WriteFile(image, png, 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, exportHeading)
+    para := doc.AddParagraph(StyleNormal)
+    para.AddRun("Bold ", FormatBold)
+    para.AddRun("café ✓ 😀")
+    para.AddTab()
+    para.AddRun("tabbed")
+    doc.AddHyperlink(StyleNormal, "link & more", "https://example.com/?a=1&b=2")
+    list := doc.AddList(true)
+    for level, text := range []string{"first", "nested"} {
+        item, err := list.AddItem(level)
+        if err != nil {
+            t.Fatal(err)
+        }
+        item.AddRun(text)
+    }
+    table := doc.AddTable(2, 2)
+    table.Rows()[0].SetHeader(true)
+    table.Cell(0, 0).AddText(StyleNormal, "cell <1>")
+    table.Cell(1, 1).AddText(StyleNormal, "cell & 4")
+    if err := doc.AddImage(image); err != nil {
+        t.Fatal(err)
+    }
+    return doc
+}
+
+
+func xmlText(t *testing.T, name string, data []byte) string {
+    t.Helper()
+    var sb strings.Builder
+    decoder := xml.NewDecoder(bytes.NewReader(data))
+    for {
+        token, err := decoder.Token()
+        if err == io.EOF {
+            return sb.String()
+        }
+        if err != nil {
+            t.Fatalf("%s is not well-formed XML: %v", name, err)
+        }
+        if text, ok := token.(xml.CharData); ok {
+            sb.Write(text)
+        }
+    }
+}
+
+
+func TestODTWriterWritesWellFormedPackage(t *testing.T) {
+    var buf bytes.Buffer
+    if err := NewODTWriter().WriteODT(&buf, newExportDocument(t)); err != nil {
+        t.Fatal(err)
+    }
+    reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    first := reader.File[0]
+    if first.Name != "mimetype" || first.Method != zip.Store {
+        t.Fatalf("first entry is %q (method %d), want an uncompressed mimetype", first.Name, first.Method)
+    }
+    parts := make(map[string][]byte)
+    for _, f := range reader.File {
+        rc, err := f.Open()
+        if err != nil {
+            t.Fatal(err)
+        }
+        data, err := io.ReadAll(rc)
+        rc.Close()
+        if err != nil {
+            t.Fatal(err)
+        }
+        parts[f.Name] = data
+    }
+    if string(parts["mimetype"]) != odtMimeType {
+        t.Errorf("mimetype = %q", parts["mimetype"])
+    }
+
+    texts := make(map[string]string)
+    for name, data := range parts {
+        if strings.HasSuffix(name, ".xml") {
+            texts[name] = xmlText(t, name, data)
+        }
+    }
+    for _, name := range []string{"content.xml", "styles.xml", "meta.xml", "META-INF/manifest.xml"} {
+        if _, ok := texts[name]; !ok {
+            t.Errorf("package has no %s", name)
+        }
+    }
+    for name := range parts {
+        if name != "mimetype" && name != "META-INF/manifest.xml" && !bytes.Contains(parts["META-INF/manifest.xml"], []byte(`manifest:full-path="`+name+`"`)) {
+            t.Errorf("manifest does not list %s", name)
+        }
+    }
+
+    pictures := 0
+    for name := range parts {
+        if strings.HasPrefix(name, "Pictures/") {
+            pictures++
+        }
+    }
+    if pictures != 1 {
+        t.Errorf("package has %d pictures, want 1", pictures)
+    }
+    for _, want := range []string{exportHeading, "Bold café ✓ 😀", "tabbed", "link & more", "first", "nested", "cell <1>", "cell & 4"} {
+        if !strings.Contains(texts["content.xml"], want) {
+            t.Errorf("content.xml text does not contain %q", want)
+        }
+    }
+    if !bytes.Contains(parts["content.xml"], []byte(`xlink:href="https://example.com/?a=1&amp;b=2"`)) {
+        t.Error("content.xml does not link to the escaped hyperlink target")
+    }
+}
@@ -0,0 +1,339 @@
+package docx
+
+import (
+    "fmt"
+    "math"
+    "strings"
+)
+
+const (
+    AlignLeft    = "left"
+    AlignCenter  = "center"
+    AlignRight   = "right"
+    AlignJustify = "both"
+)
+
+
+type Paragraph struct {
+    doc  *DocxDocument
+    data *paragraphData
+}
+
+
+type Run struct {
+    data *paragraphRun
+}
+
+
+func (d *DocxDocument) AddParagraph(style string) *Paragraph {
+    para := &paragraphData{
+        Properties: newParagraphProperties(style),
+        Content:    d.takePendingBookmarks(),
+    }
+    d.content = append(d.content, para)
+    return &Paragraph{doc: d, data: para}
+}
+
+
+func (p *Paragraph) appendRun(run *paragraphRun) *Run {
+    p.append(run)
+    return &Run{data: run}
+}
+
+
+func (p *Paragraph) append(items ...interface{}) {
+    items = append(p.doc.takePendingBookmarks(), items...)
+    content := p.data.Content
+    if n := len(content); n > 0 {
+        if end, ok := content[n-1].(*permissionEnd); ok {
+            content = append(content[:n-1:n-1], items...)
+            p.data.Content = append(content, end)
+            return
+        }
+    }
+    p.data.Content = append(content, items...)
+}
+
+
+func (p *Paragraph) AddRun(text string, formatOptions ...string) *Run {
+    return p.appendRun(&paragraphRun{
+        Properties: newRunProperties(formatOptions),
+        Text:       &paragraphRunText{Text: text, Space: "preserve"},
+    })
+}
+
+
+func (p *Paragraph) AddImage(filePath string) (*Run, error) {
+    imgRun, err := p.doc.newImageRun(filePath)
+    if err != nil {
+        return nil, err
+    }
+    return p.appendRun(imgRun), nil
+}
+
+
+func (p *Paragraph) AddBreak(breakType string) (*Run, error) {
+    switch breakType {
+    case "", BreakPage, BreakColumn, BreakTextWrapping:
+    default:
+        return nil, fmt.Errorf("unsupported break type: %s", breakType)
+    }
+    return p.appendRun(&paragraphRun{Break: &runBreak{Type: breakType}}), nil
+}
+
+
+func (p *Paragraph) AddTab() *Run {
+    return p.appendRun(&paragraphRun{Tab: &runTab{}})
+}
+
+
+func (p *Paragraph) AddField(instruction string, result string, formatOptions ...string) *Run {
+    runs := newFieldRuns(strings.TrimSpace(instruction), result, newRunProperties(formatOptions))
+    if result == "" {
+        runs[0].FieldChar.Dirty = "true"
+    }
+
+    p.append(runsToContent(runs)...)
+    return &Run{data: runs[3]}
+}
+
+
+func (p *Paragraph) properties() *paragraphProperties {
+    if p.data.Properties == nil {
+        p.data.Properties = &paragraphProperties{}
+    }
+    return p.data.Properties
+}
+
+
+func (p *Paragraph) Style() string {
+    if p.data.Properties == nil || p.data.Properties.Style == nil {
+        return StyleNormal
+    }
+    return p.data.Properties.Style.Val
+}
+
+
+func (p *Paragraph) SetStyle(style string) {
+    props := newParagraphProperties(style)
+    if props == nil || props.Style == nil {
+        if p.data.Properties != nil {
+            p.data.Properties.Style = nil
+        }
+        return
+    }
+    p.properties().Style = props.Style
+}
+
+
+func (p *Paragraph) SetAlignment(alignment string) error {
+    switch alignment {
+    case "":
+        if p.data.Properties != nil {
+            p.data.Properties.Justification = nil
+        }
+        return nil
+    case AlignLeft, AlignCenter, AlignRight, AlignJustify:
+        p.properties().Justification = &justification{Val: alignment}
+        return nil
+    default:
+        return fmt.Errorf("unsupported paragraph alignment: %s", alignment)
+    }
+}
+
+
+func (p *Paragraph) SetTabStops(stops ...TabStop) error {
+    tabs, err := newTabStops(stops)
+    if err != nil {
+        return err
+    }
+    p.properties().Tabs = tabs
+    return nil
+}
+
+
+func (p *Paragraph) SetKeepNext(keep bool) {
+    if keep {
+        p.properties().KeepNext = &onOffProperty{}
+    } else if p.data.Properties != nil {
+        p.data.Properties.KeepNext = nil
+    }
+}
+
+
+func (p *Paragraph) Runs() []*Run {
+    runs := []*Run{}
+    for _, r := range p.data.runs() {
+        runs = append(runs, &Run{data: r})
+    }
+    return runs
+}
+
+
+func (p *Paragraph) Text() string {
+    return p.data.text()
+}
+
+
+func (r *Run) properties() *runProperties {
+    if r.data.Properties == nil {
+        r.data.Properties = &runProperties{}
+    }
+    return r.data.Properties
+}
+
+
+func (r *Run) Text() string {
+    if r.data.Text == nil {
+        return ""
+    }
+    return r.data.Text.Text
+}
+
+
+func (r *Run) SetText(text string) {
+    if r.data.Text == nil {
+        r.data.Text = &paragraphRunText{Space: "preserve"}
+    }
+    r.data.Text.Text = text
+}
+
+
+func (r *Run) Bold() bool {
+    return r.data.Properties != nil && r.data.Properties.Bold != nil
+}
+
+
+func (r *Run) SetBold(bold bool) {
+    if bold {
+        r.properties().Bold = &boldProperty{}
+    } else if r.data.Properties != nil {
+        r.data.Properties.Bold = nil
+    }
+}
+
+
+func (r *Run) Italic() bool {
+    return r.data.Properties != nil && r.data.Properties.Italic != nil
+}
+
+
+func (r *Run) SetItalic(italic bool) {
+    if italic {
+        r.properties().Italic = &italicProperty{}
+    } else if r.data.Properties != nil {
+        r.data.Properties.Italic = nil
+    }
+}
+
+
+func (r *Run) Underline() bool {
+    return r.data.Properties != nil && r.data.Properties.Underline != nil && r.data.Properties.Underline.Val != "none"
+}
+
+
+func (r *Run) SetUnderline(underline bool) {
+    if underline {
+        r.properties().Underline = &underlineProperty{Val: "single"}
+    } else if r.data.Properties != nil {
+        r.data.Properties.Underline = nil
+    }
+}
+
+
+func (r *Run) Strike() bool {
+    return r.data.Properties != nil && r.data.Properties.Strike != nil
+}
+
+
+func (r *Run) SetStrike(strike bool) {
+    if strike {
+        r.properties().Strike = &onOffProperty{}
+    } else if r.data.Properties != nil {
+        r.data.Properties.Strike = nil
+    }
+}
+
+
+func (r *Run) Color() string {
+    if r.data.Properties == nil || r.data.Properties.Color == nil {
+        return ""
+    }
+    return r.data.Properties.Color.Val
+}
+
+
+func (r *Run) SetColor(hexColor string) error {
+    hexColor = strings.TrimPrefix(hexColor, "#")
+    if hexColor == "" {
+        if r.data.Properties != nil {
+            r.data.Properties.Color = nil
+        }
+        return nil
+    }
+    if len(hexColor) != 6 || strings.Trim(strings.ToUpper(hexColor), "0123456789ABCDEF") != "" {
+        return fmt.Errorf("invalid run color %q: expected six hex digits", hexColor)
+    }
+    r.properties().Color = &colorProperty{Val: strings.ToUpper(hexColor)}
+    return nil
+}
+
+
+func (r *Run) FontSize() float64 {
+    if r.data.Properties == nil || r.data.Properties.Size == nil {
+        return 0
+    }
+    return float64(r.data.Properties.Size.Val) / 2
+}
+
+
+func (r *Run) SetFontSize(points float64) {
+    if points <= 0 {
+        if r.data.Properties != nil {
+            r.data.Properties.Size = nil
+            r.data.Properties.SizeCs = nil
+        }
+        return
+    }
+    halfPoints := int(math.Round(points * 2))
+    r.properties().Size = &halfPointProperty{Val: halfPoints}
+    r.properties().SizeCs = &halfPointProperty{Val: halfPoints}
+}
+
+
+func (r *Run) Font() string {
+    if r.data.Properties == nil || r.data.Properties.Fonts == nil {
+        return ""
+    }
+    return r.data.Properties.Fonts.ASCII
+}
+
+
+func (r *Run) SetFont(name string) {
+    if name == "" {
+        if r.data.Properties != nil {
+            r.data.Properties.Fonts = nil
+        }
+        return
+    }
+    r.properties().Fonts = &runFonts{ASCII: name, HAnsi: name, EastAsia: name, CS: name}
+}
+
+
+func (r *Run) Style() string {
+    if r.data.Properties == nil || r.data.Properties.Style == nil {
+        return ""
+    }
+    return r.data.Properties.Style.Val
+}
+
+
+func (r *Run) SetStyle(style string) {
+    if strings.TrimSpace(style) == "" {
+        if r.data.Properties != nil {
+            r.data.Properties.Style = nil
+        }
+        return
+    }
+    r.properties().Style = &runStyle{Val: style}
+}
@@ -0,0 +1,104 @@
+package docx
+
+import (
+    "testing"
+)
+
+
+func TestParagraphBoundaries(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "One")
+    doc.AddText(StyleNormal, " continues")
+    doc.AddParagraph(StyleNormal).AddRun("Two")
+    doc.AddParagraph(StyleNormal).AddRun("Three")
+    doc.AddParagraph(StyleNormal)
+    doc.AddText(StyleHeading1, "Four")
+
+    got := listParagraphTexts(reopenDocument(t, doc).paragraphs())
+    if want := []string{"One continues", "Two", "Three", "", "Four"}; !equalStrings(got, want) {
+        t.Errorf("paragraph texts = %q, want %q", got, want)
+    }
+}
+
+
+func TestParagraphAndRunHandles(t *testing.T) {
+    doc := NewDocxDocument()
+    p := doc.AddParagraph(StyleNormal)
+    if p.Style() != StyleNormal {
+        t.Errorf("Style() = %q", p.Style())
+    }
+    p.SetStyle(StyleHeading2)
+    if err := p.SetAlignment(AlignCenter); err != nil {
+        t.Fatal(err)
+    }
+    if err := p.SetAlignment("middle"); err == nil {
+        t.Error("SetAlignment accepted an unknown alignment")
+    }
+    title := p.AddRun("Quarterly report", FormatItalic)
+    title.SetBold(true)
+    title.SetUnderline(true)
+    title.SetStrike(true)
+    title.SetFontSize(14.5)
+    title.SetFont("Georgia")
+    title.SetStyle("Emphasis")
+    if err := title.SetColor("#1f4d78"); err != nil {
+        t.Fatal(err)
+    }
+    for _, color := range []string{"red", "12345", "GGGGGG"} {
+        if err := title.SetColor(color); err == nil {
+            t.Errorf("SetColor(%q) succeeded", color)
+        }
+    }
+    plain := p.AddRun(" draft")
+    plain.SetText(" final")
+    if p.Text() != "Quarterly report final" {
+        t.Errorf("Text() = %q", p.Text())
+    }
+
+    reopened := reopenDocument(t, doc)
+    blocks := reopened.Body().Blocks()
+    para, ok := blocks[0].(*Paragraph)
+    if !ok {
+        t.Fatalf("first block is %T", blocks[0])
+    }
+    if para.Style() != StyleHeading2 || para.Text() != "Quarterly report final" {
+        t.Errorf("paragraph = %q in %q", para.Text(), para.Style())
+    }
+    runs := para.Runs()
+    if len(runs) != 2 {
+        t.Fatalf("got %d runs, want 2", len(runs))
+    }
+    run := runs[0]
+    if !run.Bold() || !run.Italic() || !run.Underline() || !run.Strike() {
+        t.Error("run lost its bold, italic, underline or strike formatting")
+    }
+    if run.FontSize() != 14.5 || run.Font() != "Georgia" || run.Style() != "Emphasis" || run.Color() != "1F4D78" {
+        t.Errorf("run size %v, font %q, style %q, color %q", run.FontSize(), run.Font(), run.Style(), run.Color())
+    }
+    if other := runs[1]; other.Bold() || other.Italic() || other.Color() != "" || other.FontSize() != 0 || other.Font() != "" {
+        t.Error("plain run picked up formatting")
+    }
+
+    run.SetBold(false)
+    run.SetItalic(false)
+    run.SetUnderline(false)
+    run.SetStrike(false)
+    run.SetFontSize(0)
+    run.SetFont("")
+    run.SetStyle(" ")
+    if err := run.SetColor(""); err != nil {
+        t.Fatal(err)
+    }
+    para.SetStyle(StyleNormal)
+    if err := para.SetAlignment(""); err != nil {
+        t.Fatal(err)
+    }
+    cleared := reopenDocument(t, reopened).Body().Blocks()[0].(*Paragraph)
+    run = cleared.Runs()[0]
+    if run.Bold() || run.Italic() || run.Underline() || run.Strike() || run.FontSize() != 0 || run.Font() != "" || run.Style() != "" || run.Color() != "" {
+        t.Error("cleared run still has formatting")
+    }
+    if cleared.Style() != StyleNormal {
+        t.Errorf("Style() after SetStyle(StyleNormal) = %q", cleared.Style())
+    }
+}
@@ -0,0 +1,109 @@
+package docx
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+
+type PDFWriter struct {
+    Title string
+}
+
+
+func NewPDFWriter() *PDFWriter {
+    return &PDFWriter{}
+}
+
+
+func (pw *PDFWriter) WriteDocument(filename string, doc Document) error {
+    var buf bytes.Buffer
+    if err := pw.WritePDF(&buf, doc); err != nil {
+        return err
+    }
+
+    err := os.WriteFile(filename, buf.Bytes(), 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+    return nil
+}
+
+
+func (pw *PDFWriter) WritePDF(w io.Writer, doc Document) error {
+    layout := newPDFLayout(doc)
+    layout.layout()
+    if layout.err != nil {
+        return layout.err
+    }
+
+    title := pw.Title
+    if title == "" {
+        title = layout.title
+    }
+    return layout.write(w, title)
+}
+
+
+
+
+func (l *pdfLayout) write(w io.Writer, title string) error {
+    f := l.file
+    catalog, pages, resources := f.reserve(), f.reserve(), f.reserve()
+    for _, page := range l.pages {
+        page.id = f.reserve()
+    }
+
+    kids := []string{}
+    for _, page := range l.pages {
+        content, err := f.addStream("", []byte(page.content.String()), true)
+        if err != nil {
+            return err
+        }
+        annots := []string{}
+        for _, link := range page.links {
+            rect := "/Rect [" + pdfNumber(link.x1) + " " + pdfNumber(page.height-link.y2) + " " + pdfNumber(link.x2) + " " +
+                pdfNumber(page.height-link.y1) + "] /Border [0 0 0]"
+            if strings.HasPrefix(link.target, "#") {
+                dest, ok := l.dests[strings.TrimPrefix(link.target, "#")]
+                if !ok {
+                    continue
+                }
+                rect += fmt.Sprintf(" /Dest [%d 0 R /XYZ 0 %s null]", dest.page.id, pdfNumber(dest.page.height-dest.y))
+            } else {
+                rect += " /A << /S /URI /URI " + pdfEscapeString([]byte(link.target)) + " >>"
+            }
+            annots = append(annots, strconv.Itoa(f.add("<< /Type /Annot /Subtype /Link "+rect+" >>"))+" 0 R")
+        }
+
+        dict := fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R",
+            pages, pdfNumber(page.width), pdfNumber(page.height), resources, content)
+        if len(annots) > 0 {
+            dict += " /Annots [" + strings.Join(annots, " ") + "]"
+        }
+        f.set(page.id, []byte(dict+" >>"))
+        kids = append(kids, strconv.Itoa(page.id)+" 0 R")
+    }
+
+    var res strings.Builder
+    res.WriteString("<< /ProcSet [/PDF /Text /ImageB /ImageC] /Font <<")
+    for _, name := range l.fontOrder {
+        id := f.add("<< /Type /Font /Subtype /Type1 /BaseFont /" + name + " /Encoding /WinAnsiEncoding >>")
+        res.WriteString(fmt.Sprintf(" /%s %d 0 R", l.fonts[name], id))
+    }
+    res.WriteString(" >> /XObject <<")
+    for _, img := range l.imageOrder {
+        res.WriteString(fmt.Sprintf(" /%s %d 0 R", img.name, img.id))
+    }
+    res.WriteString(" >> >>")
+    f.set(resources, []byte(res.String()))
+
+    f.set(pages, []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))))
+    f.set(catalog, []byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages)))
+    info := f.add("<< /Producer (github.com/jonelmawirat/docx) /Title " + pdfTextString(title) + " >>")
+    return f.write(w, catalog, info)
+}
@@ -0,0 +1,182 @@
+package docx
+
+import (
+    "bufio"
+    "bytes"
+    "compress/zlib"
+    "fmt"
+    "image"
+    "image/color"
+    "io"
+    "strconv"
+    "strings"
+    "unicode/utf16"
+)
+
+
+type pdfFile struct {
+    objects [][]byte
+}
+
+
+type pdfImage struct {
+    id     int
+    name   string
+    width  int
+    height int
+}
+
+
+func (f *pdfFile) reserve() int {
+    f.objects = append(f.objects, nil)
+    return len(f.objects)
+}
+
+
+func (f *pdfFile) set(id int, data []byte) {
+    f.objects[id-1] = data
+}
+
+
+func (f *pdfFile) add(data string) int {
+    id := f.reserve()
+    f.set(id, []byte(data))
+    return id
+}
+
+
+
+
+func (f *pdfFile) addStream(dict string, data []byte, compress bool) (int, error) {
+    if compress {
+        var buf bytes.Buffer
+        zw := zlib.NewWriter(&buf)
+        if _, err := zw.Write(data); err != nil {
+            return 0, fmt.Errorf("failed to compress pdf stream: %w", err)
+        }
+        if err := zw.Close(); err != nil {
+            return 0, fmt.Errorf("failed to compress pdf stream: %w", err)
+        }
+        data = buf.Bytes()
+        dict += " /Filter /FlateDecode"
+    }
+    var obj bytes.Buffer
+    obj.WriteString("<< " + dict + " /Length " + strconv.Itoa(len(data)) + " >>\nstream\n")
+    obj.Write(data)
+    obj.WriteString("\nendstream")
+    id := f.reserve()
+    f.set(id, obj.Bytes())
+    return id, nil
+}
+
+
+func (f *pdfFile) addImage(data []byte, name string) (*pdfImage, error) {
+    config, format, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode image %s: %w", name, err)
+    }
+    img := &pdfImage{width: config.Width, height: config.Height}
+    size := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", config.Width, config.Height)
+
+    if format == "jpeg" {
+        colorSpace := "/DeviceRGB"
+        switch config.ColorModel {
+        case color.GrayModel:
+            colorSpace = "/DeviceGray"
+        case color.CMYKModel:
+            colorSpace = "/DeviceCMYK /Decode [1 0 1 0 1 0 1 0]"
+        }
+        img.id, err = f.addStream(size+" /ColorSpace "+colorSpace+" /Filter /DCTDecode", data, false)
+        return img, err
+    }
+
+    decoded, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode image %s: %w", name, err)
+    }
+    bounds := decoded.Bounds()
+    rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+    alpha := make([]byte, 0, bounds.Dx()*bounds.Dy())
+    transparent := false
+    for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+        for x := bounds.Min.X; x < bounds.Max.X; x++ {
+            c := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+            rgb = append(rgb, c.R, c.G, c.B)
+            alpha = append(alpha, c.A)
+            transparent = transparent || c.A != 0xFF
+        }
+    }
+
+    dict := size + " /ColorSpace /DeviceRGB"
+    if transparent {
+        mask, err := f.addStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8 /ColorSpace /DeviceGray",
+            bounds.Dx(), bounds.Dy()), alpha, true)
+        if err != nil {
+            return nil, err
+        }
+        dict += fmt.Sprintf(" /SMask %d 0 R", mask)
+    }
+    img.id, err = f.addStream(dict, rgb, true)
+    return img, err
+}
+
+
+func (f *pdfFile) write(w io.Writer, root int, info int) error {
+    bw := bufio.NewWriter(w)
+    offset := 0
+    write := func(s string) {
+        n, _ := bw.WriteString(s)
+        offset += n
+    }
+
+    write("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+    offsets := make([]int, len(f.objects))
+    for i, obj := range f.objects {
+        offsets[i] = offset
+        write(strconv.Itoa(i+1) + " 0 obj\n")
+        n, _ := bw.Write(obj)
+        offset += n
+        write("\nendobj\n")
+    }
+
+    xref := offset
+    var sb strings.Builder
+    sb.WriteString("xref\n0 " + strconv.Itoa(len(f.objects)+1) + "\n0000000000 65535 f \n")
+    for _, o := range offsets {
+        sb.WriteString(fmt.Sprintf("%010d 00000 n \n", o))
+    }
+    sb.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(f.objects)+1, root, info, xref))
+    write(sb.String())
+
+    if err := bw.Flush(); err != nil {
+        return fmt.Errorf("failed to write pdf: %w", err)
+    }
+    return nil
+}
+
+
+func pdfTextString(text string) string {
+    ascii := true
+    for _, r := range text {
+        ascii = ascii && r < 0x80
+    }
+    if ascii {
+        return pdfEscapeString([]byte(text))
+    }
+    var sb strings.Builder
+    sb.WriteString("<FEFF")
+    for _, unit := range utf16.Encode([]rune(text)) {
+        sb.WriteString(fmt.Sprintf("%04X", unit))
+    }
+    sb.WriteString(">")
+    return sb.String()
+}
+
+
+func pdfNumber(n float64) string {
+    s := strings.TrimRight(strings.TrimRight(strconv.FormatFloat(n, 'f', 2, 64), "0"), ".")
+    if s == "-0" {
+        return "0"
+    }
+    return s
+}
@@ -0,0 +1,219 @@
+package docx
+
+import "strings"
+
+
+type pdfFont struct {
+    name    string
+    widths  []int
+    high    []int
+    fixed   int
+    ascent  float64
+    descent float64
+}
+
+
+var (
+    helveticaWidths = []int{
+        278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+        556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+        1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+        667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+        333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+        556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+    }
+    helveticaBoldWidths = []int{
+        278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+        556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+        975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+        667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+        333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+        611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+    }
+    timesWidths = []int{
+        250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+        500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+        921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+        556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+        333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+        500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541,
+    }
+    timesBoldWidths = []int{
+        250, 333, 555, 500, 500, 1000, 833, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+        500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+        930, 722, 667, 722, 722, 667, 611, 778, 778, 389, 500, 778, 667, 944, 722, 778,
+        611, 778, 722, 556, 667, 722, 722, 1000, 722, 722, 667, 333, 278, 333, 581, 500,
+        333, 500, 556, 444, 556, 444, 333, 500, 556, 278, 333, 556, 278, 833, 556, 500,
+        556, 556, 444, 389, 333, 556, 500, 722, 500, 500, 444, 394, 220, 394, 520,
+    }
+    timesItalicWidths = []int{
+        250, 333, 420, 500, 500, 833, 778, 214, 333, 333, 500, 675, 250, 333, 250, 278,
+        500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 675, 675, 675, 500,
+        920, 611, 611, 667, 722, 611, 611, 722, 722, 333, 444, 667, 556, 833, 667, 722,
+        611, 722, 611, 500, 556, 722, 611, 833, 611, 556, 556, 389, 278, 389, 422, 500,
+        333, 500, 500, 444, 500, 444, 278, 500, 500, 278, 278, 444, 278, 722, 500, 500,
+        500, 500, 389, 389, 278, 500, 444, 667, 444, 444, 389, 400, 275, 400, 541,
+    }
+    timesBoldItalicWidths = []int{
+        250, 389, 555, 500, 500, 833, 778, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+        500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+        832, 667, 667, 667, 722, 667, 667, 722, 778, 389, 500, 667, 611, 889, 722, 722,
+        611, 722, 667, 556, 611, 722, 667, 889, 667, 611, 611, 333, 278, 333, 570, 500,
+        333, 500, 500, 444, 500, 444, 333, 500, 556, 278, 278, 500, 278, 778, 556, 500,
+        500, 500, 389, 389, 278, 556, 444, 667, 500, 444, 389, 348, 220, 348, 570,
+    }
+
+
+
+
+    helveticaHighWidths = []int{
+        556, 0, 222, 556, 333, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+        0, 222, 222, 333, 333, 350, 556, 1000, 333, 1000, 500, 333, 944, 0, 500, 667,
+        278, 333, 556, 556, 556, 556, 260, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+        400, 584, 333, 333, 333, 556, 537, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+        667, 667, 667, 667, 667, 667, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+        722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+        556, 556, 556, 556, 556, 556, 889, 500, 556, 556, 556, 556, 278, 278, 278, 278,
+        556, 556, 556, 556, 556, 556, 556, 584, 611, 556, 556, 556, 556, 500, 556, 500,
+    }
+    timesHighWidths = []int{
+        500, 0, 333, 500, 444, 1000, 500, 500, 333, 1000, 556, 333, 889, 0, 611, 0,
+        0, 333, 333, 444, 444, 350, 500, 1000, 333, 980, 389, 333, 722, 0, 444, 722,
+        250, 333, 500, 500, 500, 500, 200, 500, 333, 760, 276, 500, 564, 333, 760, 333,
+        400, 564, 300, 300, 333, 500, 453, 250, 333, 300, 310, 500, 750, 750, 750, 444,
+        722, 722, 722, 722, 722, 722, 889, 667, 611, 611, 611, 611, 333, 333, 333, 333,
+        722, 722, 722, 722, 722, 722, 722, 564, 722, 722, 722, 722, 722, 722, 556, 500,
+        444, 444, 444, 444, 444, 444, 667, 444, 444, 444, 444, 444, 278, 278, 278, 278,
+        500, 500, 500, 500, 500, 500, 500, 564, 500, 500, 500, 500, 500, 500, 500, 500,
+    }
+)
+
+
+var pdfFonts = map[string]*pdfFont{
+    "Helvetica":             {name: "Helvetica", widths: helveticaWidths, high: helveticaHighWidths, ascent: 0.718, descent: 0.207},
+    "Helvetica-Bold":        {name: "Helvetica-Bold", widths: helveticaBoldWidths, high: helveticaHighWidths, ascent: 0.718, descent: 0.207},
+    "Helvetica-Oblique":     {name: "Helvetica-Oblique", widths: helveticaWidths, high: helveticaHighWidths, ascent: 0.718, descent: 0.207},
+    "Helvetica-BoldOblique": {name: "Helvetica-BoldOblique", widths: helveticaBoldWidths, high: helveticaHighWidths, ascent: 0.718, descent: 0.207},
+    "Times-Roman":           {name: "Times-Roman", widths: timesWidths, high: timesHighWidths, ascent: 0.683, descent: 0.217},
+    "Times-Bold":            {name: "Times-Bold", widths: timesBoldWidths, high: timesHighWidths, ascent: 0.683, descent: 0.217},
+    "Times-Italic":          {name: "Times-Italic", widths: timesItalicWidths, high: timesHighWidths, ascent: 0.683, descent: 0.217},
+    "Times-BoldItalic":      {name: "Times-BoldItalic", widths: timesBoldItalicWidths, high: timesHighWidths, ascent: 0.683, descent: 0.217},
+    "Courier":               {name: "Courier", fixed: 600, ascent: 0.629, descent: 0.157},
+    "Courier-Bold":          {name: "Courier-Bold", fixed: 600, ascent: 0.629, descent: 0.157},
+    "Courier-Oblique":       {name: "Courier-Oblique", fixed: 600, ascent: 0.629, descent: 0.157},
+    "Courier-BoldOblique":   {name: "Courier-BoldOblique", fixed: 600, ascent: 0.629, descent: 0.157},
+}
+
+
+var winAnsiHigh = map[rune]byte{
+    '€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89,
+    'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95,
+    '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+    '▪': 0x95, '■': 0x95, '●': 0x95, '◦': 0x6F, '○': 0x6F, '‐': 0x2D, '‑': 0x2D, '−': 0x2D,
+    '\u00A0': 0xA0, '\u2002': 0x20, '\u2003': 0x20, '\u2009': 0x20, '\u200B': 0, '\uF0B7': 0x95, '\uF0A7': 0x95,
+}
+
+
+const latin1BaseLetters = "AAAAAA\x00CEEEEIIIIDNOOOOO\x00OUUUUY\x00\x00aaaaaa\x00ceeeeiiiidnooooo\x00ouuuuy\x00y"
+
+
+
+
+func pdfFontName(font string, bold bool, italic bool) string {
+    switch genericFontFamily(font) {
+    case "monospace":
+        return "Courier" + pdfFontSuffix(bold, italic, "Oblique")
+    case "serif":
+        switch {
+        case bold && italic:
+            return "Times-BoldItalic"
+        case bold:
+            return "Times-Bold"
+        case italic:
+            return "Times-Italic"
+        }
+        return "Times-Roman"
+    }
+    return "Helvetica" + pdfFontSuffix(bold, italic, "Oblique")
+}
+
+
+func pdfFontSuffix(bold bool, italic bool, slant string) string {
+    switch {
+    case bold && italic:
+        return "-Bold" + slant
+    case bold:
+        return "-Bold"
+    case italic:
+        return "-" + slant
+    }
+    return ""
+}
+
+
+
+
+func encodeWinAnsi(text string) []byte {
+    encoded := make([]byte, 0, len(text))
+    for _, r := range text {
+        switch {
+        case r >= 0x20 && r < 0x7F, r >= 0xA1 && r <= 0xFF:
+            encoded = append(encoded, byte(r))
+        case r == '\t':
+            encoded = append(encoded, ' ')
+        default:
+            if b, ok := winAnsiHigh[r]; ok {
+                if b != 0 {
+                    encoded = append(encoded, b)
+                }
+                continue
+            }
+            encoded = append(encoded, '?')
+        }
+    }
+    return encoded
+}
+
+
+func (f *pdfFont) charWidth(c byte) int {
+    if f.fixed > 0 {
+        return f.fixed
+    }
+    switch {
+    case c >= 0x20 && c < 0x7F:
+        return f.widths[c-0x20]
+    case c >= 0xC0 && latin1BaseLetters[c-0xC0] != 0:
+        return f.widths[latin1BaseLetters[c-0xC0]-0x20]
+    case c >= 0x80:
+        if w := f.high[c-0x80]; w > 0 {
+            return w
+        }
+    }
+    return f.widths[0]
+}
+
+
+func (f *pdfFont) textWidth(encoded []byte, size float64) float64 {
+    total := 0
+    for _, c := range encoded {
+        total += f.charWidth(c)
+    }
+    return float64(total) * size / 1000
+}
+
+
+func pdfEscapeString(encoded []byte) string {
+    var sb strings.Builder
+    sb.WriteByte('(')
+    for _, c := range encoded {
+        switch c {
+        case '(', ')', '\\':
+            sb.WriteByte('\\')
+            sb.WriteByte(c)
+        default:
+            sb.WriteByte(c)
+        }
+    }
+    sb.WriteByte(')')
+    return sb.String()
+}
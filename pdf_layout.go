@@ -0,0 +1,1467 @@
+package docx
+
+import (
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+const (
+    pdfDefaultTabStop = 36.0
+    pdfLineHeight     = 1.17
+    pdfTextAscent     = 0.9
+    pdfCellMargin     = 5.4
+    pdfEmusPerPoint   = 12700
+)
+
+
+const (
+    pdfPieceText = iota
+    pdfPieceSpace
+    pdfPieceTab
+    pdfPieceBreak
+    pdfPiecePageBreak
+    pdfPieceImage
+    pdfPieceAnchor
+)
+
+
+type pdfTextStyle struct {
+    font      *pdfFont
+    size      float64
+    color     string
+    highlight string
+    underline bool
+    strike    bool
+    caps      bool
+    rise      float64
+}
+
+
+type pdfPiece struct {
+    kind   int
+    text   []byte
+    style  pdfTextStyle
+    x      float64
+    width  float64
+    height float64
+    leader string
+    link   string
+    anchor string
+    rID    string
+    part   *documentPart
+}
+
+
+type pdfLine struct {
+    pieces    []*pdfPiece
+    height    float64
+    baseline  float64
+    pageBreak bool
+}
+
+
+type pdfBox struct {
+    height      float64
+    spaceBefore float64
+    spaceAfter  float64
+    keepNext    bool
+    keep        float64
+    pageBreak   bool
+    repeat      []*pdfBox
+    draw        func(page *pdfPage, x float64, y float64)
+    split       func(avail float64, force bool) (*pdfBox, *pdfBox)
+}
+
+
+type pdfTabStop struct {
+    pos    float64
+    align  string
+    leader string
+}
+
+
+type pdfParagraphFormat struct {
+    style           string
+    width           float64
+    left            float64
+    right           float64
+    firstLine       float64
+    before          float64
+    after           float64
+    line            float64
+    lineRule        string
+    align           string
+    tabs            []pdfTabStop
+    keepNext        bool
+    keepLines       bool
+    pageBreakBefore bool
+    contextual      bool
+    props           styleProperties
+    run             styleProperties
+    mark            pdfTextStyle
+}
+
+
+type pdfContext struct {
+    width     float64
+    paragraph styleProperties
+    run       styleProperties
+}
+
+
+type pdfSection struct {
+    pageSetup
+    titlePage bool
+    started   bool
+    parts     map[string]*documentPart
+}
+
+
+type pdfPage struct {
+    section *pdfSection
+    width   float64
+    height  float64
+    first   bool
+    content strings.Builder
+    links   []pdfLink
+    id      int
+}
+
+
+type pdfLink struct {
+    x1     float64
+    y1     float64
+    x2     float64
+    y2     float64
+    target string
+}
+
+
+type pdfDest struct {
+    page *pdfPage
+    y    float64
+}
+
+
+type pdfFieldState struct {
+    depth     int
+    instr     string
+    separated bool
+    skip      bool
+}
+
+
+type pdfCell struct {
+    data   *tableCellData
+    col    int
+    span   int
+    merge  string
+    rows   int
+    x      float64
+    width  float64
+    boxes  []*pdfBox
+    height float64
+}
+
+
+type pdfLayout struct {
+    doc        Document
+    sheet      *styleSheet
+    file       *pdfFile
+    fonts      map[string]string
+    fontOrder  []string
+    images     map[string]*pdfImage
+    imageOrder []*pdfImage
+    parts      map[string]*documentPart
+    counters   map[int][]int
+    pages      []*pdfPage
+    page       *pdfPage
+    section    *pdfSection
+    y          float64
+    atTop      bool
+    dests      map[string]pdfDest
+    part       *documentPart
+    pageNumber int
+    pageCount  int
+    title      string
+    err        error
+}
+
+
+func newPDFLayout(doc Document) *pdfLayout {
+    return &pdfLayout{
+        doc:      doc,
+        sheet:    loadStyleSheet(doc),
+        file:     &pdfFile{},
+        fonts:    make(map[string]string),
+        images:   make(map[string]*pdfImage),
+        parts:    make(map[string]*documentPart),
+        counters: make(map[int][]int),
+        dests:    make(map[string]pdfDest),
+    }
+}
+
+
+
+
+func (l *pdfLayout) layout() {
+    var section *pdfSection
+    for _, s := range splitSections(l.doc.renderedContent(), l.doc.getSectionProperties()) {
+        section = l.newSection(s.props, section)
+        breakType := sectionBreakType(s.props)
+        if l.page != nil && breakType == SectionContinuous && l.page.width == section.width && l.page.height == section.height {
+            l.section = section
+            section.started = true
+        } else {
+            l.newPage(section)
+            if (breakType == SectionEvenPage && len(l.pages)%2 == 1) || (breakType == SectionOddPage && len(l.pages)%2 == 0) {
+                l.newPage(section)
+            }
+        }
+        l.place(l.blocks(s.blocks, pdfContext{width: section.width - section.left - section.right}))
+    }
+    l.decorate()
+}
+
+
+func (l *pdfLayout) newSection(props *sectPr, prev *pdfSection) *pdfSection {
+    s := &pdfSection{pageSetup: newPageSetup(props), parts: make(map[string]*documentPart)}
+    if prev != nil {
+        for kind, part := range prev.parts {
+            s.parts[kind] = part
+        }
+    }
+    if props == nil {
+        return s
+    }
+    for _, extra := range props.Extra {
+        switch extra.XMLName.Local {
+        case "w:headerReference", "w:footerReference":
+            kind := attrValue(extra, "w:type")
+            if kind == "" {
+                kind = "default"
+            }
+            if part := l.loadPart(attrValue(extra, "r:id")); part != nil {
+                s.parts[strings.TrimSuffix(strings.TrimPrefix(extra.XMLName.Local, "w:"), "Reference")+"-"+kind] = part
+            }
+        case "w:titlePg":
+            s.titlePage = isOn(extra)
+        }
+    }
+    return s
+}
+
+
+
+
+func (l *pdfLayout) loadPart(rID string) *documentPart {
+    if part, ok := l.parts[rID]; ok {
+        return part
+    }
+    part := loadDocumentPart(l.doc, rID)
+    l.parts[rID] = part
+    return part
+}
+
+
+func (l *pdfLayout) newPage(section *pdfSection) {
+    page := &pdfPage{section: section, width: section.width, height: section.height, first: !section.started}
+    section.started = true
+    l.pages = append(l.pages, page)
+    l.page = page
+    l.section = section
+    l.y = section.top
+    l.atTop = true
+}
+
+
+
+
+func (l *pdfLayout) place(boxes []*pdfBox) {
+    for i := len(boxes) - 2; i >= 0; i-- {
+        if boxes[i].keepNext {
+            next := boxes[i+1]
+            boxes[i].keep = next.spaceBefore + next.height + next.keep
+        }
+    }
+
+    for i := 0; i < len(boxes); i++ {
+        box := boxes[i]
+        if box.pageBreak && !l.atTop {
+            l.newPage(l.section)
+        }
+        before := box.spaceBefore
+        if l.atTop {
+            before = 0
+        }
+        bottom := l.section.height - l.section.bottom
+        keep := box.keep
+        if keep+box.height > bottom-l.section.top {
+            keep = 0
+        }
+        if !l.atTop && l.y+before+box.height+keep > bottom {
+            var first, rest *pdfBox
+            if box.split != nil && before+box.height > bottom-l.section.top {
+                first, rest = box.split(bottom-l.y-before, false)
+            }
+            if first == nil {
+                l.newPage(l.section)
+                before = 0
+                for _, header := range box.repeat {
+                    header.draw(l.page, l.section.left, l.y)
+                    l.y += header.height
+                }
+            } else {
+                box = first
+                boxes = insertBox(boxes, i+1, rest)
+            }
+        }
+        if l.atTop && box.split != nil && box.height > bottom-l.y {
+            if first, rest := box.split(bottom-l.y, true); first != nil {
+                box = first
+                boxes = insertBox(boxes, i+1, rest)
+            }
+        }
+        l.y += before
+        box.draw(l.page, l.section.left, l.y)
+        l.y += box.height + box.spaceAfter
+        l.atTop = false
+    }
+}
+
+
+func insertBox(boxes []*pdfBox, index int, box *pdfBox) []*pdfBox {
+    boxes = append(boxes, nil)
+    copy(boxes[index+1:], boxes[index:])
+    boxes[index] = box
+    return boxes
+}
+
+
+func (l *pdfLayout) decorate() {
+    l.pageCount = len(l.pages)
+    for i, page := range l.pages {
+        l.pageNumber = i + 1
+        s := page.section
+        kind := "default"
+        if page.first && s.titlePage {
+            kind = "first"
+        }
+        width := s.width - s.left - s.right
+
+        if part := s.parts["header-"+kind]; part != nil {
+            l.part = part
+            y := s.header
+            for _, box := range l.blocks(part.blocks, pdfContext{width: width}) {
+                y += box.spaceBefore
+                box.draw(page, s.left, y)
+                y += box.height + box.spaceAfter
+            }
+        }
+        if part := s.parts["footer-"+kind]; part != nil {
+            l.part = part
+            boxes := l.blocks(part.blocks, pdfContext{width: width})
+            y := s.height - s.footer - stackHeight(boxes)
+            for _, box := range boxes {
+                y += box.spaceBefore
+                box.draw(page, s.left, y)
+                y += box.height + box.spaceAfter
+            }
+        }
+    }
+    l.part = nil
+    l.pageNumber = 0
+}
+
+
+func stackHeight(boxes []*pdfBox) float64 {
+    height := 0.0
+    for _, box := range boxes {
+        height += box.spaceBefore + box.height + box.spaceAfter
+    }
+    return height
+}
+
+
+
+
+func (l *pdfLayout) blocks(content []interface{}, ctx pdfContext) []*pdfBox {
+    boxes := []*pdfBox{}
+    var prev *pdfParagraphFormat
+    for _, block := range content {
+        switch v := block.(type) {
+        case *paragraphData:
+            format := l.paragraphFormat(v, ctx)
+            if l.title == "" && l.pageNumber == 0 && headingLevel(format.style) > 0 {
+                l.title = strings.Join(strings.Fields(v.text()), " ")
+            }
+            para := l.paragraph(v, format)
+            if prev != nil && format.contextual && prev.contextual && prev.style == format.style && len(boxes) > 0 {
+                boxes[len(boxes)-1].spaceAfter = 0
+                para[0].spaceBefore = 0
+            }
+            boxes = append(boxes, para...)
+            prev = format
+        case *tableData:
+            boxes = append(boxes, l.table(v, ctx)...)
+            prev = nil
+        case *containerElement:
+            boxes = append(boxes, l.blocks(v.Content, ctx)...)
+            prev = nil
+        }
+    }
+    return boxes
+}
+
+
+func mergeProperties(dst styleProperties, src styleProperties) {
+    for k, v := range src {
+        dst[k] = v
+    }
+}
+
+
+func (l *pdfLayout) paragraphFormat(p *paragraphData, ctx pdfContext) *pdfParagraphFormat {
+    f := &pdfParagraphFormat{style: paragraphStyleID(p), width: ctx.width, props: styleProperties{}, run: styleProperties{}}
+    if f.style == "" {
+        f.style = l.sheet.defaultParagraph
+    }
+    mergeProperties(f.props, l.sheet.paragraphDefaults)
+    mergeProperties(f.run, l.sheet.runDefaults)
+    paragraph, run, _ := l.sheet.resolve(f.style)
+    if f.style == l.sheet.defaultParagraph {
+        mergeProperties(f.props, paragraph)
+        mergeProperties(f.run, run)
+        mergeProperties(f.props, ctx.paragraph)
+        mergeProperties(f.run, ctx.run)
+    } else {
+        mergeProperties(f.props, ctx.paragraph)
+        mergeProperties(f.run, ctx.run)
+        mergeProperties(f.props, paragraph)
+        mergeProperties(f.run, run)
+    }
+
+    if level, _, ok := l.doc.getListLevel(p); ok {
+        f.props["left"] = strconv.Itoa(720 * (level + 1))
+        f.props["hanging"] = "360"
+        delete(f.props, "firstLine")
+    }
+    direct := l.sheet.directParagraphProperties(p.Properties)
+    if direct["firstLine"] != "" && direct["hanging"] == "" {
+        delete(f.props, "hanging")
+    }
+    if direct["tabs"] != "" && f.props["tabs"] != "" {
+        direct["tabs"] = f.props["tabs"] + "," + direct["tabs"]
+    }
+    mergeProperties(f.props, direct)
+
+    f.left = pdfTwips(f.props["left"], 0)
+    f.right = pdfTwips(f.props["right"], 0)
+    f.firstLine = pdfTwips(f.props["firstLine"], 0)
+    if hanging := pdfTwips(f.props["hanging"], 0); hanging != 0 {
+        f.firstLine = -hanging
+    }
+    f.before = pdfTwips(f.props["before"], 0)
+    f.after = pdfTwips(f.props["after"], 0)
+    f.line, f.lineRule = 240, f.props["lineRule"]
+    if n, err := strconv.ParseFloat(f.props["line"], 64); err == nil && n > 0 {
+        f.line = n
+    }
+    if f.lineRule == "exact" || f.lineRule == "atLeast" {
+        f.line /= 20
+    }
+    f.align = f.props["align"]
+    f.tabs = pdfTabStops(f.props["tabs"])
+    f.keepNext = f.props["keepNext"] == "true"
+    f.keepLines = f.props["keepLines"] == "true"
+    f.pageBreakBefore = f.props["pageBreakBefore"] == "true"
+    f.contextual = f.props["contextualSpacing"] == "true"
+    f.mark = pdfTextStyleOf(f.run)
+    return f
+}
+
+
+func pdfTwips(value string, fallback float64) float64 {
+    n, err := strconv.ParseFloat(value, 64)
+    if err != nil {
+        return fallback
+    }
+    return n / 20
+}
+
+
+func pdfTabStops(value string) []pdfTabStop {
+    stops := []pdfTabStop{}
+    if value == "" {
+        return stops
+    }
+    for _, entry := range strings.Split(value, ",") {
+        parts := strings.Split(entry, ":")
+        if len(parts) != 3 {
+            continue
+        }
+        pos, err := strconv.ParseFloat(parts[1], 64)
+        if err != nil {
+            continue
+        }
+        pos /= 20
+        kept := stops[:0]
+        for _, stop := range stops {
+            if math.Abs(stop.pos-pos) > 0.01 {
+                kept = append(kept, stop)
+            }
+        }
+        stops = kept
+        if parts[0] != "clear" && parts[0] != "bar" {
+            stops = append(stops, pdfTabStop{pos: pos, align: parts[0], leader: parts[2]})
+        }
+    }
+    sort.SliceStable(stops, func(i, j int) bool {
+        return stops[i].pos < stops[j].pos
+    })
+    return stops
+}
+
+
+func pdfTextStyleOf(props styleProperties) pdfTextStyle {
+    style := pdfTextStyle{size: 10, color: "000000"}
+    if n, err := strconv.ParseFloat(props["size"], 64); err == nil && n > 0 {
+        style.size = n / 2
+    }
+    style.font = pdfFonts[pdfFontName(props["font"], props["b"] == "true", props["i"] == "true")]
+    if _, ok := cssColor(props["color"]); ok {
+        style.color = props["color"]
+    }
+    if _, ok := cssColor(props["shading"]); ok {
+        style.highlight = props["shading"]
+    } else if color, ok := highlightColors[props["highlight"]]; ok {
+        style.highlight = color
+    }
+    style.underline = props["underline"] != "" && props["underline"] != "none"
+    style.strike = props["strike"] == "true" || props["dstrike"] == "true"
+    style.caps = props["caps"] == "true" || props["smallCaps"] == "true"
+    if props["smallCaps"] == "true" && props["caps"] != "true" {
+        style.size *= 0.8
+    }
+    switch props["vertAlign"] {
+    case "superscript":
+        style.rise = style.size * 0.33
+        style.size *= 0.65
+    case "subscript":
+        style.rise = -style.size * 0.14
+        style.size *= 0.65
+    }
+    return style
+}
+
+
+func (l *pdfLayout) textStyle(base styleProperties, props *runProperties) (pdfTextStyle, bool) {
+    merged := styleProperties{}
+    mergeProperties(merged, base)
+    if props != nil && props.Style != nil {
+        _, run, _ := l.sheet.resolve(props.Style.Val)
+        mergeProperties(merged, run)
+    }
+    mergeProperties(merged, l.sheet.directRunProperties(props))
+    return pdfTextStyleOf(merged), merged["vanish"] == "true"
+}
+
+
+
+
+func (l *pdfLayout) paragraph(p *paragraphData, f *pdfParagraphFormat) []*pdfBox {
+    pieces := l.listLabel(p, f)
+    pieces = l.inlinePieces(pieces, p.Content, f.run, "", &pdfFieldState{})
+    lines := l.breakLines(pieces, f)
+
+    boxes := []*pdfBox{}
+    for i, line := range lines {
+        line := line
+        first, last := i == 0, i == len(lines)-1
+        box := &pdfBox{height: line.height, pageBreak: line.pageBreak}
+        if first {
+            box.spaceBefore = f.before
+            box.pageBreak = box.pageBreak || f.pageBreakBefore
+        }
+        if last {
+            box.spaceAfter = f.after
+            box.keepNext = f.keepNext
+        }
+        box.draw = func(page *pdfPage, x float64, y float64) {
+            l.drawLine(page, line, f, x, y, first, last)
+        }
+        boxes = append(boxes, box)
+    }
+
+    n := len(boxes)
+    for i := 0; i < n-1; i++ {
+        if f.keepLines || i == 0 || i == n-2 {
+            boxes[i].keepNext = true
+        }
+    }
+    return boxes
+}
+
+
+func (l *pdfLayout) listLabel(p *paragraphData, f *pdfParagraphFormat) []*pdfPiece {
+    level, ordered, ok := l.doc.getListLevel(p)
+    if !ok {
+        return nil
+    }
+    numID, format, start := l.doc.getListFormat(p, level, ordered)
+    counters, ok := l.counters[numID]
+    if !ok {
+        counters = make([]int, maxListLevel+1)
+        l.counters[numID] = counters
+    }
+    if level > maxListLevel {
+        level = maxListLevel
+    }
+    for deeper := level + 1; deeper <= maxListLevel; deeper++ {
+        counters[deeper] = 0
+    }
+
+    label := bulletListSymbols[level%len(bulletListSymbols)]
+    if format != "bullet" {
+        if counters[level] == 0 {
+            counters[level] = start
+        } else {
+            counters[level]++
+        }
+        label = formatListNumber(counters[level], format) + "."
+    }
+    pieces := l.textPieces(nil, label, f.mark, "")
+    return append(pieces, &pdfPiece{kind: pdfPieceTab, style: f.mark})
+}
+
+
+func formatListNumber(n int, format string) string {
+    switch format {
+    case "lowerLetter", "upperLetter":
+        if n < 1 {
+            return strconv.Itoa(n)
+        }
+        letter := strings.Repeat(string(rune('a'+(n-1)%26)), (n-1)/26+1)
+        if format == "upperLetter" {
+            return strings.ToUpper(letter)
+        }
+        return letter
+    case "lowerRoman", "upperRoman":
+        if n < 1 || n > 3999 {
+            return strconv.Itoa(n)
+        }
+        var sb strings.Builder
+        for _, numeral := range []struct {
+            value  int
+            symbol string
+        }{
+            {1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+            {50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+        } {
+            for ; n >= numeral.value; n -= numeral.value {
+                sb.WriteString(numeral.symbol)
+            }
+        }
+        if format == "upperRoman" {
+            return strings.ToUpper(sb.String())
+        }
+        return sb.String()
+    }
+    return strconv.Itoa(n)
+}
+
+
+
+
+func (l *pdfLayout) inlinePieces(pieces []*pdfPiece, content []interface{}, base styleProperties, link string, field *pdfFieldState) []*pdfPiece {
+    for _, c := range content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            pieces = l.runPieces(pieces, v, base, link, field)
+        case *simpleField:
+            if text := l.pageField(v.Instr); text != "" && len(v.Runs) > 0 {
+                style, _ := l.textStyle(base, v.Runs[0].Properties)
+                pieces = l.textPieces(pieces, text, style, link)
+                continue
+            }
+            for _, run := range v.Runs {
+                pieces = l.runPieces(pieces, run, base, link, field)
+            }
+        case *bookmarkStart:
+            if v.Name != "" && l.pageNumber == 0 {
+                pieces = append(pieces, &pdfPiece{kind: pdfPieceAnchor, anchor: v.Name})
+            }
+        case *containerElement:
+            target := link
+            if v.XMLName.Local == "w:hyperlink" {
+                target = l.linkTarget(v)
+            }
+            pieces = l.inlinePieces(pieces, v.Content, base, target, field)
+        }
+    }
+    return pieces
+}
+
+
+func (l *pdfLayout) linkTarget(link *containerElement) string {
+    target := ""
+    for _, a := range link.Attrs {
+        switch a.Name.Local {
+        case "r:id":
+            if rel, ok := l.part.relationship(l.doc, a.Value); ok {
+                target = rel.Target
+            }
+        case "w:anchor":
+            if target == "" {
+                target = "#" + a.Value
+            }
+        }
+    }
+    return target
+}
+
+
+func (l *pdfLayout) pageField(instr string) string {
+    fields := strings.Fields(instr)
+    if len(fields) == 0 || l.pageNumber == 0 {
+        return ""
+    }
+    switch strings.ToUpper(fields[0]) {
+    case "PAGE":
+        return strconv.Itoa(l.pageNumber)
+    case "NUMPAGES":
+        return strconv.Itoa(l.pageCount)
+    }
+    return ""
+}
+
+
+func (l *pdfLayout) runPieces(pieces []*pdfPiece, run *paragraphRun, base styleProperties, link string, field *pdfFieldState) []*pdfPiece {
+    if run.FieldChar != nil {
+        switch run.FieldChar.Type {
+        case fieldCharBegin:
+            field.depth++
+            if field.depth == 1 {
+                field.instr, field.separated = "", false
+            }
+        case fieldCharSeparate:
+            if field.depth == 1 {
+                field.separated = true
+                if text := l.pageField(field.instr); text != "" {
+                    style, _ := l.textStyle(base, run.Properties)
+                    pieces = l.textPieces(pieces, text, style, link)
+                    field.skip = true
+                }
+            }
+        case fieldCharEnd:
+            if field.depth == 1 && !field.separated {
+                if text := l.pageField(field.instr); text != "" {
+                    style, _ := l.textStyle(base, run.Properties)
+                    pieces = l.textPieces(pieces, text, style, link)
+                }
+            }
+            if field.depth > 0 {
+                field.depth--
+            }
+            if field.depth == 0 {
+                field.skip = false
+            }
+        }
+        return pieces
+    }
+    if run.InstrText != nil {
+        if field.depth == 1 {
+            field.instr += run.InstrText.Text
+        }
+        return pieces
+    }
+    if field.skip || (field.depth > 0 && !field.separated) {
+        return pieces
+    }
+
+    style, hidden := l.textStyle(base, run.Properties)
+    if hidden {
+        return pieces
+    }
+    switch {
+    case run.Text != nil:
+        text := run.Text.Text
+        if style.caps {
+            text = strings.ToUpper(text)
+        }
+        return l.textPieces(pieces, text, style, link)
+    case run.Tab != nil:
+        return append(pieces, &pdfPiece{kind: pdfPieceTab, style: style, link: link})
+    case run.Break != nil && (run.Break.Type == BreakPage || run.Break.Type == BreakColumn):
+        return append(pieces, &pdfPiece{kind: pdfPiecePageBreak, style: style})
+    case run.Break != nil:
+        return append(pieces, &pdfPiece{kind: pdfPieceBreak, style: style})
+    case run.hasDrawing():
+        rID, _ := drawingImage(run)
+        cx, cy := drawingExtent(run)
+        if rID == "" || cx <= 0 || cy <= 0 {
+            return pieces
+        }
+        return append(pieces, &pdfPiece{
+            kind:   pdfPieceImage,
+            style:  style,
+            width:  float64(cx) / pdfEmusPerPoint,
+            height: float64(cy) / pdfEmusPerPoint,
+            rID:    rID,
+            part:   l.part,
+            link:   link,
+        })
+    }
+    for _, extra := range run.Extra {
+        switch extra.XMLName.Local {
+        case "w:tab", "w:ptab":
+            pieces = append(pieces, &pdfPiece{kind: pdfPieceTab, style: style, link: link})
+        case "w:cr":
+            pieces = append(pieces, &pdfPiece{kind: pdfPieceBreak, style: style})
+        case "w:noBreakHyphen":
+            pieces = l.textPieces(pieces, "-", style, link)
+        case "w:t":
+            pieces = l.textPieces(pieces, extra.text(), style, link)
+        }
+    }
+    return pieces
+}
+
+
+func (l *pdfLayout) textPieces(pieces []*pdfPiece, text string, style pdfTextStyle, link string) []*pdfPiece {
+    for len(text) > 0 {
+        n := strings.IndexByte(text, ' ')
+        kind := pdfPieceText
+        switch {
+        case n == 0:
+            n = len(text) - len(strings.TrimLeft(text, " "))
+            kind = pdfPieceSpace
+        case n < 0:
+            n = len(text)
+        }
+        encoded := encodeWinAnsi(text[:n])
+        pieces = append(pieces, &pdfPiece{
+            kind:  kind,
+            text:  encoded,
+            style: style,
+            width: style.font.textWidth(encoded, style.size),
+            link:  link,
+        })
+        text = text[n:]
+    }
+    return pieces
+}
+
+
+
+
+func (l *pdfLayout) breakLines(pieces []*pdfPiece, f *pdfParagraphFormat) []*pdfLine {
+    lines := []*pdfLine{}
+    line := &pdfLine{}
+    start := f.left + f.firstLine
+    x := start
+    limit := f.width - f.right
+    hasContent := false
+    finish := func(last bool) {
+        l.alignLine(line, f, start, limit, last)
+        l.measureLine(line, f)
+        lines = append(lines, line)
+        line = &pdfLine{}
+        start, x = f.left, f.left
+        hasContent = false
+    }
+    add := func(p *pdfPiece) {
+        p.x = x
+        x += p.width
+        line.pieces = append(line.pieces, p)
+        hasContent = hasContent || p.kind == pdfPieceText || p.kind == pdfPieceImage || p.kind == pdfPieceTab
+    }
+
+    for i := 0; i < len(pieces); {
+        p := pieces[i]
+        switch p.kind {
+        case pdfPieceBreak, pdfPiecePageBreak:
+            line.pieces = append(line.pieces, p)
+            finish(true)
+            line.pageBreak = p.kind == pdfPiecePageBreak
+            i++
+            continue
+        case pdfPieceSpace, pdfPieceAnchor:
+            add(p)
+            i++
+            continue
+        case pdfPieceTab:
+            stop := f.nextTab(x)
+            segment := 0.0
+            for _, next := range pieces[i+1:] {
+                if next.kind == pdfPieceTab || next.kind == pdfPieceBreak || next.kind == pdfPiecePageBreak {
+                    break
+                }
+                segment += next.width
+            }
+            width := stop.pos - x
+            switch stop.align {
+            case TabCenter:
+                width -= segment / 2
+            case TabRight, TabDecimal, "end":
+                width -= segment
+            }
+            if x+width > limit+0.01 && hasContent && stop.pos > limit {
+                finish(false)
+                continue
+            }
+            p.width, p.leader = math.Max(width, 0), stop.leader
+            add(p)
+            i++
+            continue
+        }
+
+        j, width := i, 0.0
+        for j < len(pieces) && (j == i || (pieces[j].kind == pdfPieceText && pieces[j-1].kind != pdfPieceImage) || pieces[j].kind == pdfPieceAnchor) {
+            width += pieces[j].width
+            j++
+        }
+        if x+width <= limit+0.01 {
+            for ; i < j; i++ {
+                add(pieces[i])
+            }
+            continue
+        }
+        if hasContent {
+            finish(false)
+            continue
+        }
+
+        for i < j && x+pieces[i].width <= limit+0.01 {
+            add(pieces[i])
+            i++
+        }
+        if i == j {
+            continue
+        }
+        p = pieces[i]
+        if p.kind == pdfPieceImage {
+            if avail := limit - x; avail > 0 {
+                p.height *= avail / p.width
+                p.width = avail
+            }
+            add(p)
+            i++
+            continue
+        }
+        if len(p.text) <= 1 {
+            add(p)
+            i++
+            finish(false)
+            continue
+        }
+        n := 1
+        for n < len(p.text)-1 && x+p.style.font.textWidth(p.text[:n+1], p.style.size) <= limit {
+            n++
+        }
+        head, tail := *p, *p
+        head.text, tail.text = p.text[:n], p.text[n:]
+        head.width = p.style.font.textWidth(head.text, p.style.size)
+        tail.width = p.style.font.textWidth(tail.text, p.style.size)
+        add(&head)
+        pieces[i] = &tail
+        finish(false)
+    }
+    finish(true)
+    return lines
+}
+
+
+func (f *pdfParagraphFormat) nextTab(x float64) pdfTabStop {
+    for _, stop := range f.tabs {
+        if stop.pos > x+0.01 {
+            if f.firstLine < 0 && f.left > x+0.01 && f.left < stop.pos {
+                return pdfTabStop{pos: f.left, align: TabLeft}
+            }
+            return stop
+        }
+    }
+    if f.firstLine < 0 && f.left > x+0.01 {
+        return pdfTabStop{pos: f.left, align: TabLeft}
+    }
+    return pdfTabStop{pos: (math.Floor(x/pdfDefaultTabStop+0.001) + 1) * pdfDefaultTabStop, align: TabLeft}
+}
+
+
+func (l *pdfLayout) alignLine(line *pdfLine, f *pdfParagraphFormat, start float64, limit float64, last bool) {
+    end, lastTab := start, -1
+    for i, p := range line.pieces {
+        switch p.kind {
+        case pdfPieceText, pdfPieceImage, pdfPieceTab:
+            end = p.x + p.width
+            if p.kind == pdfPieceTab {
+                lastTab = i
+            }
+        }
+    }
+    for _, p := range line.pieces {
+        if p.kind == pdfPieceSpace && p.x >= end-0.001 {
+            p.width, p.text = 0, nil
+        }
+    }
+
+    shift, extra := 0.0, 0.0
+    switch f.align {
+    case "center":
+        shift = (limit - end) / 2
+    case "right", "end":
+        shift = limit - end
+    case "both", "distribute":
+        if last {
+            break
+        }
+        spaces := 0
+        for i, p := range line.pieces {
+            if p.kind == pdfPieceSpace && p.width > 0 && i > lastTab {
+                spaces++
+            }
+        }
+        if spaces > 0 && limit > end {
+            extra = (limit - end) / float64(spaces)
+        }
+    }
+    for i, p := range line.pieces {
+        p.x += shift
+        if extra > 0 && p.kind == pdfPieceSpace && p.width > 0 && i > lastTab {
+            p.width += extra
+            shift += extra
+        }
+    }
+}
+
+
+func (l *pdfLayout) measureLine(line *pdfLine, f *pdfParagraphFormat) {
+    ascent, descent, text := 0.0, 0.0, false
+    for _, p := range line.pieces {
+        switch p.kind {
+        case pdfPieceImage:
+            ascent = math.Max(ascent, p.height)
+        case pdfPieceText, pdfPieceSpace, pdfPieceTab:
+            text = true
+            ascent = math.Max(ascent, p.style.size*pdfTextAscent+math.Max(p.style.rise, 0))
+            descent = math.Max(descent, p.style.size*(pdfLineHeight-pdfTextAscent)-math.Min(p.style.rise, 0))
+        }
+    }
+    if !text {
+        ascent = math.Max(ascent, f.mark.size*pdfTextAscent)
+        descent = f.mark.size * (pdfLineHeight - pdfTextAscent)
+    }
+
+    natural := ascent + descent
+    switch f.lineRule {
+    case "exact":
+        line.height = f.line
+        line.baseline = line.height - descent
+    case "atLeast":
+        line.height = math.Max(natural, f.line)
+        line.baseline = line.height - descent
+    default:
+        line.height = natural * f.line / 240
+        line.baseline = ascent
+    }
+}
+
+
+
+
+func (l *pdfLayout) drawLine(page *pdfPage, line *pdfLine, f *pdfParagraphFormat, x float64, y float64, first bool, last bool) {
+    left, right := x+f.left, x+f.width-f.right
+    if fill, ok := cssColor(f.props["shading"]); ok {
+        pdfFillRect(page, strings.TrimPrefix(fill, "#"), left, y, right-left, line.height)
+    }
+    if first {
+        pdfBorder(page, f.props["border-top"], left, y, right, y)
+    }
+    if last {
+        pdfBorder(page, f.props["border-bottom"], left, y+line.height+pdfBorderSpace(f.props["border-bottom"]), right, y+line.height+pdfBorderSpace(f.props["border-bottom"]))
+    }
+    pdfBorder(page, f.props["border-left"], left-pdfBorderSpace(f.props["border-left"]), y, left-pdfBorderSpace(f.props["border-left"]), y+line.height)
+    pdfBorder(page, f.props["border-right"], right+pdfBorderSpace(f.props["border-right"]), y, right+pdfBorderSpace(f.props["border-right"]), y+line.height)
+
+    baseline := y + line.baseline
+    for _, p := range line.pieces {
+        px := x + p.x
+        switch p.kind {
+        case pdfPieceText, pdfPieceSpace:
+            if p.style.highlight != "" && p.width > 0 {
+                pdfFillRect(page, p.style.highlight, px, y, p.width, line.height)
+            }
+            if p.kind == pdfPieceText {
+                l.drawText(page, px, baseline-p.style.rise, p.text, p.style)
+            }
+            if p.style.underline && p.width > 0 {
+                pdfFillRect(page, p.style.color, px, baseline-p.style.rise+p.style.size*0.1, p.width, p.style.size*0.05)
+            }
+            if p.style.strike && p.width > 0 {
+                pdfFillRect(page, p.style.color, px, baseline-p.style.rise-p.style.size*0.3, p.width, p.style.size*0.05)
+            }
+        case pdfPieceTab:
+            leader, ok := tabLeaders[p.leader]
+            if !ok {
+                break
+            }
+            encoded := encodeWinAnsi(leader)
+            unit := p.style.font.textWidth(encoded, p.style.size)
+            count := int((p.width - unit) / unit)
+            if count > 0 {
+                dots := []byte(strings.Repeat(string(encoded), count))
+                l.drawText(page, px+p.width-unit*float64(count)-unit/2, baseline, dots, p.style)
+            }
+        case pdfPieceImage:
+            l.drawImage(page, p, px, baseline-p.height)
+        case pdfPieceAnchor:
+            if _, exists := l.dests[p.anchor]; !exists {
+                l.dests[p.anchor] = pdfDest{page: page, y: y}
+            }
+        }
+
+        if p.link != "" && p.width > 0 {
+            if n := len(page.links); n > 0 && page.links[n-1].target == p.link && page.links[n-1].y1 == y && math.Abs(page.links[n-1].x2-px) < 0.5 {
+                page.links[n-1].x2 = px + p.width
+            } else {
+                page.links = append(page.links, pdfLink{x1: px, y1: y, x2: px + p.width, y2: y + line.height, target: p.link})
+            }
+        }
+    }
+}
+
+
+func (l *pdfLayout) drawText(page *pdfPage, x float64, baseline float64, text []byte, style pdfTextStyle) {
+    if len(text) == 0 {
+        return
+    }
+    page.content.WriteString("BT /" + l.fontResource(style.font) + " " + pdfNumber(style.size) + " Tf " + pdfRGB(style.color) + " rg " +
+        pdfNumber(x) + " " + pdfNumber(page.height-baseline) + " Td " + pdfEscapeString(text) + " Tj ET\n")
+}
+
+
+func (l *pdfLayout) drawImage(page *pdfPage, p *pdfPiece, x float64, y float64) {
+    key := p.rID
+    if p.part != nil {
+        key = p.part.name + "#" + p.rID
+    }
+    img, ok := l.images[key]
+    if !ok {
+        img = l.loadImage(p)
+        l.images[key] = img
+    }
+    if img == nil {
+        return
+    }
+    page.content.WriteString("q " + pdfNumber(p.width) + " 0 0 " + pdfNumber(p.height) + " " + pdfNumber(x) + " " +
+        pdfNumber(page.height-y-p.height) + " cm /" + img.name + " Do Q\n")
+}
+
+
+func (l *pdfLayout) loadImage(p *pdfPiece) *pdfImage {
+    name, data, ok := p.part.imageData(l.doc, p.rID)
+    if !ok {
+        return nil
+    }
+
+    img, err := l.file.addImage(data, name)
+    if err != nil {
+        if l.err == nil {
+            l.err = err
+        }
+        return nil
+    }
+    img.name = "Im" + strconv.Itoa(len(l.imageOrder)+1)
+    l.imageOrder = append(l.imageOrder, img)
+    return img
+}
+
+
+func (l *pdfLayout) fontResource(font *pdfFont) string {
+    if name, ok := l.fonts[font.name]; ok {
+        return name
+    }
+    name := "F" + strconv.Itoa(len(l.fontOrder)+1)
+    l.fonts[font.name] = name
+    l.fontOrder = append(l.fontOrder, font.name)
+    return name
+}
+
+
+func pdfRGB(hex string) string {
+    n, err := strconv.ParseUint(hex, 16, 32)
+    if err != nil || len(hex) != 6 {
+        return "0 0 0"
+    }
+    return pdfNumber(float64(n>>16&0xFF)/255) + " " + pdfNumber(float64(n>>8&0xFF)/255) + " " + pdfNumber(float64(n&0xFF)/255)
+}
+
+
+func pdfFillRect(page *pdfPage, color string, x float64, y float64, width float64, height float64) {
+    page.content.WriteString(pdfRGB(color) + " rg " + pdfNumber(x) + " " + pdfNumber(page.height-y-height) + " " +
+        pdfNumber(width) + " " + pdfNumber(height) + " re f\n")
+}
+
+
+func pdfBorderSpace(spec string) float64 {
+    parts := strings.Split(spec, " ")
+    if len(parts) != 4 {
+        return 0
+    }
+    space, _ := strconv.ParseFloat(parts[2], 64)
+    return space
+}
+
+
+func pdfBorder(page *pdfPage, spec string, x1 float64, y1 float64, x2 float64, y2 float64) {
+    parts := strings.Split(spec, " ")
+    if len(parts) != 4 || parts[0] == "" || parts[0] == "none" || parts[0] == "nil" {
+        return
+    }
+    width := 0.5
+    if n, err := strconv.ParseFloat(parts[1], 64); err == nil && n > 0 {
+        width = math.Max(n/8, 0.25)
+    }
+    color := "000000"
+    if _, ok := cssColor(parts[3]); ok {
+        color = parts[3]
+    }
+    page.content.WriteString("q " + pdfNumber(width) + " w " + pdfRGB(color) + " RG " + pdfNumber(x1) + " " + pdfNumber(page.height-y1) + " m " +
+        pdfNumber(x2) + " " + pdfNumber(page.height-y2) + " l S Q\n")
+}
+
+
+
+
+func (l *pdfLayout) table(t *tableData, ctx pdfContext) []*pdfBox {
+    props, paragraph, run := styleProperties{}, styleProperties{}, styleProperties{}
+    if t.Properties != nil {
+        if t.Properties.Style != nil {
+            paragraph, run, props = l.sheet.resolve(t.Properties.Style.Val)
+        }
+        l.sheet.readTableProperties(&rawXML{Children: rawChildren(t.Properties.Extra)}, props)
+    }
+
+    widths := gridWidths(t, ctx.width)
+    total := 0.0
+    for _, w := range widths {
+        total += w
+    }
+    offsets := []float64{0}
+    for _, w := range widths {
+        if total > ctx.width {
+            w *= ctx.width / total
+        }
+        offsets = append(offsets, offsets[len(offsets)-1]+w)
+    }
+
+    top, bottom := pdfTwips(props["cell-top"], 0), pdfTwips(props["cell-bottom"], 0)
+    left, right := pdfTwips(props["cell-left"], pdfCellMargin), pdfTwips(props["cell-right"], pdfCellMargin)
+    grid := make([][]*pdfCell, len(t.Rows))
+    heights := make([]float64, len(t.Rows))
+    exact := make([]bool, len(t.Rows))
+    for r, row := range t.Rows {
+        col := 0
+        for _, data := range row.Cells {
+            span, merge := cellSpan(data)
+            if col >= len(widths) {
+                break
+            }
+            end := col + span
+            if end > len(widths) {
+                end = len(widths)
+            }
+            cell := &pdfCell{data: data, col: col, span: end - col, merge: merge, rows: 1, x: offsets[col], width: offsets[end] - offsets[col]}
+            col = end
+            grid[r] = append(grid[r], cell)
+            if merge == "continue" {
+                continue
+            }
+            if merge == "restart" {
+                cell.rows = rowSpan(t.Rows, r, cell.col)
+            }
+            blocks := data.Blocks
+            if n := len(blocks); n == 0 {
+                blocks = []interface{}{&paragraphData{}}
+            } else if _, ok := blocks[n-1].(*paragraphData); !ok {
+                blocks = append(blocks[:n:n], &paragraphData{})
+            }
+            cell.boxes = l.blocks(blocks, pdfContext{width: cell.width - left - right, paragraph: paragraph, run: run})
+            cell.height = stackHeight(cell.boxes) + top + bottom
+            if cell.rows == 1 {
+                heights[r] = math.Max(heights[r], cell.height)
+            }
+        }
+        if row.Properties != nil {
+            for _, extra := range row.Properties.Extra {
+                if extra.XMLName.Local != "w:trHeight" {
+                    continue
+                }
+                height := pdfTwips(attrValue(extra, "w:val"), 0)
+                if attrValue(extra, "w:hRule") == "exact" {
+                    heights[r] = height
+                    exact[r] = true
+                } else {
+                    heights[r] = math.Max(heights[r], height)
+                }
+            }
+        }
+    }
+    for r := range grid {
+        for _, cell := range grid[r] {
+            if cell.rows <= 1 || cell.merge == "continue" {
+                continue
+            }
+            span := 0.0
+            for _, h := range heights[r : r+cell.rows] {
+                span += h
+            }
+            if span < cell.height {
+                heights[r+cell.rows-1] += cell.height - span
+            }
+        }
+    }
+
+    boxes, headers := []*pdfBox{}, []*pdfBox{}
+    header := true
+    mergedUntil := 0
+    for r, row := range t.Rows {
+        box := l.rowBox(grid, heights, r, !exact[r], props, [4]float64{top, right, bottom, left}, len(widths))
+        for _, cell := range grid[r] {
+            if cell.rows > 1 && r+cell.rows-1 > mergedUntil {
+                mergedUntil = r + cell.rows - 1
+            }
+        }
+        box.keepNext = r < mergedUntil
+        header = header && row.Properties != nil && row.Properties.Header != nil && row.Properties.Header.Val != "0" && row.Properties.Header.Val != "false"
+        if header {
+            headers = append(headers, box)
+            box.keepNext = true
+        } else if len(headers) > 0 {
+            box.repeat = headers
+        }
+        boxes = append(boxes, box)
+    }
+    return boxes
+}
+
+
+func (l *pdfLayout) rowBox(grid [][]*pdfCell, heights []float64, r int, splittable bool, props styleProperties, margins [4]float64, cols int) *pdfBox {
+    box := &pdfBox{height: heights[r]}
+    box.draw = func(page *pdfPage, x float64, y float64) {
+        l.drawRow(page, grid, heights, r, x, y, props, margins, cols)
+    }
+    for _, cell := range grid[r] {
+        if cell.rows > 1 || cell.merge == "continue" {
+            splittable = false
+        }
+    }
+    if !splittable {
+        return box
+    }
+
+    box.split = func(avail float64, force bool) (*pdfBox, *pdfBox) {
+        head, tail := []*pdfCell{}, []*pdfCell{}
+        progress, remaining := false, false
+        for _, cell := range grid[r] {
+            first, rest := *cell, *cell
+            first.boxes, rest.boxes = nil, nil
+            used := 0.0
+            for i, b := range cell.boxes {
+                h := b.spaceBefore + b.height + b.spaceAfter
+                if used+h > avail-margins[0]-margins[2] && (i > 0 || !force) {
+                    rest.boxes = cell.boxes[i:]
+                    break
+                }
+                first.boxes = append(first.boxes, b)
+                used += h
+            }
+            first.height = stackHeight(first.boxes) + margins[0] + margins[2]
+            rest.height = stackHeight(rest.boxes) + margins[0] + margins[2]
+            progress = progress || len(first.boxes) > 0
+            remaining = remaining || len(rest.boxes) > 0
+            head, tail = append(head, &first), append(tail, &rest)
+        }
+        if !progress || !remaining {
+            return nil, nil
+        }
+        fragment := func(cells []*pdfCell) *pdfBox {
+            rows := append([][]*pdfCell{}, grid...)
+            rows[r] = cells
+            rowHeights := append([]float64{}, heights...)
+            rowHeights[r] = 0
+            for _, cell := range cells {
+                rowHeights[r] = math.Max(rowHeights[r], cell.height)
+            }
+            return l.rowBox(rows, rowHeights, r, true, props, margins, cols)
+        }
+        first, rest := fragment(head), fragment(tail)
+        rest.keepNext, rest.repeat = box.keepNext, box.repeat
+        return first, rest
+    }
+    return box
+}
+
+
+func (l *pdfLayout) drawRow(page *pdfPage, grid [][]*pdfCell, heights []float64, r int, x float64, y float64, props styleProperties, margins [4]float64, cols int) {
+    for _, cell := range grid[r] {
+        if cell.merge == "continue" {
+            continue
+        }
+        height := 0.0
+        for _, h := range heights[r : r+cell.rows] {
+            height += h
+        }
+        cx := x + cell.x
+        borders := styleProperties{}
+        for _, side := range []string{"top", "bottom", "left", "right"} {
+            borders["border-"+side] = props["border-inside"+map[bool]string{true: "H", false: "V"}[side == "top" || side == "bottom"]]
+        }
+        if r == 0 {
+            borders["border-top"] = props["border-top"]
+        }
+        if r+cell.rows == len(grid) {
+            borders["border-bottom"] = props["border-bottom"]
+        }
+        if cell.col == 0 {
+            borders["border-left"] = props["border-left"]
+        }
+        if cell.col+cell.span >= cols {
+            borders["border-right"] = props["border-right"]
+        }
+
+        offset := 0.0
+        if cell.data.Properties != nil {
+            for _, extra := range cell.data.Properties.Extra {
+                switch extra.XMLName.Local {
+                case "w:shd":
+                    if _, ok := cssColor(attrValue(extra, "w:fill")); ok {
+                        pdfFillRect(page, attrValue(extra, "w:fill"), cx, y, cell.width, height)
+                    }
+                case "w:tcBorders":
+                    readBorders(extra, borders)
+                case "w:vAlign":
+                    switch attrValue(extra, "w:val") {
+                    case "center":
+                        offset = (height - cell.height) / 2
+                    case "bottom":
+                        offset = height - cell.height
+                    }
+                }
+            }
+        }
+
+        cy := y + margins[0] + math.Max(offset, 0)
+        for _, box := range cell.boxes {
+            cy += box.spaceBefore
+            box.draw(page, cx+margins[3], cy)
+            cy += box.height + box.spaceAfter
+        }
+        pdfBorder(page, borders["border-top"], cx, y, cx+cell.width, y)
+        pdfBorder(page, borders["border-bottom"], cx, y+height, cx+cell.width, y+height)
+        pdfBorder(page, borders["border-left"], cx, y, cx, y+height)
+        pdfBorder(page, borders["border-right"], cx+cell.width, y, cx+cell.width, y+height)
+    }
+}
@@ -0,0 +1,133 @@
+package docx
+
+import (
+    "bytes"
+    "fmt"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+
+type pdfTextPlacement struct {
+    page     int
+    baseline float64
+    text     string
+}
+
+
+func pdfTexts(t *testing.T, l *pdfLayout) []pdfTextPlacement {
+    t.Helper()
+    texts := []pdfTextPlacement{}
+    for i, page := range l.pages {
+        for _, line := range strings.Split(page.content.String(), "\n") {
+            fields := strings.Fields(line)
+            for j := 2; j < len(fields)-1; j++ {
+                if fields[j] != "Td" {
+                    continue
+                }
+                y, err := strconv.ParseFloat(fields[j-1], 64)
+                if err != nil {
+                    t.Fatalf("bad Td operand in %q", line)
+                }
+                text := strings.TrimSuffix(strings.TrimPrefix(fields[j+1], "("), ")")
+                texts = append(texts, pdfTextPlacement{page: i, baseline: page.height - y, text: text})
+            }
+        }
+    }
+    return texts
+}
+
+
+func TestPDFEmptyTableCellsTakeALine(t *testing.T) {
+    empty := NewDocxDocument()
+    empty.AddTable(300, 2)
+    filled := NewDocxDocument()
+    table := filled.AddTable(300, 2)
+    for r := 0; r < 300; r++ {
+        table.Cell(r, 0).AddText(StyleNormal, "x")
+    }
+
+    emptyLayout, filledLayout := newPDFLayout(empty), newPDFLayout(filled)
+    emptyLayout.layout()
+    filledLayout.layout()
+    if emptyLayout.err != nil || filledLayout.err != nil {
+        t.Fatal(emptyLayout.err, filledLayout.err)
+    }
+    if len(emptyLayout.pages) < 4 {
+        t.Fatalf("table of 300 empty rows laid out on %d pages", len(emptyLayout.pages))
+    }
+    if len(emptyLayout.pages) != len(filledLayout.pages) {
+        t.Errorf("empty rows take %d pages, one-line rows take %d", len(emptyLayout.pages), len(filledLayout.pages))
+    }
+}
+
+
+func TestPDFTallRowsSplitAcrossPages(t *testing.T) {
+    doc := NewDocxDocument()
+    table := doc.AddTable(2, 2)
+    table.Rows()[0].SetHeader(true)
+    table.Cell(0, 0).AddText(StyleNormal, "Heading")
+    want := []string{}
+    for i := 0; i < 200; i++ {
+        line := fmt.Sprintf("L%03d", i)
+        table.Cell(1, 0).AddText(StyleNormal, line)
+        table.Cell(1, 0).AddParagraph(StyleNormal)
+        want = append(want, line)
+    }
+    table.Cell(1, 1).AddText(StyleNormal, "Short")
+    doc.AddText(StyleNormal, "After")
+
+    l := newPDFLayout(doc)
+    l.layout()
+    if l.err != nil {
+        t.Fatal(l.err)
+    }
+    if len(l.pages) < 3 {
+        t.Fatalf("row of 200 lines laid out on %d pages", len(l.pages))
+    }
+
+    lines, headings, last := []string{}, map[int]bool{}, -1
+    for _, placed := range pdfTexts(t, l) {
+        page := l.pages[placed.page]
+        if placed.baseline < page.section.top || placed.baseline > page.height-page.section.bottom {
+            t.Errorf("%q drawn at %.1f outside the body of page %d", placed.text, placed.baseline, placed.page+1)
+        }
+        switch {
+        case placed.text == "Heading":
+            headings[placed.page] = true
+        case strings.HasPrefix(placed.text, "L"):
+            lines = append(lines, placed.text)
+            last = placed.page
+        case placed.text == "After" && placed.page != last:
+            t.Errorf("paragraph after the table drawn on page %d, table ends on page %d", placed.page+1, last+1)
+        }
+    }
+    if !equalStrings(lines, want) {
+        t.Errorf("row lines drawn as %v", lines)
+    }
+    for i := range l.pages {
+        if !headings[i] {
+            t.Errorf("header row not repeated on page %d", i+1)
+        }
+    }
+}
+
+
+func TestWritePDF(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, "Report")
+    doc.AddText(StyleNormal, "Body text")
+
+    var buf bytes.Buffer
+    if err := NewPDFWriter().WritePDF(&buf, doc); err != nil {
+        t.Fatal(err)
+    }
+    data := buf.Bytes()
+    if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(bytes.TrimSpace(data), []byte("%%EOF")) {
+        t.Errorf("output is not framed as a PDF: %q ... %q", data[:8], data[len(data)-8:])
+    }
+    if !bytes.Contains(data, []byte("/Type /Catalog")) || !bytes.Contains(data, []byte("/Count 1")) {
+        t.Error("output has no catalog with one page")
+    }
+}
@@ -0,0 +1,520 @@
+package docx
+
+import (
+    "bytes"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/des"
+    "crypto/hmac"
+    "crypto/pbkdf2"
+    "crypto/sha1"
+    "crypto/sha256"
+    "crypto/sha512"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/asn1"
+    "fmt"
+    "hash"
+    "unicode/utf16"
+)
+
+var (
+    oidPKCS7Data             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+    oidPKCS7EncryptedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+    oidKeyBag                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+    oidShroudedKeyBag        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+    oidCertBag               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+    oidX509Certificate       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+    oidLocalKeyID            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+    oidPBES2                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+    oidPBKDF2                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+    oidPBEWithSHA3DES        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+    oidPBEWithSHA128RC2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+    oidPBEWithSHA40RC2       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+    oidHMACWithSHA1          = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+    oidHMACWithSHA256        = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+    oidHMACWithSHA384        = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+    oidHMACWithSHA512        = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+    oidDESEDE3CBC            = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+    oidAES128CBC             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+    oidAES192CBC             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+    oidAES256CBC             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+    oidSHA1                  = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+    oidSHA256                = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+    oidSHA384                = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+    oidSHA512                = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+
+type pfxPDU struct {
+    Version  int
+    AuthSafe pkcs12ContentInfo
+    MacData  pkcs12MacData `asn1:"optional"`
+}
+
+type pkcs12ContentInfo struct {
+    ContentType asn1.ObjectIdentifier
+    Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type pkcs12MacData struct {
+    Mac struct {
+        Algorithm pkix.AlgorithmIdentifier
+        Digest    []byte
+    }
+    MacSalt    []byte
+    Iterations int `asn1:"optional,default:1"`
+}
+
+type pkcs12EncryptedData struct {
+    Version              int
+    EncryptedContentInfo struct {
+        ContentType                asn1.ObjectIdentifier
+        ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+        EncryptedContent           []byte `asn1:"tag:0,optional"`
+    }
+}
+
+type pkcs12SafeBag struct {
+    ID         asn1.ObjectIdentifier
+    Value      asn1.RawValue `asn1:"tag:0,explicit"`
+    Attributes []struct {
+        ID    asn1.ObjectIdentifier
+        Value asn1.RawValue `asn1:"set"`
+    } `asn1:"set,optional"`
+}
+
+type pkcs12CertBag struct {
+    ID   asn1.ObjectIdentifier
+    Data []byte `asn1:"tag:0,explicit"`
+}
+
+type pkcs12EncryptedKey struct {
+    Algorithm pkix.AlgorithmIdentifier
+    Data      []byte
+}
+
+type pkcs12PBEParams struct {
+    Salt       []byte
+    Iterations int
+}
+
+type pkcs12PBES2Params struct {
+    KeyDerivationFunc pkix.AlgorithmIdentifier
+    EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pkcs12PBKDF2Params struct {
+    Salt       []byte
+    Iterations int
+    KeyLength  int                      `asn1:"optional"`
+    PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+
+
+
+func decodePKCS12(data []byte, password string) (interface{}, []*x509.Certificate, error) {
+    var pfx pfxPDU
+    if rest, err := asn1.Unmarshal(data, &pfx); err != nil {
+        return nil, nil, fmt.Errorf("failed to parse PKCS#12 data: %w", err)
+    } else if len(rest) > 0 {
+        return nil, nil, fmt.Errorf("trailing data after PKCS#12 structure")
+    }
+    if !pfx.AuthSafe.ContentType.Equal(oidPKCS7Data) {
+        return nil, nil, fmt.Errorf("unsupported PKCS#12 content type %v", pfx.AuthSafe.ContentType)
+    }
+    var authSafe []byte
+    if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+        return nil, nil, fmt.Errorf("failed to parse PKCS#12 content: %w", err)
+    }
+
+    bmpPassword := pkcs12BMPString(password)
+    if len(pfx.MacData.Mac.Digest) > 0 {
+        newHash, err := pkcs12DigestHash(pfx.MacData.Mac.Algorithm.Algorithm)
+        if err != nil {
+            return nil, nil, err
+        }
+        key := pkcs12KDF(newHash, 3, bmpPassword, pfx.MacData.MacSalt, pfx.MacData.Iterations, newHash().Size())
+        mac := hmac.New(newHash, key)
+        mac.Write(authSafe)
+        if !hmac.Equal(mac.Sum(nil), pfx.MacData.Mac.Digest) {
+            return nil, nil, fmt.Errorf("incorrect PKCS#12 password")
+        }
+    }
+
+    var contents []pkcs12ContentInfo
+    if _, err := asn1.Unmarshal(authSafe, &contents); err != nil {
+        return nil, nil, fmt.Errorf("failed to parse PKCS#12 safe contents: %w", err)
+    }
+    var key interface{}
+    var localKeyID []byte
+    certs := []*x509.Certificate{}
+    certIDs := [][]byte{}
+    for _, content := range contents {
+        var safe []byte
+        switch {
+        case content.ContentType.Equal(oidPKCS7Data):
+            if _, err := asn1.Unmarshal(content.Content.Bytes, &safe); err != nil {
+                return nil, nil, fmt.Errorf("failed to parse PKCS#12 data bag: %w", err)
+            }
+        case content.ContentType.Equal(oidPKCS7EncryptedData):
+            var encrypted pkcs12EncryptedData
+            if _, err := asn1.Unmarshal(content.Content.Bytes, &encrypted); err != nil {
+                return nil, nil, fmt.Errorf("failed to parse PKCS#12 encrypted bag: %w", err)
+            }
+            info := encrypted.EncryptedContentInfo
+            plain, err := pkcs12Decrypt(info.ContentEncryptionAlgorithm, info.EncryptedContent, password, bmpPassword)
+            if err != nil {
+                return nil, nil, err
+            }
+            safe = plain
+        default:
+            return nil, nil, fmt.Errorf("unsupported PKCS#12 content type %v", content.ContentType)
+        }
+
+        var bags []pkcs12SafeBag
+        if _, err := asn1.Unmarshal(safe, &bags); err != nil {
+            return nil, nil, fmt.Errorf("failed to parse PKCS#12 safe bags: %w", err)
+        }
+        for _, bag := range bags {
+            var id []byte
+            for _, attr := range bag.Attributes {
+                if attr.ID.Equal(oidLocalKeyID) {
+                    asn1.Unmarshal(attr.Value.Bytes, &id)
+                }
+            }
+            switch {
+            case bag.ID.Equal(oidCertBag):
+                var certBag pkcs12CertBag
+                if _, err := asn1.Unmarshal(bag.Value.Bytes, &certBag); err != nil {
+                    return nil, nil, fmt.Errorf("failed to parse PKCS#12 certificate bag: %w", err)
+                }
+                if !certBag.ID.Equal(oidX509Certificate) {
+                    continue
+                }
+                cert, err := x509.ParseCertificate(certBag.Data)
+                if err != nil {
+                    return nil, nil, fmt.Errorf("failed to parse PKCS#12 certificate: %w", err)
+                }
+                certs = append(certs, cert)
+                certIDs = append(certIDs, id)
+            case bag.ID.Equal(oidKeyBag), bag.ID.Equal(oidShroudedKeyBag):
+                der := bag.Value.Bytes
+                if bag.ID.Equal(oidShroudedKeyBag) {
+                    var encrypted pkcs12EncryptedKey
+                    if _, err := asn1.Unmarshal(bag.Value.Bytes, &encrypted); err != nil {
+                        return nil, nil, fmt.Errorf("failed to parse PKCS#12 key bag: %w", err)
+                    }
+                    plain, err := pkcs12Decrypt(encrypted.Algorithm, encrypted.Data, password, bmpPassword)
+                    if err != nil {
+                        return nil, nil, err
+                    }
+                    der = plain
+                }
+                parsed, err := x509.ParsePKCS8PrivateKey(der)
+                if err != nil {
+                    return nil, nil, fmt.Errorf("failed to parse PKCS#12 private key: %w", err)
+                }
+                if key == nil {
+                    key, localKeyID = parsed, id
+                }
+            }
+        }
+    }
+    if key == nil {
+        return nil, nil, fmt.Errorf("PKCS#12 data contains no private key")
+    }
+
+    for i := range certs {
+        if localKeyID != nil && bytes.Equal(certIDs[i], localKeyID) {
+            certs[0], certs[i] = certs[i], certs[0]
+            break
+        }
+    }
+    return key, certs, nil
+}
+
+
+func pkcs12Decrypt(algorithm pkix.AlgorithmIdentifier, data []byte, password string, bmpPassword []byte) ([]byte, error) {
+    var block cipher.Block
+    var rc2 *rc2Cipher
+    var iv []byte
+    switch {
+    case algorithm.Algorithm.Equal(oidPBES2):
+        var params pkcs12PBES2Params
+        if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+            return nil, fmt.Errorf("failed to parse PBES2 parameters: %w", err)
+        }
+        if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+            return nil, fmt.Errorf("unsupported key derivation function %v", params.KeyDerivationFunc.Algorithm)
+        }
+        var kdf pkcs12PBKDF2Params
+        if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+            return nil, fmt.Errorf("failed to parse PBKDF2 parameters: %w", err)
+        }
+        newHash := sha1.New
+        switch prf := kdf.PRF.Algorithm; {
+        case len(prf) == 0, prf.Equal(oidHMACWithSHA1):
+        case prf.Equal(oidHMACWithSHA256):
+            newHash = sha256.New
+        case prf.Equal(oidHMACWithSHA384):
+            newHash = sha512.New384
+        case prf.Equal(oidHMACWithSHA512):
+            newHash = sha512.New
+        default:
+            return nil, fmt.Errorf("unsupported PBKDF2 function %v", prf)
+        }
+        keyLength := 0
+        scheme := params.EncryptionScheme.Algorithm
+        switch {
+        case scheme.Equal(oidAES128CBC):
+            keyLength = 16
+        case scheme.Equal(oidAES192CBC), scheme.Equal(oidDESEDE3CBC):
+            keyLength = 24
+        case scheme.Equal(oidAES256CBC):
+            keyLength = 32
+        default:
+            return nil, fmt.Errorf("unsupported encryption scheme %v", scheme)
+        }
+        if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+            return nil, fmt.Errorf("failed to parse encryption scheme parameters: %w", err)
+        }
+        key, err := pbkdf2.Key(newHash, password, kdf.Salt, kdf.Iterations, keyLength)
+        if err != nil {
+            return nil, fmt.Errorf("failed to derive PKCS#12 key: %w", err)
+        }
+        if scheme.Equal(oidDESEDE3CBC) {
+            block, err = des.NewTripleDESCipher(key)
+        } else {
+            block, err = aes.NewCipher(key)
+        }
+        if err != nil {
+            return nil, err
+        }
+    case algorithm.Algorithm.Equal(oidPBEWithSHA3DES), algorithm.Algorithm.Equal(oidPBEWithSHA128RC2), algorithm.Algorithm.Equal(oidPBEWithSHA40RC2):
+        var params pkcs12PBEParams
+        if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+            return nil, fmt.Errorf("failed to parse PBE parameters: %w", err)
+        }
+        var err error
+        switch {
+        case algorithm.Algorithm.Equal(oidPBEWithSHA3DES):
+            block, err = des.NewTripleDESCipher(pkcs12KDF(sha1.New, 1, bmpPassword, params.Salt, params.Iterations, 24))
+        case algorithm.Algorithm.Equal(oidPBEWithSHA128RC2):
+            rc2, err = newRC2Cipher(pkcs12KDF(sha1.New, 1, bmpPassword, params.Salt, params.Iterations, 16), 128)
+        default:
+            rc2, err = newRC2Cipher(pkcs12KDF(sha1.New, 1, bmpPassword, params.Salt, params.Iterations, 5), 40)
+        }
+        if err != nil {
+            return nil, err
+        }
+        iv = pkcs12KDF(sha1.New, 2, bmpPassword, params.Salt, params.Iterations, 8)
+    default:
+        return nil, fmt.Errorf("unsupported PKCS#12 encryption algorithm %v", algorithm.Algorithm)
+    }
+
+    blockSize := rc2BlockSize
+    if block != nil {
+        blockSize = block.BlockSize()
+    }
+    if len(iv) != blockSize || len(data) == 0 || len(data)%blockSize != 0 {
+        return nil, fmt.Errorf("invalid PKCS#12 encrypted data")
+    }
+    plain := make([]byte, len(data))
+    if block != nil {
+        cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+    } else {
+        rc2.decryptCBC(plain, data, iv)
+    }
+    padding := int(plain[len(plain)-1])
+    if padding == 0 || padding > blockSize || padding > len(plain) {
+        return nil, fmt.Errorf("incorrect PKCS#12 password")
+    }
+    for _, b := range plain[len(plain)-padding:] {
+        if int(b) != padding {
+            return nil, fmt.Errorf("incorrect PKCS#12 password")
+        }
+    }
+    return plain[:len(plain)-padding], nil
+}
+
+
+func pkcs12DigestHash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+    switch {
+    case oid.Equal(oidSHA1):
+        return sha1.New, nil
+    case oid.Equal(oidSHA256):
+        return sha256.New, nil
+    case oid.Equal(oidSHA384):
+        return sha512.New384, nil
+    case oid.Equal(oidSHA512):
+        return sha512.New, nil
+    }
+    return nil, fmt.Errorf("unsupported PKCS#12 MAC algorithm %v", oid)
+}
+
+
+func pkcs12BMPString(password string) []byte {
+    encoded := []byte{}
+    for _, c := range utf16.Encode([]rune(password)) {
+        encoded = append(encoded, byte(c>>8), byte(c))
+    }
+    return append(encoded, 0, 0)
+}
+
+
+func pkcs12KDF(newHash func() hash.Hash, id byte, password []byte, salt []byte, iterations int, size int) []byte {
+    h := newHash()
+    v, u := h.BlockSize(), h.Size()
+    fill := func(b []byte) []byte {
+        if len(b) == 0 {
+            return nil
+        }
+        out := make([]byte, v*((len(b)+v-1)/v))
+        for i := range out {
+            out[i] = b[i%len(b)]
+        }
+        return out
+    }
+    d := bytes.Repeat([]byte{id}, v)
+    input := append(fill(salt), fill(password)...)
+
+    out := []byte{}
+    for len(out) < size {
+        h.Reset()
+        h.Write(d)
+        h.Write(input)
+        a := h.Sum(nil)
+        for i := 1; i < iterations; i++ {
+            h.Reset()
+            h.Write(a)
+            a = h.Sum(a[:0])
+        }
+        out = append(out, a...)
+
+        b := make([]byte, v)
+        for i := range b {
+            b[i] = a[i%u]
+        }
+        for j := 0; j < len(input); j += v {
+            carry := 1
+            for k := v - 1; k >= 0; k-- {
+                sum := int(input[j+k]) + int(b[k]) + carry
+                input[j+k] = byte(sum)
+                carry = sum >> 8
+            }
+        }
+    }
+    return out[:size]
+}
+
+
+
+
+const rc2BlockSize = 8
+
+
+type rc2Cipher struct {
+    k [64]uint16
+}
+
+var rc2PiTable = [256]byte{
+    0xd9, 0x78, 0xf9, 0xc4, 0x19, 0xdd, 0xb5, 0xed, 0x28, 0xe9, 0xfd, 0x79, 0x4a, 0xa0, 0xd8, 0x9d,
+    0xc6, 0x7e, 0x37, 0x83, 0x2b, 0x76, 0x53, 0x8e, 0x62, 0x4c, 0x64, 0x88, 0x44, 0x8b, 0xfb, 0xa2,
+    0x17, 0x9a, 0x59, 0xf5, 0x87, 0xb3, 0x4f, 0x13, 0x61, 0x45, 0x6d, 0x8d, 0x09, 0x81, 0x7d, 0x32,
+    0xbd, 0x8f, 0x40, 0xeb, 0x86, 0xb7, 0x7b, 0x0b, 0xf0, 0x95, 0x21, 0x22, 0x5c, 0x6b, 0x4e, 0x82,
+    0x54, 0xd6, 0x65, 0x93, 0xce, 0x60, 0xb2, 0x1c, 0x73, 0x56, 0xc0, 0x14, 0xa7, 0x8c, 0xf1, 0xdc,
+    0x12, 0x75, 0xca, 0x1f, 0x3b, 0xbe, 0xe4, 0xd1, 0x42, 0x3d, 0xd4, 0x30, 0xa3, 0x3c, 0xb6, 0x26,
+    0x6f, 0xbf, 0x0e, 0xda, 0x46, 0x69, 0x07, 0x57, 0x27, 0xf2, 0x1d, 0x9b, 0xbc, 0x94, 0x43, 0x03,
+    0xf8, 0x11, 0xc7, 0xf6, 0x90, 0xef, 0x3e, 0xe7, 0x06, 0xc3, 0xd5, 0x2f, 0xc8, 0x66, 0x1e, 0xd7,
+    0x08, 0xe8, 0xea, 0xde, 0x80, 0x52, 0xee, 0xf7, 0x84, 0xaa, 0x72, 0xac, 0x35, 0x4d, 0x6a, 0x2a,
+    0x96, 0x1a, 0xd2, 0x71, 0x5a, 0x15, 0x49, 0x74, 0x4b, 0x9f, 0xd0, 0x5e, 0x04, 0x18, 0xa4, 0xec,
+    0xc2, 0xe0, 0x41, 0x6e, 0x0f, 0x51, 0xcb, 0xcc, 0x24, 0x91, 0xaf, 0x50, 0xa1, 0xf4, 0x70, 0x39,
+    0x99, 0x7c, 0x3a, 0x85, 0x23, 0xb8, 0xb4, 0x7a, 0xfc, 0x02, 0x36, 0x5b, 0x25, 0x55, 0x97, 0x31,
+    0x2d, 0x5d, 0xfa, 0x98, 0xe3, 0x8a, 0x92, 0xae, 0x05, 0xdf, 0x29, 0x10, 0x67, 0x6c, 0xba, 0xc9,
+    0xd3, 0x00, 0xe6, 0xcf, 0xe1, 0x9e, 0xa8, 0x2c, 0x63, 0x16, 0x01, 0x3f, 0x58, 0xe2, 0x89, 0xa9,
+    0x0d, 0x38, 0x34, 0x1b, 0xab, 0x33, 0xff, 0xb0, 0xbb, 0x48, 0x0c, 0x5f, 0xb9, 0xb1, 0xcd, 0x2e,
+    0xc5, 0xf3, 0xdb, 0x47, 0xe5, 0xa5, 0x9c, 0x77, 0x0a, 0xa6, 0x20, 0x68, 0xfe, 0x7f, 0xc1, 0xad,
+}
+
+
+func newRC2Cipher(key []byte, effectiveBits int) (*rc2Cipher, error) {
+    if len(key) == 0 || len(key) > 128 {
+        return nil, fmt.Errorf("invalid RC2 key length %d", len(key))
+    }
+    var l [128]byte
+    copy(l[:], key)
+    t := len(key)
+    t8 := (effectiveBits + 7) / 8
+    tm := byte(255 % (int(1) << (8 + effectiveBits - 8*t8)))
+    for i := t; i < 128; i++ {
+        l[i] = rc2PiTable[l[i-1]+l[i-t]]
+    }
+    l[128-t8] = rc2PiTable[l[128-t8]&tm]
+    for i := 127 - t8; i >= 0; i-- {
+        l[i] = rc2PiTable[l[i+1]^l[i+t8]]
+    }
+    c := &rc2Cipher{}
+    for i := range c.k {
+        c.k[i] = uint16(l[2*i]) | uint16(l[2*i+1])<<8
+    }
+    return c, nil
+}
+
+
+func (c *rc2Cipher) decryptCBC(dst []byte, src []byte, iv []byte) {
+    prev := iv
+    for i := 0; i+rc2BlockSize <= len(src); i += rc2BlockSize {
+        c.decryptBlock(dst[i:i+rc2BlockSize], src[i:i+rc2BlockSize])
+        for j := 0; j < rc2BlockSize; j++ {
+            dst[i+j] ^= prev[j]
+        }
+        prev = src[i : i+rc2BlockSize]
+    }
+}
+
+
+func (c *rc2Cipher) decryptBlock(dst []byte, src []byte) {
+    r := [4]uint16{
+        uint16(src[0]) | uint16(src[1])<<8,
+        uint16(src[2]) | uint16(src[3])<<8,
+        uint16(src[4]) | uint16(src[5])<<8,
+        uint16(src[6]) | uint16(src[7])<<8,
+    }
+    rotr := func(x uint16, n uint) uint16 {
+        return x>>n | x<<(16-n)
+    }
+    j := 63
+    mix := func() {
+        r[3] = rotr(r[3], 5) - c.k[j] - (r[2] & r[1]) - (^r[2] & r[0])
+        j--
+        r[2] = rotr(r[2], 3) - c.k[j] - (r[1] & r[0]) - (^r[1] & r[3])
+        j--
+        r[1] = rotr(r[1], 2) - c.k[j] - (r[0] & r[3]) - (^r[0] & r[2])
+        j--
+        r[0] = rotr(r[0], 1) - c.k[j] - (r[3] & r[2]) - (^r[3] & r[1])
+        j--
+    }
+    mash := func() {
+        r[3] -= c.k[r[2]&63]
+        r[2] -= c.k[r[1]&63]
+        r[1] -= c.k[r[0]&63]
+        r[0] -= c.k[r[3]&63]
+    }
+    for i := 0; i < 5; i++ {
+        mix()
+    }
+    mash()
+    for i := 0; i < 6; i++ {
+        mix()
+    }
+    mash()
+    for i := 0; i < 5; i++ {
+        mix()
+    }
+    for i, v := range r {
+        dst[2*i] = byte(v)
+        dst[2*i+1] = byte(v >> 8)
+    }
+}
@@ -0,0 +1,458 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    relTypeCoreProperties         = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties"
+    relTypeExtendedProperties     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties"
+    relTypeCustomProperties       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
+    contentTypeCoreProperties     = "application/vnd.openxmlformats-package.core-properties+xml"
+    contentTypeExtendedProperties = "application/vnd.openxmlformats-officedocument.extended-properties+xml"
+    contentTypeCustomProperties   = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+    namespaceVT                   = "http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"
+    customPropertyFormatID        = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+    defaultApplication            = "github.com/jonelmawirat/docx"
+)
+
+
+type DocumentProperties struct {
+    Title          string
+    Subject        string
+    Author         string
+    Keywords       string
+    Description    string
+    Category       string
+    ContentStatus  string
+    LastModifiedBy string
+    Revision       string
+    Created        time.Time
+    Modified       time.Time
+    Company        string
+    Manager        string
+    Template       string
+    Application    string
+}
+
+
+type CustomProperty struct {
+    Name  string
+    Value interface{}
+}
+
+
+type w3cdtfDate struct {
+    Type  string `xml:"xsi:type,attr"`
+    Value string `xml:",chardata"`
+}
+
+
+type corePropertiesDocument struct {
+    XMLName        xml.Name    `xml:"cp:coreProperties"`
+    XmlnsCP        string      `xml:"xmlns:cp,attr"`
+    XmlnsDC        string      `xml:"xmlns:dc,attr"`
+    XmlnsDCTerms   string      `xml:"xmlns:dcterms,attr"`
+    XmlnsDCMIType  string      `xml:"xmlns:dcmitype,attr"`
+    XmlnsXSI       string      `xml:"xmlns:xsi,attr"`
+    Title          string      `xml:"dc:title,omitempty"`
+    Subject        string      `xml:"dc:subject,omitempty"`
+    Creator        string      `xml:"dc:creator,omitempty"`
+    Keywords       string      `xml:"cp:keywords,omitempty"`
+    Description    string      `xml:"dc:description,omitempty"`
+    LastModifiedBy string      `xml:"cp:lastModifiedBy,omitempty"`
+    Revision       string      `xml:"cp:revision,omitempty"`
+    Created        *w3cdtfDate `xml:"dcterms:created,omitempty"`
+    Modified       *w3cdtfDate `xml:"dcterms:modified,omitempty"`
+    Category       string      `xml:"cp:category,omitempty"`
+    ContentStatus  string      `xml:"cp:contentStatus,omitempty"`
+}
+
+
+type corePropertiesPart struct {
+    Title          string `xml:"title"`
+    Subject        string `xml:"subject"`
+    Creator        string `xml:"creator"`
+    Keywords       string `xml:"keywords"`
+    Description    string `xml:"description"`
+    LastModifiedBy string `xml:"lastModifiedBy"`
+    Revision       string `xml:"revision"`
+    Created        string `xml:"created"`
+    Modified       string `xml:"modified"`
+    Category       string `xml:"category"`
+    ContentStatus  string `xml:"contentStatus"`
+}
+
+
+type extendedPropertiesDocument struct {
+    XMLName     xml.Name `xml:"Properties"`
+    Xmlns       string   `xml:"xmlns,attr"`
+    XmlnsVT     string   `xml:"xmlns:vt,attr"`
+    Template    string   `xml:"Template,omitempty"`
+    Manager     string   `xml:"Manager,omitempty"`
+    Company     string   `xml:"Company,omitempty"`
+    Application string   `xml:"Application,omitempty"`
+}
+
+
+type extendedPropertiesPart struct {
+    Template    string `xml:"Template"`
+    Manager     string `xml:"Manager"`
+    Company     string `xml:"Company"`
+    Application string `xml:"Application"`
+}
+
+
+type customPropertyValue struct {
+    String   *string `xml:"vt:lpwstr,omitempty"`
+    Bool     *bool   `xml:"vt:bool,omitempty"`
+    Int32    *int64  `xml:"vt:i4,omitempty"`
+    Int64    *int64  `xml:"vt:i8,omitempty"`
+    Float    *string `xml:"vt:r8,omitempty"`
+    Filetime *string `xml:"vt:filetime,omitempty"`
+}
+
+
+type customPropertyElement struct {
+    XMLName  xml.Name `xml:"property"`
+    FormatID string   `xml:"fmtid,attr"`
+    PID      int      `xml:"pid,attr"`
+    Name     string   `xml:"name,attr"`
+    customPropertyValue
+}
+
+
+type customPropertiesDocument struct {
+    XMLName    xml.Name                `xml:"Properties"`
+    Xmlns      string                  `xml:"xmlns,attr"`
+    XmlnsVT    string                  `xml:"xmlns:vt,attr"`
+    Properties []customPropertyElement `xml:"property"`
+}
+
+
+type customPropertiesPart struct {
+    Properties []struct {
+        Name   string `xml:"name,attr"`
+        Values []struct {
+            XMLName xml.Name
+            Value   string `xml:",chardata"`
+        } `xml:",any"`
+    } `xml:"property"`
+}
+
+
+type documentMetadata struct {
+    properties DocumentProperties
+    custom     []CustomProperty
+    changed    bool
+}
+
+
+
+
+func (d *DocxDocument) Properties() DocumentProperties {
+    return d.metadata.properties
+}
+
+
+func (d *DocxDocument) SetProperties(props DocumentProperties) {
+    d.metadata.properties = props
+    d.metadata.changed = true
+}
+
+
+func (d *DocxDocument) CustomProperties() []CustomProperty {
+    return append([]CustomProperty{}, d.metadata.custom...)
+}
+
+
+func (d *DocxDocument) CustomProperty(name string) (interface{}, bool) {
+    for _, prop := range d.metadata.custom {
+        if prop.Name == name {
+            return prop.Value, true
+        }
+    }
+    return nil, false
+}
+
+
+func (d *DocxDocument) SetCustomProperty(name string, value interface{}) error {
+    if strings.TrimSpace(name) == "" {
+        return fmt.Errorf("custom property name cannot be empty")
+    }
+    normalized, err := customPropertyNormalize(value)
+    if err != nil {
+        return fmt.Errorf("invalid value for custom property %s: %w", name, err)
+    }
+
+    d.metadata.changed = true
+    for i, prop := range d.metadata.custom {
+        if prop.Name == name {
+            d.metadata.custom[i].Value = normalized
+            return nil
+        }
+    }
+    d.metadata.custom = append(d.metadata.custom, CustomProperty{Name: name, Value: normalized})
+    return nil
+}
+
+
+func (d *DocxDocument) RemoveCustomProperty(name string) {
+    for i, prop := range d.metadata.custom {
+        if prop.Name == name {
+            d.metadata.custom = append(d.metadata.custom[:i], d.metadata.custom[i+1:]...)
+            d.metadata.changed = true
+            return
+        }
+    }
+}
+
+
+func customPropertyNormalize(value interface{}) (interface{}, error) {
+    switch v := value.(type) {
+    case string, bool, int64, float64, time.Time:
+        return v, nil
+    case int:
+        return int64(v), nil
+    case int8:
+        return int64(v), nil
+    case int16:
+        return int64(v), nil
+    case int32:
+        return int64(v), nil
+    case uint8:
+        return int64(v), nil
+    case uint16:
+        return int64(v), nil
+    case uint32:
+        return int64(v), nil
+    case float32:
+        return float64(v), nil
+    }
+    return nil, fmt.Errorf("unsupported type %T: expected string, bool, integer, float or time.Time", value)
+}
+
+
+
+
+func (d *DocxDocument) loadProperties() error {
+    source := d.source
+    if name := source.packageTarget(relTypeCoreProperties); name != "" {
+        if data, ok := source.parts[name]; ok {
+            var part corePropertiesPart
+            if err := xml.Unmarshal(data, &part); err != nil {
+                return fmt.Errorf("failed to parse %s: %w", name, err)
+            }
+            props := &d.metadata.properties
+            props.Title = part.Title
+            props.Subject = part.Subject
+            props.Author = part.Creator
+            props.Keywords = part.Keywords
+            props.Description = part.Description
+            props.LastModifiedBy = part.LastModifiedBy
+            props.Revision = part.Revision
+            props.Created = parseW3CDTF(part.Created)
+            props.Modified = parseW3CDTF(part.Modified)
+            props.Category = part.Category
+            props.ContentStatus = part.ContentStatus
+        }
+    }
+
+    if name := source.packageTarget(relTypeExtendedProperties); name != "" {
+        if data, ok := source.parts[name]; ok {
+            var part extendedPropertiesPart
+            if err := xml.Unmarshal(data, &part); err != nil {
+                return fmt.Errorf("failed to parse %s: %w", name, err)
+            }
+            props := &d.metadata.properties
+            props.Template = part.Template
+            props.Manager = part.Manager
+            props.Company = part.Company
+            props.Application = part.Application
+        }
+    }
+
+    if name := source.packageTarget(relTypeCustomProperties); name != "" {
+        if data, ok := source.parts[name]; ok {
+            var part customPropertiesPart
+            if err := xml.Unmarshal(data, &part); err != nil {
+                return fmt.Errorf("failed to parse %s: %w", name, err)
+            }
+            for _, prop := range part.Properties {
+                if prop.Name == "" || len(prop.Values) == 0 {
+                    continue
+                }
+                value := prop.Values[0]
+                d.metadata.custom = append(d.metadata.custom, CustomProperty{
+                    Name:  prop.Name,
+                    Value: parseCustomPropertyValue(value.XMLName.Local, value.Value),
+                })
+            }
+        }
+    }
+    return nil
+}
+
+
+func parseW3CDTF(value string) time.Time {
+    value = strings.TrimSpace(value)
+    for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "2006-01", "2006"} {
+        if t, err := time.Parse(layout, value); err == nil {
+            return t
+        }
+    }
+    return time.Time{}
+}
+
+
+func parseCustomPropertyValue(kind string, value string) interface{} {
+    value = strings.TrimSpace(value)
+    switch kind {
+    case "bool":
+        return value == "true" || value == "1"
+    case "i1", "i2", "i4", "i8", "int", "ui1", "ui2", "ui4", "ui8", "uint":
+        if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+            return n
+        }
+    case "r4", "r8", "decimal":
+        if f, err := strconv.ParseFloat(value, 64); err == nil {
+            return f
+        }
+    case "filetime", "date":
+        if t := parseW3CDTF(value); !t.IsZero() {
+            return t
+        }
+    }
+    return value
+}
+
+
+
+
+func (d *DocxDocument) propertyParts() ([]packagePart, error) {
+    if d.source != nil && !d.metadata.changed {
+        return nil, nil
+    }
+
+    props := d.metadata.properties
+    core := corePropertiesDocument{
+        XmlnsCP:        "http://schemas.openxmlformats.org/package/2006/metadata/core-properties",
+        XmlnsDC:        "http://purl.org/dc/elements/1.1/",
+        XmlnsDCTerms:   "http://purl.org/dc/terms/",
+        XmlnsDCMIType:  "http://purl.org/dc/dcmitype/",
+        XmlnsXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+        Title:          props.Title,
+        Subject:        props.Subject,
+        Creator:        props.Author,
+        Keywords:       props.Keywords,
+        Description:    props.Description,
+        LastModifiedBy: props.LastModifiedBy,
+        Revision:       props.Revision,
+        Category:       props.Category,
+        ContentStatus:  props.ContentStatus,
+    }
+    if !props.Created.IsZero() {
+        core.Created = &w3cdtfDate{Type: "dcterms:W3CDTF", Value: props.Created.UTC().Format(time.RFC3339)}
+    }
+    if !props.Modified.IsZero() {
+        core.Modified = &w3cdtfDate{Type: "dcterms:W3CDTF", Value: props.Modified.UTC().Format(time.RFC3339)}
+    }
+
+    application := props.Application
+    if application == "" {
+        application = defaultApplication
+    }
+    app := extendedPropertiesDocument{
+        Xmlns:       "http://schemas.openxmlformats.org/officeDocument/2006/extended-properties",
+        XmlnsVT:     namespaceVT,
+        Template:    props.Template,
+        Manager:     props.Manager,
+        Company:     props.Company,
+        Application: application,
+    }
+
+    parts := []packagePart{}
+    add := func(relType string, defaultName string, contentType string, data interface{}) error {
+        name := defaultName
+        if d.source != nil && d.source.packageTarget(relType) != "" {
+            name = d.source.packageTarget(relType)
+        }
+        encoded, err := encodeXMLPart(data)
+        if err != nil {
+            return fmt.Errorf("failed to encode %s: %w", name, err)
+        }
+        parts = append(parts, packagePart{
+            name:        name,
+            contentType: contentType,
+            data:        encoded,
+            rootRel:     &relationship{Type: relType, Target: name},
+        })
+        return nil
+    }
+
+    if err := add(relTypeCoreProperties, "docProps/core.xml", contentTypeCoreProperties, core); err != nil {
+        return nil, err
+    }
+    if err := add(relTypeExtendedProperties, "docProps/app.xml", contentTypeExtendedProperties, app); err != nil {
+        return nil, err
+    }
+
+    if len(d.metadata.custom) == 0 && (d.source == nil || d.source.packageTarget(relTypeCustomProperties) == "") {
+        return parts, nil
+    }
+    custom := customPropertiesDocument{
+        Xmlns:   "http://schemas.openxmlformats.org/officeDocument/2006/custom-properties",
+        XmlnsVT: namespaceVT,
+    }
+    for i, prop := range d.metadata.custom {
+        custom.Properties = append(custom.Properties, customPropertyElement{
+            FormatID:            customPropertyFormatID,
+            PID:                 i + 2,
+            Name:                prop.Name,
+            customPropertyValue: newCustomPropertyValue(prop.Value),
+        })
+    }
+    if err := add(relTypeCustomProperties, "docProps/custom.xml", contentTypeCustomProperties, custom); err != nil {
+        return nil, err
+    }
+    return parts, nil
+}
+
+
+func newCustomPropertyValue(value interface{}) customPropertyValue {
+    switch v := value.(type) {
+    case bool:
+        return customPropertyValue{Bool: &v}
+    case int64:
+        if v >= math.MinInt32 && v <= math.MaxInt32 {
+            return customPropertyValue{Int32: &v}
+        }
+        return customPropertyValue{Int64: &v}
+    case float64:
+        text := strconv.FormatFloat(v, 'g', -1, 64)
+        return customPropertyValue{Float: &text}
+    case time.Time:
+        text := v.UTC().Format("2006-01-02T15:04:05Z")
+        return customPropertyValue{Filetime: &text}
+    }
+    text := fmt.Sprint(value)
+    return customPropertyValue{String: &text}
+}
+
+
+func encodeXMLPart(data interface{}) ([]byte, error) {
+    var buf bytes.Buffer
+    buf.WriteString(xml.Header)
+    encoder := xml.NewEncoder(&buf)
+    encoder.Indent("", "  ")
+    if err := encoder.Encode(data); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
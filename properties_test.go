@@ -0,0 +1,119 @@
+package docx
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+
+func TestDocumentPropertiesRoundTrip(t *testing.T) {
+    created := time.Date(2024, 2, 29, 8, 30, 0, 0, time.UTC)
+    props := DocumentProperties{
+        Title:          "Q3 <Report> & Summary",
+        Subject:        "Finance",
+        Author:         "Ada Lovelace",
+        Keywords:       "finance; quarterly",
+        Description:    "Numbers for the board",
+        Category:       "Reports",
+        ContentStatus:  "Final",
+        LastModifiedBy: "Grace Hopper",
+        Revision:       "3",
+        Created:        created,
+        Modified:       created.Add(36 * time.Hour),
+        Company:        "Analytical Engines Ltd",
+        Manager:        "Charles Babbage",
+        Template:       "Report.dotx",
+        Application:    "Report Generator",
+    }
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Body")
+    doc.SetProperties(props)
+    custom := []CustomProperty{
+        {"Client", "Acme & Co"},
+        {"Approved", true},
+        {"Pages", 12},
+        {"Budget", 1250.5},
+        {"Due", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)},
+    }
+    for _, prop := range custom {
+        if err := doc.SetCustomProperty(prop.Name, prop.Value); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := doc.SetCustomProperty("Scores", []int{1, 2}); err == nil {
+        t.Error("SetCustomProperty accepted a slice")
+    }
+    if err := doc.SetCustomProperty(" ", "x"); err == nil {
+        t.Error("SetCustomProperty accepted an empty name")
+    }
+
+    reopened := reopenDocument(t, doc)
+    if got := reopened.Properties(); got != props {
+        t.Errorf("Properties() = %+v, want %+v", got, props)
+    }
+    got := reopened.CustomProperties()
+    if len(got) != len(custom) {
+        t.Fatalf("got %d custom properties, want %d", len(got), len(custom))
+    }
+    for i, prop := range custom {
+        want := prop.Value
+        if n, ok := want.(int); ok {
+            want = int64(n)
+        }
+        if got[i].Name != prop.Name || got[i].Value != want {
+            t.Errorf("custom property %d = %v (%T), want %s = %v (%T)", i, got[i], got[i].Value, prop.Name, want, want)
+        }
+    }
+
+    reopened.RemoveCustomProperty("Budget")
+    if err := reopened.SetCustomProperty("Pages", 14); err != nil {
+        t.Fatal(err)
+    }
+    updated := reopenDocument(t, reopened)
+    if _, ok := updated.CustomProperty("Budget"); ok {
+        t.Error("removed custom property is still present")
+    }
+    if pages, _ := updated.CustomProperty("Pages"); pages != int64(14) {
+        t.Errorf("Pages = %v, want 14", pages)
+    }
+    if updated.Properties().Title != props.Title {
+        t.Errorf("Title after updating custom properties = %q", updated.Properties().Title)
+    }
+}
+
+
+func TestDocumentPropertiesPackageParts(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Body")
+    doc.SetProperties(DocumentProperties{Title: "Plain"})
+    filename := filepath.Join(t.TempDir(), "props.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, parts, err := readPackageParts(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, name := range []string{"docProps/core.xml", "docProps/app.xml"} {
+        xmlText(t, name, parts[name])
+        if !strings.Contains(string(parts["_rels/.rels"]), `Target="`+name+`"`) {
+            t.Errorf("root relationships do not reference %s", name)
+        }
+        if !strings.Contains(string(parts["[Content_Types].xml"]), `PartName="/`+name+`"`) {
+            t.Errorf("content types do not declare %s", name)
+        }
+    }
+    if _, ok := parts["docProps/custom.xml"]; ok {
+        t.Error("custom properties part written without custom properties")
+    }
+    if !strings.Contains(string(parts["docProps/app.xml"]), "<Application>"+defaultApplication+"</Application>") {
+        t.Error("extended properties do not name the default application")
+    }
+}
@@ -0,0 +1,226 @@
+package docx
+
+import (
+    "crypto/rand"
+    "crypto/sha512"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/xml"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+const (
+    ProtectReadOnly       = "readOnly"
+    ProtectForms          = "forms"
+    ProtectComments       = "comments"
+    ProtectTrackedChanges = "trackedChanges"
+)
+
+const (
+    EditorEveryone       = "everyone"
+    EditorCurrent        = "current"
+    EditorEditors        = "editors"
+    EditorOwners         = "owners"
+    EditorContributors   = "contributors"
+    EditorAdministrators = "administrators"
+)
+
+const (
+    protectionSpinCount   = 100000
+    protectionSaltLength  = 16
+    protectionMaxPassword = 15
+)
+
+var protectionInitialCodes = []uint16{
+    0xE1F0, 0x1D0F, 0xCC9C, 0x84C0, 0x110C, 0x0E10, 0xF1CE, 0x313E, 0x1872, 0xE139, 0xD40F, 0x84F9, 0x280C, 0xA96A, 0x4EC3,
+}
+
+var protectionEncryptionMatrix = [][]uint16{
+    {0xAEFC, 0x4DD9, 0x9BB2, 0x2745, 0x4E8A, 0x9D14, 0x2A09},
+    {0x7B61, 0xF6C2, 0xFDA5, 0xEB6B, 0xC6F7, 0x9DCF, 0x2BBF},
+    {0x4563, 0x8AC6, 0x05AD, 0x0B5A, 0x16B4, 0x2D68, 0x5AD0},
+    {0x0375, 0x06EA, 0x0DD4, 0x1BA8, 0x3750, 0x6EA0, 0xDD40},
+    {0xD849, 0xA0B3, 0x5147, 0xA28E, 0x553D, 0xAA7A, 0x44D5},
+    {0x6F45, 0xDE8A, 0xAD35, 0x4A4B, 0x9496, 0x390D, 0x721A},
+    {0xEB23, 0xC667, 0x9CEF, 0x29FF, 0x53FE, 0xA7FC, 0x5FD9},
+    {0x47D3, 0x8FA6, 0x0F6D, 0x1EDA, 0x3DB4, 0x7B68, 0xF6D0},
+    {0xB861, 0x60E3, 0xC1C6, 0x93AD, 0x377B, 0x6EF6, 0xDDEC},
+    {0x45A0, 0x8B40, 0x06A1, 0x0D42, 0x1A84, 0x3508, 0x6A10},
+    {0xAA51, 0x4483, 0x8906, 0x022D, 0x045A, 0x08B4, 0x1168},
+    {0x76B4, 0xED68, 0xCAF1, 0x85C3, 0x1BA7, 0x374E, 0x6E9C},
+    {0x3730, 0x6E60, 0xDCC0, 0xA9A1, 0x4363, 0x86C6, 0x1DAD},
+    {0x3331, 0x6662, 0xCCC4, 0x89A9, 0x0373, 0x06E6, 0x0DCC},
+    {0x1021, 0x2042, 0x4084, 0x0881, 0x1102, 0x2204, 0x4408},
+}
+
+
+type documentProtection struct {
+    Edit                string `xml:"w:edit,attr"`
+    Enforcement         string `xml:"w:enforcement,attr"`
+    CryptProviderType   string `xml:"w:cryptProviderType,attr,omitempty"`
+    CryptAlgorithmClass string `xml:"w:cryptAlgorithmClass,attr,omitempty"`
+    CryptAlgorithmType  string `xml:"w:cryptAlgorithmType,attr,omitempty"`
+    CryptAlgorithmSid   string `xml:"w:cryptAlgorithmSid,attr,omitempty"`
+    CryptSpinCount      string `xml:"w:cryptSpinCount,attr,omitempty"`
+    Hash                string `xml:"w:hash,attr,omitempty"`
+    Salt                string `xml:"w:salt,attr,omitempty"`
+}
+
+
+type permissionStart struct {
+    XMLName     xml.Name `xml:"w:permStart"`
+    ID          int      `xml:"w:id,attr"`
+    EditorGroup string   `xml:"w:edGrp,attr,omitempty"`
+    Editor      string   `xml:"w:ed,attr,omitempty"`
+}
+
+type permissionEnd struct {
+    XMLName xml.Name `xml:"w:permEnd"`
+    ID      int      `xml:"w:id,attr"`
+}
+
+
+
+
+func (d *DocxDocument) Protect(mode string, password string) error {
+    switch mode {
+    case ProtectReadOnly, ProtectForms, ProtectComments, ProtectTrackedChanges:
+    default:
+        return fmt.Errorf("unsupported protection mode: %s", mode)
+    }
+
+    protection := &documentProtection{Edit: mode, Enforcement: "1"}
+    if password != "" {
+        salt := make([]byte, protectionSaltLength)
+        if _, err := rand.Read(salt); err != nil {
+            return fmt.Errorf("failed to generate protection salt: %w", err)
+        }
+        protection.CryptProviderType = "rsaAES"
+        protection.CryptAlgorithmClass = "hash"
+        protection.CryptAlgorithmType = "typeAny"
+        protection.CryptAlgorithmSid = "14"
+        protection.CryptSpinCount = strconv.Itoa(protectionSpinCount)
+        protection.Hash = base64.StdEncoding.EncodeToString(protectionHash(password, salt, protectionSpinCount))
+        protection.Salt = base64.StdEncoding.EncodeToString(salt)
+    }
+    d.settings.set("w:documentProtection")
+    d.settings.DocumentProtection = protection
+    return nil
+}
+
+
+func (d *DocxDocument) Unprotect() {
+    d.settings.set("w:documentProtection")
+    d.settings.DocumentProtection = nil
+}
+
+
+func protectionHash(password string, salt []byte, spinCount int) []byte {
+    key := legacyPasswordKey(password)
+    legacy := fmt.Sprintf("%02X%02X%02X%02X", byte(key), byte(key>>8), byte(key>>16), byte(key>>24))
+
+    input := append([]byte{}, salt...)
+    for _, c := range legacy {
+        input = append(input, byte(c), 0)
+    }
+    sum := sha512.Sum512(input)
+    hash := sum[:]
+    iterator := make([]byte, 4)
+    for i := 0; i < spinCount; i++ {
+        binary.LittleEndian.PutUint32(iterator, uint32(i))
+        sum = sha512.Sum512(append(hash, iterator...))
+        hash = sum[:]
+    }
+    return hash
+}
+
+
+func legacyPasswordKey(password string) uint32 {
+    chars := []byte{}
+    for _, r := range password {
+        if len(chars) == protectionMaxPassword {
+            break
+        }
+        c := uint16(r)
+        if low := byte(c); low != 0 {
+            chars = append(chars, low)
+        } else {
+            chars = append(chars, byte(c>>8))
+        }
+    }
+    if len(chars) == 0 {
+        return 0
+    }
+
+    high := protectionInitialCodes[len(chars)-1]
+    for i, c := range chars {
+        row := protectionEncryptionMatrix[protectionMaxPassword-len(chars)+i]
+        for bit := 0; bit < 7; bit++ {
+            if c&(1<<bit) != 0 {
+                high ^= row[bit]
+            }
+        }
+    }
+
+    rotate := func(v uint16) uint16 {
+        return (v>>14)&1 | (v<<1)&0x7FFF
+    }
+    var low uint16
+    for i := len(chars) - 1; i >= 0; i-- {
+        low = rotate(low) ^ uint16(chars[i])
+    }
+    low = rotate(low) ^ uint16(len(chars)) ^ 0xCE4B
+    return uint32(high)<<16 | uint32(low)
+}
+
+
+
+
+func (p *Paragraph) AllowEditing(editor string) error {
+    if strings.TrimSpace(editor) == "" {
+        return fmt.Errorf("editor cannot be empty")
+    }
+    for _, c := range p.data.Content {
+        if _, ok := c.(*permissionStart); ok {
+            return fmt.Errorf("paragraph already has an editable range")
+        }
+    }
+
+    start := &permissionStart{ID: p.doc.nextPermissionID()}
+    switch editor {
+    case EditorEveryone, EditorCurrent, EditorEditors, EditorOwners, EditorContributors, EditorAdministrators:
+        start.EditorGroup = editor
+    default:
+        start.Editor = editor
+    }
+    content := append([]interface{}{start}, p.data.Content...)
+    p.data.Content = append(content, &permissionEnd{ID: start.ID})
+    return nil
+}
+
+
+func (d *DocxDocument) nextPermissionID() int {
+    items := append([]interface{}{}, d.content...)
+    for _, para := range d.paragraphs() {
+        items = append(items, para.Content...)
+    }
+
+    next := 0
+    for _, item := range items {
+        id := -1
+        switch v := item.(type) {
+        case *permissionStart:
+            id = v.ID
+        case *rawXML:
+            if value, ok := v.attr("w:id"); ok && v.XMLName.Local == "w:permStart" {
+                id, _ = strconv.Atoi(value)
+            }
+        }
+        if id >= next {
+            next = id + 1
+        }
+    }
+    return next
+}
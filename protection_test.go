@@ -0,0 +1,125 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/xml"
+    "path/filepath"
+    "strconv"
+    "testing"
+)
+
+
+func TestLegacyPasswordVerifier(t *testing.T) {
+    tests := []struct {
+        password string
+        verifier uint16
+    }{
+        {"password", 0x83AF},
+        {"test", 0xCBEB},
+        {"", 0},
+    }
+    for _, tt := range tests {
+        if got := uint16(legacyPasswordKey(tt.password)); got != tt.verifier {
+            t.Errorf("verifier of %q = %04X, want %04X", tt.password, got, tt.verifier)
+        }
+    }
+    if legacyPasswordKey("sixteen-chars-xx") != legacyPasswordKey("sixteen-chars-x") {
+        t.Error("passwords are not truncated to 15 characters")
+    }
+}
+
+
+func TestDocumentProtectionRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Terms that cannot be edited")
+    signature := doc.AddParagraph(StyleNormal)
+    signature.AddRun("Signature: ")
+    if err := signature.AllowEditing(EditorEveryone); err != nil {
+        t.Fatal(err)
+    }
+    if err := signature.AllowEditing("ada@example.com"); err == nil {
+        t.Error("AllowEditing added a second range to the same paragraph")
+    }
+    date := doc.AddParagraph(StyleNormal)
+    date.AddRun("Date: ")
+    if err := date.AllowEditing("ada@example.com"); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.Protect("everything", ""); err == nil {
+        t.Error("Protect accepted an unknown mode")
+    }
+    doc.SetTrackRevisions(true)
+    if err := doc.Protect(ProtectReadOnly, "s3cret"); err != nil {
+        t.Fatal(err)
+    }
+
+    dir := t.TempDir()
+    filename := filepath.Join(dir, "protected.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    settings, names := settingsXML(t, filename)
+    checkSettingsOrder(t, names)
+    var parsed struct {
+        Protection struct {
+            Edit        string `xml:"edit,attr"`
+            Enforcement string `xml:"enforcement,attr"`
+            SID         string `xml:"cryptAlgorithmSid,attr"`
+            SpinCount   string `xml:"cryptSpinCount,attr"`
+            Hash        string `xml:"hash,attr"`
+            Salt        string `xml:"salt,attr"`
+        } `xml:"documentProtection"`
+    }
+    if err := xml.Unmarshal(settings, &parsed); err != nil {
+        t.Fatal(err)
+    }
+    p := parsed.Protection
+    if p.Edit != ProtectReadOnly || p.Enforcement != "1" || p.SID != "14" {
+        t.Fatalf("documentProtection = %+v", p)
+    }
+    salt, err := base64.StdEncoding.DecodeString(p.Salt)
+    if err != nil || len(salt) != protectionSaltLength {
+        t.Fatalf("salt %q is not %d base64 bytes", p.Salt, protectionSaltLength)
+    }
+    spinCount, _ := strconv.Atoi(p.SpinCount)
+    hash := base64.StdEncoding.EncodeToString(protectionHash("s3cret", salt, spinCount))
+    if p.Hash != hash {
+        t.Error("stored hash does not match the password")
+    }
+    if p.Hash == base64.StdEncoding.EncodeToString(protectionHash("S3cret", salt, spinCount)) {
+        t.Error("hash does not depend on the password's case")
+    }
+
+    reopened := reopenDocument(t, doc)
+    paras := reopened.paragraphs()
+    ranges := []string{}
+    for _, para := range paras {
+        for _, c := range para.Content {
+            if el, ok := c.(*rawXML); ok && (el.XMLName.Local == "w:permStart" || el.XMLName.Local == "w:permEnd") {
+                id, _ := el.attr("w:id")
+                editor, _ := el.attr("w:edGrp")
+                if ed, ok := el.attr("w:ed"); ok {
+                    editor = ed
+                }
+                ranges = append(ranges, el.XMLName.Local+" "+id+" "+editor)
+            }
+        }
+    }
+    want := []string{"w:permStart 0 everyone", "w:permEnd 0 ", "w:permStart 1 ada@example.com", "w:permEnd 1 "}
+    if !equalStrings(ranges, want) {
+        t.Errorf("editable ranges = %q, want %q", ranges, want)
+    }
+    if id := reopened.nextPermissionID(); id != 2 {
+        t.Errorf("next permission ID after reopening = %d, want 2", id)
+    }
+
+    reopened.Unprotect()
+    resaved := filepath.Join(dir, "unprotected.docx")
+    if err := NewZipDocxWriter().WriteDocument(resaved, reopened); err != nil {
+        t.Fatal(err)
+    }
+    if settings, _ := settingsXML(t, resaved); bytes.Contains(settings, []byte("documentProtection")) {
+        t.Error("Unprotect left the documentProtection element")
+    }
+}
@@ -0,0 +1,204 @@
+package docx
+
+import (
+    "encoding/xml"
+    "reflect"
+    "sort"
+    "strings"
+)
+
+const (
+    namespaceW   = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+    namespaceR   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+    namespaceWp  = "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+    namespaceA   = "http://schemas.openxmlformats.org/drawingml/2006/main"
+    namespacePic = "http://schemas.openxmlformats.org/drawingml/2006/picture"
+    namespaceXML = "http://www.w3.org/XML/1998/namespace"
+)
+
+
+var knownNamespaces = map[string]string{
+    namespaceW:   "w",
+    namespaceR:   "r",
+    namespaceWp:  "wp",
+    namespaceA:   "a",
+    namespacePic: "pic",
+    namespaceXML: "xml",
+}
+
+
+type rawXML struct {
+    XMLName  xml.Name
+    Attrs    []xml.Attr
+    Children []interface{}
+}
+
+
+func (r *rawXML) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    start = xml.StartElement{Name: r.XMLName, Attr: r.Attrs}
+    if err := e.EncodeToken(start); err != nil {
+        return err
+    }
+    for _, child := range r.Children {
+        switch v := child.(type) {
+        case *rawXML:
+            if err := e.Encode(v); err != nil {
+                return err
+            }
+        case xml.CharData:
+            if err := e.EncodeToken(v); err != nil {
+                return err
+            }
+        default:
+            if err := e.Encode(v); err != nil {
+                return err
+            }
+        }
+    }
+    return e.EncodeToken(start.End())
+}
+
+
+func (r *rawXML) attr(name string) (string, bool) {
+    for _, a := range r.Attrs {
+        if a.Name.Local == name {
+            return a.Value, true
+        }
+    }
+    return "", false
+}
+
+
+func (r *rawXML) hasOnlyAttrs(names ...string) bool {
+    for _, a := range r.Attrs {
+        known := false
+        for _, name := range names {
+            if a.Name.Local == name {
+                known = true
+                break
+            }
+        }
+        if !known {
+            return false
+        }
+    }
+    return true
+}
+
+
+func (r *rawXML) elements() []*rawXML {
+    elements := []*rawXML{}
+    for _, child := range r.Children {
+        if el, ok := child.(*rawXML); ok {
+            elements = append(elements, el)
+        }
+    }
+    return elements
+}
+
+
+func (r *rawXML) text() string {
+    var sb strings.Builder
+    for _, child := range r.Children {
+        if data, ok := child.(xml.CharData); ok {
+            sb.Write(data)
+        }
+    }
+    return sb.String()
+}
+
+
+func (r *rawXML) find(name string) *rawXML {
+    for _, el := range r.elements() {
+        if el.XMLName.Local == name {
+            return el
+        }
+        if found := el.find(name); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
+
+type containerElement struct {
+    XMLName xml.Name
+    Attrs   []xml.Attr
+    Content []interface{}
+}
+
+
+func (c *containerElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    start = xml.StartElement{Name: c.XMLName, Attr: c.Attrs}
+    if err := e.EncodeToken(start); err != nil {
+        return err
+    }
+    for _, child := range c.Content {
+        if err := e.Encode(child); err != nil {
+            return err
+        }
+    }
+    return e.EncodeToken(start.End())
+}
+
+
+func marshalOrdered(e *xml.Encoder, start xml.StartElement, v interface{}, order []string) error {
+    type child struct {
+        name  string
+        rank  int
+        value interface{}
+    }
+    rank := func(name string) int {
+        for i, n := range order {
+            if n == name {
+                return i
+            }
+        }
+        return len(order)
+    }
+
+    rv := reflect.ValueOf(v).Elem()
+    rt := rv.Type()
+    children := []child{}
+    typed := make(map[string]bool)
+    var extras []*rawXML
+    for i := 0; i < rt.NumField(); i++ {
+        field := rt.Field(i)
+        tag := field.Tag.Get("xml")
+        value := rv.Field(i)
+        switch {
+        case strings.Contains(tag, ",any,attr"):
+            attrs, _ := value.Interface().([]xml.Attr)
+            start.Attr = append(start.Attr, attrs...)
+        case field.Name == "XMLName" || strings.Contains(tag, ",attr"):
+            continue
+        case strings.Contains(tag, ",any"):
+            extras, _ = value.Interface().([]*rawXML)
+        case value.Kind() == reflect.Ptr && !value.IsNil():
+            name := strings.Split(tag, ",")[0]
+            typed[name] = true
+            children = append(children, child{name: name, rank: rank(name), value: value.Interface()})
+        }
+    }
+
+
+
+    for _, extra := range extras {
+        if !typed[extra.XMLName.Local] {
+            children = append(children, child{name: extra.XMLName.Local, rank: rank(extra.XMLName.Local), value: extra})
+        }
+    }
+    sort.SliceStable(children, func(i, j int) bool {
+        return children[i].rank < children[j].rank
+    })
+
+    if err := e.EncodeToken(start); err != nil {
+        return err
+    }
+    for _, c := range children {
+        if err := e.EncodeElement(c.value, xml.StartElement{Name: xml.Name{Local: c.name}}); err != nil {
+            return err
+        }
+    }
+    return e.EncodeToken(start.End())
+}
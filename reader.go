@@ -0,0 +1,907 @@
+package docx
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "os"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+const (
+    relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+    relTypeStyles         = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+    relTypeSettings       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
+)
+
+var (
+    relationshipIDPattern = regexp.MustCompile(`^rId(\d+)$`)
+    mediaNamePattern      = regexp.MustCompile(`^image(\d+)\.`)
+)
+
+
+type sourcePackage struct {
+    parts            map[string][]byte
+    partNames        []string
+    contentTypes     types
+    documentPart     string
+    documentRels     []relationship
+    settingsPart     string
+    generateSettings bool
+    rootRels         []relationship
+    rootAttrs        []xml.Attr
+}
+
+
+func (p *sourcePackage) documentDir() string {
+    return path.Dir(p.documentPart)
+}
+
+
+func (p *sourcePackage) relationshipsPart() string {
+    return path.Join(p.documentDir(), "_rels", path.Base(p.documentPart)+".rels")
+}
+
+
+func (p *sourcePackage) resolveTarget(target string) string {
+    if strings.HasPrefix(target, "/") {
+        return strings.TrimPrefix(target, "/")
+    }
+    return path.Join(p.documentDir(), target)
+}
+
+
+func (p *sourcePackage) packageTarget(relType string) string {
+    for _, rel := range p.rootRels {
+        if rel.Type == relType && rel.TargetMode != "External" {
+            return strings.TrimPrefix(rel.Target, "/")
+        }
+    }
+    return ""
+}
+
+
+func (p *sourcePackage) relationshipTarget(relType string) string {
+    for _, rel := range p.documentRels {
+        if rel.Type == relType && rel.TargetMode != "External" {
+            return p.resolveTarget(rel.Target)
+        }
+    }
+    return ""
+}
+
+
+func OpenDocxDocument(filename string) (*DocxDocument, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
+    }
+    defer file.Close()
+
+    info, err := file.Stat()
+    if err != nil {
+        return nil, fmt.Errorf("failed to stat file %s: %w", filename, err)
+    }
+    return ReadDocxDocument(file, info.Size())
+}
+
+
+func ReadDocxDocument(r io.ReaderAt, size int64) (*DocxDocument, error) {
+    signature := make([]byte, len(cfbSignature))
+    if _, err := r.ReadAt(signature, 0); err == nil && isCompoundFile(signature) {
+        return nil, fmt.Errorf("docx package is encrypted: open it with OpenEncryptedDocxDocument")
+    }
+    zipReader, err := zip.NewReader(r, size)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open docx package: %w", err)
+    }
+
+    pkg := &sourcePackage{parts: make(map[string][]byte)}
+    for _, f := range zipReader.File {
+        if strings.HasSuffix(f.Name, "/") {
+            continue
+        }
+        data, err := readZipFile(f)
+        if err != nil {
+            return nil, err
+        }
+        pkg.parts[f.Name] = data
+        pkg.partNames = append(pkg.partNames, f.Name)
+    }
+
+    contentTypes, ok := pkg.parts["[Content_Types].xml"]
+    if !ok {
+        return nil, fmt.Errorf("docx package has no [Content_Types].xml part")
+    }
+    if err := xml.Unmarshal(contentTypes, &pkg.contentTypes); err != nil {
+        return nil, fmt.Errorf("failed to parse [Content_Types].xml: %w", err)
+    }
+
+    pkg.documentPart = "word/document.xml"
+    if data, ok := pkg.parts["_rels/.rels"]; ok {
+        var rootRels relationships
+        if err := xml.Unmarshal(data, &rootRels); err != nil {
+            return nil, fmt.Errorf("failed to parse _rels/.rels: %w", err)
+        }
+        pkg.rootRels = rootRels.Relationships
+        for _, rel := range rootRels.Relationships {
+            if rel.Type == relTypeOfficeDocument {
+                pkg.documentPart = strings.TrimPrefix(rel.Target, "/")
+                break
+            }
+        }
+    }
+    if pkg.packageTarget(relTypeOfficeDocument) == "" {
+        pkg.rootRels = append(pkg.rootRels, relationship{ID: "rId1", Type: relTypeOfficeDocument, Target: pkg.documentPart})
+    }
+
+    documentXML, ok := pkg.parts[pkg.documentPart]
+    if !ok {
+        return nil, fmt.Errorf("docx package has no main document part %s", pkg.documentPart)
+    }
+    if data, ok := pkg.parts[pkg.relationshipsPart()]; ok {
+        var docRels relationships
+        if err := xml.Unmarshal(data, &docRels); err != nil {
+            return nil, fmt.Errorf("failed to parse %s: %w", pkg.relationshipsPart(), err)
+        }
+        pkg.documentRels = docRels.Relationships
+    }
+
+    doc := NewDocxDocument()
+    doc.source = pkg
+    doc.sectPr = nil
+    doc.lastRID = 0
+    for _, rel := range pkg.documentRels {
+        if m := relationshipIDPattern.FindStringSubmatch(rel.ID); m != nil {
+            if n, err := strconv.Atoi(m[1]); err == nil && n > doc.lastRID {
+                doc.lastRID = n
+            }
+        }
+    }
+
+    reader := newDocumentReader(doc)
+    if err := reader.readDocument(documentXML); err != nil {
+        return nil, fmt.Errorf("failed to parse %s: %w", pkg.documentPart, err)
+    }
+
+    for _, name := range pkg.partNames {
+        if m := mediaNamePattern.FindStringSubmatch(path.Base(name)); m != nil {
+            if n, err := strconv.Atoi(m[1]); err == nil && uint(n) > doc.imageCounter {
+                doc.imageCounter = uint(n)
+            }
+        }
+    }
+
+
+
+    if pkg.relationshipTarget(relTypeStyles) == "" {
+        stylesPart := path.Join(pkg.documentDir(), "styles.xml")
+        pkg.documentRels = append(pkg.documentRels, relationship{ID: doc.nextRID(), Type: relTypeStyles, Target: "styles.xml"})
+        pkg.parts[stylesPart] = []byte(defaultStylesXML)
+        pkg.partNames = append(pkg.partNames, stylesPart)
+        pkg.contentTypes.Overrides = append(pkg.contentTypes.Overrides, overrideType{
+            PartName:    "/" + stylesPart,
+            ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml",
+        })
+    }
+    if numberingPart := pkg.relationshipTarget(relTypeNumbering); numberingPart != "" {
+        if data, ok := pkg.parts[numberingPart]; ok {
+            if err := doc.loadNumbering(data); err != nil {
+                return nil, fmt.Errorf("failed to parse %s: %w", numberingPart, err)
+            }
+        }
+    }
+    if err := doc.loadProperties(); err != nil {
+        return nil, err
+    }
+    doc.loadCustomXML()
+    pkg.settingsPart = pkg.relationshipTarget(relTypeSettings)
+    doc.settings = documentSettings{}
+    if data, ok := pkg.parts[pkg.settingsPart]; ok && pkg.settingsPart != "" {
+        if err := doc.loadSettings(data); err != nil {
+            return nil, fmt.Errorf("failed to parse %s: %w", pkg.settingsPart, err)
+        }
+    }
+    if pkg.settingsPart == "" {
+        pkg.settingsPart = path.Join(pkg.documentDir(), "settings.xml")
+        pkg.generateSettings = true
+        pkg.documentRels = append(pkg.documentRels, relationship{ID: doc.nextRID(), Type: relTypeSettings, Target: "settings.xml"})
+        pkg.contentTypes.Overrides = append(pkg.contentTypes.Overrides, overrideType{
+            PartName:    "/" + pkg.settingsPart,
+            ContentType: contentTypeSettings,
+        })
+    }
+
+    return doc, nil
+}
+
+
+func readZipFile(f *zip.File) ([]byte, error) {
+    rc, err := f.Open()
+    if err != nil {
+        return nil, fmt.Errorf("failed to open %s in zip: %w", f.Name, err)
+    }
+    defer rc.Close()
+
+    data, err := io.ReadAll(rc)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read %s from zip: %w", f.Name, err)
+    }
+    return data, nil
+}
+
+
+func (d *DocxDocument) getSourcePackage() *sourcePackage {
+    return d.source
+}
+
+
+type documentReader struct {
+    doc        *DocxDocument
+    prefixes   map[string]string
+    used       map[string]bool
+    declared   []xml.Attr
+    nextPrefix int
+}
+
+
+func newDocumentReader(doc *DocxDocument) *documentReader {
+    r := &documentReader{
+        doc:      doc,
+        prefixes: make(map[string]string),
+        used:     make(map[string]bool),
+    }
+    for uri, prefix := range knownNamespaces {
+        r.prefixes[uri] = prefix
+        r.used[prefix] = true
+    }
+    return r
+}
+
+
+
+
+func (r *documentReader) declare(prefix string, uri string) {
+    if _, ok := r.prefixes[uri]; ok {
+        return
+    }
+    if prefix == "" || r.used[prefix] {
+        for {
+            r.nextPrefix++
+            prefix = fmt.Sprintf("ns%d", r.nextPrefix)
+            if !r.used[prefix] {
+                break
+            }
+        }
+    }
+    r.prefixes[uri] = prefix
+    r.used[prefix] = true
+    r.declared = append(r.declared, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: uri})
+}
+
+
+func (r *documentReader) namespaceDeclarations() []xml.Attr {
+    attrs := []xml.Attr{}
+    for uri, prefix := range knownNamespaces {
+        if prefix != "xml" {
+            attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: uri})
+        }
+    }
+    sort.Slice(attrs, func(i, j int) bool {
+        return attrs[i].Name.Local < attrs[j].Name.Local
+    })
+    return append(attrs, r.declared...)
+}
+
+
+func (r *documentReader) name(n xml.Name) xml.Name {
+    if n.Space == "" {
+        return xml.Name{Local: n.Local}
+    }
+    if _, ok := r.prefixes[n.Space]; !ok {
+        r.declare("", n.Space)
+    }
+    return xml.Name{Local: r.prefixes[n.Space] + ":" + n.Local}
+}
+
+
+func (r *documentReader) parseTree(data []byte) (*rawXML, []xml.Attr, error) {
+    decoder := xml.NewDecoder(bytes.NewReader(data))
+    var root *rawXML
+    var rootAttrs []xml.Attr
+    sourcePrefixes := make(map[string]string)
+    stack := []*rawXML{}
+    for {
+        token, err := decoder.Token()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, nil, err
+        }
+
+        switch t := token.(type) {
+        case xml.StartElement:
+            for _, a := range t.Attr {
+                switch {
+                case a.Name.Space == "xmlns":
+                    sourcePrefixes[a.Name.Local] = a.Value
+                    r.declare(a.Name.Local, a.Value)
+                case a.Name.Space == "" && a.Name.Local == "xmlns":
+                    r.declare("", a.Value)
+                }
+            }
+            el := &rawXML{XMLName: r.name(t.Name)}
+            for _, a := range t.Attr {
+                if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+                    continue
+                }
+                el.Attrs = append(el.Attrs, xml.Attr{Name: r.name(a.Name), Value: a.Value})
+            }
+            if len(stack) == 0 {
+                root = el
+                rootAttrs = el.Attrs
+            } else {
+                parent := stack[len(stack)-1]
+                parent.Children = append(parent.Children, el)
+            }
+            stack = append(stack, el)
+        case xml.EndElement:
+            el := stack[len(stack)-1]
+            stack = stack[:len(stack)-1]
+
+
+
+            if len(el.elements()) > 0 {
+                children := el.Children[:0]
+                for _, child := range el.Children {
+                    if data, ok := child.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+                        continue
+                    }
+                    children = append(children, child)
+                }
+                el.Children = children
+            }
+        case xml.CharData:
+            if len(stack) > 0 {
+                parent := stack[len(stack)-1]
+                parent.Children = append(parent.Children, t.Copy())
+            }
+        }
+    }
+    if root == nil {
+        return nil, nil, fmt.Errorf("document has no root element")
+    }
+
+
+
+    for i, a := range rootAttrs {
+        if a.Name.Local != "mc:Ignorable" {
+            continue
+        }
+        prefixes := []string{}
+        for _, prefix := range strings.Fields(a.Value) {
+            if uri, ok := sourcePrefixes[prefix]; ok {
+                prefix = r.prefixes[uri]
+            }
+            prefixes = append(prefixes, prefix)
+        }
+        rootAttrs[i].Value = strings.Join(prefixes, " ")
+    }
+    return root, rootAttrs, nil
+}
+
+
+func (r *documentReader) readDocument(data []byte) error {
+    root, rootAttrs, err := r.parseTree(data)
+    if err != nil {
+        return err
+    }
+    if root.XMLName.Local != "w:document" {
+        return fmt.Errorf("unexpected root element %s", root.XMLName.Local)
+    }
+
+    r.registerBookmarks(root)
+    r.registerDrawings(root)
+
+    for _, child := range root.elements() {
+        if child.XMLName.Local != "w:body" {
+            continue
+        }
+        body := child.elements()
+        if n := len(body); n > 0 && body[n-1].XMLName.Local == "w:sectPr" {
+            r.doc.sectPr = r.readSectionProperties(body[n-1])
+            body = body[:n-1]
+        }
+        r.doc.content = r.readBlocks(body)
+    }
+
+
+
+    r.doc.source.rootAttrs = append(append([]xml.Attr{}, r.declared...), rootAttrs...)
+    return nil
+}
+
+
+func (r *documentReader) registerBookmarks(el *rawXML) {
+    for _, child := range el.elements() {
+        if child.XMLName.Local == "w:bookmarkStart" {
+            id, _ := child.attr("w:id")
+            name, _ := child.attr("w:name")
+            if n, err := strconv.ParseUint(id, 10, 32); err == nil && uint(n) >= r.doc.bookmarkCounter {
+                r.doc.bookmarkCounter = uint(n) + 1
+            }
+            if name != "" {
+                r.doc.bookmarks[name] = &bookmarkState{closed: true}
+            }
+        }
+        r.registerBookmarks(child)
+    }
+}
+
+
+func (r *documentReader) registerDrawings(el *rawXML) {
+    for _, child := range el.elements() {
+        if child.XMLName.Local == "wp:docPr" {
+            id, _ := child.attr("id")
+            if n, err := strconv.ParseUint(id, 10, 32); err == nil && uint(n) > r.doc.imageCounter {
+                r.doc.imageCounter = uint(n)
+            }
+        }
+        r.registerDrawings(child)
+    }
+}
+
+
+func (r *documentReader) readBlocks(elements []*rawXML) []interface{} {
+    blocks := []interface{}{}
+    for _, el := range elements {
+        switch el.XMLName.Local {
+        case "w:p":
+            blocks = append(blocks, r.readParagraph(el))
+        case "w:tbl":
+            blocks = append(blocks, r.readTable(el))
+        case "w:sdt", "w:sdtContent", "w:customXml":
+            blocks = append(blocks, &containerElement{XMLName: el.XMLName, Attrs: el.Attrs, Content: r.readBlocks(el.elements())})
+        default:
+            blocks = append(blocks, el)
+        }
+    }
+    return blocks
+}
+
+
+func (r *documentReader) readParagraph(el *rawXML) interface{} {
+    para := &paragraphData{Attrs: el.Attrs}
+    for _, child := range el.elements() {
+        if child.XMLName.Local == "w:pPr" {
+            para.Properties = r.readParagraphProperties(child)
+            continue
+        }
+        para.Content = append(para.Content, r.readInline(child)...)
+    }
+
+
+
+    props := para.Properties
+    if len(para.Content) == 0 && props != nil && props.Style == nil && props.KeepNext == nil && props.Numbering == nil &&
+        props.Tabs == nil && props.Justification == nil && len(props.Extra) == 1 && props.Extra[0].XMLName.Local == "w:sectPr" {
+        return &sectionBreakData{Properties: sectionBreakProperties{SectPr: r.readSectionProperties(props.Extra[0])}}
+    }
+    return para
+}
+
+
+func (r *documentReader) readInline(el *rawXML) []interface{} {
+    switch el.XMLName.Local {
+    case "w:r":
+        return runsToContent(r.readRun(el))
+    case "w:bookmarkStart":
+        id, err := strconv.ParseUint(attrValue(el, "w:id"), 10, 32)
+        if err == nil && el.hasOnlyAttrs("w:id", "w:name") {
+            return []interface{}{&bookmarkStart{ID: uint(id), Name: attrValue(el, "w:name")}}
+        }
+    case "w:bookmarkEnd":
+        id, err := strconv.ParseUint(attrValue(el, "w:id"), 10, 32)
+        if err == nil && el.hasOnlyAttrs("w:id") {
+            return []interface{}{&bookmarkEnd{ID: uint(id)}}
+        }
+    case "w:fldSimple":
+        if !el.hasOnlyAttrs("w:instr", "w:dirty") {
+            break
+        }
+        field := &simpleField{Instr: attrValue(el, "w:instr"), Dirty: attrValue(el, "w:dirty")}
+        for _, child := range el.elements() {
+            if child.XMLName.Local != "w:r" {
+                return []interface{}{r.readContainer(el)}
+            }
+            field.Runs = append(field.Runs, r.readRun(child)...)
+        }
+        return []interface{}{field}
+    case "w:hyperlink", "w:ins", "w:moveTo", "w:smartTag", "w:customXml", "w:sdt", "w:sdtContent", "w:dir", "w:bdo":
+        return []interface{}{r.readContainer(el)}
+    }
+    return []interface{}{el}
+}
+
+
+func (r *documentReader) readContainer(el *rawXML) *containerElement {
+    container := &containerElement{XMLName: el.XMLName, Attrs: el.Attrs}
+    for _, child := range el.elements() {
+        container.Content = append(container.Content, r.readInline(child)...)
+    }
+    return container
+}
+
+
+
+
+func (r *documentReader) readRun(el *rawXML) []*paragraphRun {
+    var props *runProperties
+    newRun := func() *paragraphRun {
+        run := &paragraphRun{Attrs: el.Attrs}
+        if props != nil {
+            p := *props
+            run.Properties = &p
+        }
+        return run
+    }
+
+    runs := []*paragraphRun{}
+    for _, child := range el.elements() {
+        if child.XMLName.Local == "w:rPr" {
+            props = r.readRunProperties(child)
+            continue
+        }
+
+        run := newRun()
+        switch {
+        case child.XMLName.Local == "w:t" && child.hasOnlyAttrs("xml:space"):
+            run.Text = &paragraphRunText{Space: attrValue(child, "xml:space"), Text: child.text()}
+        case child.XMLName.Local == "w:tab" && len(child.Attrs) == 0:
+            run.Tab = &runTab{}
+        case child.XMLName.Local == "w:br" && child.hasOnlyAttrs("w:type", "w:clear"):
+            run.Break = &runBreak{Type: attrValue(child, "w:type"), Clear: attrValue(child, "w:clear")}
+        case child.XMLName.Local == "w:cr":
+            run.Break = &runBreak{}
+        case child.XMLName.Local == "w:instrText" && child.hasOnlyAttrs("xml:space"):
+            run.InstrText = &instrText{Space: attrValue(child, "xml:space"), Text: child.text()}
+        case child.XMLName.Local == "w:fldChar" && child.hasOnlyAttrs("w:fldCharType", "w:fldLock", "w:dirty"):
+            run.FieldChar = &fieldChar{
+                Type:  attrValue(child, "w:fldCharType"),
+                Lock:  attrValue(child, "w:fldLock"),
+                Dirty: attrValue(child, "w:dirty"),
+                Extra: child.elements(),
+            }
+        default:
+            run.Extra = []*rawXML{child}
+        }
+        runs = append(runs, run)
+    }
+    if len(runs) == 0 {
+        runs = append(runs, newRun())
+    }
+    return runs
+}
+
+
+func (r *documentReader) readRunProperties(el *rawXML) *runProperties {
+    props := &runProperties{}
+    for _, child := range el.elements() {
+        switch {
+        case child.XMLName.Local == "w:rStyle" && child.hasOnlyAttrs("w:val"):
+            props.Style = &runStyle{Val: attrValue(child, "w:val")}
+        case child.XMLName.Local == "w:rFonts":
+            fonts := &runFonts{}
+            for _, a := range child.Attrs {
+                switch a.Name.Local {
+                case "w:ascii":
+                    fonts.ASCII = a.Value
+                case "w:hAnsi":
+                    fonts.HAnsi = a.Value
+                case "w:eastAsia":
+                    fonts.EastAsia = a.Value
+                case "w:cs":
+                    fonts.CS = a.Value
+                default:
+                    fonts.Attrs = append(fonts.Attrs, a)
+                }
+            }
+            props.Fonts = fonts
+        case child.XMLName.Local == "w:b" && isOn(child):
+            props.Bold = &boldProperty{}
+        case child.XMLName.Local == "w:i" && isOn(child):
+            props.Italic = &italicProperty{}
+        case child.XMLName.Local == "w:strike" && isOn(child):
+            props.Strike = &onOffProperty{}
+        case child.XMLName.Local == "w:color":
+            color := &colorProperty{}
+            for _, a := range child.Attrs {
+                if a.Name.Local == "w:val" {
+                    color.Val = a.Value
+                } else {
+                    color.Attrs = append(color.Attrs, a)
+                }
+            }
+            props.Color = color
+        case (child.XMLName.Local == "w:sz" || child.XMLName.Local == "w:szCs") && child.hasOnlyAttrs("w:val"):
+            n, err := strconv.Atoi(attrValue(child, "w:val"))
+            if err != nil {
+                props.Extra = append(props.Extra, child)
+            } else if child.XMLName.Local == "w:sz" {
+                props.Size = &halfPointProperty{Val: n}
+            } else {
+                props.SizeCs = &halfPointProperty{Val: n}
+            }
+        case child.XMLName.Local == "w:u":
+            underline := &underlineProperty{}
+            for _, a := range child.Attrs {
+                if a.Name.Local == "w:val" {
+                    underline.Val = a.Value
+                } else {
+                    underline.Attrs = append(underline.Attrs, a)
+                }
+            }
+            props.Underline = underline
+        default:
+            props.Extra = append(props.Extra, child)
+        }
+    }
+    return props
+}
+
+
+func (r *documentReader) readParagraphProperties(el *rawXML) *paragraphProperties {
+    props := &paragraphProperties{}
+    for _, child := range el.elements() {
+        switch {
+        case child.XMLName.Local == "w:pStyle" && child.hasOnlyAttrs("w:val"):
+            props.Style = &paragraphStyle{Val: attrValue(child, "w:val")}
+        case child.XMLName.Local == "w:keepNext" && isOn(child):
+            props.KeepNext = &onOffProperty{}
+        case child.XMLName.Local == "w:numPr" && readNumberingProperties(child) != nil:
+            props.Numbering = readNumberingProperties(child)
+        case child.XMLName.Local == "w:jc" && child.hasOnlyAttrs("w:val"):
+            props.Justification = &justification{Val: attrValue(child, "w:val")}
+        case child.XMLName.Local == "w:tabs":
+            tabs := readTabStops(child)
+            if tabs == nil {
+                props.Extra = append(props.Extra, child)
+            } else {
+                props.Tabs = tabs
+            }
+        default:
+            props.Extra = append(props.Extra, child)
+        }
+    }
+    return props
+}
+
+
+func readNumberingProperties(el *rawXML) *numberingProperties {
+    props := &numberingProperties{}
+    for _, child := range el.elements() {
+        n, err := strconv.Atoi(attrValue(child, "w:val"))
+        if err != nil || !child.hasOnlyAttrs("w:val") {
+            return nil
+        }
+        switch child.XMLName.Local {
+        case "w:ilvl":
+            props.Level = &numberProperty{Val: n}
+        case "w:numId":
+            props.ID = &numberProperty{Val: n}
+        default:
+            return nil
+        }
+    }
+    return props
+}
+
+
+func readTabStops(el *rawXML) *tabStops {
+    tabs := &tabStops{}
+    for _, child := range el.elements() {
+        if child.XMLName.Local != "w:tab" || !child.hasOnlyAttrs("w:val", "w:leader", "w:pos") {
+            return nil
+        }
+        pos, err := strconv.Atoi(attrValue(child, "w:pos"))
+        if err != nil {
+            return nil
+        }
+        tabs.Tabs = append(tabs.Tabs, tabStop{Val: attrValue(child, "w:val"), Leader: attrValue(child, "w:leader"), Pos: pos})
+    }
+    return tabs
+}
+
+
+func (r *documentReader) readSectionProperties(el *rawXML) *sectPr {
+    props := &sectPr{Attrs: el.Attrs}
+    for _, child := range el.elements() {
+        known := false
+        switch child.XMLName.Local {
+        case "w:type":
+            if child.hasOnlyAttrs("w:val") {
+                props.Type = &sectionType{Val: attrValue(child, "w:val")}
+                known = true
+            }
+        case "w:pgSz":
+            w, okW := attrUint(child, "w:w")
+            h, okH := attrUint(child, "w:h")
+            if okW && okH && child.hasOnlyAttrs("w:w", "w:h", "w:orient") {
+                props.PgSz = &pgSz{W: w, H: h, Orient: attrValue(child, "w:orient")}
+                known = true
+            }
+        case "w:pgMar":
+            margins := []uint{}
+            for _, name := range []string{"w:top", "w:right", "w:bottom", "w:left", "w:header", "w:footer", "w:gutter"} {
+                if v, ok := attrUint(child, name); ok {
+                    margins = append(margins, v)
+                }
+            }
+            if len(margins) == 7 && len(child.Attrs) == 7 {
+                props.PgMar = &pgMar{
+                    Top: int(margins[0]), Right: margins[1], Bottom: int(margins[2]), Left: margins[3],
+                    Header: margins[4], Footer: margins[5], Gutter: margins[6],
+                }
+                known = true
+            }
+        case "w:cols":
+            if len(child.elements()) == 0 && child.hasOnlyAttrs("w:space", "w:num") {
+                space, _ := attrUint(child, "w:space")
+                num, _ := attrUint(child, "w:num")
+                props.Cols = &sectionColumns{Space: space, Num: num}
+                known = true
+            }
+        case "w:docGrid":
+            if child.hasOnlyAttrs("w:type", "w:linePitch", "w:charSpace") {
+                linePitch, _ := attrUint(child, "w:linePitch")
+                charSpace, _ := strconv.Atoi(attrValue(child, "w:charSpace"))
+                props.DocGrid = &documentGrid{Type: attrValue(child, "w:type"), LinePitch: linePitch, CharSpace: charSpace}
+                known = true
+            }
+        }
+        if !known {
+            props.Extra = append(props.Extra, child)
+        }
+    }
+    return props
+}
+
+
+
+
+func (r *documentReader) readTable(el *rawXML) interface{} {
+    table := &tableData{}
+    for _, child := range el.elements() {
+        switch child.XMLName.Local {
+        case "w:tblPr":
+            table.Properties = r.readTableProperties(child)
+        case "w:tblGrid":
+            for _, col := range child.elements() {
+                w, err := strconv.Atoi(attrValue(col, "w:w"))
+                if col.XMLName.Local != "w:gridCol" || err != nil || !col.hasOnlyAttrs("w:w") {
+                    return el
+                }
+                table.Grid.Columns = append(table.Grid.Columns, gridColumn{W: w})
+            }
+        case "w:tr":
+            row := r.readTableRow(child)
+            if row == nil {
+                return el
+            }
+            table.Rows = append(table.Rows, row)
+        default:
+            return el
+        }
+    }
+    return table
+}
+
+
+func (r *documentReader) readTableProperties(el *rawXML) *tableProperties {
+    props := &tableProperties{}
+    for _, child := range el.elements() {
+        switch {
+        case child.XMLName.Local == "w:tblStyle" && child.hasOnlyAttrs("w:val"):
+            props.Style = &tableStyle{Val: attrValue(child, "w:val")}
+        case child.XMLName.Local == "w:tblW" && readTableWidth(child) != nil:
+            props.Width = readTableWidth(child)
+        case child.XMLName.Local == "w:tblLook" &&
+            child.hasOnlyAttrs("w:val", "w:firstRow", "w:lastRow", "w:firstColumn", "w:lastColumn", "w:noHBand", "w:noVBand"):
+            props.Look = &tableLook{
+                Val:      attrValue(child, "w:val"),
+                FirstRow: attrValue(child, "w:firstRow"),
+                LastRow:  attrValue(child, "w:lastRow"),
+                FirstCol: attrValue(child, "w:firstColumn"),
+                LastCol:  attrValue(child, "w:lastColumn"),
+                NoHBand:  attrValue(child, "w:noHBand"),
+                NoVBand:  attrValue(child, "w:noVBand"),
+            }
+        default:
+            props.Extra = append(props.Extra, child)
+        }
+    }
+    return props
+}
+
+
+func readTableWidth(el *rawXML) *tableWidth {
+    w, err := strconv.Atoi(attrValue(el, "w:w"))
+    widthType, ok := el.attr("w:type")
+    if err != nil || !ok || !el.hasOnlyAttrs("w:w", "w:type") {
+        return nil
+    }
+    return &tableWidth{W: w, Type: widthType}
+}
+
+
+func (r *documentReader) readTableRow(el *rawXML) *tableRowData {
+    row := &tableRowData{Attrs: el.Attrs}
+    for _, child := range el.elements() {
+        switch child.XMLName.Local {
+        case "w:trPr":
+            row.Properties = &tableRowProperties{}
+            for _, prop := range child.elements() {
+                if prop.XMLName.Local == "w:tblHeader" && isOn(prop) {
+                    row.Properties.Header = &onOffProperty{}
+                } else {
+                    row.Properties.Extra = append(row.Properties.Extra, prop)
+                }
+            }
+        case "w:tc":
+            cell := &tableCellData{}
+            for _, c := range child.elements() {
+                if c.XMLName.Local != "w:tcPr" {
+                    cell.Blocks = append(cell.Blocks, r.readBlocks([]*rawXML{c})...)
+                    continue
+                }
+                cell.Properties = &tableCellProperties{}
+                for _, prop := range c.elements() {
+                    if width := readTableWidth(prop); prop.XMLName.Local == "w:tcW" && width != nil {
+                        cell.Properties.Width = width
+                    } else {
+                        cell.Properties.Extra = append(cell.Properties.Extra, prop)
+                    }
+                }
+            }
+            row.Cells = append(row.Cells, cell)
+        default:
+            return nil
+        }
+    }
+    return row
+}
+
+
+func attrValue(el *rawXML, name string) string {
+    value, _ := el.attr(name)
+    return value
+}
+
+
+func attrUint(el *rawXML, name string) (uint, bool) {
+    value, ok := el.attr(name)
+    if !ok {
+        return 0, false
+    }
+    n, err := strconv.ParseUint(value, 10, 32)
+    if err != nil {
+        return 0, false
+    }
+    return uint(n), true
+}
+
+
+func isOn(el *rawXML) bool {
+    if !el.hasOnlyAttrs("w:val") {
+        return false
+    }
+    value, ok := el.attr("w:val")
+    return !ok || value == "1" || value == "true" || value == "on"
+}
@@ -0,0 +1,225 @@
+package docx
+
+import (
+    "regexp"
+    "strings"
+)
+
+
+type textMatcher func(text string) [][]int
+
+type textExpander func(text string, match []int) string
+
+
+func (d *DocxDocument) Replace(old string, new string, formatOptions ...string) int {
+    if old == "" {
+        return 0
+    }
+    find := func(text string) [][]int {
+        matches := [][]int{}
+        for offset := 0; ; {
+            i := strings.Index(text[offset:], old)
+            if i < 0 {
+                return matches
+            }
+            matches = append(matches, []int{offset + i, offset + i + len(old)})
+            offset += i + len(old)
+        }
+    }
+    expand := func(string, []int) string {
+        return new
+    }
+    return d.replaceText(find, expand, formatOptions)
+}
+
+
+func (d *DocxDocument) ReplaceRegexp(pattern *regexp.Regexp, replacement string, formatOptions ...string) int {
+    find := func(text string) [][]int {
+        return pattern.FindAllStringSubmatchIndex(text, -1)
+    }
+    expand := func(text string, match []int) string {
+        return string(pattern.ExpandString(nil, replacement, text, match))
+    }
+    return d.replaceText(find, expand, formatOptions)
+}
+
+
+func (d *DocxDocument) replaceText(find textMatcher, expand textExpander, formatOptions []string) int {
+    count := 0
+    for _, para := range d.paragraphs() {
+        count += replaceInContent(&para.Content, find, expand, formatOptions)
+    }
+    return count
+}
+
+
+
+
+func replaceInContent(content *[]interface{}, find textMatcher, expand textExpander, formatOptions []string) int {
+    count := 0
+    segment := []*paragraphRun{}
+    segments := [][]*paragraphRun{}
+    for _, c := range *content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            if v.Text != nil {
+                segment = append(segment, v)
+                continue
+            }
+        case *containerElement:
+            count += replaceInContent(&v.Content, find, expand, formatOptions)
+        case *simpleField:
+        default:
+            continue
+        }
+        if len(segment) > 0 {
+            segments = append(segments, segment)
+            segment = []*paragraphRun{}
+        }
+    }
+    if len(segment) > 0 {
+        segments = append(segments, segment)
+    }
+
+    emptied := make(map[*paragraphRun]bool)
+    for _, runs := range segments {
+        count += replaceInRuns(content, runs, find, expand, formatOptions, emptied)
+    }
+
+    if len(emptied) > 0 {
+        kept := (*content)[:0]
+        for _, c := range *content {
+            if run, ok := c.(*paragraphRun); ok && emptied[run] && run.Text.Text == "" {
+                continue
+            }
+            kept = append(kept, c)
+        }
+        *content = kept
+    }
+    return count
+}
+
+
+func replaceInRuns(content *[]interface{}, runs []*paragraphRun, find textMatcher, expand textExpander, formatOptions []string, emptied map[*paragraphRun]bool) int {
+    offsets := make([]int, len(runs))
+    var sb strings.Builder
+    for i, run := range runs {
+        offsets[i] = sb.Len()
+        sb.WriteString(run.Text.Text)
+    }
+    text := sb.String()
+
+    runAt := func(pos int) int {
+        for i := range runs {
+            if pos < offsets[i]+len(runs[i].Text.Text) {
+                return i
+            }
+        }
+        return len(runs) - 1
+    }
+
+
+
+    matches := find(text)
+    count := 0
+    for k := len(matches) - 1; k >= 0; k-- {
+        start, end := matches[k][0], matches[k][1]
+        if start == end {
+            continue
+        }
+        replacement := expand(text, matches[k])
+        i, j := runAt(start), runAt(end-1)
+        first := runs[i]
+        current := first.Text.Text
+        prefix := current[:start-offsets[i]]
+        suffix := ""
+        if i == j {
+            suffix = current[end-offsets[i]:]
+        } else {
+            for m := i + 1; m < j; m++ {
+                runs[m].Text.Text = ""
+                emptied[runs[m]] = true
+            }
+            last := runs[j]
+            last.Text.Text = last.Text.Text[end-offsets[j]:]
+            if last.Text.Text == "" {
+                emptied[last] = true
+            }
+        }
+
+        first.Text.Space = "preserve"
+        if len(formatOptions) == 0 {
+            first.Text.Text = prefix + replacement + suffix
+            if first.Text.Text == "" {
+                emptied[first] = true
+            }
+            count++
+            continue
+        }
+
+        first.Text.Text = prefix
+        if prefix == "" {
+            emptied[first] = true
+        }
+        inserted := []interface{}{&paragraphRun{
+            Properties: applyFormatOptions(first.Properties, formatOptions),
+            Text:       &paragraphRunText{Space: "preserve", Text: replacement},
+        }}
+        if suffix != "" {
+            inserted = append(inserted, &paragraphRun{
+                Properties: cloneRunProperties(first.Properties),
+                Text:       &paragraphRunText{Space: "preserve", Text: suffix},
+            })
+        }
+        insertAfter(content, first, inserted)
+        count++
+    }
+    return count
+}
+
+
+func insertAfter(content *[]interface{}, target interface{}, items []interface{}) {
+    for i, c := range *content {
+        if c != target {
+            continue
+        }
+        updated := append([]interface{}{}, (*content)[:i+1]...)
+        updated = append(updated, items...)
+        *content = append(updated, (*content)[i+1:]...)
+        return
+    }
+}
+
+
+func cloneRunProperties(props *runProperties) *runProperties {
+    if props == nil {
+        return nil
+    }
+    clone := *props
+    return &clone
+}
+
+
+func applyFormatOptions(props *runProperties, formatOptions []string) *runProperties {
+    merged := runProperties{}
+    if props != nil {
+        merged = *props
+    }
+    formatting := newRunProperties(formatOptions)
+    if formatting == nil {
+        return cloneRunProperties(props)
+    }
+    if formatting.Bold != nil {
+        merged.Bold = formatting.Bold
+    }
+    if formatting.Italic != nil {
+        merged.Italic = formatting.Italic
+    }
+    if formatting.Underline != nil {
+        merged.Underline = formatting.Underline
+    }
+    if formatting.Strike != nil {
+        merged.Strike = formatting.Strike
+    }
+    return &merged
+}
@@ -0,0 +1,102 @@
+package docx
+
+import (
+    "bytes"
+    "path/filepath"
+    "regexp"
+    "testing"
+)
+
+const splitRunsXML = `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Dear {{cust</w:t></w:r>` +
+    `<w:proofErr w:type="spellStart"/><w:r><w:rPr><w:i/></w:rPr><w:t>omer</w:t></w:r><w:proofErr w:type="spellEnd"/>` +
+    `<w:bookmarkStart w:id="7" w:name="greeting"/><w:r><w:t>_name}}</w:t></w:r><w:bookmarkEnd w:id="7"/>` +
+    `<w:r><w:t xml:space="preserve">, your order {{or</w:t></w:r><w:r><w:rPr><w:u w:val="single"/></w:rPr><w:t>der}} shipped.</w:t></w:r>`
+
+
+func openSplitRunsDocument(t *testing.T) (*DocxDocument, string) {
+    t.Helper()
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "PLACEHOLDER")
+    dir := t.TempDir()
+    filename := filepath.Join(dir, "split.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    rewritePart(t, filename, "word/document.xml", func(data []byte) []byte {
+        return bytes.Replace(data, []byte(`<w:r>
+        <w:t xml:space="preserve">PLACEHOLDER</w:t>
+      </w:r>`), []byte(splitRunsXML), 1)
+    })
+    opened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := opened.paragraphs()[0].text(); got != "Dear {{customer_name}}, your order {{order}} shipped." {
+        t.Fatalf("fixture text = %q", got)
+    }
+    return opened, dir
+}
+
+
+func TestReplaceAcrossSplitRuns(t *testing.T) {
+    doc, dir := openSplitRunsDocument(t)
+    if n := doc.Replace("{{customer_name}}", "Ada Lovelace"); n != 1 {
+        t.Fatalf("Replace() = %d, want 1", n)
+    }
+    if n := doc.Replace("{{order}}", "#42", FormatItalic); n != 1 {
+        t.Fatalf("Replace() with formatting = %d, want 1", n)
+    }
+    if n := doc.Replace("{{missing}}", "x"); n != 0 {
+        t.Errorf("Replace() of missing text = %d, want 0", n)
+    }
+
+    filename := filepath.Join(dir, "replaced.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    reopened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    para := reopened.paragraphs()[0]
+    if got, want := para.text(), "Dear Ada Lovelace, your order #42 shipped."; got != want {
+        t.Fatalf("text = %q, want %q", got, want)
+    }
+
+    formats := make(map[string][2]bool)
+    bookmarks := 0
+    for _, c := range para.Content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            if v.Text != nil {
+                formats[v.Text.Text] = [2]bool{v.Properties != nil && v.Properties.Bold != nil, v.Properties != nil && v.Properties.Italic != nil}
+            }
+        case *bookmarkStart, *bookmarkEnd:
+            bookmarks++
+        }
+    }
+    if f := formats["Dear Ada Lovelace"]; !f[0] || f[1] {
+        t.Errorf("replacement across runs has formatting %v, want the first run's bold", f)
+    }
+    if f := formats["#42"]; !f[1] {
+        t.Errorf("formatted replacement has formatting %v, want italic", f)
+    }
+    if _, ok := formats[" shipped."]; !ok {
+        t.Errorf("text after the formatted replacement was not split into its own run: %v", formats)
+    }
+    if bookmarks != 2 {
+        t.Errorf("got %d bookmark markers, want the bookmark start and end kept", bookmarks)
+    }
+}
+
+
+func TestReplaceRegexpAcrossSplitRuns(t *testing.T) {
+    doc, _ := openSplitRunsDocument(t)
+    n := doc.ReplaceRegexp(regexp.MustCompile(`\{\{(\w+)\}\}`), "<$1>")
+    if n != 2 {
+        t.Fatalf("ReplaceRegexp() = %d, want 2", n)
+    }
+    if got, want := doc.paragraphs()[0].text(), "Dear <customer_name>, your order <order> shipped."; got != want {
+        t.Errorf("text = %q, want %q", got, want)
+    }
+}
@@ -0,0 +1,828 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/hex"
+    "fmt"
+    "image"
+    "image/png"
+    "io"
+    "math"
+    "os"
+    "strconv"
+    "strings"
+    "unicode/utf16"
+)
+
+const rtfEmusPerTwip = 635
+
+
+var (
+    rtfBorderStyles  = map[string]string{"double": `\brdrdb`, "dotted": `\brdrdot`, "dashed": `\brdrdash`, "dashSmallGap": `\brdrdashsm`}
+    rtfTabLeaders    = map[string]string{"dot": `\tldot`, "hyphen": `\tlhyph`, "underscore": `\tlul`, "middleDot": `\tlmdot`, "heavy": `\tlth`}
+    rtfSectionBreaks = map[string]string{
+        SectionContinuous: `\sbknone`, SectionNextColumn: `\sbkcol`, SectionEvenPage: `\sbkeven`, SectionOddPage: `\sbkodd`,
+    }
+)
+
+
+type RTFWriter struct {
+    Title string
+}
+
+
+func NewRTFWriter() *RTFWriter {
+    return &RTFWriter{}
+}
+
+
+func (rw *RTFWriter) WriteDocument(filename string, doc Document) error {
+    var buf bytes.Buffer
+    if err := rw.WriteRTF(&buf, doc); err != nil {
+        return err
+    }
+
+    err := os.WriteFile(filename, buf.Bytes(), 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+    return nil
+}
+
+
+func (rw *RTFWriter) WriteRTF(w io.Writer, doc Document) error {
+    r := newRTFRenderer(doc)
+    r.render()
+
+    title := rw.Title
+    if title == "" {
+        title = r.title
+    }
+    return r.write(w, title)
+}
+
+
+type rtfRenderer struct {
+    doc       Document
+    sheet     *styleSheet
+    numbers   map[string]int
+    fonts     map[string]int
+    fontOrder []string
+    colors    map[string]int
+    colorList []string
+    counters  map[int][]int
+    bookmarks map[uint]string
+    part      *documentPart
+    first     pageSetup
+    setup     pageSetup
+    body      strings.Builder
+    field     rtfFieldState
+    title     string
+}
+
+
+type rtfFieldState struct {
+    depth     int
+    instr     string
+    separated bool
+}
+
+
+func newRTFRenderer(doc Document) *rtfRenderer {
+    r := &rtfRenderer{
+        doc:       doc,
+        sheet:     loadStyleSheet(doc),
+        numbers:   make(map[string]int),
+        fonts:     make(map[string]int),
+        colors:    make(map[string]int),
+        counters:  make(map[int][]int),
+        bookmarks: make(map[uint]string),
+    }
+    r.font(r.sheet.minorFont)
+
+    next := 1
+    for _, id := range r.sheet.order {
+        switch {
+        case id == r.sheet.defaultParagraph:
+            r.numbers[id] = 0
+        case r.sheet.styles[id].kind == "paragraph" || r.sheet.styles[id].kind == "character":
+            r.numbers[id] = next
+            next++
+        }
+    }
+    return r
+}
+
+
+
+
+func (r *rtfRenderer) render() {
+    sections := splitSections(r.doc.renderedContent(), r.doc.getSectionProperties())
+    for i, section := range sections {
+        if i > 0 {
+            r.body.WriteString(`\sect` + "\n")
+        }
+        r.setup = newPageSetup(section.props)
+        if i == 0 {
+            r.first = r.setup
+        }
+        r.body.WriteString(r.sectionFormat(section.props) + "\n")
+        if section.props != nil {
+            for _, extra := range section.props.Extra {
+                kind := strings.TrimSuffix(strings.TrimPrefix(extra.XMLName.Local, "w:"), "Reference")
+                if kind != "header" && kind != "footer" {
+                    continue
+                }
+                switch attrValue(extra, "w:type") {
+                case "", "default":
+                case "first":
+                    kind += "f"
+                default:
+                    continue
+                }
+                if r.part = loadDocumentPart(r.doc, attrValue(extra, "r:id")); r.part != nil {
+                    r.body.WriteString(`{\` + kind + "\n" + r.blocks(r.part.blocks, false) + "}\n")
+                }
+                r.part = nil
+            }
+        }
+        r.body.WriteString(r.blocks(section.blocks, false))
+    }
+}
+
+
+func (r *rtfRenderer) sectionFormat(props *sectPr) string {
+    s := r.setup
+    format := `\sectd` + rtfSectionBreaks[sectionBreakType(props)] + `\pgwsxn` + rtfPoints(s.width) + `\pghsxn` + rtfPoints(s.height) +
+        `\marglsxn` + rtfPoints(s.left) + `\margrsxn` + rtfPoints(s.right) + `\margtsxn` + rtfPoints(s.top) + `\margbsxn` + rtfPoints(s.bottom) +
+        `\headery` + rtfPoints(s.header) + `\footery` + rtfPoints(s.footer)
+    if s.width > s.height {
+        format += `\lndscpsxn`
+    }
+    if props != nil {
+        for _, extra := range props.Extra {
+            if extra.XMLName.Local == "w:titlePg" && isOn(extra) {
+                format += `\titlepg`
+            }
+        }
+    }
+    return format
+}
+
+
+func (r *rtfRenderer) blocks(content []interface{}, inTable bool) string {
+    var sb strings.Builder
+    for _, block := range content {
+        switch v := block.(type) {
+        case *paragraphData:
+            sb.WriteString(r.paragraph(v, inTable) + `\par` + "\n")
+        case *tableData:
+            if inTable {
+                for _, row := range v.Rows {
+                    for _, cell := range row.Cells {
+                        sb.WriteString(r.blocks(cell.Blocks, true))
+                    }
+                }
+                continue
+            }
+            sb.WriteString(r.table(v))
+        case *containerElement:
+            sb.WriteString(r.blocks(v.Content, inTable))
+        }
+    }
+    return sb.String()
+}
+
+
+
+
+func (r *rtfRenderer) paragraph(p *paragraphData, inTable bool) string {
+    style := paragraphStyleID(p)
+    if style == "" {
+        style = r.sheet.defaultParagraph
+    }
+    level := headingLevel(style)
+    if level > 0 && r.title == "" && r.part == nil {
+        r.title = strings.Join(strings.Fields(p.text()), " ")
+    }
+
+    props, run := styleProperties{}, styleProperties{}
+    mergeProperties(props, r.sheet.paragraphDefaults)
+    mergeProperties(run, r.sheet.runDefaults)
+    paragraph, styleRun, _ := r.sheet.resolve(style)
+    mergeProperties(props, paragraph)
+    mergeProperties(run, styleRun)
+    listLevel, ordered, isList := r.doc.getListLevel(p)
+    if isList {
+        props["left"] = strconv.Itoa(720 * (listLevel + 1))
+        props["hanging"] = "360"
+        delete(props, "firstLine")
+    }
+    direct := r.sheet.directParagraphProperties(p.Properties)
+    if direct["firstLine"] != "" && direct["hanging"] == "" {
+        delete(props, "hanging")
+    }
+    mergeProperties(props, direct)
+
+    var sb strings.Builder
+    sb.WriteString(`\pard\plain`)
+    if n, ok := r.numbers[style]; ok && r.sheet.styles[style].kind == "paragraph" {
+        sb.WriteString(`\s` + strconv.Itoa(n))
+    }
+    sb.WriteString(r.paragraphFormat(props))
+    if level > 0 {
+        sb.WriteString(`\outlinelevel` + strconv.Itoa(level-1))
+    }
+    if inTable {
+        sb.WriteString(`\intbl`)
+    }
+    sb.WriteString(r.runFormat(run) + " ")
+
+    if isList {
+        sb.WriteString("{" + r.runFormat(run) + " " + rtfEscape(r.listLabel(p, listLevel, ordered)) + `\tab}`)
+    }
+    r.field = rtfFieldState{}
+    sb.WriteString(r.inline(p.Content, run))
+    return sb.String()
+}
+
+
+func (r *rtfRenderer) listLabel(p *paragraphData, level int, ordered bool) string {
+    numID, format, start := r.doc.getListFormat(p, level, ordered)
+    counters, ok := r.counters[numID]
+    if !ok {
+        counters = make([]int, maxListLevel+1)
+        r.counters[numID] = counters
+    }
+    if level > maxListLevel {
+        level = maxListLevel
+    }
+    for deeper := level + 1; deeper <= maxListLevel; deeper++ {
+        counters[deeper] = 0
+    }
+    if format == "bullet" {
+        return bulletListSymbols[level%len(bulletListSymbols)]
+    }
+    if counters[level] == 0 {
+        counters[level] = start
+    } else {
+        counters[level]++
+    }
+    return formatListNumber(counters[level], format) + "."
+}
+
+
+
+
+func (r *rtfRenderer) inline(content []interface{}, base styleProperties) string {
+    var sb strings.Builder
+    for _, c := range content {
+        switch v := c.(type) {
+        case *paragraphRun:
+            sb.WriteString(r.run(v, base))
+        case *simpleField:
+            sb.WriteString(`{\field{\*\fldinst ` + rtfEscape(v.Instr) + `}{\fldrslt `)
+            for _, run := range v.Runs {
+                sb.WriteString(r.run(run, base))
+            }
+            sb.WriteString("}}")
+        case *bookmarkStart:
+            if v.Name != "" && !strings.HasPrefix(v.Name, "_GoBack") {
+                r.bookmarks[v.ID] = v.Name
+                sb.WriteString(`{\*\bkmkstart ` + rtfEscape(v.Name) + "}")
+            }
+        case *bookmarkEnd:
+            if name, ok := r.bookmarks[v.ID]; ok {
+                sb.WriteString(`{\*\bkmkend ` + rtfEscape(name) + "}")
+            }
+        case *containerElement:
+            if v.XMLName.Local != "w:hyperlink" {
+                sb.WriteString(r.inline(v.Content, base))
+                continue
+            }
+            instr := ""
+            for _, a := range v.Attrs {
+                switch a.Name.Local {
+                case "r:id":
+                    if rel, ok := r.part.relationship(r.doc, a.Value); ok {
+                        instr = `HYPERLINK "` + rel.Target + `"`
+                    }
+                case "w:anchor":
+                    if instr == "" {
+                        instr = `HYPERLINK \l "` + a.Value + `"`
+                    }
+                }
+            }
+            if instr == "" {
+                sb.WriteString(r.inline(v.Content, base))
+                continue
+            }
+            sb.WriteString(`{\field{\*\fldinst ` + rtfEscape(instr) + `}{\fldrslt ` + r.inline(v.Content, base) + "}}")
+        }
+    }
+    return sb.String()
+}
+
+
+func (r *rtfRenderer) run(run *paragraphRun, base styleProperties) string {
+    field := &r.field
+    if run.FieldChar != nil {
+        switch run.FieldChar.Type {
+        case fieldCharBegin:
+            field.depth++
+            if field.depth == 1 {
+                field.instr, field.separated = "", false
+            }
+        case fieldCharSeparate:
+            if field.depth == 1 {
+                field.separated = true
+                return `{\field{\*\fldinst ` + rtfEscape(field.instr) + `}{\fldrslt `
+            }
+        case fieldCharEnd:
+            if field.depth > 0 {
+                field.depth--
+            }
+            if field.depth == 0 {
+                if field.separated {
+                    return "}}"
+                }
+                return `{\field{\*\fldinst ` + rtfEscape(field.instr) + `}{\fldrslt }}`
+            }
+        }
+        return ""
+    }
+    if run.InstrText != nil {
+        if field.depth == 1 {
+            field.instr += run.InstrText.Text
+        }
+        return ""
+    }
+    if field.depth > 0 && !field.separated {
+        return ""
+    }
+
+    props := styleProperties{}
+    mergeProperties(props, base)
+    if run.Properties != nil && run.Properties.Style != nil {
+        _, styleRun, _ := r.sheet.resolve(run.Properties.Style.Val)
+        mergeProperties(props, styleRun)
+    }
+    mergeProperties(props, r.sheet.directRunProperties(run.Properties))
+    format := `\plain`
+    if run.Properties != nil && run.Properties.Style != nil {
+        if n, ok := r.numbers[run.Properties.Style.Val]; ok && r.sheet.styles[run.Properties.Style.Val].kind == "character" {
+            format += `\cs` + strconv.Itoa(n)
+        }
+    }
+    format += r.runFormat(props)
+
+    text := ""
+    switch {
+    case run.Text != nil:
+        text = rtfEscape(run.Text.Text)
+    case run.Tab != nil:
+        text = `\tab`
+    case run.Break != nil && run.Break.Type == BreakPage:
+        text = `\page`
+    case run.Break != nil && run.Break.Type == BreakColumn:
+        text = `\column`
+    case run.Break != nil:
+        text = `\line`
+    case run.hasDrawing():
+        text = r.image(run)
+    default:
+        for _, extra := range run.Extra {
+            switch extra.XMLName.Local {
+            case "w:tab", "w:ptab":
+                text += `\tab `
+            case "w:cr":
+                text += `\line `
+            case "w:noBreakHyphen":
+                text += `\_`
+            case "w:t":
+                text += rtfEscape(extra.text())
+            }
+        }
+    }
+    if text == "" {
+        return ""
+    }
+    return "{" + format + " " + text + "}"
+}
+
+
+func (r *rtfRenderer) image(run *paragraphRun) string {
+    rID, _ := drawingImage(run)
+    cx, cy := drawingExtent(run)
+    if rID == "" || cx <= 0 || cy <= 0 {
+        return ""
+    }
+    _, data, ok := r.part.imageData(r.doc, rID)
+    if !ok {
+        return ""
+    }
+    config, format, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        return ""
+    }
+
+    blip := `\pngblip`
+    switch format {
+    case "jpeg":
+        blip = `\jpegblip`
+    case "png":
+    default:
+        decoded, _, err := image.Decode(bytes.NewReader(data))
+        if err != nil {
+            return ""
+        }
+        var buf bytes.Buffer
+        if err := png.Encode(&buf, decoded); err != nil {
+            return ""
+        }
+        data = buf.Bytes()
+    }
+
+    var sb strings.Builder
+    sb.WriteString(fmt.Sprintf(`{\*\shppict{\pict%s\picw%d\pich%d\picwgoal%d\pichgoal%d`, blip, config.Width, config.Height,
+        cx/rtfEmusPerTwip, cy/rtfEmusPerTwip))
+    encoded := hex.EncodeToString(data)
+    for len(encoded) > 0 {
+        n := 128
+        if n > len(encoded) {
+            n = len(encoded)
+        }
+        sb.WriteString("\n" + encoded[:n])
+        encoded = encoded[n:]
+    }
+    sb.WriteString("}}")
+    return sb.String()
+}
+
+
+
+
+func (r *rtfRenderer) table(t *tableData) string {
+    props := styleProperties{}
+    if t.Properties != nil {
+        if t.Properties.Style != nil {
+            _, _, props = r.sheet.resolve(t.Properties.Style.Val)
+        }
+        r.sheet.readTableProperties(&rawXML{Children: rawChildren(t.Properties.Extra)}, props)
+    }
+    widths := gridWidths(t, r.setup.width-r.setup.left-r.setup.right)
+    gap := "108"
+    if v, ok := rtfTwips(props["cell-left"]); ok {
+        gap = strconv.Itoa(v)
+    }
+
+    var sb strings.Builder
+    header := true
+    for i, row := range t.Rows {
+        var cells strings.Builder
+        sb.WriteString(`\trowd\trgaph` + gap + `\trleft-` + gap)
+        header = header && row.Properties != nil && row.Properties.Header != nil && row.Properties.Header.Val != "0" && row.Properties.Header.Val != "false"
+        if header {
+            sb.WriteString(`\trhdr`)
+        }
+        if row.Properties != nil {
+            for _, extra := range row.Properties.Extra {
+                if height, ok := rtfTwips(attrValue(extra, "w:val")); ok && extra.XMLName.Local == "w:trHeight" {
+                    if attrValue(extra, "w:hRule") == "exact" {
+                        height = -height
+                    }
+                    sb.WriteString(`\trrh` + strconv.Itoa(height))
+                }
+            }
+        }
+
+        col, x := 0, 0.0
+        for _, cell := range row.Cells {
+            span, merge := cellSpan(cell)
+            if col >= len(widths) {
+                break
+            }
+            end := col + span
+            if end > len(widths) {
+                end = len(widths)
+            }
+            rows := 1
+            if merge == "restart" {
+                rows = rowSpan(t.Rows, i, col)
+            }
+            cellProps := cellProperties(cell, props, i == 0, i+rows == len(t.Rows), col == 0, end == len(widths))
+            switch merge {
+            case "restart":
+                sb.WriteString(`\clvmgf`)
+            case "continue":
+                sb.WriteString(`\clvmrg`)
+            }
+            switch cellProps["vAlign"] {
+            case "center":
+                sb.WriteString(`\clvertalc`)
+            case "bottom":
+                sb.WriteString(`\clvertalb`)
+            }
+            for _, side := range []string{"top", "left", "bottom", "right"} {
+                if border := r.border(cellProps["border-"+side]); border != "" {
+                    sb.WriteString(`\clbrdr` + side[:1] + border)
+                }
+            }
+            if fill, ok := cssColor(cellProps["shading"]); ok {
+                sb.WriteString(`\clcbpat` + strconv.Itoa(r.color(fill)))
+            }
+            for _, w := range widths[col:end] {
+                x += w
+            }
+            sb.WriteString(`\cellx` + rtfPoints(x))
+            col = end
+
+            content := ""
+            if merge != "continue" {
+                content = strings.TrimSuffix(r.blocks(cell.Blocks, true), `\par`+"\n")
+            }
+            if content == "" {
+                content = `\pard\plain\intbl`
+            }
+            cells.WriteString(content + `\cell` + "\n")
+        }
+        sb.WriteString("\n" + cells.String() + `\row` + "\n")
+    }
+    return sb.String()
+}
+
+
+
+
+func (r *rtfRenderer) runFormat(props styleProperties) string {
+    font := props["font"]
+    if font == "" {
+        font = r.sheet.minorFont
+    }
+    size := 20
+    if n, err := strconv.ParseFloat(props["size"], 64); err == nil && n > 0 {
+        size = int(math.Round(n))
+    }
+    var sb strings.Builder
+    sb.WriteString(`\f` + strconv.Itoa(r.font(font)) + `\fs` + strconv.Itoa(size))
+    for _, flag := range []struct{ prop, word string }{
+        {"b", `\b`}, {"i", `\i`}, {"strike", `\strike`}, {"dstrike", `\striked1`}, {"caps", `\caps`}, {"smallCaps", `\scaps`}, {"vanish", `\v`},
+    } {
+        if props[flag.prop] == "true" {
+            sb.WriteString(flag.word)
+        }
+    }
+    switch u := props["underline"]; {
+    case u == "double":
+        sb.WriteString(`\uldb`)
+    case u != "" && u != "none":
+        sb.WriteString(`\ul`)
+    }
+    if color, ok := cssColor(props["color"]); ok {
+        sb.WriteString(`\cf` + strconv.Itoa(r.color(color)))
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        sb.WriteString(`\chcbpat` + strconv.Itoa(r.color(fill)))
+    } else if color, ok := highlightColors[props["highlight"]]; ok {
+        sb.WriteString(`\highlight` + strconv.Itoa(r.color("#"+color)))
+    }
+    switch props["vertAlign"] {
+    case "superscript":
+        sb.WriteString(`\super`)
+    case "subscript":
+        sb.WriteString(`\sub`)
+    }
+    return sb.String()
+}
+
+
+func (r *rtfRenderer) paragraphFormat(props styleProperties) string {
+    var sb strings.Builder
+    switch props["align"] {
+    case "center":
+        sb.WriteString(`\qc`)
+    case "right", "end":
+        sb.WriteString(`\qr`)
+    case "both", "distribute":
+        sb.WriteString(`\qj`)
+    default:
+        sb.WriteString(`\ql`)
+    }
+    for _, side := range []struct{ prop, word string }{
+        {"left", `\li`}, {"right", `\ri`}, {"before", `\sb`}, {"after", `\sa`}, {"firstLine", `\fi`},
+    } {
+        if v, ok := rtfTwips(props[side.prop]); ok {
+            sb.WriteString(side.word + strconv.Itoa(v))
+        }
+    }
+    if v, ok := rtfTwips(props["hanging"]); ok {
+        sb.WriteString(`\fi-` + strconv.Itoa(v))
+    }
+    if line, ok := rtfTwips(props["line"]); ok && line > 0 {
+        switch props["lineRule"] {
+        case "exact":
+            sb.WriteString(`\sl-` + strconv.Itoa(line) + `\slmult0`)
+        case "atLeast":
+            sb.WriteString(`\sl` + strconv.Itoa(line) + `\slmult0`)
+        default:
+            sb.WriteString(`\sl` + strconv.Itoa(line) + `\slmult1`)
+        }
+    }
+    for _, flag := range []struct{ prop, word string }{
+        {"keepNext", `\keepn`}, {"keepLines", `\keep`}, {"pageBreakBefore", `\pagebb`}, {"contextualSpacing", `\contextualspace`},
+    } {
+        if props[flag.prop] == "true" {
+            sb.WriteString(flag.word)
+        }
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        sb.WriteString(`\cbpat` + strconv.Itoa(r.color(fill)))
+    }
+    for _, side := range []string{"top", "left", "bottom", "right"} {
+        if border := r.border(props["border-"+side]); border != "" {
+            sb.WriteString(`\brdr` + side[:1] + border)
+        }
+    }
+    for _, tab := range strings.Split(props["tabs"], ",") {
+        parts := strings.Split(tab, ":")
+        pos, ok := 0, len(parts) == 3
+        if ok {
+            pos, ok = rtfTwips(parts[1])
+        }
+        if !ok {
+            continue
+        }
+        switch parts[0] {
+        case "center":
+            sb.WriteString(`\tqc`)
+        case "right", "end":
+            sb.WriteString(`\tqr`)
+        case "decimal":
+            sb.WriteString(`\tqdec`)
+        case "left", "start":
+        default:
+            continue
+        }
+        sb.WriteString(rtfTabLeaders[parts[2]] + `\tx` + strconv.Itoa(pos))
+    }
+    return sb.String()
+}
+
+
+func (r *rtfRenderer) border(spec string) string {
+    parts := strings.Split(spec, " ")
+    if _, ok := cssBorder(spec); !ok {
+        return ""
+    }
+    style, ok := rtfBorderStyles[parts[0]]
+    if !ok {
+        style = `\brdrs`
+    }
+    width := 10
+    if n, err := strconv.ParseFloat(parts[1], 64); err == nil && n > 0 {
+        width = int(math.Round(n * 2.5))
+    }
+    border := style + `\brdrw` + strconv.Itoa(width)
+    if space, err := strconv.ParseFloat(parts[2], 64); err == nil && space > 0 {
+        border += `\brsp` + strconv.Itoa(int(math.Round(space*20)))
+    }
+    if color, ok := cssColor(parts[3]); ok {
+        border += `\brdrcf` + strconv.Itoa(r.color(color))
+    }
+    return border
+}
+
+
+func (r *rtfRenderer) font(name string) int {
+    if n, ok := r.fonts[name]; ok {
+        return n
+    }
+    r.fonts[name] = len(r.fontOrder)
+    r.fontOrder = append(r.fontOrder, name)
+    return r.fonts[name]
+}
+
+
+func (r *rtfRenderer) color(hex string) int {
+    if n, ok := r.colors[hex]; ok {
+        return n
+    }
+    r.colorList = append(r.colorList, hex)
+    r.colors[hex] = len(r.colorList)
+    return r.colors[hex]
+}
+
+
+
+
+func (r *rtfRenderer) stylesheet() string {
+    var sb strings.Builder
+    sb.WriteString(`{\stylesheet`)
+    for _, id := range r.sheet.order {
+        n, ok := r.numbers[id]
+        if !ok {
+            continue
+        }
+        style := r.sheet.styles[id]
+        paragraph, run, _ := r.sheet.resolve(id)
+        props, runProps := styleProperties{}, styleProperties{}
+        mergeProperties(props, r.sheet.paragraphDefaults)
+        mergeProperties(props, paragraph)
+        mergeProperties(runProps, r.sheet.runDefaults)
+        mergeProperties(runProps, run)
+
+        name := id
+        if level := headingLevel(id); level > 0 {
+            name = "heading " + strconv.Itoa(level)
+        }
+        based := ""
+        if b, ok := r.numbers[style.basedOn]; ok && style.basedOn != "" {
+            based = `\sbasedon` + strconv.Itoa(b)
+        }
+        if style.kind == "character" {
+            sb.WriteString("\n" + `{\*\cs` + strconv.Itoa(n) + `\additive` + r.runFormat(run) + based + " " + rtfEscape(name) + ";}")
+            continue
+        }
+        format := r.paragraphFormat(props)
+        if level := headingLevel(id); level > 0 {
+            format += `\outlinelevel` + strconv.Itoa(level-1)
+        }
+        sb.WriteString("\n" + `{\s` + strconv.Itoa(n) + format + r.runFormat(runProps) + based + " " + rtfEscape(name) + ";}")
+    }
+    sb.WriteString("}\n")
+    return sb.String()
+}
+
+
+func (r *rtfRenderer) write(w io.Writer, title string) error {
+    stylesheet := r.stylesheet()
+
+    var sb strings.Builder
+    sb.WriteString(`{\rtf1\ansi\ansicpg1252\deff0\uc1` + "\n" + `{\fonttbl`)
+    for i, name := range r.fontOrder {
+        family := map[string]string{"serif": `\froman`, "monospace": `\fmodern`}[genericFontFamily(name)]
+        if family == "" {
+            family = `\fswiss`
+        }
+        sb.WriteString(`{\f` + strconv.Itoa(i) + family + `\fcharset0 ` + rtfEscape(name) + ";}")
+    }
+    sb.WriteString("}\n" + `{\colortbl;`)
+    for _, color := range r.colorList {
+        rgb, _ := hex.DecodeString(strings.TrimPrefix(color, "#"))
+        sb.WriteString(fmt.Sprintf(`\red%d\green%d\blue%d;`, rgb[0], rgb[1], rgb[2]))
+    }
+    sb.WriteString("}\n" + stylesheet)
+    sb.WriteString(`{\info{\title ` + rtfEscape(title) + `}{\doccomm github.com/jonelmawirat/docx}}` + "\n")
+
+    first := r.first
+    sb.WriteString(`\paperw` + rtfPoints(first.width) + `\paperh` + rtfPoints(first.height) + `\margl` + rtfPoints(first.left) +
+        `\margr` + rtfPoints(first.right) + `\margt` + rtfPoints(first.top) + `\margb` + rtfPoints(first.bottom) + `\deftab720\viewkind1`)
+    if first.width > first.height {
+        sb.WriteString(`\landscape`)
+    }
+    sb.WriteString("\n" + r.body.String() + "}\n")
+
+    _, err := io.WriteString(w, sb.String())
+    if err != nil {
+        return fmt.Errorf("failed to write rtf: %w", err)
+    }
+    return nil
+}
+
+
+
+
+func rtfEscape(text string) string {
+    var sb strings.Builder
+    for _, c := range text {
+        switch {
+        case c == '\\' || c == '{' || c == '}':
+            sb.WriteString(`\` + string(c))
+        case c == '\t':
+            sb.WriteString(`\tab `)
+        case c == '\n':
+            sb.WriteString(`\line `)
+        case c < 0x80:
+            sb.WriteRune(c)
+        default:
+            for _, unit := range utf16.Encode([]rune{c}) {
+                sb.WriteString(`\u` + strconv.Itoa(int(int16(unit))) + "?")
+            }
+        }
+    }
+    return sb.String()
+}
+
+
+func rtfTwips(value string) (int, bool) {
+    n, err := strconv.ParseFloat(value, 64)
+    if err != nil {
+        return 0, false
+    }
+    return int(math.Round(n)), true
+}
+
+
+func rtfPoints(points float64) string {
+    return strconv.Itoa(int(math.Round(points * 20)))
+}
@@ -0,0 +1,66 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/hex"
+    "strings"
+    "testing"
+)
+
+
+func TestRTFWriterWritesBalancedDocument(t *testing.T) {
+    var buf bytes.Buffer
+    if err := NewRTFWriter().WriteRTF(&buf, newExportDocument(t)); err != nil {
+        t.Fatal(err)
+    }
+    rtf := buf.String()
+    if !strings.HasPrefix(rtf, `{\rtf1\ansi`) {
+        t.Fatalf("output starts with %q", rtf[:12])
+    }
+
+    depth, closed := 0, -1
+    for i := 0; i < len(rtf); i++ {
+        switch c := rtf[i]; {
+        case c >= 0x80:
+            t.Fatalf("non-ASCII byte %#x at offset %d", c, i)
+        case c == '\\':
+            i++
+        case c == '{':
+            if closed >= 0 {
+                t.Fatalf("content after the closing brace at offset %d", i)
+            }
+            depth++
+        case c == '}':
+            depth--
+            if depth < 0 {
+                t.Fatalf("unbalanced closing brace at offset %d", i)
+            }
+            if depth == 0 {
+                closed = i
+            }
+        }
+    }
+    if depth != 0 {
+        t.Fatalf("%d unclosed groups", depth)
+    }
+    if rest := strings.TrimSpace(rtf[closed+1:]); rest != "" {
+        t.Errorf("content after the document group: %q", rest)
+    }
+
+    png, _ := base64.StdEncoding.DecodeString(testPNG)
+    for _, want := range []string{
+        `Q&A <draft> "v1" \{braces\} \\ back`,
+        `caf\u233?`,
+        `\u10003?`,
+        `\u-10179?\u-8704?`,
+        `HYPERLINK "https://example.com/?a=1&b=2"`,
+        `cell <1>`,
+        `\pngblip`,
+        hex.EncodeToString(png),
+    } {
+        if !strings.Contains(strings.ReplaceAll(rtf, "\n", ""), want) {
+            t.Errorf("output does not contain %q", want)
+        }
+    }
+}
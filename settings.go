@@ -0,0 +1,392 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "strconv"
+)
+
+const (
+    CompatibilityWord2003 = 11
+    CompatibilityWord2007 = 12
+    CompatibilityWord2010 = 14
+    CompatibilityWord2013 = 15
+)
+
+const (
+    ProofStateClean = "clean"
+    ProofStateDirty = "dirty"
+)
+
+const (
+    contentTypeSettings    = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+    compatibilitySettingNS = "http://schemas.microsoft.com/office/word"
+    defaultTabStopTwips    = 720
+)
+
+
+type onOffProperty struct {
+    Val string `xml:"w:val,attr,omitempty"`
+}
+
+
+type intProperty struct {
+    Val int `xml:"w:val,attr"`
+}
+
+
+type zoomSetting struct {
+    Percent int `xml:"w:percent,attr"`
+}
+
+
+type proofStateSetting struct {
+    Spelling string `xml:"w:spelling,attr,omitempty"`
+    Grammar  string `xml:"w:grammar,attr,omitempty"`
+}
+
+
+type compatibilitySetting struct {
+    XMLName xml.Name `xml:"w:compatSetting"`
+    Name    string   `xml:"w:name,attr"`
+    URI     string   `xml:"w:uri,attr"`
+    Val     string   `xml:"w:val,attr"`
+}
+
+
+type compatibilitySettings struct {
+    Settings []compatibilitySetting `xml:"w:compatSetting"`
+    Extra    []*rawXML              `xml:",any"`
+}
+
+
+type documentVariable struct {
+    XMLName xml.Name `xml:"w:docVar"`
+    Name    string   `xml:"w:name,attr"`
+    Val     string   `xml:"w:val,attr"`
+}
+
+
+type documentVariables struct {
+    Variables []documentVariable `xml:"w:docVar"`
+}
+
+
+type documentSettings struct {
+    XMLName            xml.Name               `xml:"w:settings"`
+    Attrs              []xml.Attr             `xml:",any,attr"`
+    Zoom               *zoomSetting           `xml:"w:zoom,omitempty"`
+    DocumentProtection *documentProtection    `xml:"w:documentProtection,omitempty"`
+    EmbedTrueTypeFonts *onOffProperty         `xml:"w:embedTrueTypeFonts,omitempty"`
+    ProofState         *proofStateSetting     `xml:"w:proofState,omitempty"`
+    TrackRevisions     *onOffProperty         `xml:"w:trackRevisions,omitempty"`
+    DefaultTabStop     *intProperty           `xml:"w:defaultTabStop,omitempty"`
+    EvenAndOddHeaders  *onOffProperty         `xml:"w:evenAndOddHeaders,omitempty"`
+    UpdateFields       *onOffProperty         `xml:"w:updateFields,omitempty"`
+    Compat             *compatibilitySettings `xml:"w:compat,omitempty"`
+    DocVars            *documentVariables     `xml:"w:docVars,omitempty"`
+    Extra              []*rawXML              `xml:",any"`
+    changed            bool
+}
+
+
+var settingsOrder = []string{
+    "w:writeProtection", "w:view", "w:zoom", "w:removePersonalInformation", "w:removeDateAndTime",
+    "w:doNotDisplayPageBoundaries", "w:displayBackgroundShape", "w:printPostScriptOverText",
+    "w:printFractionalCharacterWidth", "w:printFormsData", "w:embedTrueTypeFonts", "w:embedSystemFonts",
+    "w:saveSubsetFonts", "w:saveFormsData", "w:mirrorMargins", "w:alignBordersAndEdges",
+    "w:bordersDoNotSurroundHeader", "w:bordersDoNotSurroundFooter", "w:gutterAtTop", "w:hideSpellingErrors",
+    "w:hideGrammaticalErrors", "w:activeWritingStyle", "w:proofState", "w:formsDesign", "w:attachedTemplate",
+    "w:linkStyles", "w:stylePaneFormatFilter", "w:stylePaneSortMethod", "w:documentType", "w:mailMerge",
+    "w:revisionView", "w:trackRevisions", "w:doNotTrackMoves", "w:doNotTrackFormatting", "w:documentProtection",
+    "w:autoFormatOverride", "w:styleLockTheme", "w:styleLockQFSet", "w:defaultTabStop", "w:autoHyphenation",
+    "w:consecutiveHyphenLimit", "w:hyphenationZone", "w:doNotHyphenateCaps", "w:showEnvelope",
+    "w:summaryLength", "w:clickAndTypeStyle", "w:defaultTableStyle", "w:evenAndOddHeaders",
+    "w:bookFoldRevPrinting", "w:bookFoldPrinting", "w:bookFoldPrintingSheets",
+    "w:drawingGridHorizontalSpacing", "w:drawingGridVerticalSpacing", "w:displayHorizontalDrawingGridEvery",
+    "w:displayVerticalDrawingGridEvery", "w:doNotUseMarginsForDrawingGridOrigin",
+    "w:drawingGridHorizontalOrigin", "w:drawingGridVerticalOrigin", "w:doNotShadeFormData",
+    "w:noPunctuationKerning", "w:characterSpacingControl", "w:printTwoOnOne", "w:strictFirstAndLastChars",
+    "w:noLineBreaksAfter", "w:noLineBreaksBefore", "w:savePreviewPicture", "w:doNotValidateAgainstSchema",
+    "w:saveInvalidXml", "w:ignoreMixedContent", "w:alwaysShowPlaceholderText", "w:doNotDemarcateInvalidXml",
+    "w:saveXmlDataOnly", "w:useXSLTWhenSaving", "w:saveThroughXslt", "w:showXMLTags",
+    "w:alwaysMergeEmptyNamespace", "w:updateFields", "w:hdrShapeDefaults", "w:footnotePr", "w:endnotePr",
+    "w:compat", "w:docVars", "w:rsids", "m:mathPr", "w:attachedSchema", "w:themeFontLang",
+    "w:clrSchemeMapping", "w:doNotIncludeSubdocsInStats", "w:doNotAutoCompressPictures", "w:forceUpgrade",
+    "w:captions", "w:readModeInkLockDown", "w:smartTagType", "sl:schemaLibrary", "w:shapeDefaults",
+    "w:doNotEmbedSmartTags", "w:decimalSymbol", "w:listSeparator",
+}
+
+
+func (s *documentSettings) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    start.Name = xml.Name{Local: "w:settings"}
+    return marshalOrdered(e, start, s, settingsOrder)
+}
+
+
+func (c *compatibilitySettings) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    if err := e.EncodeToken(start); err != nil {
+        return err
+    }
+    for _, extra := range c.Extra {
+        if err := e.Encode(extra); err != nil {
+            return err
+        }
+    }
+    for _, setting := range c.Settings {
+        if err := e.Encode(setting); err != nil {
+            return err
+        }
+    }
+    return e.EncodeToken(start.End())
+}
+
+
+func newDocumentSettings() documentSettings {
+    return documentSettings{
+        DefaultTabStop: &intProperty{Val: defaultTabStopTwips},
+        Compat: &compatibilitySettings{Settings: []compatibilitySetting{
+            {Name: "compatibilityMode", URI: compatibilitySettingNS, Val: strconv.Itoa(CompatibilityWord2013)},
+        }},
+    }
+}
+
+
+func (s *documentSettings) set(name string) {
+    extra := s.Extra[:0]
+    for _, el := range s.Extra {
+        if el.XMLName.Local != name {
+            extra = append(extra, el)
+        }
+    }
+    s.Extra = extra
+    s.changed = true
+}
+
+
+
+
+func (d *DocxDocument) SetUpdateFieldsOnOpen(update bool) {
+    d.settings.set("w:updateFields")
+    if update {
+        d.settings.UpdateFields = &onOffProperty{Val: "true"}
+    } else {
+        d.settings.UpdateFields = nil
+    }
+}
+
+
+func (d *DocxDocument) SetCompatibilityMode(mode int) error {
+    switch mode {
+    case CompatibilityWord2003, CompatibilityWord2007, CompatibilityWord2010, CompatibilityWord2013:
+    default:
+        return fmt.Errorf("unsupported compatibility mode: %d", mode)
+    }
+
+    compat := d.settings.Compat
+    if compat == nil {
+        compat = &compatibilitySettings{}
+        d.settings.set("w:compat")
+        d.settings.Compat = compat
+    }
+    d.settings.changed = true
+    for i, setting := range compat.Settings {
+        if setting.Name == "compatibilityMode" {
+            compat.Settings[i].Val = strconv.Itoa(mode)
+            return nil
+        }
+    }
+    compat.Settings = append(compat.Settings, compatibilitySetting{Name: "compatibilityMode", URI: compatibilitySettingNS, Val: strconv.Itoa(mode)})
+    return nil
+}
+
+
+func (d *DocxDocument) SetDefaultTabStop(twips int) error {
+    if twips <= 0 {
+        return fmt.Errorf("default tab stop must be positive: %d", twips)
+    }
+    d.settings.set("w:defaultTabStop")
+    d.settings.DefaultTabStop = &intProperty{Val: twips}
+    return nil
+}
+
+
+func (d *DocxDocument) SetEvenAndOddHeaders(enabled bool) {
+    d.settings.set("w:evenAndOddHeaders")
+    if enabled {
+        d.settings.EvenAndOddHeaders = &onOffProperty{}
+    } else {
+        d.settings.EvenAndOddHeaders = nil
+    }
+}
+
+
+func (d *DocxDocument) SetTrackRevisions(enabled bool) {
+    d.settings.set("w:trackRevisions")
+    if enabled {
+        d.settings.TrackRevisions = &onOffProperty{}
+    } else {
+        d.settings.TrackRevisions = nil
+    }
+}
+
+
+func (d *DocxDocument) SetZoom(percent int) error {
+    if percent < 10 || percent > 500 {
+        return fmt.Errorf("unsupported zoom percentage %d: expected 10 to 500", percent)
+    }
+    d.settings.set("w:zoom")
+    d.settings.Zoom = &zoomSetting{Percent: percent}
+    return nil
+}
+
+
+func (d *DocxDocument) SetProofState(spelling string, grammar string) error {
+    for _, state := range []string{spelling, grammar} {
+        switch state {
+        case "", ProofStateClean, ProofStateDirty:
+        default:
+            return fmt.Errorf("unsupported proofing state: %s", state)
+        }
+    }
+    d.settings.set("w:proofState")
+    d.settings.ProofState = nil
+    if spelling != "" || grammar != "" {
+        d.settings.ProofState = &proofStateSetting{Spelling: spelling, Grammar: grammar}
+    }
+    return nil
+}
+
+
+func (d *DocxDocument) SetDocumentVariable(name string, value string) error {
+    if name == "" {
+        return fmt.Errorf("document variable name cannot be empty")
+    }
+
+    d.settings.changed = true
+    if d.settings.DocVars == nil {
+        d.settings.DocVars = &documentVariables{}
+    }
+    vars := d.settings.DocVars
+    for i, v := range vars.Variables {
+        if v.Name == name {
+            vars.Variables[i].Val = value
+            return nil
+        }
+    }
+    vars.Variables = append(vars.Variables, documentVariable{Name: name, Val: value})
+    return nil
+}
+
+
+func (d *DocxDocument) DocumentVariable(name string) (string, bool) {
+    if d.settings.DocVars == nil {
+        return "", false
+    }
+    for _, v := range d.settings.DocVars.Variables {
+        if v.Name == name {
+            return v.Val, true
+        }
+    }
+    return "", false
+}
+
+
+func (d *DocxDocument) RemoveDocumentVariable(name string) {
+    vars := d.settings.DocVars
+    if vars == nil {
+        return
+    }
+    for i, v := range vars.Variables {
+        if v.Name == name {
+            vars.Variables = append(vars.Variables[:i], vars.Variables[i+1:]...)
+            d.settings.changed = true
+            break
+        }
+    }
+    if len(vars.Variables) == 0 {
+        d.settings.DocVars = nil
+    }
+}
+
+
+
+
+func (d *DocxDocument) loadSettings(data []byte) error {
+    reader := newDocumentReader(nil)
+    root, rootAttrs, err := reader.parseTree(data)
+    if err != nil {
+        return err
+    }
+    if root.XMLName.Local != "w:settings" {
+        return fmt.Errorf("unexpected settings root element %s", root.XMLName.Local)
+    }
+
+    settings := documentSettings{Attrs: append(reader.namespaceDeclarations(), rootAttrs...)}
+    for _, el := range root.elements() {
+        switch el.XMLName.Local {
+        case "w:compat":
+            compat := &compatibilitySettings{}
+            for _, child := range el.elements() {
+                if child.XMLName.Local != "w:compatSetting" {
+                    compat.Extra = append(compat.Extra, child)
+                    continue
+                }
+                name, _ := child.attr("w:name")
+                uri, _ := child.attr("w:uri")
+                val, _ := child.attr("w:val")
+                compat.Settings = append(compat.Settings, compatibilitySetting{Name: name, URI: uri, Val: val})
+            }
+            settings.Compat = compat
+        case "w:docVars":
+            vars := &documentVariables{}
+            for _, child := range el.elements() {
+                name, _ := child.attr("w:name")
+                val, _ := child.attr("w:val")
+                vars.Variables = append(vars.Variables, documentVariable{Name: name, Val: val})
+            }
+            settings.DocVars = vars
+        default:
+            settings.Extra = append(settings.Extra, el)
+        }
+    }
+    d.settings = settings
+    return nil
+}
+
+
+func (d *DocxDocument) settingsPart() ([]packagePart, error) {
+    if d.source == nil || (!d.source.generateSettings && !d.settings.changed) {
+        return nil, nil
+    }
+
+    var buf bytes.Buffer
+    if err := d.renderSettings(&buf); err != nil {
+        return nil, err
+    }
+    return []packagePart{{name: d.source.settingsPart, contentType: contentTypeSettings, data: buf.Bytes()}}, nil
+}
+
+
+func (d *DocxDocument) renderSettings(w io.Writer) error {
+    settings := d.settings
+    if len(settings.Attrs) == 0 {
+        settings.Attrs = []xml.Attr{{Name: xml.Name{Local: "xmlns:w"}, Value: namespaceW}}
+    }
+
+    _, err := w.Write([]byte(xml.Header))
+    if err != nil {
+        return fmt.Errorf("failed to write xml header: %w", err)
+    }
+
+    encoder := xml.NewEncoder(w)
+    encoder.Indent("", "  ")
+    err = encoder.Encode(&settings)
+    if err != nil {
+        return fmt.Errorf("failed to encode document settings: %w", err)
+    }
+
+    return nil
+}
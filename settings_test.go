@@ -0,0 +1,154 @@
+package docx
+
+import (
+    "bytes"
+    "encoding/xml"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+
+func settingsXML(t *testing.T, filename string) ([]byte, []string) {
+    t.Helper()
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, parts, err := readPackageParts(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    settings := parts["word/settings.xml"]
+    names := []string{}
+    decoder := xml.NewDecoder(bytes.NewReader(settings))
+    depth := 0
+    for {
+        token, err := decoder.Token()
+        if err != nil {
+            break
+        }
+        switch v := token.(type) {
+        case xml.StartElement:
+            if depth == 1 {
+                names = append(names, v.Name.Local)
+            }
+            depth++
+        case xml.EndElement:
+            depth--
+        }
+    }
+    return settings, names
+}
+
+
+func checkSettingsOrder(t *testing.T, names []string) {
+    t.Helper()
+    rank := make(map[string]int)
+    for i, name := range settingsOrder {
+        rank[strings.TrimPrefix(name, "w:")] = i
+    }
+    seen := make(map[string]bool)
+    for i, name := range names {
+        if seen[name] {
+            t.Errorf("settings contain %s twice", name)
+        }
+        seen[name] = true
+        if i > 0 && rank[names[i-1]] > rank[name] {
+            t.Errorf("settings element %s comes after %s", name, names[i-1])
+        }
+    }
+}
+
+
+func TestDocumentSettingsRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Body")
+    if err := doc.SetCompatibilityMode(CompatibilityWord2010); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.SetDefaultTabStop(360); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.SetZoom(150); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.SetProofState(ProofStateClean, ProofStateDirty); err != nil {
+        t.Fatal(err)
+    }
+    doc.SetEvenAndOddHeaders(true)
+    doc.SetTrackRevisions(true)
+    doc.SetUpdateFieldsOnOpen(true)
+    if err := doc.SetDocumentVariable("Client", "Acme & Co"); err != nil {
+        t.Fatal(err)
+    }
+    if err := doc.SetDocumentVariable("Build", "42"); err != nil {
+        t.Fatal(err)
+    }
+    for name, err := range map[string]error{
+        "compatibility mode": doc.SetCompatibilityMode(13),
+        "default tab stop":   doc.SetDefaultTabStop(0),
+        "zoom":               doc.SetZoom(5),
+        "proofing state":     doc.SetProofState("unknown", ""),
+        "document variable":  doc.SetDocumentVariable("", "x"),
+    } {
+        if err == nil {
+            t.Errorf("invalid %s accepted", name)
+        }
+    }
+
+    dir := t.TempDir()
+    filename := filepath.Join(dir, "settings.docx")
+    if err := NewZipDocxWriter().WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    settings, names := settingsXML(t, filename)
+    checkSettingsOrder(t, names)
+    for _, want := range []string{
+        `<w:zoom w:percent="150">`, `<w:proofState w:spelling="clean" w:grammar="dirty">`, `<w:trackRevisions>`,
+        `<w:defaultTabStop w:val="360">`, `<w:evenAndOddHeaders>`, `<w:updateFields w:val="true">`,
+        `w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="14"`,
+        `<w:docVar w:name="Client" w:val="Acme &amp; Co">`,
+    } {
+        if !bytes.Contains(settings, []byte(want)) {
+            t.Errorf("settings.xml does not contain %s", want)
+        }
+    }
+
+    rewritePart(t, filename, "word/settings.xml", func(data []byte) []byte {
+        return bytes.Replace(data, []byte("</w:settings>"), []byte(`<w:doNotAutoCompressPictures/></w:settings>`), 1)
+    })
+    reopened, err := OpenDocxDocument(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if value, ok := reopened.DocumentVariable("Client"); !ok || value != "Acme & Co" {
+        t.Errorf("DocumentVariable(Client) = %q, %v", value, ok)
+    }
+    reopened.RemoveDocumentVariable("Build")
+    if err := reopened.SetZoom(80); err != nil {
+        t.Fatal(err)
+    }
+    reopened.SetTrackRevisions(false)
+    if err := reopened.SetCompatibilityMode(CompatibilityWord2013); err != nil {
+        t.Fatal(err)
+    }
+
+    resaved := filepath.Join(dir, "resaved.docx")
+    if err := NewZipDocxWriter().WriteDocument(resaved, reopened); err != nil {
+        t.Fatal(err)
+    }
+    settings, names = settingsXML(t, resaved)
+    checkSettingsOrder(t, names)
+    for _, want := range []string{`<w:zoom w:percent="80">`, `<w:doNotAutoCompressPictures>`, `<w:evenAndOddHeaders>`, `w:val="15"`} {
+        if !bytes.Contains(settings, []byte(want)) {
+            t.Errorf("resaved settings.xml does not contain %s", want)
+        }
+    }
+    for _, unwanted := range []string{"trackRevisions", `w:name="Build"`, `w:percent="150"`, `w:val="14"`} {
+        if bytes.Contains(settings, []byte(unwanted)) {
+            t.Errorf("resaved settings.xml still contains %s", unwanted)
+        }
+    }
+}
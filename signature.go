@@ -0,0 +1,880 @@
+package docx
+
+import (
+    "archive/zip"
+    "bytes"
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/asn1"
+    "encoding/base64"
+    "encoding/pem"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "math/big"
+    "net/url"
+    "os"
+    "path"
+    "sort"
+    "strings"
+    "time"
+)
+
+const (
+    relTypeSignatureOrigin     = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/origin"
+    relTypeSignature           = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/signature"
+    relTypeThumbnail           = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/thumbnail"
+    contentTypeSignatureOrigin = "application/vnd.openxmlformats-package.digital-signature-origin"
+    contentTypeSignature       = "application/vnd.openxmlformats-package.digital-signature-xmlsignature+xml"
+    namespaceXMLDSig           = "http://www.w3.org/2000/09/xmldsig#"
+    namespaceDigitalSignature  = "http://schemas.openxmlformats.org/package/2006/digital-signature"
+    namespaceOfficeDigSig      = "http://schemas.microsoft.com/office/2006/digsig"
+    namespaceXAdES             = "http://uri.etsi.org/01903/v1.3.2#"
+    algorithmSHA256            = "http://www.w3.org/2001/04/xmlenc#sha256"
+    algorithmRSASHA256         = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+    algorithmECDSASHA256       = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+    signatureDir               = "_xmlsignatures"
+    signatureOriginPart        = "_xmlsignatures/origin.sigs"
+    signaturePart              = "_xmlsignatures/sig1.xml"
+    signatureTimeLayout        = "2006-01-02T15:04:05Z"
+)
+
+var unsignedRelationshipTypes = map[string]bool{
+    relTypeCoreProperties:     true,
+    relTypeExtendedProperties: true,
+    relTypeCustomProperties:   true,
+    relTypeThumbnail:          true,
+    relTypeSignatureOrigin:    true,
+}
+
+var signatureDigests = map[string]crypto.Hash{
+    "http://www.w3.org/2000/09/xmldsig#sha1":        crypto.SHA1,
+    "http://www.w3.org/2001/04/xmlenc#sha256":       crypto.SHA256,
+    "http://www.w3.org/2001/04/xmldsig-more#sha384": crypto.SHA384,
+    "http://www.w3.org/2001/04/xmlenc#sha512":       crypto.SHA512,
+}
+
+var signatureMethods = map[string]crypto.Hash{
+    "http://www.w3.org/2000/09/xmldsig#rsa-sha1":          crypto.SHA1,
+    "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":   crypto.SHA256,
+    "http://www.w3.org/2001/04/xmldsig-more#rsa-sha384":   crypto.SHA384,
+    "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":   crypto.SHA512,
+    "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha1":   crypto.SHA1,
+    "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256": crypto.SHA256,
+    "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384": crypto.SHA384,
+    "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": crypto.SHA512,
+}
+
+
+type Signer struct {
+    Certificate *x509.Certificate
+    Chain       []*x509.Certificate
+    Key         crypto.Signer
+    Comment     string
+    SigningTime time.Time
+}
+
+
+type DocumentSignature struct {
+    Certificate *x509.Certificate
+    Chain       []*x509.Certificate
+    SigningTime time.Time
+    Comment     string
+    Parts       []string
+    Err         error
+    TrustErr    error
+}
+
+
+func (s DocumentSignature) Intact() bool {
+    return s.Err == nil
+}
+
+
+func (s DocumentSignature) Trusted() bool {
+    return s.Err == nil && s.TrustErr == nil
+}
+
+
+func (s DocumentSignature) Valid() bool {
+    return s.Intact() && s.Trusted()
+}
+
+
+type signedReference struct {
+    part        string
+    contentType string
+    sourceIDs   []string
+    digest      []byte
+}
+
+
+
+
+func LoadPEMSigner(certFile string, keyFile string) (*Signer, error) {
+    certData, err := os.ReadFile(certFile)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read certificate file %s: %w", certFile, err)
+    }
+    keyData := certData
+    if keyFile != "" && keyFile != certFile {
+        keyData, err = os.ReadFile(keyFile)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read key file %s: %w", keyFile, err)
+        }
+    }
+
+    certs := []*x509.Certificate{}
+    for rest := certData; ; {
+        var block *pem.Block
+        block, rest = pem.Decode(rest)
+        if block == nil {
+            break
+        }
+        if block.Type == "CERTIFICATE" {
+            cert, err := x509.ParseCertificate(block.Bytes)
+            if err != nil {
+                return nil, fmt.Errorf("failed to parse certificate: %w", err)
+            }
+            certs = append(certs, cert)
+        }
+    }
+
+    var key interface{}
+    for rest := keyData; key == nil; {
+        var block *pem.Block
+        block, rest = pem.Decode(rest)
+        if block == nil {
+            break
+        }
+        switch block.Type {
+        case "PRIVATE KEY":
+            key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+        case "RSA PRIVATE KEY":
+            key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+        case "EC PRIVATE KEY":
+            key, err = x509.ParseECPrivateKey(block.Bytes)
+        case "ENCRYPTED PRIVATE KEY":
+            return nil, fmt.Errorf("encrypted PEM private keys are not supported: use a PKCS#12 file instead")
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse private key: %w", err)
+        }
+    }
+    if key == nil {
+        return nil, fmt.Errorf("no private key found in %s", keyFile)
+    }
+    return newSigner(key, certs)
+}
+
+
+func LoadPKCS12Signer(filename string, password string) (*Signer, error) {
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read PKCS#12 file %s: %w", filename, err)
+    }
+    key, certs, err := decodePKCS12(data, password)
+    if err != nil {
+        return nil, err
+    }
+    return newSigner(key, certs)
+}
+
+
+func newSigner(key interface{}, certs []*x509.Certificate) (*Signer, error) {
+    signer, ok := key.(crypto.Signer)
+    if !ok {
+        return nil, fmt.Errorf("unsupported private key type %T", key)
+    }
+    switch signer.Public().(type) {
+    case *rsa.PublicKey, *ecdsa.PublicKey:
+    default:
+        return nil, fmt.Errorf("unsupported private key type %T: only RSA and ECDSA keys can sign", key)
+    }
+
+    result := &Signer{Key: signer}
+    public := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+    for _, cert := range certs {
+        if result.Certificate == nil && public.Equal(cert.PublicKey) {
+            result.Certificate = cert
+        } else {
+            result.Chain = append(result.Chain, cert)
+        }
+    }
+    if result.Certificate == nil {
+        return nil, fmt.Errorf("no certificate matches the private key")
+    }
+    return result, nil
+}
+
+
+func SignDocx(inputFile string, outputFile string, signer *Signer) error {
+    data, err := os.ReadFile(inputFile)
+    if err != nil {
+        return fmt.Errorf("failed to read file %s: %w", inputFile, err)
+    }
+    signed, err := signPackage(data, signer)
+    if err != nil {
+        return err
+    }
+    err = os.WriteFile(outputFile, signed, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", outputFile, err)
+    }
+    return nil
+}
+
+
+
+
+func signPackage(data []byte, signer *Signer) ([]byte, error) {
+    if signer == nil || signer.Key == nil || signer.Certificate == nil {
+        return nil, fmt.Errorf("signer needs a certificate and a private key")
+    }
+    names, parts, err := readPackageParts(data)
+    if err != nil {
+        return nil, err
+    }
+
+    var contentTypes types
+    if err := xml.Unmarshal(parts["[Content_Types].xml"], &contentTypes); err != nil {
+        return nil, fmt.Errorf("failed to parse [Content_Types].xml: %w", err)
+    }
+    var rootRels relationships
+    if data, ok := parts["_rels/.rels"]; ok {
+        if err := xml.Unmarshal(data, &rootRels); err != nil {
+            return nil, fmt.Errorf("failed to parse _rels/.rels: %w", err)
+        }
+    }
+    stripSignatureParts(&names, &contentTypes, &rootRels.Relationships)
+
+    rootRels.Xmlns = "http://schemas.openxmlformats.org/package/2006/relationships"
+    rootRels.Relationships = append(rootRels.Relationships, relationship{
+        ID:     nextRelationshipID(rootRels.Relationships),
+        Type:   relTypeSignatureOrigin,
+        Target: signatureOriginPart,
+    })
+    contentTypes.Xmlns = "http://schemas.openxmlformats.org/package/2006/content-types"
+    contentTypes.Defaults = append(contentTypes.Defaults, defaultType{Extension: "sigs", ContentType: contentTypeSignatureOrigin})
+    contentTypes.Overrides = append(contentTypes.Overrides, overrideType{PartName: "/" + signaturePart, ContentType: contentTypeSignature})
+    if parts["_rels/.rels"], err = encodeXMLPart(rootRels); err != nil {
+        return nil, fmt.Errorf("failed to encode _rels/.rels: %w", err)
+    }
+    if parts["[Content_Types].xml"], err = encodeXMLPart(contentTypes); err != nil {
+        return nil, fmt.Errorf("failed to encode [Content_Types].xml: %w", err)
+    }
+    if !containsString(names, "_rels/.rels") {
+        names = append(names, "_rels/.rels")
+    }
+
+    references, err := signedReferences(parts, contentTypes)
+    if err != nil {
+        return nil, err
+    }
+    signature, err := buildSignature(references, signer)
+    if err != nil {
+        return nil, err
+    }
+    originRels, err := encodeXMLPart(relationships{
+        Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
+        Relationships: []relationship{{ID: "rId1", Type: relTypeSignature, Target: path.Base(signaturePart)}},
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode signature relationships: %w", err)
+    }
+    parts[signatureOriginPart] = []byte{}
+    parts[relationshipsPartName(signatureOriginPart)] = originRels
+    parts[signaturePart] = signature
+    names = append(names, signatureOriginPart, relationshipsPartName(signatureOriginPart), signaturePart)
+
+    var buf bytes.Buffer
+    zipWriter := zip.NewWriter(&buf)
+    for _, name := range names {
+        w, err := zipWriter.Create(name)
+        if err != nil {
+            return nil, fmt.Errorf("failed to create %s in zip: %w", name, err)
+        }
+        if _, err := w.Write(parts[name]); err != nil {
+            return nil, fmt.Errorf("failed to write %s: %w", name, err)
+        }
+    }
+    if err := zipWriter.Close(); err != nil {
+        return nil, fmt.Errorf("failed to finish signed package: %w", err)
+    }
+    return buf.Bytes(), nil
+}
+
+
+func readPackageParts(data []byte) ([]string, map[string][]byte, error) {
+    zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to open docx package: %w", err)
+    }
+    names := []string{}
+    parts := make(map[string][]byte)
+    for _, f := range zipReader.File {
+        if strings.HasSuffix(f.Name, "/") {
+            continue
+        }
+        data, err := readZipFile(f)
+        if err != nil {
+            return nil, nil, err
+        }
+        names = append(names, f.Name)
+        parts[f.Name] = data
+    }
+    if _, ok := parts["[Content_Types].xml"]; !ok {
+        return nil, nil, fmt.Errorf("docx package has no [Content_Types].xml part")
+    }
+    return names, parts, nil
+}
+
+
+func stripSignatureParts(names *[]string, contentTypes *types, rootRels *[]relationship) {
+    kept := []string{}
+    for _, name := range *names {
+        if !strings.HasPrefix(name, signatureDir+"/") {
+            kept = append(kept, name)
+        }
+    }
+    *names = kept
+
+    overrides := []overrideType{}
+    for _, o := range contentTypes.Overrides {
+        if !strings.HasPrefix(strings.TrimPrefix(o.PartName, "/"), signatureDir+"/") {
+            overrides = append(overrides, o)
+        }
+    }
+    contentTypes.Overrides = overrides
+    defaults := []defaultType{}
+    for _, d := range contentTypes.Defaults {
+        if d.ContentType != contentTypeSignatureOrigin {
+            defaults = append(defaults, d)
+        }
+    }
+    contentTypes.Defaults = defaults
+
+    rels := []relationship{}
+    for _, rel := range *rootRels {
+        if rel.Type != relTypeSignatureOrigin {
+            rels = append(rels, rel)
+        }
+    }
+    *rootRels = rels
+}
+
+
+func signedReferences(parts map[string][]byte, contentTypes types) ([]signedReference, error) {
+    references := []signedReference{}
+    visited := map[string]bool{"": true}
+    queue := []string{""}
+    for len(queue) > 0 {
+        source := queue[0]
+        queue = queue[1:]
+        relsPart := "_rels/.rels"
+        if source != "" {
+            relsPart = relationshipsPartName(source)
+        }
+        data, ok := parts[relsPart]
+        if !ok {
+            continue
+        }
+        var rels relationships
+        if err := xml.Unmarshal(data, &rels); err != nil {
+            return nil, fmt.Errorf("failed to parse %s: %w", relsPart, err)
+        }
+
+        ids := []string{}
+        for _, rel := range rels.Relationships {
+            if unsignedRelationshipTypes[rel.Type] {
+                continue
+            }
+            ids = append(ids, rel.ID)
+            if rel.TargetMode == "External" {
+                continue
+            }
+            target := resolvePartName(source, rel.Target)
+            if _, ok := parts[target]; ok && !visited[target] {
+                visited[target] = true
+                queue = append(queue, target)
+                sum := sha256.Sum256(parts[target])
+                references = append(references, signedReference{part: target, contentType: flatPartContentType(contentTypes, target), digest: sum[:]})
+            }
+        }
+        if len(ids) == 0 {
+            continue
+        }
+        idSet := make(map[string]bool)
+        for _, id := range ids {
+            idSet[id] = true
+        }
+        transformed, err := relationshipTransform(data, idSet, nil)
+        if err != nil {
+            return nil, fmt.Errorf("failed to transform %s: %w", relsPart, err)
+        }
+        sort.Strings(ids)
+        sum := sha256.Sum256(transformed)
+        references = append(references, signedReference{part: relsPart, contentType: flatPartContentType(contentTypes, relsPart), sourceIDs: ids, digest: sum[:]})
+    }
+    sort.SliceStable(references, func(i, j int) bool {
+        return references[i].part < references[j].part
+    })
+    return references, nil
+}
+
+
+func resolvePartName(source string, target string) string {
+    if strings.HasPrefix(target, "/") {
+        return strings.TrimPrefix(path.Clean(target), "/")
+    }
+    return strings.TrimPrefix(path.Join(path.Dir(source), target), "/")
+}
+
+
+func containsString(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
+
+
+
+
+func buildSignature(references []signedReference, signer *Signer) ([]byte, error) {
+    signingTime := signer.SigningTime
+    if signingTime.IsZero() {
+        signingTime = time.Now()
+    }
+    timestamp := signingTime.UTC().Format(signatureTimeLayout)
+    digestValue := func(b []byte) string {
+        return base64.StdEncoding.EncodeToString(b)
+    }
+    escape := func(s string) string {
+        return c14nEscape(s, false)
+    }
+    escapeAttr := func(s string) string {
+        return c14nEscape(s, true)
+    }
+    digestMethod := `<DigestMethod Algorithm="` + algorithmSHA256 + `"></DigestMethod>`
+
+    var objects strings.Builder
+    objects.WriteString(`<Object Id="idPackageObject"><Manifest>`)
+    for _, ref := range references {
+        objects.WriteString(`<Reference URI="` + escapeAttr("/"+ref.part+"?ContentType="+ref.contentType) + `">`)
+        if ref.sourceIDs != nil {
+            objects.WriteString(`<Transforms><Transform Algorithm="` + algorithmRelationshipTransform + `">`)
+            for _, id := range ref.sourceIDs {
+                objects.WriteString(`<mdssi:RelationshipReference xmlns:mdssi="` + namespaceDigitalSignature + `" SourceId="` + escapeAttr(id) + `"></mdssi:RelationshipReference>`)
+            }
+            objects.WriteString(`</Transform><Transform Algorithm="` + algorithmC14N + `"></Transform></Transforms>`)
+        }
+        objects.WriteString(digestMethod + `<DigestValue>` + digestValue(ref.digest) + `</DigestValue></Reference>`)
+    }
+    objects.WriteString(`</Manifest><SignatureProperties><SignatureProperty Id="idSignatureTime" Target="#idPackageSignature">`)
+    objects.WriteString(`<mdssi:SignatureTime xmlns:mdssi="` + namespaceDigitalSignature + `"><mdssi:Format>YYYY-MM-DDThh:mm:ssTZD</mdssi:Format>`)
+    objects.WriteString(`<mdssi:Value>` + timestamp + `</mdssi:Value></mdssi:SignatureTime></SignatureProperty></SignatureProperties></Object>`)
+
+    objects.WriteString(`<Object Id="idOfficeObject"><SignatureProperties><SignatureProperty Id="idOfficeV1Details" Target="#idPackageSignature">`)
+    objects.WriteString(`<SignatureInfoV1 xmlns="` + namespaceOfficeDigSig + `"><SetupID></SetupID><SignatureText></SignatureText><SignatureImage></SignatureImage>`)
+    objects.WriteString(`<SignatureComments>` + escape(signer.Comment) + `</SignatureComments><WindowsVersion>10.0</WindowsVersion><OfficeVersion>16.0</OfficeVersion>`)
+    objects.WriteString(`<ApplicationVersion>16.0</ApplicationVersion><Monitors>1</Monitors><HorizontalResolution>1920</HorizontalResolution><VerticalResolution>1080</VerticalResolution>`)
+    objects.WriteString(`<ColorDepth>32</ColorDepth><SignatureProviderId>{00000000-0000-0000-0000-000000000000}</SignatureProviderId><SignatureProviderUrl></SignatureProviderUrl>`)
+    objects.WriteString(`<SignatureProviderDetails>9</SignatureProviderDetails><SignatureType>1</SignatureType></SignatureInfoV1></SignatureProperty></SignatureProperties></Object>`)
+
+    certDigest := sha256.Sum256(signer.Certificate.Raw)
+    objects.WriteString(`<Object><xd:QualifyingProperties xmlns:xd="` + namespaceXAdES + `" Target="#idPackageSignature"><xd:SignedProperties Id="idSignedProperties">`)
+    objects.WriteString(`<xd:SignedSignatureProperties><xd:SigningTime>` + timestamp + `</xd:SigningTime><xd:SigningCertificate><xd:Cert><xd:CertDigest>`)
+    objects.WriteString(digestMethod + `<DigestValue>` + digestValue(certDigest[:]) + `</DigestValue></xd:CertDigest><xd:IssuerSerial>`)
+    objects.WriteString(`<X509IssuerName>` + escape(signer.Certificate.Issuer.String()) + `</X509IssuerName><X509SerialNumber>` + signer.Certificate.SerialNumber.String() + `</X509SerialNumber>`)
+    objects.WriteString(`</xd:IssuerSerial></xd:Cert></xd:SigningCertificate><xd:SignaturePolicyIdentifier><xd:SignaturePolicyImplied></xd:SignaturePolicyImplied></xd:SignaturePolicyIdentifier>`)
+    objects.WriteString(`</xd:SignedSignatureProperties></xd:SignedProperties></xd:QualifyingProperties></Object>`)
+
+    open := `<Signature xmlns="` + namespaceXMLDSig + `" Id="idPackageSignature">`
+    tree, err := parseC14N([]byte(open + objects.String() + `</Signature>`))
+    if err != nil {
+        return nil, fmt.Errorf("failed to build signature objects: %w", err)
+    }
+    var signedInfo strings.Builder
+    signedInfo.WriteString(`<SignedInfo><CanonicalizationMethod Algorithm="` + algorithmC14N + `"></CanonicalizationMethod>`)
+    method := algorithmRSASHA256
+    if _, ok := signer.Key.Public().(*ecdsa.PublicKey); ok {
+        method = algorithmECDSASHA256
+    }
+    signedInfo.WriteString(`<SignatureMethod Algorithm="` + method + `"></SignatureMethod>`)
+    for _, ref := range []struct{ id, refType string }{
+        {"idPackageObject", "http://www.w3.org/2000/09/xmldsig#Object"},
+        {"idOfficeObject", "http://www.w3.org/2000/09/xmldsig#Object"},
+        {"idSignedProperties", "http://uri.etsi.org/01903#SignedProperties"},
+    } {
+        sum := sha256.Sum256(canonicalize(tree.findID(ref.id), false))
+        signedInfo.WriteString(`<Reference Type="` + ref.refType + `" URI="#` + ref.id + `">`)
+        if ref.id == "idSignedProperties" {
+            signedInfo.WriteString(`<Transforms><Transform Algorithm="` + algorithmC14N + `"></Transform></Transforms>`)
+        }
+        signedInfo.WriteString(digestMethod + `<DigestValue>` + digestValue(sum[:]) + `</DigestValue></Reference>`)
+    }
+    signedInfo.WriteString(`</SignedInfo>`)
+
+    tree, err = parseC14N([]byte(open + signedInfo.String() + `</Signature>`))
+    if err != nil {
+        return nil, fmt.Errorf("failed to build signed info: %w", err)
+    }
+    sum := sha256.Sum256(canonicalize(tree.element("SignedInfo"), false))
+    value, err := signer.Key.Sign(rand.Reader, sum[:], crypto.SHA256)
+    if err != nil {
+        return nil, fmt.Errorf("failed to sign package: %w", err)
+    }
+    if public, ok := signer.Key.Public().(*ecdsa.PublicKey); ok {
+        var parsed struct{ R, S *big.Int }
+        if _, err := asn1.Unmarshal(value, &parsed); err != nil {
+            return nil, fmt.Errorf("failed to parse ECDSA signature: %w", err)
+        }
+        size := (public.Curve.Params().BitSize + 7) / 8
+        value = append(parsed.R.FillBytes(make([]byte, size)), parsed.S.FillBytes(make([]byte, size))...)
+    }
+
+    var out strings.Builder
+    out.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + open + signedInfo.String())
+    out.WriteString(`<SignatureValue>` + base64.StdEncoding.EncodeToString(value) + `</SignatureValue><KeyInfo><X509Data>`)
+    for _, cert := range append([]*x509.Certificate{signer.Certificate}, signer.Chain...) {
+        out.WriteString(`<X509Certificate>` + base64.StdEncoding.EncodeToString(cert.Raw) + `</X509Certificate>`)
+    }
+    out.WriteString(`</X509Data></KeyInfo>` + objects.String() + `</Signature>`)
+    return []byte(out.String()), nil
+}
+
+
+
+
+func VerifyDocxSignatures(filename string, opts x509.VerifyOptions) ([]DocumentSignature, error) {
+    file, err := os.Open(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
+    }
+    defer file.Close()
+
+    info, err := file.Stat()
+    if err != nil {
+        return nil, fmt.Errorf("failed to stat file %s: %w", filename, err)
+    }
+    return ReadDocxSignatures(file, info.Size(), opts)
+}
+
+
+func ReadDocxSignatures(r io.ReaderAt, size int64, opts x509.VerifyOptions) ([]DocumentSignature, error) {
+    data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+    if err != nil {
+        return nil, fmt.Errorf("failed to read docx package: %w", err)
+    }
+    _, parts, err := readPackageParts(data)
+    if err != nil {
+        return nil, err
+    }
+    var contentTypes types
+    if err := xml.Unmarshal(parts["[Content_Types].xml"], &contentTypes); err != nil {
+        return nil, fmt.Errorf("failed to parse [Content_Types].xml: %w", err)
+    }
+
+    signatureParts := []string{}
+    var rootRels relationships
+    if data, ok := parts["_rels/.rels"]; ok {
+        if err := xml.Unmarshal(data, &rootRels); err != nil {
+            return nil, fmt.Errorf("failed to parse _rels/.rels: %w", err)
+        }
+    }
+    for _, rel := range rootRels.Relationships {
+        if rel.Type != relTypeSignatureOrigin {
+            continue
+        }
+        origin := resolvePartName("", rel.Target)
+        var originRels relationships
+        if data, ok := parts[relationshipsPartName(origin)]; ok {
+            if err := xml.Unmarshal(data, &originRels); err != nil {
+                return nil, fmt.Errorf("failed to parse %s: %w", relationshipsPartName(origin), err)
+            }
+        }
+        for _, sigRel := range originRels.Relationships {
+            if sigRel.Type == relTypeSignature {
+                signatureParts = append(signatureParts, resolvePartName(origin, sigRel.Target))
+            }
+        }
+    }
+
+    signatures := []DocumentSignature{}
+    for _, name := range signatureParts {
+        data, ok := parts[name]
+        if !ok {
+            signatures = append(signatures, DocumentSignature{Err: fmt.Errorf("signature part %s is missing", name)})
+            continue
+        }
+        signatures = append(signatures, verifySignature(data, parts, contentTypes, opts))
+    }
+    return signatures, nil
+}
+
+
+func verifySignature(data []byte, parts map[string][]byte, contentTypes types, opts x509.VerifyOptions) DocumentSignature {
+    result := DocumentSignature{}
+    fail := func(format string, args ...interface{}) DocumentSignature {
+        result.Err = fmt.Errorf(format, args...)
+        return result
+    }
+
+    root, err := parseC14N(data)
+    if err != nil || root.name.Local != "Signature" {
+        return fail("failed to parse signature: %v", err)
+    }
+    if value := findC14NElement(root, "SignatureComments"); value != nil {
+        result.Comment = value.text()
+    }
+    for _, local := range []string{"Value", "SigningTime"} {
+        if value := findC14NElement(root, local); value != nil && result.SigningTime.IsZero() {
+            result.SigningTime, _ = time.Parse(time.RFC3339, strings.TrimSpace(value.text()))
+        }
+    }
+
+    signedInfo := root.element("SignedInfo")
+    if signedInfo == nil {
+        return fail("signature has no SignedInfo")
+    }
+    withComments, err := c14nMethod(signedInfo.element("CanonicalizationMethod"))
+    if err != nil {
+        return fail("%v", err)
+    }
+    method := ""
+    if el := signedInfo.element("SignatureMethod"); el != nil {
+        method = el.attr("Algorithm")
+    }
+    hashType, ok := signatureMethods[method]
+    if !ok {
+        return fail("unsupported signature method %s", method)
+    }
+    h := hashType.New()
+    h.Write(canonicalize(signedInfo, withComments))
+    digest := h.Sum(nil)
+    signatureValue, err := base64.StdEncoding.DecodeString(removeWhitespace(root.element("SignatureValue").textOrEmpty()))
+    if err != nil {
+        return fail("invalid signature value: %v", err)
+    }
+
+    keyInfo := findC14NElement(root, "X509Data")
+    if keyInfo == nil {
+        return fail("signature has no X509 certificate")
+    }
+    embedded := []*x509.Certificate{}
+    for _, el := range keyInfo.elements("X509Certificate") {
+        der, err := base64.StdEncoding.DecodeString(removeWhitespace(el.text()))
+        if err != nil {
+            continue
+        }
+        cert, err := x509.ParseCertificate(der)
+        if err != nil {
+            continue
+        }
+        embedded = append(embedded, cert)
+        if result.Certificate == nil && verifySignatureValue(cert.PublicKey, hashType, digest, signatureValue) {
+            result.Certificate = cert
+        }
+    }
+    if result.Certificate == nil {
+        return fail("signature value does not match any certificate in the signature")
+    }
+
+    for _, ref := range signedInfo.elements("Reference") {
+        uri := ref.attr("URI")
+        if !strings.HasPrefix(uri, "#") {
+            return fail("unsupported signed info reference %s", uri)
+        }
+        target := root.findID(strings.TrimPrefix(uri, "#"))
+        if target == nil {
+            return fail("signed info reference %s not found", uri)
+        }
+        comments := false
+        if transforms := ref.element("Transforms"); transforms != nil {
+            for _, transform := range transforms.elements("Transform") {
+                if comments, err = c14nMethod(transform); err != nil {
+                    return fail("%v", err)
+                }
+            }
+        }
+        if err := checkReferenceDigest(ref, canonicalize(target, comments)); err != nil {
+            return fail("reference %s: %v", uri, err)
+        }
+
+        for _, manifest := range target.elements("Manifest") {
+            for _, partRef := range manifest.elements("Reference") {
+                name, err := verifyPartReference(partRef, parts, contentTypes)
+                if err != nil {
+                    return fail("%v", err)
+                }
+                result.Parts = append(result.Parts, name)
+            }
+        }
+    }
+    if len(result.Parts) == 0 {
+        return fail("signature does not cover any package part")
+    }
+    result.Chain, result.TrustErr = verifyCertificateChain(result.Certificate, embedded, opts)
+    return result
+}
+
+
+func verifyCertificateChain(cert *x509.Certificate, embedded []*x509.Certificate, opts x509.VerifyOptions) ([]*x509.Certificate, error) {
+    intermediates := x509.NewCertPool()
+    if opts.Intermediates != nil {
+        intermediates = opts.Intermediates.Clone()
+    }
+    for _, c := range embedded {
+        if c != cert {
+            intermediates.AddCert(c)
+        }
+    }
+    opts.Intermediates = intermediates
+    if len(opts.KeyUsages) == 0 {
+        opts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+    }
+    chains, err := cert.Verify(opts)
+    if err != nil {
+        return nil, fmt.Errorf("signing certificate is not trusted: %w", err)
+    }
+    return chains[0], nil
+}
+
+
+func verifyPartReference(ref *c14nNode, parts map[string][]byte, contentTypes types) (string, error) {
+    uri := ref.attr("URI")
+    partURI, query, _ := strings.Cut(uri, "?")
+    name, err := url.PathUnescape(strings.TrimPrefix(partURI, "/"))
+    if err != nil {
+        return "", fmt.Errorf("invalid part reference %s", uri)
+    }
+    data, ok := parts[name]
+    if !ok {
+        return "", fmt.Errorf("signed part %s is missing", name)
+    }
+    if contentType, ok := strings.CutPrefix(query, "ContentType="); ok {
+        if !strings.EqualFold(contentType, flatPartContentType(contentTypes, name)) {
+            return "", fmt.Errorf("content type of signed part %s has changed", name)
+        }
+    }
+
+    if transforms := ref.element("Transforms"); transforms != nil {
+        for _, transform := range transforms.elements("Transform") {
+            if transform.attr("Algorithm") == algorithmRelationshipTransform {
+                ids, relTypes := make(map[string]bool), make(map[string]bool)
+                for _, el := range transform.elements("") {
+                    switch el.name.Local {
+                    case "RelationshipReference":
+                        ids[el.attr("SourceId")] = true
+                    case "RelationshipsGroupReference":
+                        relTypes[el.attr("SourceType")] = true
+                    }
+                }
+                if data, err = relationshipTransform(data, ids, relTypes); err != nil {
+                    return "", fmt.Errorf("failed to transform %s: %w", name, err)
+                }
+                continue
+            }
+            comments, err := c14nMethod(transform)
+            if err != nil {
+                return "", err
+            }
+            tree, err := parseC14N(data)
+            if err != nil {
+                return "", fmt.Errorf("failed to parse %s: %w", name, err)
+            }
+            data = canonicalize(tree, comments)
+        }
+    }
+    if err := checkReferenceDigest(ref, data); err != nil {
+        return "", fmt.Errorf("signed part %s has been modified", name)
+    }
+    return name, nil
+}
+
+
+func checkReferenceDigest(ref *c14nNode, data []byte) error {
+    method := ""
+    if el := ref.element("DigestMethod"); el != nil {
+        method = el.attr("Algorithm")
+    }
+    hashType, ok := signatureDigests[method]
+    if !ok {
+        return fmt.Errorf("unsupported digest method %s", method)
+    }
+    expected, err := base64.StdEncoding.DecodeString(removeWhitespace(ref.element("DigestValue").textOrEmpty()))
+    if err != nil {
+        return fmt.Errorf("invalid digest value")
+    }
+    h := hashType.New()
+    h.Write(data)
+    if !bytes.Equal(h.Sum(nil), expected) {
+        return fmt.Errorf("digest mismatch")
+    }
+    return nil
+}
+
+
+func verifySignatureValue(publicKey crypto.PublicKey, hashType crypto.Hash, digest []byte, value []byte) bool {
+    switch key := publicKey.(type) {
+    case *rsa.PublicKey:
+        return rsa.VerifyPKCS1v15(key, hashType, digest, value) == nil
+    case *ecdsa.PublicKey:
+        if len(value)%2 != 0 {
+            return false
+        }
+        r := new(big.Int).SetBytes(value[:len(value)/2])
+        s := new(big.Int).SetBytes(value[len(value)/2:])
+        return ecdsa.Verify(key, digest, r, s)
+    }
+    return false
+}
+
+
+func c14nMethod(el *c14nNode) (bool, error) {
+    algorithm := ""
+    if el != nil {
+        algorithm = el.attr("Algorithm")
+    }
+    switch algorithm {
+    case algorithmC14N:
+        return false, nil
+    case algorithmC14NComments:
+        return true, nil
+    }
+    return false, fmt.Errorf("unsupported canonicalization method %s", algorithm)
+}
+
+
+func findC14NElement(n *c14nNode, local string) *c14nNode {
+    for _, el := range n.elements("") {
+        if el.name.Local == local {
+            return el
+        }
+        if found := findC14NElement(el, local); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
+
+func (n *c14nNode) textOrEmpty() string {
+    if n == nil {
+        return ""
+    }
+    return n.text()
+}
+
+
+func removeWhitespace(s string) string {
+    return strings.Join(strings.Fields(s), "")
+}
+
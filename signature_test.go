@@ -0,0 +1,291 @@
+package docx
+
+import (
+    "archive/zip"
+    "bytes"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/pem"
+    "math/big"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, ca bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+    t.Helper()
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+    if err != nil {
+        t.Fatal(err)
+    }
+    template := &x509.Certificate{
+        SerialNumber:          serial,
+        Subject:               pkix.Name{CommonName: name},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().Add(24 * time.Hour),
+        KeyUsage:              x509.KeyUsageDigitalSignature,
+        BasicConstraintsValid: true,
+        IsCA:                  ca,
+    }
+    if ca {
+        template.KeyUsage |= x509.KeyUsageCertSign
+    }
+    if parent == nil {
+        parent, parentKey = template, key
+    }
+    der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cert, err := x509.ParseCertificate(der)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return cert, key
+}
+
+
+func writeTestDocument(t *testing.T, dir string, signer *Signer) string {
+    t.Helper()
+    doc := NewDocxDocument()
+    doc.AddText(StyleNormal, "Quarterly figures")
+    writer := NewZipDocxWriter()
+    writer.Signer = signer
+    filename := filepath.Join(dir, "signed.docx")
+    if err := writer.WriteDocument(filename, doc); err != nil {
+        t.Fatal(err)
+    }
+    return filename
+}
+
+
+func rewritePart(t *testing.T, filename string, part string, edit func([]byte) []byte) {
+    t.Helper()
+    data, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    names, parts, err := readPackageParts(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var buf bytes.Buffer
+    zw := zip.NewWriter(&buf)
+    for _, name := range names {
+        content := parts[name]
+        if name == part {
+            content = edit(content)
+        }
+        w, err := zw.Create(name)
+        if err != nil {
+            t.Fatal(err)
+        }
+        w.Write(content)
+    }
+    if err := zw.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+        t.Fatal(err)
+    }
+}
+
+
+func TestSignatureRoundTripAndTrust(t *testing.T) {
+    root, rootKey := newTestCertificate(t, "Test Root", nil, nil, true)
+    intermediate, intermediateKey := newTestCertificate(t, "Test Intermediate", root, rootKey, true)
+    leaf, leafKey := newTestCertificate(t, "Report Signer", intermediate, intermediateKey, false)
+    roots := x509.NewCertPool()
+    roots.AddCert(root)
+    trusted := x509.VerifyOptions{Roots: roots}
+
+    dir := t.TempDir()
+    filename := writeTestDocument(t, dir, &Signer{Certificate: leaf, Chain: []*x509.Certificate{intermediate}, Key: leafKey, Comment: "Approved"})
+
+    signatures, err := VerifyDocxSignatures(filename, trusted)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(signatures) != 1 {
+        t.Fatalf("got %d signatures, want 1", len(signatures))
+    }
+    sig := signatures[0]
+    if !sig.Valid() || !sig.Intact() || !sig.Trusted() {
+        t.Fatalf("signature not valid: err=%v trust=%v", sig.Err, sig.TrustErr)
+    }
+    if sig.Certificate.Subject.CommonName != "Report Signer" || sig.Comment != "Approved" {
+        t.Errorf("got signer %q comment %q", sig.Certificate.Subject.CommonName, sig.Comment)
+    }
+    if len(sig.Chain) != 3 || !sig.Chain[2].Equal(root) {
+        t.Errorf("got chain of %d certificates, want leaf, intermediate and root", len(sig.Chain))
+    }
+    if !containsString(sig.Parts, "word/document.xml") {
+        t.Errorf("signed parts %v do not include word/document.xml", sig.Parts)
+    }
+
+    signatures, err = VerifyDocxSignatures(filename, x509.VerifyOptions{Roots: x509.NewCertPool()})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if sig := signatures[0]; !sig.Intact() || sig.Trusted() || sig.Valid() || sig.TrustErr == nil {
+        t.Errorf("signature under unknown roots: intact=%v trusted=%v valid=%v", sig.Intact(), sig.Trusted(), sig.Valid())
+    }
+
+    rewritePart(t, filename, "word/document.xml", func(data []byte) []byte {
+        return bytes.Replace(data, []byte("Quarterly"), []byte("Doctored"), 1)
+    })
+    signatures, err = VerifyDocxSignatures(filename, trusted)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if sig := signatures[0]; sig.Intact() || sig.Valid() || !strings.Contains(sig.Err.Error(), "word/document.xml") {
+        t.Errorf("tampered document: intact=%v err=%v", sig.Intact(), sig.Err)
+    }
+
+    attacker, attackerKey := newTestCertificate(t, "Report Signer", nil, nil, false)
+    resigned := filepath.Join(dir, "resigned.docx")
+    if err := SignDocx(filename, resigned, &Signer{Certificate: attacker, Key: attackerKey}); err != nil {
+        t.Fatal(err)
+    }
+    signatures, err = VerifyDocxSignatures(resigned, trusted)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(signatures) != 1 {
+        t.Fatalf("re-signed document has %d signatures, want 1", len(signatures))
+    }
+    if sig := signatures[0]; !sig.Intact() || sig.Trusted() || sig.Valid() {
+        t.Errorf("self-signed re-signature: intact=%v trusted=%v valid=%v", sig.Intact(), sig.Trusted(), sig.Valid())
+    }
+}
+
+
+func TestVerifyIndependentSignatureFixture(t *testing.T) {
+    data, err := os.ReadFile(filepath.Join("testdata", "signing-root.pem"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    block, _ := pem.Decode(data)
+    if block == nil {
+        t.Fatal("testdata/signing-root.pem is not PEM")
+    }
+    root, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        t.Fatal(err)
+    }
+    roots := x509.NewCertPool()
+    roots.AddCert(root)
+    signedAt := time.Date(2024, 3, 14, 9, 26, 53, 0, time.UTC)
+    filename := filepath.Join("testdata", "signed.docx")
+
+    signatures, err := VerifyDocxSignatures(filename, x509.VerifyOptions{Roots: roots, CurrentTime: signedAt})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(signatures) != 1 {
+        t.Fatalf("got %d signatures, want 1", len(signatures))
+    }
+    sig := signatures[0]
+    if !sig.Valid() {
+        t.Fatalf("signature not valid: err=%v trust=%v", sig.Err, sig.TrustErr)
+    }
+    if sig.Certificate.Subject.CommonName != "Fixture Signer" {
+        t.Errorf("signer = %q, want %q", sig.Certificate.Subject.CommonName, "Fixture Signer")
+    }
+    if sig.Comment != "Approved for release" {
+        t.Errorf("Comment = %q", sig.Comment)
+    }
+    if !sig.SigningTime.Equal(signedAt) {
+        t.Errorf("SigningTime = %v, want %v", sig.SigningTime, signedAt)
+    }
+    want := []string{"_rels/.rels", "word/_rels/document.xml.rels", "word/document.xml", "word/styles.xml"}
+    if !equalStrings(sig.Parts, want) {
+        t.Errorf("Parts = %q, want %q", sig.Parts, want)
+    }
+
+    signatures, err = VerifyDocxSignatures(filename, x509.VerifyOptions{Roots: roots, CurrentTime: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if sig := signatures[0]; !sig.Intact() || sig.Trusted() {
+        t.Errorf("signature with an expired certificate: intact=%v trusted=%v", sig.Intact(), sig.Trusted())
+    }
+
+    copied := filepath.Join(t.TempDir(), "signed.docx")
+    original, err := os.ReadFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(copied, original, 0644); err != nil {
+        t.Fatal(err)
+    }
+    rewritePart(t, copied, "word/_rels/document.xml.rels", func(data []byte) []byte {
+        return bytes.Replace(data, []byte("https://example.com/"), []byte("https://example.net/"), 1)
+    })
+    signatures, err = VerifyDocxSignatures(copied, x509.VerifyOptions{Roots: roots, CurrentTime: signedAt})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if sig := signatures[0]; sig.Intact() || !strings.Contains(sig.Err.Error(), "word/_rels/document.xml.rels") {
+        t.Errorf("retargeted hyperlink: intact=%v err=%v", sig.Intact(), sig.Err)
+    }
+}
+
+
+func TestLoadPKCS12Signer(t *testing.T) {
+    tests := []struct {
+        file   string
+        signer string
+        chain  int
+    }{
+        {"pkcs12-rsa-legacy.p12", "PKCS12 RSA Signer", 1},
+        {"pkcs12-rsa-3des.p12", "PKCS12 RSA Signer", 1},
+        {"pkcs12-rsa-aes.p12", "PKCS12 RSA Signer", 1},
+        {"pkcs12-ec-aes.p12", "PKCS12 EC Signer", 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.file, func(t *testing.T) {
+            filename := filepath.Join("testdata", tt.file)
+            signer, err := LoadPKCS12Signer(filename, "fixture")
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got := signer.Certificate.Subject.CommonName; got != tt.signer {
+                t.Errorf("Certificate = %q, want %q", got, tt.signer)
+            }
+            if len(signer.Chain) != tt.chain {
+                t.Errorf("got %d chain certificates, want %d", len(signer.Chain), tt.chain)
+            }
+            for _, cert := range signer.Chain {
+                if cert.Subject.CommonName != "PKCS12 Fixture CA" {
+                    t.Errorf("unexpected chain certificate %q", cert.Subject.CommonName)
+                }
+            }
+
+            signed := writeTestDocument(t, t.TempDir(), signer)
+            signatures, err := VerifyDocxSignatures(signed, x509.VerifyOptions{})
+            if err != nil {
+                t.Fatal(err)
+            }
+            if len(signatures) != 1 || !signatures[0].Intact() {
+                t.Errorf("document signed with %s is not intact: %v", tt.file, signatures)
+            }
+
+            for _, password := range []string{"", "Fixture", "fixture "} {
+                if _, err := LoadPKCS12Signer(filename, password); err == nil {
+                    t.Errorf("LoadPKCS12Signer(%q) succeeded", password)
+                }
+            }
+        })
+    }
+}
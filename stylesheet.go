@@ -0,0 +1,598 @@
+package docx
+
+import (
+    "fmt"
+    "io"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+const relTypeTheme = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme"
+
+var cssClassPattern = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+var highlightColors = map[string]string{
+    "black": "000000", "blue": "0000FF", "cyan": "00FFFF", "green": "00FF00", "magenta": "FF00FF", "red": "FF0000",
+    "yellow": "FFFF00", "white": "FFFFFF", "darkBlue": "000080", "darkCyan": "008080", "darkGreen": "008000",
+    "darkMagenta": "800080", "darkRed": "800000", "darkYellow": "808000", "darkGray": "808080", "lightGray": "C0C0C0",
+}
+
+var tabLeaders = map[string]string{"dot": ".", "hyphen": "-", "underscore": "_", "middleDot": "·", "heavy": "_"}
+
+
+type styleProperties map[string]string
+
+
+type documentStyle struct {
+    id        string
+    kind      string
+    basedOn   string
+    isDefault bool
+    paragraph styleProperties
+    run       styleProperties
+    table     styleProperties
+}
+
+
+type styleSheet struct {
+    styles            map[string]*documentStyle
+    order             []string
+    defaultParagraph  string
+    paragraphDefaults styleProperties
+    runDefaults       styleProperties
+    majorFont         string
+    minorFont         string
+}
+
+
+
+
+func loadStyleSheet(doc Document) *styleSheet {
+    sheet := &styleSheet{
+        styles:            make(map[string]*documentStyle),
+        paragraphDefaults: styleProperties{},
+        runDefaults:       styleProperties{},
+        majorFont:         "Calibri Light",
+        minorFont:         "Calibri",
+    }
+    if data, ok := doc.getRelatedPart(relTypeTheme); ok {
+        if theme, _, err := newDocumentReader(nil).parseTree(data); err == nil {
+            for _, font := range []struct {
+                name   string
+                target *string
+            }{{"a:majorFont", &sheet.majorFont}, {"a:minorFont", &sheet.minorFont}} {
+                if el := theme.find(font.name); el != nil {
+                    if latin := el.find("a:latin"); latin != nil && attrValue(latin, "typeface") != "" {
+                        *font.target = attrValue(latin, "typeface")
+                    }
+                }
+            }
+        }
+    }
+
+    data, ok := doc.getRelatedPart(relTypeStyles)
+    if !ok {
+        data = []byte(defaultStylesXML)
+    }
+    root, _, err := newDocumentReader(nil).parseTree(data)
+    if err != nil {
+        root, _, _ = newDocumentReader(nil).parseTree([]byte(defaultStylesXML))
+    }
+
+    for _, el := range root.elements() {
+        switch el.XMLName.Local {
+        case "w:docDefaults":
+            if pPr := el.find("w:pPr"); pPr != nil {
+                sheet.readParagraphProperties(pPr, sheet.paragraphDefaults)
+            }
+            if rPr := el.find("w:rPr"); rPr != nil {
+                sheet.readRunProperties(rPr, sheet.runDefaults)
+            }
+        case "w:style":
+            style := &documentStyle{
+                id:        attrValue(el, "w:styleId"),
+                kind:      attrValue(el, "w:type"),
+                isDefault: attrValue(el, "w:default") == "1" || attrValue(el, "w:default") == "true",
+                paragraph: styleProperties{},
+                run:       styleProperties{},
+                table:     styleProperties{},
+            }
+            for _, child := range el.elements() {
+                switch child.XMLName.Local {
+                case "w:basedOn":
+                    style.basedOn = attrValue(child, "w:val")
+                case "w:pPr":
+                    sheet.readParagraphProperties(child, style.paragraph)
+                case "w:rPr":
+                    sheet.readRunProperties(child, style.run)
+                case "w:tblPr":
+                    sheet.readTableProperties(child, style.table)
+                }
+            }
+            if style.id == "" {
+                continue
+            }
+            if _, exists := sheet.styles[style.id]; !exists {
+                sheet.order = append(sheet.order, style.id)
+            }
+            sheet.styles[style.id] = style
+            if style.kind == "paragraph" && style.isDefault {
+                sheet.defaultParagraph = style.id
+            }
+        }
+    }
+    return sheet
+}
+
+
+func (s *styleSheet) readRunProperties(el *rawXML, props styleProperties) {
+    for _, child := range el.elements() {
+        name := strings.TrimPrefix(child.XMLName.Local, "w:")
+        switch name {
+        case "rFonts":
+            switch {
+            case attrValue(child, "w:ascii") != "":
+                props["font"] = attrValue(child, "w:ascii")
+            case strings.HasPrefix(attrValue(child, "w:asciiTheme"), "major"):
+                props["font"] = s.majorFont
+            case strings.HasPrefix(attrValue(child, "w:asciiTheme"), "minor"):
+                props["font"] = s.minorFont
+            }
+        case "b", "i", "strike", "dstrike", "caps", "smallCaps", "vanish":
+            props[name] = strconv.FormatBool(isOn(child))
+        case "u":
+            props["underline"] = attrValue(child, "w:val")
+        case "sz":
+            props["size"] = attrValue(child, "w:val")
+        case "color":
+            props["color"] = attrValue(child, "w:val")
+        case "highlight":
+            props["highlight"] = attrValue(child, "w:val")
+        case "shd":
+            props["shading"] = attrValue(child, "w:fill")
+        case "vertAlign":
+            props["vertAlign"] = attrValue(child, "w:val")
+        }
+    }
+}
+
+
+func (s *styleSheet) readParagraphProperties(el *rawXML, props styleProperties) {
+    for _, child := range el.elements() {
+        switch child.XMLName.Local {
+        case "w:jc":
+            props["align"] = attrValue(child, "w:val")
+        case "w:spacing":
+            for _, name := range []string{"before", "after", "line", "lineRule"} {
+                if v := attrValue(child, "w:"+name); v != "" {
+                    props[name] = v
+                }
+            }
+        case "w:ind":
+            for _, name := range []string{"left", "start", "right", "end", "firstLine", "hanging"} {
+                if v := attrValue(child, "w:"+name); v != "" {
+                    props[strings.NewReplacer("start", "left", "end", "right").Replace(name)] = v
+                }
+            }
+        case "w:keepNext", "w:keepLines", "w:pageBreakBefore", "w:contextualSpacing":
+            props[strings.TrimPrefix(child.XMLName.Local, "w:")] = strconv.FormatBool(isOn(child))
+        case "w:shd":
+            props["shading"] = attrValue(child, "w:fill")
+        case "w:pBdr":
+            readBorders(child, props)
+        case "w:outlineLvl":
+            props["outlineLevel"] = attrValue(child, "w:val")
+        case "w:tabs":
+            tabs := []string{}
+            if props["tabs"] != "" {
+                tabs = strings.Split(props["tabs"], ",")
+            }
+            for _, tab := range child.elements() {
+                tabs = append(tabs, attrValue(tab, "w:val")+":"+attrValue(tab, "w:pos")+":"+attrValue(tab, "w:leader"))
+            }
+            props["tabs"] = strings.Join(tabs, ",")
+        }
+    }
+}
+
+
+func (s *styleSheet) readTableProperties(el *rawXML, props styleProperties) {
+    for _, child := range el.elements() {
+        switch child.XMLName.Local {
+        case "w:tblBorders":
+            readBorders(child, props)
+        case "w:tblCellMar":
+            for _, side := range child.elements() {
+                name := strings.NewReplacer("start", "left", "end", "right").Replace(strings.TrimPrefix(side.XMLName.Local, "w:"))
+                props["cell-"+name] = attrValue(side, "w:w")
+            }
+        }
+    }
+}
+
+
+func readBorders(el *rawXML, props styleProperties) {
+    for _, side := range el.elements() {
+        name := strings.NewReplacer("start", "left", "end", "right").Replace(strings.TrimPrefix(side.XMLName.Local, "w:"))
+        props["border-"+name] = strings.Join([]string{
+            attrValue(side, "w:val"), attrValue(side, "w:sz"), attrValue(side, "w:space"), attrValue(side, "w:color"),
+        }, " ")
+    }
+}
+
+
+
+
+func (s *styleSheet) directRunProperties(props *runProperties) styleProperties {
+    direct := styleProperties{}
+    if props == nil {
+        return direct
+    }
+    s.readRunProperties(&rawXML{Children: rawChildren(props.Extra)}, direct)
+    if props.Fonts != nil && props.Fonts.ASCII != "" {
+        direct["font"] = props.Fonts.ASCII
+    }
+    if props.Bold != nil {
+        direct["b"] = "true"
+    }
+    if props.Italic != nil {
+        direct["i"] = "true"
+    }
+    if props.Strike != nil {
+        direct["strike"] = strconv.FormatBool(props.Strike.Val != "0" && props.Strike.Val != "false")
+    }
+    if props.Color != nil {
+        direct["color"] = props.Color.Val
+    }
+    if props.Size != nil {
+        direct["size"] = strconv.Itoa(props.Size.Val)
+    }
+    if props.Underline != nil {
+        direct["underline"] = props.Underline.Val
+    }
+    return direct
+}
+
+
+func (s *styleSheet) directParagraphProperties(props *paragraphProperties) styleProperties {
+    direct := styleProperties{}
+    if props == nil {
+        return direct
+    }
+    s.readParagraphProperties(&rawXML{Children: rawChildren(props.Extra)}, direct)
+    if props.Justification != nil {
+        direct["align"] = props.Justification.Val
+    }
+    if props.KeepNext != nil {
+        direct["keepNext"] = strconv.FormatBool(props.KeepNext.Val != "0" && props.KeepNext.Val != "false")
+    }
+    if props.Tabs != nil {
+        tabs := []string{}
+        if direct["tabs"] != "" {
+            tabs = append(tabs, direct["tabs"])
+        }
+        for _, tab := range props.Tabs.Tabs {
+            tabs = append(tabs, tab.Val+":"+strconv.Itoa(tab.Pos)+":"+tab.Leader)
+        }
+        direct["tabs"] = strings.Join(tabs, ",")
+    }
+    return direct
+}
+
+
+
+
+func (s *styleSheet) resolve(id string) (paragraph styleProperties, run styleProperties, table styleProperties) {
+    paragraph, run, table = styleProperties{}, styleProperties{}, styleProperties{}
+    chain := []*documentStyle{}
+    for seen := make(map[string]bool); id != "" && !seen[id]; {
+        style, ok := s.styles[id]
+        if !ok {
+            break
+        }
+        seen[id] = true
+        chain = append(chain, style)
+        id = style.basedOn
+    }
+    for i := len(chain) - 1; i >= 0; i-- {
+        for k, v := range chain[i].paragraph {
+            paragraph[k] = v
+        }
+        for k, v := range chain[i].run {
+            run[k] = v
+        }
+        for k, v := range chain[i].table {
+            table[k] = v
+        }
+    }
+    return paragraph, run, table
+}
+
+
+func cssClassName(styleID string) string {
+    name := cssClassPattern.ReplaceAllString(styleID, "-")
+    if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+        name = "s" + name
+    }
+    return name
+}
+
+
+func (s *styleSheet) css() string {
+    var sb strings.Builder
+    body := styleProperties{}
+    for k, v := range s.runDefaults {
+        body[k] = v
+    }
+    writeCSSRule(&sb, "body", runCSS(body))
+    sb.WriteString("p, h1, h2, h3, h4, h5, h6, ul, ol, li, pre, blockquote, figure, figcaption { margin: 0; padding: 0; }\n")
+    sb.WriteString("h1, h2, h3, h4, h5, h6 { font-size: inherit; font-weight: normal; }\n")
+    sb.WriteString("ul, ol { padding-left: 36pt; }\n")
+    sb.WriteString("pre { white-space: pre-wrap; }\n")
+    sb.WriteString("figure img { max-width: 100%; height: auto; }\n")
+    sb.WriteString("table.docx-table { border-collapse: collapse; }\n")
+    sb.WriteString("table.docx-table td, table.docx-table th { vertical-align: top; text-align: left; font-weight: normal; padding: 0 5.4pt; }\n")
+
+    for _, id := range s.order {
+        style := s.styles[id]
+        paragraph, run, table := s.resolve(id)
+        class := "." + cssClassName(id)
+        switch style.kind {
+        case "paragraph":
+            merged := styleProperties{}
+            for k, v := range s.paragraphDefaults {
+                merged[k] = v
+            }
+            for k, v := range paragraph {
+                merged[k] = v
+            }
+            writeCSSRule(&sb, class, append(paragraphCSS(merged), runCSS(run)...))
+        case "character":
+            writeCSSRule(&sb, class, runCSS(run))
+        case "table":
+            writeCSSRule(&sb, "table"+class, tableCSS(table, false))
+            writeCSSRule(&sb, "table"+class+" td, table"+class+" th", tableCSS(table, true))
+            writeCSSRule(&sb, "table"+class+" p", append(paragraphCSS(paragraph), runCSS(run)...))
+        }
+    }
+    return strings.ReplaceAll(sb.String(), "</", `<\/`)
+}
+
+
+func (s *styleSheet) writeCSS(w io.Writer) error {
+    if _, err := io.WriteString(w, s.css()); err != nil {
+        return fmt.Errorf("failed to write stylesheet: %w", err)
+    }
+    return nil
+}
+
+
+func writeCSSRule(sb *strings.Builder, selector string, declarations []string) {
+    if len(declarations) == 0 {
+        return
+    }
+    sort.Strings(declarations)
+    sb.WriteString(selector + " { " + strings.Join(declarations, " ") + " }\n")
+}
+
+
+func twipsToPoints(value string) (string, bool) {
+    n, err := strconv.ParseFloat(value, 64)
+    if err != nil {
+        return "", false
+    }
+    return strconv.FormatFloat(n/20, 'f', -1, 64) + "pt", true
+}
+
+
+func cssColor(value string) (string, bool) {
+    if len(value) != 6 || strings.Trim(strings.ToUpper(value), "0123456789ABCDEF") != "" {
+        return "", false
+    }
+    return "#" + strings.ToUpper(value), true
+}
+
+
+func genericFontFamily(font string) string {
+    lower := strings.ToLower(font)
+    switch {
+    case strings.Contains(lower, "courier") || strings.Contains(lower, "consolas") || strings.Contains(lower, "mono"):
+        return "monospace"
+    case strings.Contains(lower, "times") || strings.Contains(lower, "cambria") || strings.Contains(lower, "georgia") ||
+        strings.Contains(lower, "garamond"):
+        return "serif"
+    }
+    return "sans-serif"
+}
+
+
+func fontFamilyClass(font string) string {
+    switch genericFontFamily(font) {
+    case "monospace":
+        return "modern"
+    case "serif":
+        return "roman"
+    }
+    return "swiss"
+}
+
+
+func cssFontFamily(font string) string {
+    return `"` + cssEscape(font) + `", ` + genericFontFamily(font)
+}
+
+
+func cssEscape(value string) string {
+    var sb strings.Builder
+    for _, r := range value {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_' {
+            sb.WriteRune(r)
+        } else {
+            sb.WriteString(`\` + strconv.FormatInt(int64(r), 16) + " ")
+        }
+    }
+    return sb.String()
+}
+
+
+func cssBorder(value string) (string, bool) {
+    parts := strings.Split(value, " ")
+    if len(parts) != 4 || parts[0] == "" || parts[0] == "none" || parts[0] == "nil" {
+        return "", false
+    }
+    width := "0.5pt"
+    if n, err := strconv.ParseFloat(parts[1], 64); err == nil && n > 0 {
+        width = strconv.FormatFloat(n/8, 'f', -1, 64) + "pt"
+    }
+    style := "solid"
+    switch parts[0] {
+    case "double":
+        style = "double"
+    case "dotted":
+        style = "dotted"
+    case "dashed", "dashSmallGap", "dotDash", "dotDotDash":
+        style = "dashed"
+    }
+    color, ok := cssColor(parts[3])
+    if !ok {
+        color = "#000000"
+    }
+    return width + " " + style + " " + color, true
+}
+
+
+func runCSS(props styleProperties) []string {
+    css := []string{}
+    if font := props["font"]; font != "" {
+        css = append(css, "font-family: "+cssFontFamily(font)+";")
+    }
+    if n, err := strconv.ParseFloat(props["size"], 64); err == nil {
+        css = append(css, "font-size: "+strconv.FormatFloat(n/2, 'f', -1, 64)+"pt;")
+    }
+    if v, ok := props["b"]; ok {
+        css = append(css, map[bool]string{true: "font-weight: bold;", false: "font-weight: normal;"}[v == "true"])
+    }
+    if v, ok := props["i"]; ok {
+        css = append(css, map[bool]string{true: "font-style: italic;", false: "font-style: normal;"}[v == "true"])
+    }
+    decorations := []string{}
+    if u := props["underline"]; u != "" && u != "none" {
+        decorations = append(decorations, "underline")
+    }
+    if props["strike"] == "true" || props["dstrike"] == "true" {
+        decorations = append(decorations, "line-through")
+    }
+    if len(decorations) > 0 {
+        css = append(css, "text-decoration: "+strings.Join(decorations, " ")+";")
+    }
+    if props["caps"] == "true" {
+        css = append(css, "text-transform: uppercase;")
+    }
+    if props["smallCaps"] == "true" {
+        css = append(css, "font-variant: small-caps;")
+    }
+    if props["vanish"] == "true" {
+        css = append(css, "display: none;")
+    }
+    if color, ok := cssColor(props["color"]); ok {
+        css = append(css, "color: "+color+";")
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        css = append(css, "background-color: "+fill+";")
+    } else if h := props["highlight"]; h != "" && h != "none" {
+        if color, ok := highlightColors[h]; ok {
+            css = append(css, "background-color: #"+color+";")
+        }
+    }
+    switch props["vertAlign"] {
+    case "superscript":
+        css = append(css, "vertical-align: super;")
+    case "subscript":
+        css = append(css, "vertical-align: sub;")
+    }
+    return css
+}
+
+
+func paragraphCSS(props styleProperties) []string {
+    css := []string{}
+    switch props["align"] {
+    case "left", "start":
+        css = append(css, "text-align: left;")
+    case "center":
+        css = append(css, "text-align: center;")
+    case "right", "end":
+        css = append(css, "text-align: right;")
+    case "both", "distribute":
+        css = append(css, "text-align: justify;")
+    }
+    for _, side := range []struct{ prop, css string }{
+        {"before", "margin-top"}, {"after", "margin-bottom"}, {"left", "margin-left"}, {"right", "margin-right"},
+    } {
+        if v, ok := twipsToPoints(props[side.prop]); ok {
+            css = append(css, side.css+": "+v+";")
+        }
+    }
+    if v, ok := twipsToPoints(props["firstLine"]); ok {
+        css = append(css, "text-indent: "+v+";")
+    }
+    if v, ok := twipsToPoints(props["hanging"]); ok {
+        css = append(css, "text-indent: -"+v+";")
+    }
+    if line, err := strconv.ParseFloat(props["line"], 64); err == nil && line > 0 {
+        if props["lineRule"] == "" || props["lineRule"] == "auto" {
+            css = append(css, "line-height: "+strconv.FormatFloat(line/240*1.15, 'f', 2, 64)+";")
+        } else {
+            css = append(css, "line-height: "+strconv.FormatFloat(line/20, 'f', -1, 64)+"pt;")
+        }
+    }
+    if fill, ok := cssColor(props["shading"]); ok {
+        css = append(css, "background-color: "+fill+";")
+    }
+    for _, side := range []string{"top", "left", "bottom", "right"} {
+        border, ok := cssBorder(props["border-"+side])
+        if !ok {
+            continue
+        }
+        css = append(css, "border-"+side+": "+border+";")
+        if space, err := strconv.ParseFloat(strings.Split(props["border-"+side], " ")[2], 64); err == nil {
+            css = append(css, "padding-"+side+": "+strconv.FormatFloat(space, 'f', -1, 64)+"pt;")
+        }
+    }
+    if props["keepNext"] == "true" {
+        css = append(css, "page-break-after: avoid;")
+    }
+    if props["pageBreakBefore"] == "true" {
+        css = append(css, "page-break-before: always;")
+    }
+    return css
+}
+
+
+func tableCSS(props styleProperties, cells bool) []string {
+    css := []string{}
+    if !cells {
+        for _, side := range []string{"top", "left", "bottom", "right"} {
+            if border, ok := cssBorder(props["border-"+side]); ok {
+                css = append(css, "border-"+side+": "+border+";")
+            }
+        }
+        return css
+    }
+
+    if border, ok := cssBorder(props["border-insideH"]); ok {
+        css = append(css, "border-top: "+border+";", "border-bottom: "+border+";")
+    }
+    if border, ok := cssBorder(props["border-insideV"]); ok {
+        css = append(css, "border-left: "+border+";", "border-right: "+border+";")
+    }
+    for _, side := range []string{"top", "left", "bottom", "right"} {
+        if v, ok := twipsToPoints(props["cell-"+side]); ok {
+            css = append(css, "padding-"+side+": "+v+";")
+        }
+    }
+    return css
+}
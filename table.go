@@ -0,0 +1,315 @@
+package docx
+
+import (
+    "encoding/xml"
+    "strings"
+)
+
+const StyleTableGrid = "TableGrid"
+
+const defaultTableWidth = 9360
+
+
+type tableStyle struct {
+    XMLName xml.Name `xml:"w:tblStyle"`
+    Val     string   `xml:"w:val,attr,omitempty"`
+}
+
+type tableWidth struct {
+    W    int    `xml:"w:w,attr"`
+    Type string `xml:"w:type,attr"`
+}
+
+type tableLook struct {
+    XMLName  xml.Name `xml:"w:tblLook"`
+    Val      string   `xml:"w:val,attr,omitempty"`
+    FirstRow string   `xml:"w:firstRow,attr,omitempty"`
+    LastRow  string   `xml:"w:lastRow,attr,omitempty"`
+    FirstCol string   `xml:"w:firstColumn,attr,omitempty"`
+    LastCol  string   `xml:"w:lastColumn,attr,omitempty"`
+    NoHBand  string   `xml:"w:noHBand,attr,omitempty"`
+    NoVBand  string   `xml:"w:noVBand,attr,omitempty"`
+}
+
+type tableProperties struct {
+    XMLName xml.Name    `xml:"w:tblPr"`
+    Style   *tableStyle `xml:"w:tblStyle,omitempty"`
+    Width   *tableWidth `xml:"w:tblW,omitempty"`
+    Look    *tableLook  `xml:"w:tblLook,omitempty"`
+    Extra   []*rawXML   `xml:",any"`
+}
+
+
+var tablePropertiesOrder = []string{
+    "w:tblStyle", "w:tblpPr", "w:tblOverlap", "w:bidiVisual", "w:tblStyleRowBandSize", "w:tblStyleColBandSize",
+    "w:tblW", "w:jc", "w:tblCellSpacing", "w:tblInd", "w:tblBorders", "w:shd", "w:tblLayout", "w:tblCellMar",
+    "w:tblLook", "w:tblCaption", "w:tblDescription", "w:tblPrChange",
+}
+
+
+func (p *tableProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    return marshalOrdered(e, start, p, tablePropertiesOrder)
+}
+
+type gridColumn struct {
+    XMLName xml.Name `xml:"w:gridCol"`
+    W       int      `xml:"w:w,attr"`
+}
+
+type tableGrid struct {
+    XMLName xml.Name     `xml:"w:tblGrid"`
+    Columns []gridColumn `xml:"w:gridCol"`
+}
+
+type tableRowProperties struct {
+    XMLName xml.Name       `xml:"w:trPr"`
+    Header  *onOffProperty `xml:"w:tblHeader,omitempty"`
+    Extra   []*rawXML      `xml:",any"`
+}
+
+
+func (p *tableRowProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    return marshalOrdered(e, start, p, nil)
+}
+
+type tableCellProperties struct {
+    XMLName xml.Name    `xml:"w:tcPr"`
+    Width   *tableWidth `xml:"w:tcW,omitempty"`
+    Extra   []*rawXML   `xml:",any"`
+}
+
+
+var tableCellPropertiesOrder = []string{
+    "w:cnfStyle", "w:tcW", "w:gridSpan", "w:hMerge", "w:vMerge", "w:tcBorders", "w:shd", "w:noWrap", "w:tcMar",
+    "w:textDirection", "w:tcFitText", "w:vAlign", "w:hideMark", "w:headers", "w:cellIns", "w:cellDel",
+    "w:cellMerge", "w:tcPrChange",
+}
+
+
+func (p *tableCellProperties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+    return marshalOrdered(e, start, p, tableCellPropertiesOrder)
+}
+
+type tableCellData struct {
+    XMLName    xml.Name             `xml:"w:tc"`
+    Properties *tableCellProperties `xml:"w:tcPr,omitempty"`
+    Blocks     []interface{}        `xml:",any"`
+}
+
+type tableRowData struct {
+    XMLName    xml.Name            `xml:"w:tr"`
+    Attrs      []xml.Attr          `xml:",any,attr"`
+    Properties *tableRowProperties `xml:"w:trPr,omitempty"`
+    Cells      []*tableCellData    `xml:"w:tc"`
+}
+
+type tableData struct {
+    XMLName    xml.Name         `xml:"w:tbl"`
+    Properties *tableProperties `xml:"w:tblPr"`
+    Grid       tableGrid        `xml:"w:tblGrid"`
+    Rows       []*tableRowData  `xml:"w:tr"`
+}
+
+
+type Table struct {
+    doc  *DocxDocument
+    data *tableData
+}
+
+
+type TableRow struct {
+    doc  *DocxDocument
+    data *tableRowData
+}
+
+
+type TableCell struct {
+    blockList
+    data *tableCellData
+}
+
+
+func newTableData(rows int, cols int) *tableData {
+    if cols < 1 {
+        cols = 1
+    }
+    if rows < 0 {
+        rows = 0
+    }
+
+    colWidth := defaultTableWidth / cols
+    table := &tableData{
+        Properties: &tableProperties{
+            Style: &tableStyle{Val: StyleTableGrid},
+            Width: &tableWidth{W: 0, Type: "auto"},
+            Look: &tableLook{
+                Val: "04A0", FirstRow: "1", LastRow: "0", FirstCol: "1", LastCol: "0", NoHBand: "0", NoVBand: "1",
+            },
+        },
+    }
+    for i := 0; i < cols; i++ {
+        table.Grid.Columns = append(table.Grid.Columns, gridColumn{W: colWidth})
+    }
+    for i := 0; i < rows; i++ {
+        table.Rows = append(table.Rows, newTableRowData(cols, colWidth))
+    }
+    return table
+}
+
+
+func newTableRowData(cols int, colWidth int) *tableRowData {
+    row := &tableRowData{}
+    for i := 0; i < cols; i++ {
+        row.Cells = append(row.Cells, &tableCellData{
+            Properties: &tableCellProperties{Width: &tableWidth{W: colWidth, Type: "dxa"}},
+        })
+    }
+    return row
+}
+
+
+func normalizeTableCells(blocks []interface{}) {
+    for _, block := range blocks {
+        if container, ok := block.(*containerElement); ok {
+            normalizeTableCells(container.Content)
+            continue
+        }
+        table, ok := block.(*tableData)
+        if !ok {
+            continue
+        }
+        for _, row := range table.Rows {
+            for _, cell := range row.Cells {
+                normalizeTableCells(cell.Blocks)
+                if len(cell.Blocks) == 0 {
+                    cell.Blocks = append(cell.Blocks, &paragraphData{})
+                    continue
+                }
+                if _, ok := cell.Blocks[len(cell.Blocks)-1].(*paragraphData); !ok {
+                    cell.Blocks = append(cell.Blocks, &paragraphData{})
+                }
+            }
+        }
+    }
+}
+
+
+func (d *DocxDocument) AddTable(rows int, cols int) *Table {
+    table := newTableData(rows, cols)
+    d.content = append(d.content, table)
+    return &Table{doc: d, data: table}
+}
+
+
+func (t *Table) block() interface{} {
+    return t.data
+}
+
+
+func (t *Table) Rows() []*TableRow {
+    rows := []*TableRow{}
+    for _, row := range t.data.Rows {
+        rows = append(rows, &TableRow{doc: t.doc, data: row})
+    }
+    return rows
+}
+
+
+func (t *Table) AddRow() *TableRow {
+    colWidth := defaultTableWidth
+    if len(t.data.Grid.Columns) > 0 {
+        colWidth = t.data.Grid.Columns[0].W
+    }
+    row := newTableRowData(len(t.data.Grid.Columns), colWidth)
+    t.data.Rows = append(t.data.Rows, row)
+    return &TableRow{doc: t.doc, data: row}
+}
+
+
+func (t *Table) Cell(row int, col int) *TableCell {
+    if row < 0 || row >= len(t.data.Rows) {
+        return nil
+    }
+    cells := t.data.Rows[row].Cells
+    if col < 0 || col >= len(cells) {
+        return nil
+    }
+    return newTableCell(t.doc, cells[col])
+}
+
+
+func (t *Table) ColumnCount() int {
+    return len(t.data.Grid.Columns)
+}
+
+
+func (t *Table) Style() string {
+    if t.data.Properties == nil || t.data.Properties.Style == nil {
+        return ""
+    }
+    return t.data.Properties.Style.Val
+}
+
+
+func (t *Table) SetStyle(style string) {
+    if t.data.Properties == nil {
+        t.data.Properties = &tableProperties{}
+    }
+    if style == "" {
+        t.data.Properties.Style = nil
+        return
+    }
+    t.data.Properties.Style = &tableStyle{Val: style}
+}
+
+
+func (r *TableRow) Cells() []*TableCell {
+    cells := []*TableCell{}
+    for _, cell := range r.data.Cells {
+        cells = append(cells, newTableCell(r.doc, cell))
+    }
+    return cells
+}
+
+
+func (r *TableRow) IsHeader() bool {
+    return r.data.Properties != nil && r.data.Properties.Header != nil
+}
+
+
+func (r *TableRow) SetHeader(header bool) {
+    if header {
+        if r.data.Properties == nil {
+            r.data.Properties = &tableRowProperties{}
+        }
+        r.data.Properties.Header = &onOffProperty{}
+    } else if r.data.Properties != nil {
+        r.data.Properties.Header = nil
+    }
+}
+
+
+func newTableCell(doc *DocxDocument, cell *tableCellData) *TableCell {
+    return &TableCell{blockList: blockList{doc: doc, blocks: &cell.Blocks, cell: true}, data: cell}
+}
+
+
+func (c *TableCell) AddParagraph(style string) *Paragraph {
+    para := &paragraphData{Properties: newParagraphProperties(style)}
+    c.data.Blocks = append(c.data.Blocks, para)
+    return &Paragraph{doc: c.doc, data: para}
+}
+
+
+func (c *TableCell) AddText(style string, text string, formatOptions ...string) *Run {
+    return c.AddParagraph(style).AddRun(text, formatOptions...)
+}
+
+
+func (c *TableCell) Text() string {
+    texts := []string{}
+    for _, para := range collectParagraphs(c.data.Blocks, nil) {
+        texts = append(texts, para.text())
+    }
+    return strings.Join(texts, "\n")
+}
@@ -0,0 +1,95 @@
+package docx
+
+import (
+    "encoding/xml"
+    "fmt"
+    "sort"
+)
+
+const (
+    TabLeft    = "left"
+    TabCenter  = "center"
+    TabRight   = "right"
+    TabDecimal = "decimal"
+    TabBar     = "bar"
+)
+
+const (
+    LeaderNone       = "none"
+    LeaderDot        = "dot"
+    LeaderHyphen     = "hyphen"
+    LeaderUnderscore = "underscore"
+)
+
+
+type TabStop struct {
+    Position  int
+    Alignment string
+    Leader    string
+}
+
+
+type tabStop struct {
+    XMLName xml.Name `xml:"w:tab"`
+    Val     string   `xml:"w:val,attr"`
+    Leader  string   `xml:"w:leader,attr,omitempty"`
+    Pos     int      `xml:"w:pos,attr"`
+}
+
+type tabStops struct {
+    XMLName xml.Name  `xml:"w:tabs"`
+    Tabs    []tabStop `xml:"w:tab"`
+}
+
+
+func newTabStops(stops []TabStop) (*tabStops, error) {
+    tabs := &tabStops{}
+    for _, stop := range stops {
+        alignment := stop.Alignment
+        switch alignment {
+        case "":
+            alignment = TabLeft
+        case TabLeft, TabCenter, TabRight, TabDecimal, TabBar:
+        default:
+            return nil, fmt.Errorf("unsupported tab stop alignment: %s", stop.Alignment)
+        }
+
+        switch stop.Leader {
+        case "", LeaderNone, LeaderDot, LeaderHyphen, LeaderUnderscore:
+        default:
+            return nil, fmt.Errorf("unsupported tab stop leader: %s", stop.Leader)
+        }
+
+        if stop.Position < 0 {
+            return nil, fmt.Errorf("tab stop position must not be negative: %d", stop.Position)
+        }
+
+        tabs.Tabs = append(tabs.Tabs, tabStop{Val: alignment, Leader: stop.Leader, Pos: stop.Position})
+    }
+    if len(tabs.Tabs) == 0 {
+        return nil, nil
+    }
+
+    sort.SliceStable(tabs.Tabs, func(i, j int) bool {
+        return tabs.Tabs[i].Pos < tabs.Tabs[j].Pos
+    })
+    return tabs, nil
+}
+
+
+func (d *DocxDocument) SetTabStops(stops ...TabStop) error {
+    tabs, err := newTabStops(stops)
+    if err != nil {
+        return err
+    }
+
+    lastPara := d.lastParagraph()
+    if lastPara.Properties == nil {
+        if tabs == nil {
+            return nil
+        }
+        lastPara.Properties = &paragraphProperties{}
+    }
+    lastPara.Properties.Tabs = tabs
+    return nil
+}
@@ -0,0 +1,65 @@
+package docx
+
+import (
+    "fmt"
+    "testing"
+)
+
+
+func TestTabStopsRoundTrip(t *testing.T) {
+    doc := NewDocxDocument()
+    doc.AddText(StyleHeading1, "Contents")
+    doc.AddText(StyleNormal, "Chapter 1")
+    doc.AddTab()
+    doc.AddText(StyleNormal, "7")
+    if err := doc.SetTabStops(TabStop{Position: 9000, Alignment: TabRight, Leader: LeaderDot}, TabStop{Position: 720}); err != nil {
+        t.Fatal(err)
+    }
+    for _, stop := range []TabStop{{Position: 720, Alignment: "middle"}, {Position: 720, Leader: "wave"}, {Position: -1}} {
+        if err := doc.SetTabStops(stop); err == nil {
+            t.Errorf("SetTabStops(%+v) succeeded", stop)
+        }
+    }
+
+    para := doc.AddParagraph(StyleNormal)
+    para.AddRun("Total")
+    para.AddTab()
+    para.AddRun("12.50")
+    if err := para.SetTabStops(TabStop{Position: 7200, Alignment: TabDecimal, Leader: LeaderUnderscore}, TabStop{Position: 4320, Alignment: TabBar}); err != nil {
+        t.Fatal(err)
+    }
+    para.SetKeepNext(true)
+    if err := para.SetAlignment(AlignRight); err != nil {
+        t.Fatal(err)
+    }
+
+    plain := doc.AddParagraph("")
+    plain.AddRun("No stops")
+    if err := doc.SetTabStops(); err != nil {
+        t.Fatal(err)
+    }
+    if plain.data.Properties != nil {
+        t.Error("SetTabStops without stops added paragraph properties")
+    }
+
+    reopened := reopenDocument(t, doc)
+    want := [][]string{nil, {"left 720 ", "right 9000 dot"}, {"bar 4320 ", "decimal 7200 underscore"}, nil}
+    paras := reopened.paragraphs()
+    if len(paras) != len(want) {
+        t.Fatalf("got %d paragraphs, want %d", len(paras), len(want))
+    }
+    for i, para := range paras {
+        got := []string(nil)
+        if para.Properties != nil && para.Properties.Tabs != nil {
+            for _, tab := range para.Properties.Tabs.Tabs {
+                got = append(got, fmt.Sprintf("%s %d %s", tab.Val, tab.Pos, tab.Leader))
+            }
+        }
+        if !equalStrings(got, want[i]) {
+            t.Errorf("paragraph %d tab stops = %q, want %q", i, got, want[i])
+        }
+    }
+    if props := paras[2].Properties; props.KeepNext == nil || props.Justification == nil || props.Justification.Val != AlignRight {
+        t.Errorf("tab stops replaced the other paragraph properties: %+v", props)
+    }
+}
@@ -0,0 +1,29 @@
+package docx
+
+import "strings"
+
+
+func (d *DocxDocument) Text() string {
+    return strings.Join(blockTexts(d.content, []string{}), "\n")
+}
+
+
+func blockTexts(blocks []interface{}, lines []string) []string {
+    for _, block := range blocks {
+        switch v := block.(type) {
+        case *paragraphData:
+            lines = append(lines, v.text())
+        case *tableData:
+            for _, row := range v.Rows {
+                cells := []string{}
+                for _, cell := range row.Cells {
+                    cells = append(cells, strings.Join(blockTexts(cell.Blocks, []string{}), " "))
+                }
+                lines = append(lines, strings.Join(cells, "\t"))
+            }
+        case *containerElement:
+            lines = blockTexts(v.Content, lines)
+        }
+    }
+    return lines
+}
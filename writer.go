@@ -7,7 +7,8 @@ import (
     "fmt"
     "io"
     "os"
-
+    "path"
+    "strings"
 )
 
 
@@ -49,12 +50,29 @@ type types struct {
 }
 
 
+type packagePart struct {
+    name        string
+    contentType string
+    data        []byte
+    rel         *relationship
+    rootRel     *relationship
+}
+
+
 type DocumentWriter interface {
     WriteDocument(filename string, doc Document) error
 }
 
 
-type ZipDocxWriter struct{}
+type packageWriter interface {
+    Create(name string) (io.Writer, error)
+}
+
+
+type ZipDocxWriter struct {
+    Password string
+    Signer   *Signer
+}
 
 
 func NewZipDocxWriter() *ZipDocxWriter {
@@ -62,8 +80,8 @@ func NewZipDocxWriter() *ZipDocxWriter {
 }
 
 
-func (zw *ZipDocxWriter) addXMLPart(zipWriter *zip.Writer, filename string, data interface{}) error {
-    partWriter, err := zipWriter.Create(filename)
+func (zw *ZipDocxWriter) addXMLPart(pkg packageWriter, filename string, data interface{}) error {
+    partWriter, err := pkg.Create(filename)
     if err != nil {
         return fmt.Errorf("failed to create %s in zip: %w", filename, err)
     }
@@ -83,8 +101,8 @@ func (zw *ZipDocxWriter) addXMLPart(zipWriter *zip.Writer, filename string, data
 }
 
 
-func (zw *ZipDocxWriter) writeStringPart(zipWriter *zip.Writer, filename string, content string) error {
-    partWriter, err := zipWriter.Create(filename)
+func (zw *ZipDocxWriter) writeStringPart(pkg packageWriter, filename string, content string) error {
+    partWriter, err := pkg.Create(filename)
     if err != nil {
         return fmt.Errorf("failed to create %s in zip: %w", filename, err)
     }
@@ -96,24 +114,23 @@ func (zw *ZipDocxWriter) writeStringPart(zipWriter *zip.Writer, filename string,
 }
 
 
-func (zw *ZipDocxWriter) writeBytesPart(zipWriter *zip.Writer, filename string, content []byte) error {
-    fmt.Printf("DEBUG: writeBytesPart called for: '%s'\n", filename)
-    partWriter, err := zipWriter.Create(filename)
+func (zw *ZipDocxWriter) writeBytesPart(pkg packageWriter, filename string, content []byte) error {
+    partWriter, err := pkg.Create(filename)
     if err != nil {
-         fmt.Fprintf(os.Stderr, "ERROR in writeBytesPart (Create) for %s: %v\n", filename, err)
         return fmt.Errorf("failed to create %s in zip: %w", filename, err)
     }
-    n, err := io.Copy(partWriter, bytes.NewReader(content))
+    _, err = io.Copy(partWriter, bytes.NewReader(content))
     if err != nil {
-         fmt.Fprintf(os.Stderr, "ERROR in writeBytesPart (Copy) for %s after writing %d bytes: %v\n", filename, n, err)
         return fmt.Errorf("failed to write byte content to %s: %w", filename, err)
     }
-     fmt.Printf("DEBUG: writeBytesPart successfully copied %d bytes to '%s'\n", n, filename)
     return nil
 }
 
 
 func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
+    if zw.Password != "" || zw.Signer != nil {
+        return zw.writeSecuredDocument(filename, doc)
+    }
 
     file, err := os.Create(filename)
     if err != nil {
@@ -125,6 +142,49 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
     zipWriter := zip.NewWriter(file)
     defer zipWriter.Close()
 
+    return zw.writePackage(zipWriter, doc)
+}
+
+
+func (zw *ZipDocxWriter) writeSecuredDocument(filename string, doc Document) error {
+    var buf bytes.Buffer
+    zipWriter := zip.NewWriter(&buf)
+    if err := zw.writePackage(zipWriter, doc); err != nil {
+        return err
+    }
+    if err := zipWriter.Close(); err != nil {
+        return fmt.Errorf("failed to finish docx package: %w", err)
+    }
+
+    data := buf.Bytes()
+    var err error
+    if zw.Signer != nil {
+        if data, err = signPackage(data, zw.Signer); err != nil {
+            return err
+        }
+    }
+    if zw.Password != "" {
+        if data, err = encryptPackage(data, zw.Password); err != nil {
+            return err
+        }
+    }
+    err = os.WriteFile(filename, data, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write file %s: %w", filename, err)
+    }
+    return nil
+}
+
+
+func (zw *ZipDocxWriter) writePackage(pkg packageWriter, doc Document) error {
+    parts, err := doc.renderParts()
+    if err != nil {
+        return fmt.Errorf("failed to render document parts: %w", err)
+    }
+
+    if source := doc.getSourcePackage(); source != nil {
+        return zw.writeSourcePackage(pkg, doc, source, parts)
+    }
 
     contentTypes := types{
         Xmlns: "http:
@@ -137,9 +197,15 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
 
             {PartName: "/word/document.xml", ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"},
             {PartName: "/word/styles.xml", ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"},
+            {PartName: "/word/settings.xml", ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"},
 
         },
     }
+    for _, part := range parts {
+        if part.contentType != "" {
+            contentTypes.Overrides = append(contentTypes.Overrides, overrideType{PartName: "/" + part.name, ContentType: part.contentType})
+        }
+    }
 
 
 
@@ -155,7 +221,7 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
     }
 
 
-    err = zw.addXMLPart(zipWriter, "[Content_Types].xml", contentTypes)
+    err = zw.addXMLPart(pkg, "[Content_Types].xml", contentTypes)
     if err != nil {
         return fmt.Errorf("failed writing [Content_Types].xml: %w", err)
     }
@@ -172,7 +238,8 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
 
         },
     }
-    err = zw.addXMLPart(zipWriter, "_rels/.rels", rootRels)
+    rootRels.Relationships = packageRelationships(rootRels.Relationships, parts)
+    err = zw.addXMLPart(pkg, "_rels/.rels", rootRels)
     if err != nil {
         return fmt.Errorf("failed writing _rels/.rels: %w", err)
     }
@@ -185,31 +252,60 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
             Type:   "http:
             Target: "styles.xml",
         },
+        {
+            ID:     "rId2",
+            Type:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings",
+            Target: "settings.xml",
+        },
 
     }
 
     docRelsList = append(docRelsList, doc.getImageRelationships()...)
+    docRelsList = append(docRelsList, doc.getHyperlinkRelationships()...)
+    for _, part := range parts {
+        if part.rel != nil {
+            docRelsList = append(docRelsList, *part.rel)
+        }
+    }
 
     docRels := relationships{
         Xmlns:         "http:
         Relationships: docRelsList,
     }
-    err = zw.addXMLPart(zipWriter, "word/_rels/document.xml.rels", docRels)
+    err = zw.addXMLPart(pkg, "word/_rels/document.xml.rels", docRels)
     if err != nil {
         return fmt.Errorf("failed writing word/_rels/document.xml.rels: %w", err)
     }
 
 
-    err = zw.writeStringPart(zipWriter, "word/styles.xml", defaultStylesXML)
+    err = zw.writeStringPart(pkg, "word/styles.xml", defaultStylesXML)
     if err != nil {
         return fmt.Errorf("failed writing word/styles.xml: %w", err)
     }
 
 
+    settingsPartWriter, err := pkg.Create("word/settings.xml")
+    if err != nil {
+        return fmt.Errorf("failed to create word/settings.xml in zip: %w", err)
+    }
+    err = doc.renderSettings(settingsPartWriter)
+    if err != nil {
+        return fmt.Errorf("failed writing word/settings.xml: %w", err)
+    }
+
+
+    for _, part := range parts {
+        err = zw.writeStringPart(pkg, part.name, string(part.data))
+        if err != nil {
+            return fmt.Errorf("failed writing %s: %w", part.name, err)
+        }
+    }
+
+
     for imgFilename, imgBytes := range doc.getImages() {
 
         mediaPath := "word/media/" + imgFilename
-        err = zw.writeBytesPart(zipWriter, mediaPath, imgBytes)
+        err = zw.writeBytesPart(pkg, mediaPath, imgBytes)
         if err != nil {
 
             return fmt.Errorf("failed to write image %s to zip path %s: %w", imgFilename, mediaPath, err)
@@ -217,7 +313,7 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
     }
 
 
-    docPartWriter, err := zipWriter.Create("word/document.xml")
+    docPartWriter, err := pkg.Create("word/document.xml")
     if err != nil {
         return fmt.Errorf("failed to create word/document.xml in zip: %w", err)
     }
@@ -229,3 +325,129 @@ func (zw *ZipDocxWriter) WriteDocument(filename string, doc Document) error {
 
     return nil
 }
+
+
+func (zw *ZipDocxWriter) writeSourcePackage(pkg packageWriter, doc Document, source *sourcePackage, parts []packagePart) error {
+    contentTypes := source.contentTypes
+    contentTypes.Xmlns = "http://schemas.openxmlformats.org/package/2006/content-types"
+    contentTypes.Defaults = append([]defaultType{}, source.contentTypes.Defaults...)
+    contentTypes.Overrides = append([]overrideType{}, source.contentTypes.Overrides...)
+    partNames := append([]string{}, source.partNames...)
+    sourceRels := append([]relationship{}, source.rootRels...)
+    stripSignatureParts(&partNames, &contentTypes, &sourceRels)
+    rendered := make(map[string]bool)
+    for _, part := range parts {
+        rendered[part.name] = true
+        exists := false
+        for _, o := range contentTypes.Overrides {
+            if strings.EqualFold(strings.TrimPrefix(o.PartName, "/"), part.name) {
+                exists = true
+                break
+            }
+        }
+        if !exists && part.contentType != "" {
+            contentTypes.Overrides = append(contentTypes.Overrides, overrideType{PartName: "/" + part.name, ContentType: part.contentType})
+        }
+    }
+    for contentType, ext := range doc.getImageContentTypes() {
+        exists := false
+        for _, d := range contentTypes.Defaults {
+            if strings.EqualFold(d.Extension, ext) {
+                exists = true
+                break
+            }
+        }
+        if !exists {
+            contentTypes.Defaults = append(contentTypes.Defaults, defaultType{Extension: ext, ContentType: contentType})
+        }
+    }
+    err := zw.addXMLPart(pkg, "[Content_Types].xml", contentTypes)
+    if err != nil {
+        return fmt.Errorf("failed writing [Content_Types].xml: %w", err)
+    }
+
+
+    relsPart := source.relationshipsPart()
+    for _, name := range partNames {
+        if name == "[Content_Types].xml" || name == "_rels/.rels" || name == relsPart || name == source.documentPart || rendered[name] {
+            continue
+        }
+        err = zw.writeStringPart(pkg, name, string(source.parts[name]))
+        if err != nil {
+            return fmt.Errorf("failed writing %s: %w", name, err)
+        }
+    }
+
+    rootRels := relationships{
+        Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
+        Relationships: packageRelationships(sourceRels, parts),
+    }
+    err = zw.addXMLPart(pkg, "_rels/.rels", rootRels)
+    if err != nil {
+        return fmt.Errorf("failed writing _rels/.rels: %w", err)
+    }
+
+    docRels := relationships{
+        Xmlns:         "http://schemas.openxmlformats.org/package/2006/relationships",
+        Relationships: append(append([]relationship{}, source.documentRels...), doc.getImageRelationships()...),
+    }
+    docRels.Relationships = append(docRels.Relationships, doc.getHyperlinkRelationships()...)
+    for _, part := range parts {
+        if part.rel != nil {
+            docRels.Relationships = append(docRels.Relationships, *part.rel)
+        }
+    }
+    err = zw.addXMLPart(pkg, relsPart, docRels)
+    if err != nil {
+        return fmt.Errorf("failed writing %s: %w", relsPart, err)
+    }
+
+    for _, part := range parts {
+        err = zw.writeStringPart(pkg, part.name, string(part.data))
+        if err != nil {
+            return fmt.Errorf("failed writing %s: %w", part.name, err)
+        }
+    }
+
+    for imgFilename, imgBytes := range doc.getImages() {
+        mediaPath := path.Join(source.documentDir(), "media", imgFilename)
+        err = zw.writeBytesPart(pkg, mediaPath, imgBytes)
+        if err != nil {
+            return fmt.Errorf("failed to write image %s to zip path %s: %w", imgFilename, mediaPath, err)
+        }
+    }
+
+    docPartWriter, err := pkg.Create(source.documentPart)
+    if err != nil {
+        return fmt.Errorf("failed to create %s in zip: %w", source.documentPart, err)
+    }
+    err = doc.renderContent(docPartWriter)
+    if err != nil {
+        return fmt.Errorf("failed to render document content to zip: %w", err)
+    }
+
+    return nil
+}
+
+
+func packageRelationships(rels []relationship, parts []packagePart) []relationship {
+    rels = append([]relationship{}, rels...)
+    for _, part := range parts {
+        if part.rootRel == nil {
+            continue
+        }
+        exists := false
+        for _, rel := range rels {
+            if rel.Type == part.rootRel.Type {
+                exists = true
+                break
+            }
+        }
+        if !exists {
+            rel := *part.rootRel
+            rel.ID = nextRelationshipID(rels)
+            rels = append(rels, rel)
+        }
+    }
+    return rels
+}